@@ -32,6 +32,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/snap"
 )
 
@@ -56,6 +57,33 @@ type AppInfo struct {
 	Active      bool             `json:"active,omitempty"`
 	CommonID    string           `json:"common-id,omitempty"`
 	Activators  []AppActivator   `json:"activators,omitempty"`
+	// Health is the health of the service as reported by the health
+	// probe declared for it, if any.
+	Health *SnapHealth `json:"health,omitempty"`
+	// Restarts is the restart history of the service, as tracked by
+	// snapd, if any.
+	Restarts *AppRestarts `json:"restarts,omitempty"`
+	// Resources are the resource hints declared by the service, if any.
+	Resources *AppResources `json:"resources,omitempty"`
+}
+
+// AppResources describes the resources a service declares it needs.
+type AppResources struct {
+	Memory        quantity.Size `json:"memory,omitempty"`
+	CPUCount      int           `json:"cpu-count,omitempty"`
+	CPUPercentage int           `json:"cpu-percentage,omitempty"`
+	Threads       int           `json:"threads,omitempty"`
+}
+
+// AppRestarts describes the restarts and the last exit of a service.
+type AppRestarts struct {
+	// Count is the number of automatic restarts of the service since it
+	// was last started explicitly.
+	Count       int        `json:"count"`
+	ExitCode    int        `json:"exit-code,omitempty"`
+	ExitSignal  string     `json:"exit-signal,omitempty"`
+	LastFailure *time.Time `json:"last-failure,omitempty"`
+	CrashLoop   bool       `json:"crash-loop,omitempty"`
 }
 
 // IsService returns true if the application is a background daemon.
@@ -107,6 +135,13 @@ func (client *Client) Apps(names []string, opts AppOptions) ([]*AppInfo, error)
 type LogOptions struct {
 	N      int  // The maximum number of log lines to retrieve initially. If <0, no limit.
 	Follow bool // Whether to continue returning new lines as they appear
+
+	Since       time.Time // If set, only retrieve lines logged at or after this time
+	Until       time.Time // If set, only retrieve lines logged at or before this time
+	Priority    string    // A syslog priority, by name or number, or a FROM..TO range of them
+	Grep        string    // A regular expression the messages must match
+	Boot        string    // A boot offset (0 is the current boot, -1 the previous one) or boot ID
+	AfterCursor string    // Only retrieve lines after the one with this cursor
 }
 
 // A Log holds the information of a single syslog entry
@@ -115,6 +150,10 @@ type Log struct {
 	Message   string    `json:"message"`   // The log message itself
 	SID       string    `json:"sid"`       // The syslog identifier
 	PID       string    `json:"pid"`       // The process identifier
+
+	Unit     string `json:"unit,omitempty"`     // The systemd unit that logged the message
+	Priority *int   `json:"priority,omitempty"` // The syslog priority, from 0 (emerg) to 7 (debug)
+	Cursor   string `json:"cursor,omitempty"`   // The journal cursor, to resume reading after this line
 }
 
 // String will format the log entry with the timestamp in the local timezone
@@ -145,6 +184,24 @@ func (client *Client) Logs(names []string, opts LogOptions) (<-chan Log, error)
 	if opts.Follow {
 		query.Set("follow", strconv.FormatBool(opts.Follow))
 	}
+	if !opts.Since.IsZero() {
+		query.Set("since", opts.Since.Format(time.RFC3339Nano))
+	}
+	if !opts.Until.IsZero() {
+		query.Set("until", opts.Until.Format(time.RFC3339Nano))
+	}
+	if opts.Priority != "" {
+		query.Set("priority", opts.Priority)
+	}
+	if opts.Grep != "" {
+		query.Set("grep", opts.Grep)
+	}
+	if opts.Boot != "" {
+		query.Set("boot", opts.Boot)
+	}
+	if opts.AfterCursor != "" {
+		query.Set("after-cursor", opts.AfterCursor)
+	}
 
 	rsp, err := client.raw(context.Background(), "GET", "/v2/logs", query, nil, nil)
 	if err != nil {
@@ -375,6 +432,10 @@ type RestartOptions struct {
 	// Reload the services, if possible (i.e. if the App has a
 	// ReloadCommand, invoque it), instead of restarting.
 	Reload bool `json:"reload,omitempty"`
+	// Rolling restarts the services one snap at a time, waiting for
+	// the services of a snap to be active and healthy again before
+	// moving on to the next snap.
+	Rolling bool `json:"rolling,omitempty"`
 }
 
 // Restart services.
@@ -22,9 +22,11 @@ package client_test
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os/user"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
@@ -222,6 +224,44 @@ func (cs *clientSuite) TestClientLogsOpts(c *check.C) {
 	}
 }
 
+func (cs *clientSuite) TestClientLogsQuery(c *check.C) {
+	cs.rsp = "\x1e" + `{"message":"hello","unit":"snap.foo.svc.service","priority":3,"cursor":"s=abc;i=1"}` + "\n"
+	since := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	until := since.Add(90 * time.Minute)
+	ch, err := cs.cli.Logs([]string{"foo"}, client.LogOptions{
+		N:           -1,
+		Since:       since,
+		Until:       until,
+		Priority:    "err",
+		Grep:        "oops",
+		Boot:        "-1",
+		AfterCursor: "s=abc;i=0",
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.URL.Query(), check.DeepEquals, url.Values{
+		"names":        {"foo"},
+		"n":            {"-1"},
+		"since":        {"2024-03-01T10:00:00Z"},
+		"until":        {"2024-03-01T11:30:00Z"},
+		"priority":     {"err"},
+		"grep":         {"oops"},
+		"boot":         {"-1"},
+		"after-cursor": {"s=abc;i=0"},
+	})
+
+	var logs []client.Log
+	for log := range ch {
+		logs = append(logs, log)
+	}
+	prio := 3
+	c.Check(logs, check.DeepEquals, []client.Log{{
+		Message:  "hello",
+		Unit:     "snap.foo.svc.service",
+		Priority: &prio,
+		Cursor:   "s=abc;i=1",
+	}})
+}
+
 func (cs *clientSuite) TestClientLogsNotFound(c *check.C) {
 	cs.rsp = `{"type":"error","status-code":404,"status":"Not Found","result":{"message":"snap \"foo\" not found","kind":"snap-not-found","value":"foo"}}`
 	cs.status = 404
@@ -472,6 +512,12 @@ func (cs *clientSuite) TestClientServiceRestart(c *check.C) {
 				Reload: true,
 			},
 		},
+		{
+			names: []string{"foo_*"},
+			opts: client.RestartOptions{
+				Rolling: true,
+			},
+		},
 		{
 			names: []string{"foo"},
 			scope: []string{"user"},
@@ -521,6 +567,11 @@ func (cs *clientSuite) TestClientServiceRestart(c *check.C) {
 			} else {
 				c.Check(reqOp["reload"], check.IsNil, comment)
 			}
+			if sc.opts.Rolling {
+				c.Check(reqOp["rolling"], check.Equals, true, comment)
+			} else {
+				c.Check(reqOp["rolling"], check.IsNil, comment)
+			}
 		}
 	}
 }
@@ -670,6 +670,17 @@ type RefreshInfo struct {
 	Last     string `json:"last,omitempty"`
 	Hold     string `json:"hold,omitempty"`
 	Next     string `json:"next,omitempty"`
+	// Windows contains the refresh.windows settings.
+	Windows []RefreshWindowInfo `json:"windows,omitempty"`
+}
+
+// RefreshWindowInfo contains information about a refresh window.
+type RefreshWindowInfo struct {
+	Name     string   `json:"name"`
+	Snaps    []string `json:"snaps"`
+	Schedule string   `json:"schedule"`
+	Open     bool     `json:"open,omitempty"`
+	Next     string   `json:"next,omitempty"`
 }
 
 // SysInfo holds system information
@@ -100,7 +100,7 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 	if app.DaemonScope == snap.UserDaemon {
 		notes = append(notes, "user")
 	}
-	var seenTimer, seenSocket, seenDbus bool
+	var seenTimer, seenSocket, seenDbus, seenPath bool
 	for _, act := range app.Activators {
 		switch act.Type {
 		case "timer":
@@ -109,6 +109,8 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 			seenSocket = true
 		case "dbus":
 			seenDbus = true
+		case "path":
+			seenPath = true
 		}
 	}
 	if seenTimer {
@@ -120,6 +122,12 @@ func ClientAppInfoNotes(app *client.AppInfo) string {
 	if seenDbus {
 		notes = append(notes, "dbus-activated")
 	}
+	if seenPath {
+		notes = append(notes, "path-activated")
+	}
+	if app.Restarts != nil && app.Restarts.CrashLoop {
+		notes = append(notes, "crash-loop")
+	}
 	if len(notes) == 0 {
 		return "-"
 	}
@@ -145,6 +153,14 @@ func ClientAppInfosFromSnapAppInfos(apps []*snap.AppInfo, decorator StatusDecora
 
 		appInfo.Daemon = app.Daemon
 		appInfo.DaemonScope = app.DaemonScope
+		if app.Resources != nil {
+			appInfo.Resources = &client.AppResources{
+				Memory:        app.Resources.Memory,
+				CPUCount:      app.Resources.CPUCount,
+				CPUPercentage: app.Resources.CPUPercentage,
+				Threads:       app.Resources.Threads,
+			}
+		}
 		if !app.IsService() || decorator == nil || !app.Snap.IsActive() {
 			out = append(out, appInfo)
 			continue
@@ -30,6 +30,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -267,6 +268,42 @@ func (*cmdSuite) TestClientSnapFromSnapInfoAppsActive(c *C) {
 	c.Check(sd.calls, Equals, 1)
 }
 
+func (*cmdSuite) TestClientSnapFromSnapInfoAppsResources(c *C) {
+	si := &snap.Info{
+		SnapType: snap.TypeApp,
+		SideInfo: snap.SideInfo{
+			RealName: "the-snap",
+			Revision: snap.R(99),
+		},
+	}
+	svc := &snap.AppInfo{Snap: si, Name: "svc", Daemon: "simple", DaemonScope: snap.SystemDaemon}
+	svc.Resources = &snap.ResourceHintsInfo{
+		App:           svc,
+		Memory:        quantity.SizeGiB,
+		CPUCount:      2,
+		CPUPercentage: 50,
+		Threads:       64,
+	}
+	si.Apps = map[string]*snap.AppInfo{"svc": svc}
+
+	ci, err := clientutil.ClientSnapFromSnapInfo(si, nil)
+	c.Check(err, IsNil)
+	c.Check(ci.Apps, DeepEquals, []client.AppInfo{
+		{
+			Snap:        "the-snap",
+			Name:        "svc",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+			Resources: &client.AppResources{
+				Memory:        quantity.SizeGiB,
+				CPUCount:      2,
+				CPUPercentage: 50,
+				Threads:       64,
+			},
+		},
+	})
+}
+
 func (*cmdSuite) TestAppStatusNotes(c *C) {
 	ai := client.AppInfo{}
 	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "-")
@@ -306,6 +343,20 @@ func (*cmdSuite) TestAppStatusNotes(c *C) {
 	}
 	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "dbus-activated")
 
+	ai = client.AppInfo{
+		Daemon: "simple",
+		Activators: []client.AppActivator{
+			{Type: "path"},
+		},
+	}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "path-activated")
+
+	ai = client.AppInfo{
+		Daemon:   "simple",
+		Restarts: &client.AppRestarts{Count: 5, CrashLoop: true},
+	}
+	c.Check(clientutil.ClientAppInfoNotes(&ai), Equals, "crash-loop")
+
 	// check that the output is stable regardless of the order of activators
 	ai = client.AppInfo{
 		Daemon: "oneshot",
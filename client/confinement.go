@@ -0,0 +1,68 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ConfinementProfile holds a file that a security backend generates for a
+// snap, such as an AppArmor profile or a seccomp profile source.
+type ConfinementProfile struct {
+	Backend string `json:"backend"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	// Provenance tells which lines of the content were contributed by
+	// which plug, slot or connection. Other lines come from the templates
+	// of the backend or from the snap itself.
+	Provenance []ProfileProvenance `json:"provenance,omitempty"`
+}
+
+// ProfileProvenance holds the numbers, starting from 1, of the lines of a
+// profile contributed by a plug, slot or connection.
+type ProfileProvenance struct {
+	Source string `json:"source"`
+	Lines  []int  `json:"lines"`
+}
+
+// SnapConfinement holds the policy generated by the security backends for
+// a snap.
+type SnapConfinement struct {
+	Snap        string               `json:"snap"`
+	Confinement string               `json:"confinement"`
+	Profiles    []ConfinementProfile `json:"profiles"`
+}
+
+// SnapConfinement returns the policy generated by the security backends for
+// the given snap. If app is not empty, only the policy applying to that app
+// is returned.
+func (client *Client) SnapConfinement(name, app string) (*SnapConfinement, error) {
+	var query url.Values
+	if app != "" {
+		query = url.Values{"app": []string{app}}
+	}
+	var confinement SnapConfinement
+	path := fmt.Sprintf("/v2/snaps/%s/confinement", name)
+	if _, err := client.doSync("GET", path, query, nil, nil, &confinement); err != nil {
+		return nil, fmt.Errorf("cannot get confinement of snap %q: %w", name, err)
+	}
+	return &confinement, nil
+}
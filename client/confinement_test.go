@@ -0,0 +1,68 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package client_test
+
+import (
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+)
+
+func (cs *clientSuite) TestClientSnapConfinement(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {
+			"snap": "foo",
+			"confinement": "strict",
+			"profiles": [{
+				"backend": "apparmor",
+				"path": "/var/lib/snapd/apparmor/profiles/snap.foo.app",
+				"content": "#include <tunables/global>\n/foo r,\n",
+				"provenance": [{"source": "plug foo:home (home interface)", "lines": [2]}]
+			}]
+		}
+	}`
+	confinement, err := cs.cli.SnapConfinement("foo", "app")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "GET")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps/foo/confinement")
+	c.Check(cs.req.URL.Query().Get("app"), check.Equals, "app")
+	c.Check(confinement, check.DeepEquals, &client.SnapConfinement{
+		Snap:        "foo",
+		Confinement: "strict",
+		Profiles: []client.ConfinementProfile{{
+			Backend: "apparmor",
+			Path:    "/var/lib/snapd/apparmor/profiles/snap.foo.app",
+			Content: "#include <tunables/global>\n/foo r,\n",
+			Provenance: []client.ProfileProvenance{{
+				Source: "plug foo:home (home interface)",
+				Lines:  []int{2},
+			}},
+		}},
+	})
+}
+
+func (cs *clientSuite) TestClientSnapConfinementError(c *check.C) {
+	cs.status = 404
+	cs.rsp = `{"type": "error", "status-code": 404, "result": {"message": "snap not installed", "kind": "snap-not-found"}}`
+	_, err := cs.cli.SnapConfinement("foo", "")
+	c.Assert(err, check.ErrorMatches, `cannot get confinement of snap "foo": snap not installed`)
+	c.Check(cs.req.URL.RawQuery, check.Equals, "")
+}
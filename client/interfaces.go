@@ -73,10 +73,30 @@ type Interface struct {
 type InterfaceAction struct {
 	Action string `json:"action"`
 	Forget bool   `json:"forget,omitempty"`
+	DryRun bool   `json:"dry-run,omitempty"`
 	Plugs  []Plug `json:"plugs,omitempty"`
 	Slots  []Slot `json:"slots,omitempty"`
 }
 
+// SecurityChange describes the lines a security backend would add to or
+// remove from a file it writes for a snap.
+type SecurityChange struct {
+	Backend string   `json:"backend"`
+	Snap    string   `json:"snap"`
+	Path    string   `json:"path"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ConnectPreview describes the changes that connecting a plug to a slot
+// would make to the security policy of the affected snaps.
+type ConnectPreview struct {
+	Plug      PlugRef          `json:"plug"`
+	Slot      SlotRef          `json:"slot"`
+	Interface string           `json:"interface"`
+	Changes   []SecurityChange `json:"changes"`
+}
+
 // InterfaceOptions represents opt-in elements include in responses.
 type InterfaceOptions struct {
 	Names     []string
@@ -138,6 +158,26 @@ func (client *Client) Connect(plugSnapName, plugName, slotSnapName, slotName str
 	})
 }
 
+// ConnectDryRun returns the changes to the security policy of the affected
+// snaps that connecting the plug to the slot would make, without connecting
+// them.
+func (client *Client) ConnectDryRun(plugSnapName, plugName, slotSnapName, slotName string) (*ConnectPreview, error) {
+	b, err := json.Marshal(&InterfaceAction{
+		Action: "connect",
+		DryRun: true,
+		Plugs:  []Plug{{Snap: plugSnapName, Name: plugName}},
+		Slots:  []Slot{{Snap: slotSnapName, Name: slotName}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var preview ConnectPreview
+	if _, err := client.doSync("POST", "/v2/interfaces", nil, nil, bytes.NewReader(b), &preview); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
 // Disconnect breaks the connection between a plug and a slot.
 func (client *Client) Disconnect(plugSnapName, plugName, slotSnapName, slotName string, opts *DisconnectOptions) (changeID string, err error) {
 	return client.performInterfaceAction(&InterfaceAction{
@@ -194,6 +194,53 @@ func (cs *clientSuite) TestClientConnect(c *check.C) {
 	})
 }
 
+func (cs *clientSuite) TestClientConnectDryRun(c *check.C) {
+	cs.rsp = `{
+		"type": "sync",
+		"result": {
+			"plug": {"snap": "consumer", "plug": "plug"},
+			"slot": {"snap": "producer", "slot": "slot"},
+			"interface": "test",
+			"changes": [{"backend": "apparmor", "snap": "consumer", "path": "/var/lib/snapd/apparmor/profiles/snap.consumer.app", "added": ["/foo r,"]}]
+		}
+	}`
+	preview, err := cs.cli.ConnectDryRun("consumer", "plug", "producer", "slot")
+	c.Assert(err, check.IsNil)
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/interfaces")
+	c.Check(preview, check.DeepEquals, &client.ConnectPreview{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      client.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Changes: []client.SecurityChange{{
+			Backend: "apparmor",
+			Snap:    "consumer",
+			Path:    "/var/lib/snapd/apparmor/profiles/snap.consumer.app",
+			Added:   []string{"/foo r,"},
+		}},
+	})
+	var body map[string]interface{}
+	decoder := json.NewDecoder(cs.req.Body)
+	err = decoder.Decode(&body)
+	c.Check(err, check.IsNil)
+	c.Check(body, check.DeepEquals, map[string]interface{}{
+		"action":  "connect",
+		"dry-run": true,
+		"plugs": []interface{}{
+			map[string]interface{}{
+				"snap": "consumer",
+				"plug": "plug",
+			},
+		},
+		"slots": []interface{}{
+			map[string]interface{}{
+				"snap": "producer",
+				"slot": "slot",
+			},
+		},
+	})
+}
+
 func (cs *clientSuite) TestClientDisconnectCallsEndpoint(c *check.C) {
 	cs.cli.Disconnect("producer", "plug", "consumer", "slot", nil)
 	c.Check(cs.req.Method, check.Equals, "POST")
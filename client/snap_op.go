@@ -28,6 +28,8 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+
+	"github.com/snapcore/snapd/snap"
 )
 
 // TransactionType says whether we want to treat each snap separately
@@ -51,6 +53,7 @@ type SnapOptions struct {
 	JailMode         bool            `json:"jailmode,omitempty"`
 	Classic          bool            `json:"classic,omitempty"`
 	Dangerous        bool            `json:"dangerous,omitempty"`
+	Delta            bool            `json:"delta,omitempty"`
 	IgnoreValidation bool            `json:"ignore-validation,omitempty"`
 	IgnoreRunning    bool            `json:"ignore-running,omitempty"`
 	Unaliased        bool            `json:"unaliased,omitempty"`
@@ -102,6 +105,7 @@ func (opts *SnapOptions) writeOptionFields(mw *multipart.Writer) error {
 		{"ignore-running", opts.IgnoreRunning},
 		{"unaliased", opts.Unaliased},
 		{"prefer", opts.Prefer},
+		{"delta", opts.Delta},
 	}
 	if opts.Transaction != "" {
 		if err := mw.WriteField("transaction", string(opts.Transaction)); err != nil {
@@ -135,6 +139,7 @@ type multiActionData struct {
 	Time           string              `json:"time,omitempty"`
 	HoldLevel      string              `json:"hold-level,omitempty"`
 	Components     map[string][]string `json:"components,omitempty"`
+	DryRun         bool                `json:"dry-run,omitempty"`
 }
 
 // Install adds the snap with the given name from the given channel (or
@@ -169,6 +174,82 @@ func (client *Client) RefreshMany(names []string, options *SnapOptions) (changeI
 	return client.doMultiSnapAction("refresh", names, nil, options)
 }
 
+// RefreshReport describes what a refresh would do.
+type RefreshReport struct {
+	Snaps        []RefreshReportSnap    `json:"snaps,omitempty"`
+	Skipped      []RefreshReportSkipped `json:"skipped,omitempty"`
+	DownloadSize int64                  `json:"download-size"`
+}
+
+// RefreshReportSnap describes the impact of refreshing a single snap.
+type RefreshReportSnap struct {
+	Name            string        `json:"name"`
+	Channel         string        `json:"channel,omitempty"`
+	Version         string        `json:"version"`
+	Revision        snap.Revision `json:"revision"`
+	CurrentVersion  string        `json:"current-version"`
+	CurrentRevision snap.Revision `json:"current-revision"`
+	DownloadSize    int64         `json:"download-size"`
+
+	Base          string   `json:"base,omitempty"`
+	CurrentBase   string   `json:"current-base,omitempty"`
+	Prerequisites []string `json:"prerequisites,omitempty"`
+
+	Connections    []RefreshReportConnection `json:"connections,omitempty"`
+	Disconnections []RefreshReportConnection `json:"disconnections,omitempty"`
+
+	RestartedServices []string `json:"restarted-services,omitempty"`
+	AddedServices     []string `json:"added-services,omitempty"`
+	RemovedServices   []string `json:"removed-services,omitempty"`
+
+	ValidationSets   []string       `json:"validation-sets,omitempty"`
+	RequiredRevision *snap.Revision `json:"required-revision,omitempty"`
+
+	RunningApps  []string `json:"running-apps,omitempty"`
+	RunningHooks []string `json:"running-hooks,omitempty"`
+}
+
+// RefreshReportConnection is a connection made or removed by a refresh.
+type RefreshReportConnection struct {
+	Plug      string `json:"plug"`
+	Slot      string `json:"slot"`
+	Interface string `json:"interface"`
+}
+
+// RefreshReportSkipped is a snap that would not be refreshed.
+type RefreshReportSkipped struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// RefreshDryRun reports what refreshing the given snaps, or all snaps if
+// names is empty, would do without refreshing anything.
+func (client *Client) RefreshDryRun(names []string, options *SnapOptions) (*RefreshReport, error) {
+	action := multiActionData{
+		Action: "refresh",
+		Snaps:  names,
+		DryRun: true,
+	}
+	if options != nil {
+		action.IgnoreRunning = options.IgnoreRunning
+	}
+
+	data, err := json.Marshal(&action)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal multi-snap action: %s", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var report RefreshReport
+	if _, err := client.doSync("POST", "/v2/snaps", nil, headers, bytes.NewBuffer(data), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
 func (client *Client) HoldRefreshes(name string, options *SnapOptions) (changeID string, err error) {
 	return client.doSnapAction("hold", name, nil, options)
 }
@@ -223,10 +304,15 @@ func (client *Client) SnapshotMany(names []string, users []string) (setID uint64
 
 var ErrDangerousNotApplicable = fmt.Errorf("dangerous option only meaningful when installing from a local file")
 
+var ErrDeltaNotApplicable = fmt.Errorf("delta option only meaningful when installing from a local file")
+
 func (client *Client) doSnapAction(actionName string, snapName string, components []string, options *SnapOptions) (changeID string, err error) {
 	if options != nil && options.Dangerous {
 		return "", ErrDangerousNotApplicable
 	}
+	if options != nil && options.Delta {
+		return "", ErrDeltaNotApplicable
+	}
 
 	action := actionData{
 		Action:      actionName,
@@ -345,6 +431,9 @@ func (client *Client) Try(path string, options *SnapOptions) (changeID string, e
 	if options.Dangerous {
 		return "", ErrDangerousNotApplicable
 	}
+	if options.Delta {
+		return "", ErrDeltaNotApplicable
+	}
 
 	buf := bytes.NewBuffer(nil)
 	mw := multipart.NewWriter(buf)
@@ -33,6 +33,7 @@ import (
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -561,6 +562,40 @@ func (cs *clientSuite) TestClientOpInstallDangerous(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (cs *clientSuite) TestClientOpInstallDelta(c *check.C) {
+	cs.status = 202
+	cs.rsp = `{
+		"change": "66b3",
+		"status-code": 202,
+		"type": "async"
+	}`
+	bodyData := []byte("delta-data")
+
+	delta := filepath.Join(c.MkDir(), "foo.delta")
+	err := os.WriteFile(delta, bodyData, 0644)
+	c.Assert(err, check.IsNil)
+
+	opts := client.SnapOptions{
+		Delta: true,
+	}
+
+	// InstallPath takes Delta
+	_, err = cs.cli.InstallPath(delta, "", &opts)
+	c.Assert(err, check.IsNil)
+
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(string(body), check.Matches, "(?s).*Content-Disposition: form-data; name=\"delta\"\r\n\r\ntrue\r\n.*")
+	c.Assert(string(body), testutil.Contains, "\r\ndelta-data\r\n")
+
+	// Install and Try do not
+	_, err = cs.cli.Install("foo", nil, &opts)
+	c.Assert(err, check.Equals, client.ErrDeltaNotApplicable)
+	_, err = cs.cli.Try(c.MkDir(), &opts)
+	c.Assert(err, check.Equals, client.ErrDeltaNotApplicable)
+}
+
 func (cs *clientSuite) TestClientOpInstallUnaliased(c *check.C) {
 	cs.status = 202
 	cs.rsp = `{
@@ -865,6 +900,58 @@ func (cs *clientSuite) TestClientOpDownloadResume(c *check.C) {
 	c.Check(rc.Close(), check.IsNil)
 }
 
+func (cs *clientSuite) TestClientRefreshDryRun(c *check.C) {
+	cs.status = 200
+	cs.rsp = `{
+		"type": "sync",
+		"status-code": 200,
+		"result": {
+			"snaps": [{
+				"name": "foo",
+				"version": "2.0",
+				"revision": "2",
+				"current-version": "1.0",
+				"current-revision": "1",
+				"download-size": 1024,
+				"connections": [{"plug": "foo:net", "slot": "snapd:network", "interface": "network"}],
+				"running-apps": ["app"]
+			}],
+			"skipped": [{"name": "bar", "reason": "held"}],
+			"download-size": 1024
+		}
+	}`
+
+	report, err := cs.cli.RefreshDryRun([]string{"foo", "bar"}, &client.SnapOptions{IgnoreRunning: true})
+	c.Assert(err, check.IsNil)
+	c.Check(report, check.DeepEquals, &client.RefreshReport{
+		Snaps: []client.RefreshReportSnap{{
+			Name:            "foo",
+			Version:         "2.0",
+			Revision:        snap.R(2),
+			CurrentVersion:  "1.0",
+			CurrentRevision: snap.R(1),
+			DownloadSize:    1024,
+			Connections:     []client.RefreshReportConnection{{Plug: "foo:net", Slot: "snapd:network", Interface: "network"}},
+			RunningApps:     []string{"app"},
+		}},
+		Skipped:      []client.RefreshReportSkipped{{Name: "bar", Reason: "held"}},
+		DownloadSize: 1024,
+	})
+
+	c.Check(cs.req.Method, check.Equals, "POST")
+	c.Check(cs.req.URL.Path, check.Equals, "/v2/snaps")
+	body, err := io.ReadAll(cs.req.Body)
+	c.Assert(err, check.IsNil)
+	var jsonBody map[string]interface{}
+	c.Assert(json.Unmarshal(body, &jsonBody), check.IsNil)
+	c.Check(jsonBody, check.DeepEquals, map[string]interface{}{
+		"action":         "refresh",
+		"snaps":          []interface{}{"foo", "bar"},
+		"dry-run":        true,
+		"ignore-running": true,
+	})
+}
+
 func (cs *clientSuite) TestClientRefreshWithValidationSets(c *check.C) {
 	cs.status = 202
 	cs.rsp = `{
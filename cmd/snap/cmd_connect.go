@@ -20,13 +20,17 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/jessevdk/go-flags"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/i18n"
 )
 
 type cmdConnect struct {
 	waitMixin
+	DryRun      bool `long:"dry-run"`
 	Positionals struct {
 		PlugSpec connectPlugSpec `required:"yes"`
 		SlotSpec connectSlotSpec
@@ -52,12 +56,19 @@ $ snap connect <snap>:<plug>
 
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
+
+With --dry-run, nothing is connected. Instead, the lines that each security
+backend would add to or remove from the policy of the affected snaps are
+shown.
 `)
 
 func init() {
 	addCommand("connect", shortConnectHelp, longConnectHelp, func() flags.Commander {
 		return &cmdConnect{}
-	}, waitDescs, []argDesc{
+	}, waitDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"dry-run": i18n.G("Show the security policy changes without connecting"),
+	}), []argDesc{
 		// TRANSLATORS: This needs to begin with < and end with >
 		{name: i18n.G("<snap>:<plug>")},
 		// TRANSLATORS: This needs to begin with < and end with >
@@ -77,6 +88,15 @@ func (x *cmdConnect) Execute(args []string) error {
 		x.Positionals.PlugSpec.Snap = ""
 	}
 
+	if x.DryRun {
+		preview, err := x.client.ConnectDryRun(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
+		if err != nil {
+			return err
+		}
+		printConnectPreview(preview)
+		return nil
+	}
+
 	id, err := x.client.Connect(x.Positionals.PlugSpec.Snap, x.Positionals.PlugSpec.Name, x.Positionals.SlotSpec.Snap, x.Positionals.SlotSpec.Name)
 	if err != nil {
 		return err
@@ -91,3 +111,22 @@ func (x *cmdConnect) Execute(args []string) error {
 
 	return nil
 }
+
+func printConnectPreview(preview *client.ConnectPreview) {
+	if len(preview.Changes) == 0 {
+		fmt.Fprintf(Stdout, i18n.G("Connecting %s:%s to %s:%s (%q interface) would not change any security policy.\n"),
+			preview.Plug.Snap, preview.Plug.Name, preview.Slot.Snap, preview.Slot.Name, preview.Interface)
+		return
+	}
+	fmt.Fprintf(Stdout, i18n.G("Connecting %s:%s to %s:%s (%q interface) would change:\n"),
+		preview.Plug.Snap, preview.Plug.Name, preview.Slot.Snap, preview.Slot.Name, preview.Interface)
+	for _, change := range preview.Changes {
+		fmt.Fprintf(Stdout, "\n%s: %s (%s)\n", change.Backend, change.Snap, change.Path)
+		for _, line := range change.Removed {
+			fmt.Fprintf(Stdout, "- %s\n", line)
+		}
+		for _, line := range change.Added {
+			fmt.Fprintf(Stdout, "+ %s\n", line)
+		}
+	}
+}
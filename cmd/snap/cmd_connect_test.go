@@ -53,9 +53,14 @@ $ snap connect <snap>:<plug>
 Connects the provided plug to the slot in the core snap with a name matching
 the plug name.
 
+With --dry-run, nothing is connected. Instead, the lines that each security
+backend would add to or remove from the policy of the affected snaps are
+shown.
+
 [connect command options]
       --no-wait          Do not wait for the operation to finish but just print
                          the change id.
+      --dry-run          Show the security policy changes without connecting
 `
 	s.testSubCommandHelp(c, "connect", msg)
 }
@@ -94,6 +99,75 @@ func (s *SnapSuite) TestConnectExplicitEverything(c *C) {
 	c.Assert(rest, DeepEquals, []string{})
 }
 
+func (s *SnapSuite) TestConnectDryRun(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			c.Check(r.Method, Equals, "POST")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action":  "connect",
+				"dry-run": true,
+				"plugs": []interface{}{
+					map[string]interface{}{
+						"snap": "producer",
+						"plug": "plug",
+					},
+				},
+				"slots": []interface{}{
+					map[string]interface{}{
+						"snap": "consumer",
+						"slot": "slot",
+					},
+				},
+			})
+			fmt.Fprintln(w, `{"type":"sync", "result": {
+				"plug": {"snap": "producer", "plug": "plug"},
+				"slot": {"snap": "consumer", "slot": "slot"},
+				"interface": "test",
+				"changes": [
+					{"backend": "apparmor", "snap": "producer", "path": "/var/lib/snapd/apparmor/profiles/snap.producer.app", "added": ["/foo r,", "/bar w,"]},
+					{"backend": "udev", "snap": "producer", "path": "/etc/udev/rules.d/70-snap.producer.rules", "added": ["KERNEL==\"foo\""], "removed": ["# no rules"]}
+				]
+			}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	rest, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `Connecting producer:plug to consumer:slot ("test" interface) would change:
+
+apparmor: producer (/var/lib/snapd/apparmor/profiles/snap.producer.app)
++ /foo r,
++ /bar w,
+
+udev: producer (/etc/udev/rules.d/70-snap.producer.rules)
+- # no rules
++ KERNEL=="foo"
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestConnectDryRunNoChanges(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/interfaces":
+			fmt.Fprintln(w, `{"type":"sync", "result": {
+				"plug": {"snap": "producer", "plug": "plug"},
+				"slot": {"snap": "consumer", "slot": "slot"},
+				"interface": "test",
+				"changes": []
+			}}`)
+		default:
+			c.Fatalf("unexpected path %q", r.URL.Path)
+		}
+	})
+	_, err := Parser(Client()).ParseArgs([]string{"connect", "--dry-run", "producer:plug", "consumer:slot"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Connecting producer:plug to consumer:slot (\"test\" interface) would not change any security policy.\n")
+}
+
 func (s *SnapSuite) TestConnectExplicitPlugImplicitSlot(c *C) {
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
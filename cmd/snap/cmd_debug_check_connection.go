@@ -0,0 +1,185 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+type cmdDebugCheckConnection struct {
+	clientMixin
+
+	PlugSnapYaml flags.Filename   `long:"plug-snap-yaml"`
+	SlotSnapYaml flags.Filename   `long:"slot-snap-yaml"`
+	Assertions   []flags.Filename `long:"assertions"`
+
+	Positionals struct {
+		PlugSpec  SnapAndName `required:"yes"`
+		SlotSpec  SnapAndName `required:"yes"`
+		Interface string
+	} `positional-args:"true"`
+}
+
+var shortDebugCheckConnectionHelp = i18n.G("Explain the interface policy decision for a connection")
+var longDebugCheckConnectionHelp = i18n.G(`
+The check-connection command evaluates whether the given plug can be connected,
+manually and automatically, to the given slot according to the base
+declaration, the snap declarations and the model, and shows the steps taken
+to reach each decision. No connection is made.
+
+If the plug or slot name is omitted, the only plug or slot of the given
+interface is used.
+
+Local snap.yaml files and assertions (for example draft snap-declarations)
+can be given to be used instead of what is known to the system.
+`)
+
+func init() {
+	addDebugCommand("check-connection", shortDebugCheckConnectionHelp, longDebugCheckConnectionHelp, func() flags.Commander {
+		return &cmdDebugCheckConnection{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"plug-snap-yaml": i18n.G("Use the given snap.yaml for the plug snap"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"slot-snap-yaml": i18n.G("Use the given snap.yaml for the slot snap"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"assertions": i18n.G("Use the assertions from the given file (can be repeated)"),
+	}, []argDesc{
+		// TRANSLATORS: This needs to begin with < and end with >
+		{name: i18n.G("<snap>:<plug>")},
+		// TRANSLATORS: This needs to begin with < and end with >
+		{name: i18n.G("<snap>:<slot>")},
+		// TRANSLATORS: This needs to begin with < and end with >
+		{name: i18n.G("<interface>")},
+	})
+}
+
+type policyCheckResult struct {
+	Allowed bool     `json:"allowed"`
+	Error   string   `json:"error"`
+	Steps   []string `json:"steps"`
+}
+
+type connectionPolicyCheck struct {
+	Plug struct {
+		Snap string `json:"snap"`
+		Plug string `json:"plug"`
+	} `json:"plug"`
+	Slot struct {
+		Snap string `json:"snap"`
+		Slot string `json:"slot"`
+	} `json:"slot"`
+	Interface      string            `json:"interface"`
+	Connection     policyCheckResult `json:"connection"`
+	AutoConnection policyCheckResult `json:"auto-connection"`
+}
+
+func readOptionalFile(fn flags.Filename) (string, error) {
+	if fn == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(string(fn))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (x *cmdDebugCheckConnection) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	plugSnapYaml, err := readOptionalFile(x.PlugSnapYaml)
+	if err != nil {
+		return err
+	}
+	slotSnapYaml, err := readOptionalFile(x.SlotSnapYaml)
+	if err != nil {
+		return err
+	}
+	var assertions bytes.Buffer
+	for _, fn := range x.Assertions {
+		data, err := os.ReadFile(string(fn))
+		if err != nil {
+			return err
+		}
+		// assertions in a stream are separated by an empty line
+		if assertions.Len() > 0 {
+			assertions.WriteString("\n")
+		}
+		assertions.Write(bytes.TrimRight(data, "\n"))
+		assertions.WriteString("\n")
+	}
+
+	params := map[string]string{
+		"plug-snap": x.Positionals.PlugSpec.Snap,
+		"plug":      x.Positionals.PlugSpec.Name,
+		"slot-snap": x.Positionals.SlotSpec.Snap,
+		"slot":      x.Positionals.SlotSpec.Name,
+		"interface": x.Positionals.Interface,
+	}
+	if plugSnapYaml != "" {
+		params["plug-snap-yaml"] = plugSnapYaml
+	}
+	if slotSnapYaml != "" {
+		params["slot-snap-yaml"] = slotSnapYaml
+	}
+	if assertions.Len() > 0 {
+		params["assertions"] = assertions.String()
+	}
+
+	var check connectionPolicyCheck
+	if err := x.client.Debug("check-connection", params, &check); err != nil {
+		return err
+	}
+
+	w := tabWriter()
+	fmt.Fprintf(w, "plug:\t%s:%s\n", check.Plug.Snap, check.Plug.Plug)
+	fmt.Fprintf(w, "slot:\t%s:%s\n", check.Slot.Snap, check.Slot.Slot)
+	fmt.Fprintf(w, "interface:\t%s\n", check.Interface)
+	w.Flush()
+
+	printPolicyCheckResult(Stdout, "connection", &check.Connection)
+	printPolicyCheckResult(Stdout, "auto-connection", &check.AutoConnection)
+	return nil
+}
+
+func printPolicyCheckResult(w io.Writer, what string, res *policyCheckResult) {
+	verdict := "allowed"
+	if !res.Allowed {
+		verdict = "denied"
+	}
+	fmt.Fprintf(w, "%s: %s\n", what, verdict)
+	if res.Error != "" {
+		fmt.Fprintf(w, "  error: %s\n", res.Error)
+	}
+	fmt.Fprintln(w, "  steps:")
+	for _, step := range res.Steps {
+		fmt.Fprintf(w, "    - %s\n", step)
+	}
+}
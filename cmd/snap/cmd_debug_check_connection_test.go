@@ -0,0 +1,129 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const checkConnectionResponse = `{"type": "sync", "result": {
+  "plug": {"snap": "consumer", "plug": "plug"},
+  "slot": {"snap": "producer", "slot": "slot"},
+  "interface": "test",
+  "connection": {"allowed": true, "steps": ["checking connection of plug consumer:plug to slot producer:slot", "connection allowed"]},
+  "auto-connection": {"allowed": false, "error": "auto-connection not allowed", "steps": ["checking auto-connection of plug consumer:plug to slot producer:slot", "auto-connection denied"]}
+}}`
+
+func (s *SnapSuite) TestDebugCheckConnection(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "POST")
+			c.Check(r.URL.Path, Equals, "/v2/debug")
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "check-connection",
+				"params": map[string]interface{}{
+					"plug-snap": "consumer",
+					"plug":      "",
+					"slot-snap": "producer",
+					"slot":      "slot",
+					"interface": "test",
+				},
+			})
+			fmt.Fprintln(w, checkConnectionResponse)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-connection", "consumer", "producer:slot", "test"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `plug:       consumer:plug
+slot:       producer:slot
+interface:  test
+connection: allowed
+  steps:
+    - checking connection of plug consumer:plug to slot producer:slot
+    - connection allowed
+auto-connection: denied
+  error: auto-connection not allowed
+  steps:
+    - checking auto-connection of plug consumer:plug to slot producer:slot
+    - auto-connection denied
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugCheckConnectionLocalFiles(c *C) {
+	d := c.MkDir()
+	snapYaml := filepath.Join(d, "snap.yaml")
+	c.Assert(os.WriteFile(snapYaml, []byte("name: consumer\n"), 0644), IsNil)
+	decl1 := filepath.Join(d, "decl1.assert")
+	c.Assert(os.WriteFile(decl1, []byte("type: snap-declaration\n\nsig1\n"), 0644), IsNil)
+	decl2 := filepath.Join(d, "decl2.assert")
+	c.Assert(os.WriteFile(decl2, []byte("type: snap-declaration\n\nsig2"), 0644), IsNil)
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(DecodedRequestBody(c, r), DeepEquals, map[string]interface{}{
+				"action": "check-connection",
+				"params": map[string]interface{}{
+					"plug-snap":      "consumer",
+					"plug":           "plug",
+					"slot-snap":      "producer",
+					"slot":           "",
+					"interface":      "",
+					"plug-snap-yaml": "name: consumer\n",
+					"assertions":     "type: snap-declaration\n\nsig1\n\ntype: snap-declaration\n\nsig2\n",
+				},
+			})
+			fmt.Fprintln(w, checkConnectionResponse)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d", n+1)
+		}
+		n++
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-connection",
+		"--plug-snap-yaml", snapYaml, "--assertions", decl1, "--assertions", decl2,
+		"consumer:plug", "producer"})
+	c.Assert(err, IsNil)
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugCheckConnectionError(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "cannot check connection: boom"}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "check-connection", "consumer", "producer"})
+	c.Assert(err, ErrorMatches, "cannot check connection: boom")
+}
@@ -0,0 +1,117 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortConfinementProfileHelp = i18n.G("Print the confinement profiles of a snap or app")
+var longConfinementProfileHelp = i18n.G(`
+The confinement-profile command prints the files that the security backends
+(AppArmor, seccomp, mount, udev and others) generate for the given snap, or
+for the given app of the snap, based on the snap's plugs, slots and
+connections. Each group of lines contributed by a plug, slot or connection is
+preceded by a comment naming it, other lines come from the templates of the
+backend or from the snap itself.
+`)
+
+type cmdConfinementProfile struct {
+	clientMixin
+
+	Backend    string `long:"backend"`
+	Positional struct {
+		SnapApp string `positional-arg-name:"<snap>[.<app>]" required:"yes"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func init() {
+	addDebugCommand("confinement-profile", shortConfinementProfileHelp, longConfinementProfileHelp, func() flags.Commander {
+		return &cmdConfinementProfile{}
+	}, map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"backend": i18n.G("Only show the profiles of the given security backend"),
+	}, nil)
+}
+
+func (x *cmdConfinementProfile) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	snapName, app := x.Positional.SnapApp, ""
+	if idx := strings.IndexByte(snapName, '.'); idx >= 0 {
+		snapName, app = snapName[:idx], snapName[idx+1:]
+	}
+
+	confinement, err := x.client.SnapConfinement(snapName, app)
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, profile := range confinement.Profiles {
+		if x.Backend != "" && profile.Backend != x.Backend {
+			continue
+		}
+		if shown > 0 {
+			fmt.Fprintln(Stdout)
+		}
+		fmt.Fprintf(Stdout, "### %s: %s\n", profile.Backend, profile.Path)
+		printProfileContent(profile)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Fprintf(Stderr, i18n.G("No confinement profiles for %q.\n"), x.Positional.SnapApp)
+	}
+	return nil
+}
+
+// printProfileContent prints the content of the profile, preceding each group
+// of lines coming from the same source with a comment naming it.
+func printProfileContent(profile client.ConfinementProfile) {
+	sources := make(map[int]string)
+	for _, provenance := range profile.Provenance {
+		for _, n := range provenance.Lines {
+			sources[n] = provenance.Source
+		}
+	}
+	current, started := "", false
+	for i, line := range strings.SplitAfter(profile.Content, "\n") {
+		if line == "" {
+			continue
+		}
+		if source := sources[i+1]; source != current || !started {
+			if source != "" {
+				fmt.Fprintf(Stdout, "# from %s\n", source)
+			} else {
+				fmt.Fprintf(Stdout, "# from the %s templates\n", profile.Backend)
+			}
+			current, started = source, true
+		}
+		fmt.Fprint(Stdout, line)
+	}
+}
@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const confinementProfileResponse = `{"type": "sync", "result": {
+  "snap": "foo",
+  "confinement": "strict",
+  "profiles": [
+    {"backend": "apparmor", "path": "/var/lib/snapd/apparmor/profiles/snap.foo.app", "content": "#include <tunables/global>\n/foo r,\n/bar r,\n}\n", "provenance": [{"source": "plug foo:foo (foo interface)", "lines": [2]}, {"source": "plug foo:bar (bar interface)", "lines": [3]}]},
+    {"backend": "udev", "path": "/etc/udev/rules.d/70-snap.foo.rules", "content": "KERNEL==\"bar\"\n", "provenance": [{"source": "plug foo:bar (bar interface)", "lines": [1]}]}
+  ]
+}}`
+
+func (s *SnapSuite) TestDebugConfinementProfile(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/snaps/foo/confinement")
+			c.Check(r.URL.Query().Get("app"), Equals, "app")
+			fmt.Fprintln(w, confinementProfileResponse)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confinement-profile", "foo.app"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `### apparmor: /var/lib/snapd/apparmor/profiles/snap.foo.app
+# from the apparmor templates
+#include <tunables/global>
+# from plug foo:foo (foo interface)
+/foo r,
+# from plug foo:bar (bar interface)
+/bar r,
+# from the apparmor templates
+}
+
+### udev: /etc/udev/rules.d/70-snap.foo.rules
+# from plug foo:bar (bar interface)
+KERNEL=="bar"
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugConfinementProfileBackend(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v2/snaps/foo/confinement")
+		c.Check(r.URL.RawQuery, Equals, "")
+		fmt.Fprintln(w, confinementProfileResponse)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confinement-profile", "--backend=udev", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, `### udev: /etc/udev/rules.d/70-snap.foo.rules
+# from plug foo:bar (bar interface)
+KERNEL=="bar"
+`)
+
+	s.ResetStdStreams()
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"debug", "confinement-profile", "--backend=kmod", "foo"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No confinement profiles for \"foo\".\n")
+}
@@ -0,0 +1,146 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+)
+
+var shortDenialsHelp = i18n.G("Show the AppArmor and seccomp denials of snaps")
+var longDenialsHelp = i18n.G(`
+The denials command shows the operations of snap applications and hooks
+which were denied by AppArmor or seccomp since snapd started, the most
+recently seen first, together with the interfaces which may allow them.
+Interfaces the snap has an unconnected plug of are marked with an asterisk.
+
+Denial monitoring must be enabled with
+  snap set system experimental.denial-monitoring=true
+followed by a restart of snapd.
+`)
+
+type cmdDebugDenials struct {
+	clientMixin
+	timeMixin
+
+	Positional struct {
+		Snap installedSnapName `positional-arg-name:"<snap>"`
+	} `positional-args:"yes"`
+}
+
+type denial struct {
+	Time          time.Time `json:"time"`
+	Kind          string    `json:"kind"`
+	Snap          string    `json:"snap"`
+	App           string    `json:"app"`
+	Hook          string    `json:"hook"`
+	Path          string    `json:"path"`
+	Mask          string    `json:"mask"`
+	Capability    string    `json:"capability"`
+	Syscall       string    `json:"syscall"`
+	DBusInterface string    `json:"dbus-interface"`
+	DBusMember    string    `json:"dbus-member"`
+	Operation     string    `json:"operation"`
+	Candidates    []string  `json:"candidates"`
+	Suggestions   []string  `json:"suggestions"`
+	Count         int       `json:"count"`
+}
+
+func init() {
+	addDebugCommand("denials", shortDenialsHelp, longDenialsHelp, func() flags.Commander {
+		return &cmdDebugDenials{}
+	}, timeDescs, nil)
+}
+
+func (d *denial) source() string {
+	if d.Hook != "" {
+		return fmt.Sprintf("%s (%s hook)", d.Snap, d.Hook)
+	}
+	return d.Snap + "." + d.App
+}
+
+func (d *denial) target() string {
+	var target string
+	switch {
+	case d.Path != "":
+		target = d.Path
+	case d.Capability != "":
+		target = "capability " + d.Capability
+	case d.Syscall != "":
+		target = "syscall " + d.Syscall
+	case d.DBusInterface != "":
+		target = fmt.Sprintf("dbus %s.%s", d.DBusInterface, d.DBusMember)
+	default:
+		target = d.Operation
+	}
+	if d.Mask != "" {
+		target += " (" + d.Mask + ")"
+	}
+	return target
+}
+
+func (d *denial) interfaces() string {
+	if len(d.Candidates) == 0 {
+		return "-"
+	}
+	suggested := make(map[string]bool, len(d.Suggestions))
+	for _, iface := range d.Suggestions {
+		suggested[iface] = true
+	}
+	ifaces := make([]string, 0, len(d.Candidates))
+	for _, iface := range d.Candidates {
+		if suggested[iface] {
+			iface += "*"
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return strings.Join(ifaces, ",")
+}
+
+func (x *cmdDebugDenials) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+
+	var params map[string]string
+	if x.Positional.Snap != "" {
+		params = map[string]string{"snap": string(x.Positional.Snap)}
+	}
+	var denials []*denial
+	if err := x.client.DebugGet("denials", &denials, params); err != nil {
+		return err
+	}
+	if len(denials) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("No denials found."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, i18n.G("Time\tApp\tKind\tDenied\tCount\tInterfaces"))
+	for _, d := range denials {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", x.fmtTime(d.Time), d.source(), d.Kind, d.target(), d.Count, d.interfaces())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+const denialsResponse = `{"type": "sync", "result": [
+  {"time": "2024-01-02T03:04:05Z", "kind": "apparmor", "label": "snap.foo.app", "snap": "foo", "app": "app", "operation": "open", "path": "/dev/video0", "mask": "r", "candidates": ["camera"], "suggestions": ["camera"], "count": 3},
+  {"time": "2024-01-02T03:04:00Z", "kind": "seccomp", "label": "snap.foo.hook.configure", "snap": "foo", "hook": "configure", "syscall": "mount", "candidates": ["mount-control"], "count": 1},
+  {"time": "2024-01-02T03:03:00Z", "kind": "apparmor", "label": "snap.foo.app", "snap": "foo", "app": "app", "operation": "open", "path": "/srv/data", "mask": "w", "count": 1}
+]}`
+
+func (s *SnapSuite) TestDebugDenials(c *C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, Equals, "GET")
+			c.Check(r.URL.Path, Equals, "/v2/debug")
+			c.Check(r.URL.Query().Get("aspect"), Equals, "denials")
+			c.Check(r.URL.Query().Get("snap"), Equals, "foo")
+			fmt.Fprintln(w, denialsResponse)
+		default:
+			c.Fatalf("expected to get 1 request, now on %d", n+1)
+		}
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials", "--abs-time", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `Time                  App                   Kind      Denied           Count  Interfaces
+2024-01-02T03:04:05Z  foo.app               apparmor  /dev/video0 (r)  3      camera*
+2024-01-02T03:04:00Z  foo (configure hook)  seccomp   syscall mount    1      mount-control
+2024-01-02T03:03:00Z  foo.app               apparmor  /srv/data (w)    1      -
+`)
+	c.Check(s.Stderr(), Equals, "")
+	c.Check(n, Equals, 1)
+}
+
+func (s *SnapSuite) TestDebugDenialsNone(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Query().Get("snap"), Equals, "")
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "No denials found.\n")
+}
+
+func (s *SnapSuite) TestDebugDenialsNotEnabled(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"type": "error", "status-code": 400, "result": {"message": "denial monitoring is not enabled"}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "denials"})
+	c.Assert(err, ErrorMatches, "denial monitoring is not enabled")
+}
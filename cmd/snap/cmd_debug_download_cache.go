@@ -0,0 +1,169 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var shortDownloadCacheHelp = i18n.G("Inspect and clean the download cache")
+var longDownloadCacheHelp = i18n.G(`
+The download-cache command lists the snaps kept in the download cache of
+snapd, by sha3-384 digest, the most recently used first.
+
+Snaps which are also installed take no space of their own in the cache.
+Snaps needed by pending refreshes or changes in progress are pinned and
+never evicted.
+
+With --verify the snaps are hashed again and the corrupted ones removed.
+With --purge the given snaps, or all the snaps which are not pinned, are
+removed from the cache.
+`)
+
+type cmdDebugDownloadCache struct {
+	clientMixin
+	timeMixin
+
+	Verify bool `long:"verify"`
+	Purge  bool `long:"purge"`
+
+	Positional struct {
+		Digests []string `positional-arg-name:"<digest>"`
+	} `positional-args:"yes"`
+}
+
+type downloadCacheEntry struct {
+	Digest  string    `json:"digest"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	InUse   bool      `json:"in-use"`
+	Pinned  string    `json:"pinned"`
+}
+
+func init() {
+	addDebugCommand("download-cache", shortDownloadCacheHelp, longDownloadCacheHelp, func() flags.Commander {
+		return &cmdDebugDownloadCache{}
+	}, timeDescs.also(map[string]string{
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verify": i18n.G("Hash the cached snaps and remove the corrupted ones"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"purge": i18n.G("Remove the given snaps, or all the snaps which are not pinned"),
+	}), []argDesc{{
+		// TRANSLATORS: This needs to begin with < and end with >
+		name: i18n.G("<digest>"),
+		// TRANSLATORS: This should not start with a lowercase letter.
+		desc: i18n.G("The sha3-384 digest of a cached snap"),
+	}})
+}
+
+func (x *cmdDebugDownloadCache) Execute(args []string) error {
+	if len(args) > 0 {
+		return ErrExtraArgs
+	}
+	switch {
+	case x.Verify && x.Purge:
+		return errors.New(i18n.G("cannot use --verify and --purge together"))
+	case x.Verify:
+		if len(x.Positional.Digests) > 0 {
+			return errors.New(i18n.G("cannot verify given snaps, the whole cache is verified"))
+		}
+		return x.verify()
+	case x.Purge:
+		return x.purge()
+	case len(x.Positional.Digests) > 0:
+		return errors.New(i18n.G("digests can only be given with --purge"))
+	}
+	return x.list()
+}
+
+func (x *cmdDebugDownloadCache) list() error {
+	var entries []*downloadCacheEntry
+	if err := x.client.DebugGet("download-cache", &entries, nil); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("The download cache is empty."))
+		return nil
+	}
+
+	w := tabWriter()
+	defer w.Flush()
+	fmt.Fprintln(w, i18n.G("Digest\tSize\tUsed\tNotes"))
+	var total int64
+	for _, entry := range entries {
+		var notes []string
+		if entry.InUse {
+			notes = append(notes, i18n.G("installed"))
+		} else {
+			total += entry.Size
+		}
+		if entry.Pinned != "" {
+			notes = append(notes, fmt.Sprintf(i18n.G("pinned for %s"), entry.Pinned))
+		}
+		note := "-"
+		if len(notes) > 0 {
+			note = strings.Join(notes, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Digest, strutil.SizeToStr(entry.Size), x.fmtTime(entry.ModTime), note)
+	}
+	w.Flush()
+	fmt.Fprintf(Stdout, i18n.G("\nThe cache takes %s of its own.\n"), strutil.SizeToStr(total))
+	return nil
+}
+
+func (x *cmdDebugDownloadCache) verify() error {
+	var result struct {
+		Corrupted []string `json:"corrupted"`
+	}
+	if err := x.client.Debug("verify-download-cache", nil, &result); err != nil {
+		return err
+	}
+	if len(result.Corrupted) == 0 {
+		fmt.Fprintln(Stdout, i18n.G("All the cached snaps are valid."))
+		return nil
+	}
+	for _, digest := range result.Corrupted {
+		fmt.Fprintf(Stdout, i18n.G("Removed corrupted %s\n"), digest)
+	}
+	return nil
+}
+
+func (x *cmdDebugDownloadCache) purge() error {
+	var params struct {
+		Digests []string `json:"digests,omitempty"`
+	}
+	params.Digests = x.Positional.Digests
+	var result struct {
+		Purged []string `json:"purged"`
+	}
+	if err := x.client.Debug("purge-download-cache", params, &result); err != nil {
+		return err
+	}
+	fmt.Fprintf(Stdout, i18n.NG("Removed %d snap from the download cache.\n", "Removed %d snaps from the download cache.\n", len(result.Purged)), len(result.Purged))
+	return nil
+}
@@ -0,0 +1,107 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "gopkg.in/check.v1"
+
+	snap "github.com/snapcore/snapd/cmd/snap"
+)
+
+func (s *SnapSuite) TestDebugDownloadCache(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		c.Check(r.URL.Path, Equals, "/v2/debug")
+		c.Check(r.URL.Query().Get("aspect"), Equals, "download-cache")
+		fmt.Fprintln(w, `{"type": "sync", "result": [
+  {"digest": "aaaa", "size": 2000000, "mtime": "2024-01-02T03:04:05Z", "pinned": "pending refresh of \"foo\""},
+  {"digest": "bbbb", "size": 3000000, "mtime": "2024-01-02T03:00:00Z", "in-use": true},
+  {"digest": "cccc", "size": 1000000, "mtime": "2024-01-01T03:00:00Z"}
+]}`)
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "download-cache", "--abs-time"})
+	c.Assert(err, IsNil)
+	c.Assert(rest, DeepEquals, []string{})
+	c.Check(s.Stdout(), Equals, `Digest  Size  Used                  Notes
+aaaa    2MB   2024-01-02T03:04:05Z  pinned for pending refresh of "foo"
+bbbb    3MB   2024-01-02T03:00:00Z  installed
+cccc    1MB   2024-01-01T03:00:00Z  -
+
+The cache takes 3MB of its own.
+`)
+	c.Check(s.Stderr(), Equals, "")
+}
+
+func (s *SnapSuite) TestDebugDownloadCacheEmpty(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"type": "sync", "result": []}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "download-cache"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "")
+	c.Check(s.Stderr(), Equals, "The download cache is empty.\n")
+}
+
+func (s *SnapSuite) TestDebugDownloadCacheVerify(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		var body map[string]interface{}
+		c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		c.Check(body["action"], Equals, "verify-download-cache")
+		fmt.Fprintln(w, `{"type": "sync", "result": {"corrupted": ["aaaa"]}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "download-cache", "--verify"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Removed corrupted aaaa\n")
+}
+
+func (s *SnapSuite) TestDebugDownloadCachePurge(c *C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "POST")
+		var body map[string]interface{}
+		c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		c.Check(body, DeepEquals, map[string]interface{}{
+			"action": "purge-download-cache",
+			"params": map[string]interface{}{"digests": []interface{}{"aaaa", "bbbb"}},
+		})
+		fmt.Fprintln(w, `{"type": "sync", "result": {"purged": ["aaaa", "bbbb"]}}`)
+	})
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"debug", "download-cache", "--purge", "aaaa", "bbbb"})
+	c.Assert(err, IsNil)
+	c.Check(s.Stdout(), Equals, "Removed 2 snaps from the download cache.\n")
+}
+
+func (s *SnapSuite) TestDebugDownloadCacheErrors(c *C) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--verify", "--purge"}, "cannot use --verify and --purge together"},
+		{[]string{"--verify", "aaaa"}, "cannot verify given snaps, the whole cache is verified"},
+		{[]string{"aaaa"}, "digests can only be given with --purge"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(append([]string{"debug", "download-cache"}, tc.args...))
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
@@ -465,6 +465,9 @@ func (iw *infoWriter) maybePrintServices() {
 			enabled = "disabled"
 		}
 		services = append(services, fmt.Sprintf("  %s:\t%s, %s, %s", snap.JoinSnapApp(iw.theSnap.Name, app.Name), app.Daemon, enabled, active))
+		if iw.verbose && app.Resources != nil {
+			services = append(services, fmt.Sprintf("    resources:\t%s", fmtAppResources(app.Resources)))
+		}
 	}
 	if len(services) == 0 {
 		return
@@ -476,6 +479,26 @@ func (iw *infoWriter) maybePrintServices() {
 	}
 }
 
+// fmtAppResources formats resource hints like the constraints of quota
+// groups, e.g. memory=256MB,cpu=2x50%,threads=64
+func fmtAppResources(res *client.AppResources) string {
+	var hints []string
+	if res.Memory != 0 {
+		hints = append(hints, "memory="+strings.TrimSpace(fmtSize(int64(res.Memory))))
+	}
+	if res.CPUPercentage != 0 {
+		if res.CPUCount != 0 {
+			hints = append(hints, fmt.Sprintf("cpu=%dx%d%%", res.CPUCount, res.CPUPercentage))
+		} else {
+			hints = append(hints, fmt.Sprintf("cpu=%d%%", res.CPUPercentage))
+		}
+	}
+	if res.Threads != 0 {
+		hints = append(hints, "threads="+strconv.Itoa(res.Threads))
+	}
+	return strings.Join(hints, ",")
+}
+
 func (iw *infoWriter) maybePrintNotes() {
 	if !iw.verbose {
 		return
@@ -86,6 +86,47 @@ func (s *infoSuite) TestMaybePrintServices(c *check.C) {
 	}
 }
 
+func (s *infoSuite) TestMaybePrintServicesResources(c *check.C) {
+	infos := []client.AppInfo{
+		{
+			Name:    "svc1",
+			Daemon:  "simple",
+			Enabled: true,
+			Active:  true,
+			Resources: &client.AppResources{
+				Memory:        256 * 1000 * 1000,
+				CPUCount:      2,
+				CPUPercentage: 50,
+				Threads:       64,
+			},
+		},
+		{
+			Name:      "svc2",
+			Daemon:    "simple",
+			Resources: &client.AppResources{CPUPercentage: 25},
+		},
+	}
+
+	var buf flushBuffer
+	iw := snap.NewInfoWriter(&buf)
+	snap.SetupDiskSnap(iw, "", &client.Snap{Name: "foo", Apps: infos})
+	snap.MaybePrintServices(iw)
+	c.Check(buf.String(), check.Equals, `services:
+  foo.svc1:	simple, enabled, active
+  foo.svc2:	simple, disabled, inactive
+`)
+
+	buf.Reset()
+	snap.SetVerbose(iw, true)
+	snap.MaybePrintServices(iw)
+	c.Check(buf.String(), check.Equals, `services:
+  foo.svc1:	simple, enabled, active
+    resources:	memory=256MB,cpu=2x50%,threads=64
+  foo.svc2:	simple, disabled, inactive
+    resources:	cpu=25%
+`)
+}
+
 func (s *infoSuite) TestMaybePrintServicesNoServices(c *check.C) {
 	var buf flushBuffer
 	iw := snap.NewInfoWriter(&buf)
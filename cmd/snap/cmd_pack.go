@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/xerrors"
@@ -31,8 +32,12 @@ import (
 
 	// for SanitizePlugsSlots
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/delta"
 	"github.com/snapcore/snapd/snap/pack"
+	"github.com/snapcore/snapd/snap/snapdir"
+	"github.com/snapcore/snapd/snap/snapfile"
 )
 
 type packCmd struct {
@@ -40,6 +45,7 @@ type packCmd struct {
 	AppendVerity  bool   `long:"append-integrity-data" hidden:"yes"`
 	Filename      string `long:"filename"`
 	Compression   string `long:"compression"`
+	DeltaFrom     string `long:"delta-from"`
 	Positional    struct {
 		SnapDir   string `positional-arg-name:"<snap-dir>"`
 		TargetDir string `positional-arg-name:"<target-dir>"`
@@ -62,7 +68,13 @@ When used with --check-skeleton, pack only checks whether snap-dir contains
 valid snap metadata and raises an error otherwise. Application commands listed
 in snap metadata file, but appearing with incorrect permission bits result in an
 error. Commands that are missing from snap-dir are listed in diagnostic
-messages.`,
+messages.
+
+When used with --delta-from, pack additionally writes next to the snap a delta
+artifact turning the given, previously built revision of the same snap into
+the new one. The delta can be installed with 'snap install --delta' on systems
+that have the previous revision installed. The delta is not signed: it is only
+installed if the system knows the snap-revision assertion of the new snap.`,
 
 /*
 When used with --append-integrity-data, pack will append dm-verity data at the end
@@ -85,6 +97,8 @@ func init() {
 			"compression": i18n.G("Compression to use (e.g. xz or lzo)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"append-integrity-data": i18n.G("Generate and append dm-verity data"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"delta-from": i18n.G("Also generate a delta from this previously built snap"),
 		}, nil)
 	cmd.extra = func(cmd *flags.Command) {
 		// TRANSLATORS: this describes the default filename for a snap, e.g. core_16-2.35.2_amd64.snap
@@ -116,6 +130,15 @@ func (x *packCmd) Execute([]string) error {
 		return err
 	}
 
+	var deltaSnapName string
+	if x.DeltaFrom != "" {
+		var err error
+		deltaSnapName, err = checkDeltaSource(x.DeltaFrom, x.Positional.SnapDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	snapPath, err := pack.Pack(x.Positional.SnapDir, &pack.Options{
 		TargetDir:   x.Positional.TargetDir,
 		SnapName:    x.Filename,
@@ -130,5 +153,40 @@ func (x *packCmd) Execute([]string) error {
 	}
 	// TRANSLATORS: %s is the path to the built snap file
 	fmt.Fprintf(Stdout, i18n.G("built: %s\n"), snapPath)
+
+	if x.DeltaFrom != "" {
+		deltaPath := strings.TrimSuffix(snapPath, ".snap") + ".delta"
+		if _, err := deltaGenerate(deltaSnapName, x.DeltaFrom, snapPath, deltaPath); err != nil {
+			return fmt.Errorf(i18n.G("cannot generate delta from %q: %v"), x.DeltaFrom, err)
+		}
+		// TRANSLATORS: %s is the path to the built delta file
+		fmt.Fprintf(Stdout, i18n.G("built delta: %s\n"), deltaPath)
+	}
 	return nil
 }
+
+var deltaGenerate = delta.Generate
+
+// checkDeltaSource checks that the snap at oldSnapPath is a previous
+// build of the snap in snapDir and returns their name.
+func checkDeltaSource(oldSnapPath, snapDir string) (string, error) {
+	if !strings.HasSuffix(oldSnapPath, ".snap") || !osutil.FileExists(oldSnapPath) {
+		return "", fmt.Errorf(i18n.G("cannot use %q as delta source: not a snap file"), oldSnapPath)
+	}
+	container, err := snapfile.Open(oldSnapPath)
+	if err != nil {
+		return "", fmt.Errorf(i18n.G("cannot use %q as delta source: %v"), oldSnapPath, err)
+	}
+	oldInfo, err := snap.ReadInfoFromSnapFile(container, nil)
+	if err != nil {
+		return "", fmt.Errorf(i18n.G("cannot use %q as delta source: %v"), oldSnapPath, err)
+	}
+	newInfo, err := snap.ReadInfoFromSnapFile(snapdir.New(snapDir), nil)
+	if err != nil {
+		return "", fmt.Errorf(i18n.G("cannot pack %q: %v"), snapDir, err)
+	}
+	if oldInfo.SnapName() != newInfo.SnapName() {
+		return "", fmt.Errorf(i18n.G("cannot use %q as delta source: snap name %q does not match %q"), oldSnapPath, oldInfo.SnapName(), newInfo.SnapName())
+	}
+	return newInfo.SnapName(), nil
+}
@@ -10,6 +10,8 @@ import (
 
 	snaprun "github.com/snapcore/snapd/cmd/snap"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/snap/delta"
+	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/testutil"
 )
 
@@ -229,3 +231,64 @@ type: test
 	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", snapDir})
 	c.Assert(err, check.ErrorMatches, `.*: cannot parse component.yaml: incorrect component name "snapcomp"`)
 }
+
+func (s *SnapSuite) TestPackDeltaFrom(c *check.C) {
+	oldSnap := snaptest.MakeTestSnapWithFiles(c, "name: hello\nversion: 0.9", nil)
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 1.0")
+
+	var called int
+	restore := snaprun.MockDeltaGenerate(func(snapName, source, target, out string) (*delta.Header, error) {
+		called++
+		c.Check(snapName, check.Equals, "hello")
+		c.Check(source, check.Equals, oldSnap)
+		c.Check(target, check.Equals, filepath.Join(snapDir, "hello_1.0_all.snap"))
+		c.Check(out, check.Equals, filepath.Join(snapDir, "hello_1.0_all.delta"))
+		return &delta.Header{}, nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", oldSnap, snapDir, snapDir})
+	c.Assert(err, check.IsNil)
+	c.Check(called, check.Equals, 1)
+	c.Check(s.Stdout(), check.Matches, `(?s)built: .*/hello_1.0_all.snap\nbuilt delta: .*/hello_1.0_all.delta\n`)
+}
+
+func (s *SnapSuite) TestPackDeltaFromDifferentSnap(c *check.C) {
+	oldSnap := snaptest.MakeTestSnapWithFiles(c, "name: other\nversion: 0.9", nil)
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 1.0")
+
+	restore := snaprun.MockDeltaGenerate(func(snapName, source, target, out string) (*delta.Header, error) {
+		c.Fatal("unexpected call")
+		return nil, nil
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", oldSnap, snapDir, snapDir})
+	c.Assert(err, check.ErrorMatches, `cannot use ".*" as delta source: snap name "other" does not match "hello"`)
+
+	matches, err := filepath.Glob(snapDir + "/hello*.snap")
+	c.Assert(err, check.IsNil)
+	c.Check(matches, check.HasLen, 0)
+}
+
+func (s *SnapSuite) TestPackDeltaFromNotASnap(c *check.C) {
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 1.0")
+
+	for _, from := range []string{filepath.Join(snapDir, "missing.snap"), snapDir} {
+		_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", from, snapDir, snapDir})
+		c.Check(err, check.ErrorMatches, `cannot use ".*" as delta source: not a snap file`)
+	}
+}
+
+func (s *SnapSuite) TestPackDeltaFromFails(c *check.C) {
+	oldSnap := snaptest.MakeTestSnapWithFiles(c, "name: hello\nversion: 0.9", nil)
+	snapDir := makeSnapDirForPack(c, "name: hello\nversion: 1.0")
+
+	restore := snaprun.MockDeltaGenerate(func(snapName, source, target, out string) (*delta.Header, error) {
+		return nil, fmt.Errorf("xdelta3 failed: boom")
+	})
+	defer restore()
+
+	_, err := snaprun.Parser(snaprun.Client()).ParseArgs([]string{"pack", "--delta-from", oldSnap, snapDir, snapDir})
+	c.Assert(err, check.ErrorMatches, `cannot generate delta from ".*": xdelta3 failed: boom`)
+}
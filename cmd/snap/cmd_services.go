@@ -20,10 +20,12 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/user"
 	"strconv"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
@@ -34,19 +36,28 @@ import (
 
 type svcStatus struct {
 	clientMixin
+	timeMixin
 	Positional struct {
 		ServiceNames []serviceName
 	} `positional-args:"yes"`
-	Global bool `long:"global" short:"g"`
-	User   bool `long:"user" short:"u"`
+	Global  bool `long:"global" short:"g"`
+	User    bool `long:"user" short:"u"`
+	Verbose bool `long:"verbose"`
 }
 
 type svcLogs struct {
 	clientMixin
 	timeMixin
-	N          string `short:"n" default:"10"`
-	Follow     bool   `short:"f"`
-	Positional struct {
+	N           string `short:"n"`
+	Follow      bool   `short:"f"`
+	Since       string `long:"since"`
+	Until       string `long:"until"`
+	Priority    string `long:"priority" short:"p"`
+	Grep        string `long:"grep" short:"g"`
+	Boot        string `long:"boot" short:"b"`
+	AfterCursor string `long:"after-cursor"`
+	JSON        bool   `long:"json"`
+	Positional  struct {
 		ServiceNames []serviceName `required:"1"`
 	} `positional-args:"yes" required:"yes"`
 }
@@ -64,11 +75,21 @@ status of the user services for the root user itself, --user can be provided.
 If executed as a non-root user, the 'Startup'|'Current' status of user services 
 will be the current status for the invoking user. To view the global enablement
 status of user services, --global can be provided.
+
+With --verbose, the number of automatic restarts of each service, how its main
+process last exited and when it last failed are shown as well.
 `)
 	shortLogsHelp = i18n.G("Retrieve logs for services")
 	longLogsHelp  = i18n.G(`
 The logs command fetches logs of the given services and displays them in
 chronological order.
+
+The --since and --until options take either a time in RFC 3339 format or a
+duration such as 90m, meaning that long ago. Boot offsets are given as
+--boot=-1 for the previous boot, and so on. With --json, each line is
+printed as a JSON object that includes the journal cursor of the entry, which
+can be passed to --after-cursor to fetch only the lines logged since. Unless
+-n is given, all those lines are shown rather than only the last 10.
 `)
 	shortStartHelp = i18n.G("Start services")
 	longStartHelp  = i18n.G(`
@@ -84,6 +105,11 @@ The restart command restarts the given services.
 
 If the --reload option is given, for each service whose app has a reload
 command, a reload is performed instead of a restart.
+
+If the --rolling option is given, the snaps are restarted one at a time, each
+one only after the services of the previous one are active and, when they
+have a health probe, healthy again. The snap name can then be a pattern like
+foo_* to restart all the parallel installed instances of a snap.
 `)
 )
 
@@ -94,18 +120,34 @@ func init() {
 		// TRANSLATORS: This should not start with a lowercase letter.
 		desc: i18n.G("A service specification, which can be just a snap name (for all services in the snap), or <snap>.<app> for a single service."),
 	}}
-	addCommand("services", shortServicesHelp, longServicesHelp, func() flags.Commander { return &svcStatus{} }, map[string]string{
+	addCommand("services", shortServicesHelp, longServicesHelp, func() flags.Commander { return &svcStatus{} }, timeDescs.also(map[string]string{
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"global": i18n.G("Show the global enable status for user services instead of the status for the current user."),
 		// TRANSLATORS: This should not start with a lowercase letter.
 		"user": i18n.G("Show the current status of the user services instead of the global enable status."),
-	}, argdescs)
+		// TRANSLATORS: This should not start with a lowercase letter.
+		"verbose": i18n.G("Show the restarts and the last exit of the services."),
+	}), argdescs)
 	addCommand("logs", shortLogsHelp, longLogsHelp, func() flags.Commander { return &svcLogs{} },
 		timeDescs.also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
-			"n": i18n.G("Show only the given number of lines, or 'all'."),
+			"n": i18n.G("Show only the given number of lines, or 'all' (defaults to 10, or all with --after-cursor)."),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"f": i18n.G("Wait for new lines and print them as they come in."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"since": i18n.G("Show only lines logged at or after the given time or duration ago."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"until": i18n.G("Show only lines logged at or before the given time or duration ago."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"priority": i18n.G("Show only lines of the given syslog priority or higher, or of a FROM..TO range of priorities."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"grep": i18n.G("Show only lines whose message matches the given regular expression."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"boot": i18n.G("Show only lines of the given boot, by offset (0 is the current boot, -1 the previous one) or boot ID."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"after-cursor": i18n.G("Show only lines after the one with the given journal cursor."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"json": i18n.G("Output one JSON object per line, including the journal fields."),
 		}), argdescs)
 
 	addCommand("start", shortStartHelp, longStartHelp, func() flags.Commander { return &svcStart{} },
@@ -122,6 +164,8 @@ func init() {
 		waitDescs.also(userAndScopeDescs).also(map[string]string{
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"reload": i18n.G("If the service has a reload command, use it instead of restarting."),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"rolling": i18n.G("Restart one snap at a time, waiting for its services to be ready before moving on."),
 		}), argdescs)
 }
 
@@ -181,34 +225,111 @@ func (s *svcStatus) Execute(args []string) error {
 	w := tabWriter()
 	defer w.Flush()
 
-	fmt.Fprintln(w, i18n.G("Service\tStartup\tCurrent\tNotes"))
+	// the health column is only shown if some service has a health probe
+	withHealth := false
+	for _, svc := range services {
+		if svc.Health != nil {
+			withHealth = true
+			break
+		}
+	}
+
+	header := i18n.G("Service\tStartup\tCurrent\tNotes")
+	if withHealth {
+		header = i18n.G("Service\tStartup\tCurrent\tNotes\tHealth")
+	}
+	if s.Verbose {
+		header += "\t" + i18n.G("Restarts\tLast-exit\tLast-failure")
+	}
+	fmt.Fprintln(w, header)
 	for _, svc := range services {
-		fmt.Fprintln(w, clientutil.FmtServiceStatus(svc, isGlobal))
+		line := clientutil.FmtServiceStatus(svc, isGlobal)
+		if withHealth {
+			health := "-"
+			if svc.Health != nil {
+				health = svc.Health.Status
+			}
+			line += "\t" + health
+		}
+		if s.Verbose {
+			line += "\t" + s.fmtRestarts(svc.Restarts)
+		}
+		fmt.Fprintln(w, line)
 	}
 	return nil
 }
 
+// fmtRestarts formats the restart count, the last exit and the time of the
+// last failure of a service.
+func (s *svcStatus) fmtRestarts(restarts *client.AppRestarts) string {
+	if restarts == nil {
+		return "-\t-\t-"
+	}
+	lastExit := "-"
+	switch {
+	case restarts.ExitSignal != "":
+		lastExit = "signal=" + restarts.ExitSignal
+	case restarts.ExitCode != 0:
+		lastExit = fmt.Sprintf("code=%d", restarts.ExitCode)
+	}
+	lastFailure := "-"
+	if restarts.LastFailure != nil {
+		lastFailure = s.fmtTime(*restarts.LastFailure)
+	}
+	return fmt.Sprintf("%d\t%s\t%s", restarts.Count, lastExit, lastFailure)
+}
+
 func (s *svcLogs) Execute(args []string) error {
 	if len(args) > 0 {
 		return ErrExtraArgs
 	}
 
+	// resuming from a cursor shows all the lines logged since, unless
+	// asked otherwise
+	lines := s.N
+	if lines == "" {
+		lines = "10"
+		if s.AfterCursor != "" {
+			lines = "all"
+		}
+	}
 	sN := -1
-	if s.N != "all" {
-		n, err := strconv.ParseInt(s.N, 0, 32)
+	if lines != "all" {
+		n, err := strconv.ParseInt(lines, 0, 32)
 		if n < 0 || err != nil {
 			return errors.New(i18n.G("invalid argument for flag ‘-n’: expected a non-negative integer argument, or “all”."))
 		}
 		sN = int(n)
 	}
 
-	logs, err := s.client.Logs(svcNames(s.Positional.ServiceNames), client.LogOptions{N: sN, Follow: s.Follow})
+	opts := client.LogOptions{
+		N:           sN,
+		Follow:      s.Follow,
+		Priority:    s.Priority,
+		Grep:        s.Grep,
+		Boot:        s.Boot,
+		AfterCursor: s.AfterCursor,
+	}
+	var err error
+	if opts.Since, err = parseLogTime("since", s.Since); err != nil {
+		return err
+	}
+	if opts.Until, err = parseLogTime("until", s.Until); err != nil {
+		return err
+	}
+
+	logs, err := s.client.Logs(svcNames(s.Positional.ServiceNames), opts)
 	if err != nil {
 		return err
 	}
 
+	enc := json.NewEncoder(Stdout)
 	for log := range logs {
-		if s.AbsTime {
+		if s.JSON {
+			if err := enc.Encode(log); err != nil {
+				return err
+			}
+		} else if s.AbsTime {
 			fmt.Fprintln(Stdout, log.StringInUTC())
 		} else {
 			fmt.Fprintln(Stdout, log)
@@ -218,6 +339,22 @@ func (s *svcLogs) Execute(args []string) error {
 	return nil
 }
 
+// parseLogTime parses the value of the --since or --until flags, which can
+// either be an RFC 3339 time or a duration before now.
+func parseLogTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	dur, err := time.ParseDuration(value)
+	if err != nil || dur < 0 {
+		return time.Time{}, fmt.Errorf(i18n.G("invalid argument for flag ‘--%s’: expected a time in RFC 3339 format or a positive duration, got %q"), flag, value)
+	}
+	return timeNow().Add(-dur), nil
+}
+
 var userAndScopeDescs = mixinDescs{
 	// TRANSLATORS: This should not start with a lowercase letter.
 	"system": i18n.G("The operation should only affect system services."),
@@ -299,7 +436,8 @@ type svcRestart struct {
 	Positional struct {
 		ServiceNames []serviceName `required:"1"`
 	} `positional-args:"yes" required:"yes"`
-	Reload bool `long:"reload"`
+	Reload  bool `long:"reload"`
+	Rolling bool `long:"rolling"`
 }
 
 func (s *svcRestart) Execute(args []string) error {
@@ -310,7 +448,7 @@ func (s *svcRestart) Execute(args []string) error {
 		return err
 	}
 	names := svcNames(s.Positional.ServiceNames)
-	changeID, err := s.client.Restart(names, s.Scope(), s.Users(), client.RestartOptions{Reload: s.Reload})
+	changeID, err := s.client.Restart(names, s.Scope(), s.Users(), client.RestartOptions{Reload: s.Reload, Rolling: s.Rolling})
 	if err != nil {
 		return err
 	}
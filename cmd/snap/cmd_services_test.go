@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os/user"
 	"sort"
 	"strings"
@@ -171,6 +172,17 @@ func (s *appOpSuite) TestAppOps(c *check.C) {
 	}
 }
 
+func (s *appOpSuite) TestAppOpsRestartRolling(c *check.C) {
+	for _, extra := range [][]string{{"rolling"}, {"reload", "rolling"}} {
+		for _, noWait := range []bool{false, true} {
+			names := []string{"foo_*"}
+			s.testOpErrorResponse(c, "restart", names, extra, noWait)
+			s.testOp(c, "restart", "Restarted.", names, extra, noWait)
+			s.stdout.Reset()
+		}
+	}
+}
+
 func (s *appOpSuite) TestAppOpsScopeSwitches(c *check.C) {
 	var n int
 	var body map[string]interface{}
@@ -367,6 +379,83 @@ foo.zed  enabled  active    -
 	}
 }
 
+func (s *appOpSuite) TestAppStatusHealth(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/apps")
+			c.Check(r.Method, check.Equals, "GET")
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": [
+{"snap": "foo", "name": "db", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true,
+ "health": {"revision": "1", "timestamp": "2024-05-01T10:00:00Z", "status": "error", "code": "snapd-probe-failed", "message": "health probe failed 3 times"}},
+{"snap": "foo", "name": "web", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true,
+ "health": {"revision": "1", "timestamp": "2024-05-01T10:00:00Z", "status": "okay"}},
+{"snap": "foo", "name": "worker", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true}
+]}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	defer snap.MockUserCurrent(func() (*user.User, error) {
+		return &user.User{Uid: "0"}, nil
+	})()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"services"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Service     Startup  Current  Notes  Health
+foo.db      enabled  active   -      error
+foo.web     enabled  active   -      okay
+foo.worker  enabled  active   -      -
+`)
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *appOpSuite) TestAppStatusVerbose(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/apps")
+			c.Check(r.Method, check.Equals, "GET")
+			w.WriteHeader(200)
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": [
+{"snap": "foo", "name": "db", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true,
+ "restarts": {"count": 7, "exit-signal": "SIGSEGV", "last-failure": "2024-05-01T10:00:00Z", "crash-loop": true}},
+{"snap": "foo", "name": "web", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true,
+ "restarts": {"count": 1, "exit-code": 2, "last-failure": "2024-05-01T09:00:00Z"}},
+{"snap": "foo", "name": "worker", "daemon": "simple", "daemon-scope": "system", "active": true, "enabled": true,
+ "restarts": {"count": 0}},
+{"snap": "foo", "name": "agent", "daemon": "simple", "daemon-scope": "user", "enabled": true}
+]}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	defer snap.MockUserCurrent(func() (*user.User, error) {
+		return &user.User{Uid: "0"}, nil
+	})()
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"services", "--verbose", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Check(rest, check.HasLen, 0)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(s.Stdout(), check.Equals, `Service     Startup  Current  Notes       Restarts  Last-exit       Last-failure
+foo.db      enabled  active   crash-loop  7         signal=SIGSEGV  2024-05-01T10:00:00Z
+foo.web     enabled  active   -           1         code=2          2024-05-01T09:00:00Z
+foo.worker  enabled  active   -           0         -               -
+foo.agent   enabled  -        user        -         -               -
+`)
+	c.Check(n, check.Equals, 1)
+}
+
 func (s *appOpSuite) TestAppStatusGlobal(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -621,3 +710,95 @@ func (s *appOpSuite) TestLogsCommandWithAbsTimeFlag(c *check.C) {
 	// ensure that the fake server api was actually hit
 	c.Check(n, check.Equals, 1)
 }
+
+func (s *appOpSuite) TestLogsCommandQueryJSON(c *check.C) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	restore := snap.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/logs")
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"names":        {"snap"},
+				"n":            {"-1"},
+				"since":        {"2024-03-01T10:30:00Z"},
+				"until":        {"2024-03-01T11:00:00Z"},
+				"priority":     {"warning"},
+				"grep":         {"oops"},
+				"boot":         {"-1"},
+				"after-cursor": {"s=abc;i=1"},
+			})
+			w.WriteHeader(200)
+			_, err := w.Write([]byte{0x1E})
+			c.Assert(err, check.IsNil)
+
+			enc := json.NewEncoder(w)
+			err = enc.Encode(map[string]interface{}{
+				"timestamp": "2024-03-01T10:45:00Z",
+				"message":   "oops",
+				"sid":       "service1",
+				"pid":       "1000",
+				"unit":      "snap.snap.service1.service",
+				"priority":  4,
+				"cursor":    "s=abc;i=2",
+			})
+			c.Assert(err, check.IsNil)
+
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	// resuming from a cursor shows all the lines logged since
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap",
+		"--since", "90m", "--until", "2024-03-01T11:00:00Z", "--priority", "warning",
+		"--grep", "oops", "--boot=-1", "--after-cursor", "s=abc;i=1", "--json"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+
+	c.Check(s.Stdout(), check.Equals, `{"timestamp":"2024-03-01T10:45:00Z","message":"oops","sid":"service1","pid":"1000","unit":"snap.snap.service1.service","priority":4,"cursor":"s=abc;i=2"}`+"\n")
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *appOpSuite) TestLogsCommandAfterCursorWithN(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.URL.Path, check.Equals, "/v2/logs")
+			c.Check(r.URL.Query(), check.DeepEquals, url.Values{
+				"names":        {"snap"},
+				"n":            {"5"},
+				"after-cursor": {"s=abc;i=1"},
+			})
+			w.WriteHeader(200)
+			_, err := w.Write([]byte{0x1E})
+			c.Assert(err, check.IsNil)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+		n++
+	})
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap", "-n", "5", "--after-cursor", "s=abc;i=1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.HasLen, 0)
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *appOpSuite) TestLogsCommandBadTime(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	})
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap", "--since", "yesterday"})
+	c.Assert(err, check.ErrorMatches, `invalid argument for flag ‘--since’: expected a time in RFC 3339 format or a positive duration, got "yesterday"`)
+	_, err = snap.Parser(snap.Client()).ParseArgs([]string{"logs", "snap", "--until=-1h"})
+	c.Assert(err, check.ErrorMatches, `invalid argument for flag ‘--until’: expected a time in RFC 3339 format or a positive duration, got "-1h"`)
+}
@@ -71,6 +71,11 @@ override, taking the snap back to the current revision of the channel it's
 tracking.
 
 Use --name to set the instance name when installing from snap file.
+
+Use --delta to install delta files built with 'snap pack --delta-from'. Each
+delta is applied against the currently installed revision of its snap and the
+result must be covered by acknowledged assertions, like any other snap file
+installed without --dangerous.
 `)
 
 var longRemoveHelp = i18n.G(`
@@ -106,6 +111,10 @@ requests from 'snap refresh target-snap'.
 When snaps are specified --hold is effective on both their auto-refreshes
 and general refresh requests from 'snap refresh'. However, specific snap
 requests from 'snap refresh target-snap' remain unblocked and will proceed.
+
+Dry-run (--dry-run) shows what the refresh would do, including download sizes,
+new prerequisites, interface and service changes, validation set constraints
+and running apps that would inhibit it, without refreshing anything.
 `)
 
 var longTryHelp = i18n.G(`
@@ -695,6 +704,8 @@ type cmdInstall struct {
 	// because we released 2.14.2 with --force-dangerous
 	ForceDangerous bool `long:"force-dangerous" hidden:"yes"`
 
+	Delta bool `long:"delta"`
+
 	Unaliased bool `long:"unaliased"`
 	Prefer    bool `long:"prefer"`
 
@@ -716,7 +727,7 @@ func (x *cmdInstall) installOne(nameOrPath, desiredName string, opts *client.Sna
 	var snapName string
 	var path string
 
-	if isLocalContainer(nameOrPath) {
+	if x.Delta || isLocalContainer(nameOrPath) {
 		// don't log the request's body because the encoded snap is large.
 		x.client.SetMayLogBody(false)
 		path = nameOrPath
@@ -771,9 +782,9 @@ func isLocalContainer(name string) bool {
 }
 
 func (x *cmdInstall) installMany(names []string, opts *client.SnapOptions) error {
-	isLocal := isLocalContainer(names[0])
+	isLocal := x.Delta || isLocalContainer(names[0])
 	for _, name := range names {
-		if isLocalContainer(name) != isLocal {
+		if !x.Delta && isLocalContainer(name) != isLocal {
 			return errors.New(i18n.G("cannot install local and store snaps at the same time"))
 		}
 	}
@@ -863,10 +874,19 @@ func (x *cmdInstall) Execute([]string) error {
 	}
 
 	dangerous := x.Dangerous || x.ForceDangerous
+	if x.Delta {
+		if dangerous || x.DevMode {
+			return errors.New(i18n.G("cannot install deltas with --dangerous or --devmode"))
+		}
+		if x.Name != "" {
+			return errors.New(i18n.G("cannot use --name when installing deltas"))
+		}
+	}
 	opts := &client.SnapOptions{
 		Channel:          x.Channel,
 		Revision:         x.Revision,
 		Dangerous:        dangerous,
+		Delta:            x.Delta,
 		Unaliased:        x.Unaliased,
 		CohortKey:        x.Cohort,
 		IgnoreValidation: x.IgnoreValidation,
@@ -922,6 +942,7 @@ type cmdRefresh struct {
 	Transaction      client.TransactionType `long:"transaction" default:"per-snap" choice:"all-snaps" choice:"per-snap"`
 	Hold             string                 `long:"hold" optional:"yes" optional-value:"forever"`
 	Unhold           bool                   `long:"unhold"`
+	DryRun           bool                   `long:"dry-run"`
 	Positional       struct {
 		Snaps []installedSnapName `positional-arg-name:"<snap>"`
 	} `positional-args:"yes"`
@@ -1031,6 +1052,16 @@ func (x *cmdRefresh) showRefreshTimes() error {
 	} else {
 		fmt.Fprintf(Stdout, "next: n/a\n")
 	}
+	for _, w := range sysinfo.Refresh.Windows {
+		fmt.Fprintf(Stdout, "window %s: %s (%s)", w.Name, w.Schedule, strings.Join(w.Snaps, ", "))
+		if w.Open {
+			fmt.Fprintf(Stdout, ", open now\n")
+		} else if next := parseSysinfoTime(w.Next); !next.IsZero() {
+			fmt.Fprintf(Stdout, ", next: %s\n", x.fmtTime(next))
+		} else {
+			fmt.Fprintf(Stdout, "\n")
+		}
+	}
 	return nil
 }
 
@@ -1061,6 +1092,72 @@ func (x *cmdRefresh) listRefresh() error {
 	return nil
 }
 
+func (x *cmdRefresh) refreshDryRun(names []string, opts *client.SnapOptions) error {
+	report, err := x.client.RefreshDryRun(names, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Snaps) == 0 {
+		fmt.Fprintln(Stderr, i18n.G("All snaps up to date."))
+	} else {
+		w := tabWriter()
+		fmt.Fprintln(w, i18n.G("Name\tCurrent\tNew\tSize"))
+		for _, sn := range report.Snaps {
+			fmt.Fprintf(w, "%s\t%s (%s)\t%s (%s)\t%s\n", sn.Name, sn.CurrentVersion, sn.CurrentRevision,
+				sn.Version, sn.Revision, strutil.SizeToStr(sn.DownloadSize))
+		}
+		w.Flush()
+		fmt.Fprintf(Stdout, i18n.G("Total download size: %s\n"), strutil.SizeToStr(report.DownloadSize))
+	}
+
+	connsStr := func(conns []client.RefreshReportConnection) string {
+		l := make([]string, 0, len(conns))
+		for _, conn := range conns {
+			l = append(l, fmt.Sprintf("%s %s", conn.Plug, conn.Slot))
+		}
+		return strings.Join(l, ", ")
+	}
+	for _, sn := range report.Snaps {
+		var details []string
+		add := func(what, value string) {
+			if value != "" {
+				details = append(details, fmt.Sprintf("  %s: %s", what, value))
+			}
+		}
+		if sn.Base != sn.CurrentBase {
+			add(i18n.G("base"), fmt.Sprintf("%s -> %s", sn.CurrentBase, sn.Base))
+		}
+		add(i18n.G("prerequisites to install"), strings.Join(sn.Prerequisites, ", "))
+		add(i18n.G("connections added"), connsStr(sn.Connections))
+		add(i18n.G("connections removed"), connsStr(sn.Disconnections))
+		add(i18n.G("services restarted"), strings.Join(sn.RestartedServices, ", "))
+		add(i18n.G("services added"), strings.Join(sn.AddedServices, ", "))
+		add(i18n.G("services removed"), strings.Join(sn.RemovedServices, ", "))
+		if len(sn.ValidationSets) > 0 {
+			vsets := strings.Join(sn.ValidationSets, ", ")
+			if sn.RequiredRevision != nil {
+				vsets = fmt.Sprintf(i18n.G("%s (revision %s)"), vsets, sn.RequiredRevision)
+			}
+			add(i18n.G("required by validation sets"), vsets)
+		}
+		add(i18n.G("inhibited by running apps"), strings.Join(sn.RunningApps, ", "))
+		add(i18n.G("inhibited by running hooks"), strings.Join(sn.RunningHooks, ", "))
+		if len(details) > 0 {
+			fmt.Fprintf(Stdout, "%s:\n%s\n", sn.Name, strings.Join(details, "\n"))
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		fmt.Fprintln(Stdout, i18n.G("Skipped:"))
+		for _, sk := range report.Skipped {
+			fmt.Fprintf(Stdout, "  %s: %s\n", sk.Name, sk.Reason)
+		}
+	}
+
+	return nil
+}
+
 func (x *cmdRefresh) Execute([]string) error {
 	if err := x.setChannelFromCommandline(); err != nil {
 		return err
@@ -1091,7 +1188,7 @@ func (x *cmdRefresh) Execute([]string) error {
 
 	otherFlags := x.Amend || x.Revision != "" || x.Cohort != "" ||
 		x.LeaveCohort || x.List || x.Time || x.IgnoreValidation || x.IgnoreRunning ||
-		x.Transaction != client.TransactionPerSnap
+		x.Transaction != client.TransactionPerSnap || x.DryRun
 
 	if x.Hold != "" && (x.Unhold || otherFlags) {
 		return errors.New(i18n.G("cannot use --hold with other flags"))
@@ -1104,6 +1201,13 @@ func (x *cmdRefresh) Execute([]string) error {
 	}
 
 	names := installedSnapNames(x.Positional.Snaps)
+	if x.DryRun {
+		if x.Amend || x.Revision != "" || x.Cohort != "" || x.LeaveCohort ||
+			x.IgnoreValidation || x.asksForMode() || x.asksForChannel() {
+			return errors.New(i18n.G("--dry-run cannot be used with mode, channel, revision, cohort, amend or validation flags"))
+		}
+		return x.refreshDryRun(names, &client.SnapOptions{IgnoreRunning: x.IgnoreRunning})
+	}
 	if len(names) == 1 {
 		opts := &client.SnapOptions{
 			Amend:            x.Amend,
@@ -1503,6 +1607,8 @@ func init() {
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"force-dangerous": i18n.G("Alias for --dangerous (DEPRECATED)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
+			"delta": i18n.G("Install the given delta files against the installed revisions of their snaps"),
+			// TRANSLATORS: This should not start with a lowercase letter.
 			"unaliased": i18n.G("Install the given snap without enabling its automatic aliases"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"name": i18n.G("Install the snap file under the given instance name"),
@@ -1543,6 +1649,8 @@ func init() {
 			"hold": i18n.G("Hold refreshes for a specified duration (or forever, if no value is specified)"),
 			// TRANSLATORS: This should not start with a lowercase letter.
 			"unhold": i18n.G("Remove refresh hold"),
+			// TRANSLATORS: This should not start with a lowercase letter.
+			"dry-run": i18n.G("Show what the refresh would do without refreshing anything"),
 		}), nil)
 	addCommand("try", shortTryHelp, longTryHelp, func() flags.Commander { return &cmdTry{} }, waitDescs.also(modeDescs), nil)
 	addCommand("enable", shortEnableHelp, longEnableHelp, func() flags.Commander { return &cmdEnable{} }, waitDescs, nil)
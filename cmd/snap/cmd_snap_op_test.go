@@ -1399,6 +1399,59 @@ func (s *SnapOpSuite) TestInstallPathDangerous(c *check.C) {
 	c.Check(s.srv.n, check.Equals, s.srv.total)
 }
 
+func (s *SnapOpSuite) TestInstallPathDelta(c *check.C) {
+	s.srv.checker = func(r *http.Request) {
+		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+		form := testForm(r, c)
+		defer form.RemoveAll()
+
+		c.Check(form.Value["action"], check.DeepEquals, []string{"install"})
+		c.Check(form.Value["delta"], check.DeepEquals, []string{"true"})
+		c.Check(form.Value["snap-path"], check.DeepEquals, []string{"foo_2.delta"})
+		c.Check(form.Value["transaction"], check.NotNil)
+		c.Check(form.Value, check.HasLen, 4)
+
+		name, _, body := formFile(form, c)
+		c.Check(name, check.Equals, "snap")
+		c.Check(string(body), check.Equals, "delta-data")
+	}
+
+	s.RedirectClientToTestServer(s.srv.handle)
+	// a delta file in the current directory does not look like a
+	// local snap but --delta implies it is one
+	dir := c.MkDir()
+	err := os.WriteFile(filepath.Join(dir, "foo_2.delta"), []byte("delta-data"), 0644)
+	c.Assert(err, check.IsNil)
+	oldCwd, err := os.Getwd()
+	c.Assert(err, check.IsNil)
+	c.Assert(os.Chdir(dir), check.IsNil)
+	defer os.Chdir(oldCwd)
+
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"install", "--delta", "foo_2.delta"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `(?sm).*foo 1.0 from Bar installed`)
+	c.Check(s.Stderr(), check.Equals, "")
+	// ensure that the fake server api was actually hit
+	c.Check(s.srv.n, check.Equals, s.srv.total)
+}
+
+func (s *SnapOpSuite) TestInstallPathDeltaInvalidOptions(c *check.C) {
+	for _, t := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--dangerous"}, "cannot install deltas with --dangerous or --devmode"},
+		{[]string{"--devmode"}, "cannot install deltas with --dangerous or --devmode"},
+		{[]string{"--name", "foo_bar"}, "cannot use --name when installing deltas"},
+	} {
+		args := append([]string{"install", "--delta"}, t.args...)
+		args = append(args, "foo_2.delta")
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Check(err, check.ErrorMatches, t.err, check.Commentf("%v", t.args))
+	}
+}
+
 func (s *SnapOpSuite) TestInstallPathQuotaGroup(c *check.C) {
 	s.srv.checker = func(r *http.Request) {
 		c.Check(r.URL.Path, check.Equals, "/v2/snaps")
@@ -1770,6 +1823,71 @@ foo +4.2update1 +17 +436MB +bar +-.*
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestRefreshDryRun(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "POST")
+			c.Check(r.URL.Path, check.Equals, "/v2/snaps")
+			c.Check(DecodedRequestBody(c, r), check.DeepEquals, map[string]interface{}{
+				"action":  "refresh",
+				"snaps":   []interface{}{"foo", "bar"},
+				"dry-run": true,
+			})
+			fmt.Fprintln(w, `{"type": "sync", "result": {
+"snaps": [{"name": "foo", "version": "2.0", "revision": "12", "current-version": "1.0", "current-revision": "10", "download-size": 436375552,
+ "base": "core24", "current-base": "core22", "prerequisites": ["core24"],
+ "connections": [{"plug": "foo:net", "slot": "snapd:network", "interface": "network"}],
+ "disconnections": [{"plug": "foo:old", "slot": "snapd:home", "interface": "home"}], "restarted-services": ["svc"],
+ "validation-sets": ["16/acme/set/1"], "required-revision": "12", "running-apps": ["app"]}],
+"skipped": [{"name": "bar", "reason": "held"}],
+"download-size": 436375552}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--dry-run", "foo", "bar"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Matches, `Name +Current +New +Size
+foo +1.0 \(10\) +2.0 \(12\) +436MB
+Total download size: 436MB
+foo:
+  base: core22 -> core24
+  prerequisites to install: core24
+  connections added: foo:net snapd:network
+  connections removed: foo:old snapd:home
+  services restarted: svc
+  required by validation sets: 16/acme/set/1 \(revision 12\)
+  inhibited by running apps: app
+Skipped:
+  bar: held
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
+func (s *SnapSuite) TestRefreshDryRunConflictingFlags(c *check.C) {
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatal("expected to get 0 requests")
+	})
+
+	for _, args := range [][]string{
+		{"refresh", "--dry-run", "--beta", "foo"},
+		{"refresh", "--dry-run", "--revision=2", "foo"},
+		{"refresh", "--dry-run", "--ignore-validation", "foo"},
+	} {
+		_, err := snap.Parser(snap.Client()).ParseArgs(args)
+		c.Check(err, check.ErrorMatches, "--dry-run cannot be used with mode, channel, revision, cohort, amend or validation flags")
+	}
+
+	_, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--dry-run", "--hold"})
+	c.Check(err, check.ErrorMatches, "cannot use --hold with other flags")
+}
+
 func (s *SnapSuite) TestRefreshLegacyTime(c *check.C) {
 	n := 0
 	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
@@ -1822,6 +1940,35 @@ next: 2017-04-26T00:58:00+02:00
 	c.Check(n, check.Equals, 1)
 }
 
+func (s *SnapSuite) TestRefreshTimerWindows(c *check.C) {
+	n := 0
+	s.RedirectClientToTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 0:
+			c.Check(r.Method, check.Equals, "GET")
+			c.Check(r.URL.Path, check.Equals, "/v2/system-info")
+			fmt.Fprintln(w, `{"type": "sync", "status-code": 200, "result": {"refresh": {"timer": "0:00-24:00/4", "last": "2017-04-25T17:35:00+02:00", "next": "2017-04-26T00:58:00+02:00", "windows": [
+{"name": "databases", "snaps": ["postgres", "redis"], "schedule": "sun,02:00-04:00", "next": "2017-04-30T02:00:00+02:00"},
+{"name": "web", "snaps": ["nginx"], "schedule": "22:00-23:00", "open": true, "next": "2017-04-26T22:00:00+02:00"}]}}}`)
+		default:
+			c.Fatalf("expected to get 1 requests, now on %d", n+1)
+		}
+
+		n++
+	})
+	rest, err := snap.Parser(snap.Client()).ParseArgs([]string{"refresh", "--time", "--abs-time"})
+	c.Assert(err, check.IsNil)
+	c.Assert(rest, check.DeepEquals, []string{})
+	c.Check(s.Stdout(), check.Equals, `timer: 0:00-24:00/4
+last: 2017-04-25T17:35:00+02:00
+next: 2017-04-26T00:58:00+02:00
+window databases: sun,02:00-04:00 (postgres, redis), next: 2017-04-30T02:00:00+02:00
+window web: 22:00-23:00 (nginx), open now
+`)
+	c.Check(s.Stderr(), check.Equals, "")
+	c.Check(n, check.Equals, 1)
+}
+
 func (s *SnapSuite) TestRefreshTimeShowsHolds(c *check.C) {
 	type testcase struct {
 		in  string
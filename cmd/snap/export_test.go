@@ -35,6 +35,7 @@ import (
 	"github.com/snapcore/snapd/sandbox/selinux"
 	"github.com/snapcore/snapd/seed/seedwriter"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/delta"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/store/tooling"
 	"github.com/snapcore/snapd/testutil"
@@ -196,6 +197,10 @@ func MockStoreNew(f func(*store.Config, store.DeviceAndAuthContext) *store.Store
 	}
 }
 
+func MockDeltaGenerate(f func(snapName, source, target, out string) (*delta.Header, error)) (restore func()) {
+	return testutil.Mock(&deltaGenerate, f)
+}
+
 func MockGetEnv(f func(name string) string) (restore func()) {
 	osGetenvOrig := osGetenv
 	osGetenv = f
@@ -48,6 +48,7 @@ var api = []*Command{
 	snapFileCmd,
 	snapDownloadCmd,
 	snapConfCmd,
+	snapConfinementCmd,
 	interfacesCmd,
 	assertsCmd,
 	assertsFindManyCmd,
@@ -152,6 +153,7 @@ var (
 	snapstateTryPath                        = snapstate.TryPath
 	snapstateUpdate                         = snapstate.Update
 	snapstateUpdateMany                     = snapstate.UpdateMany
+	snapstateRefreshDryRun                  = snapstate.RefreshDryRun
 	snapstateRemove                         = snapstate.Remove
 	snapstateRemoveMany                     = snapstate.RemoveMany
 	snapstateResolveValSetsEnforcementError = snapstate.ResolveValidationSetsEnforcementError
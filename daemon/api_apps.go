@@ -27,17 +27,23 @@ import (
 	"net/http"
 	"net/url"
 	"os/user"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/systemd"
 )
 
 var (
@@ -108,9 +114,86 @@ func getAppsInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 		return InternalError("%v", err)
 	}
 
+	st := c.d.overlord.State()
+	st.Lock()
+	err = decorateWithHealth(st, clientAppInfos)
+	st.Unlock()
+	if err != nil {
+		return InternalError("cannot get health of services: %v", err)
+	}
+	st.Lock()
+	err = decorateWithRestarts(st, clientAppInfos)
+	st.Unlock()
+	if err != nil {
+		return InternalError("cannot get restarts of services: %v", err)
+	}
+
 	return SyncResponse(clientAppInfos)
 }
 
+// decorateWithHealth sets the health of the services that have a health
+// probe.
+func decorateWithHealth(st *state.State, appInfos []client.AppInfo) error {
+	healths := make(map[string]map[string]*healthstate.HealthState)
+	for i := range appInfos {
+		app := &appInfos[i]
+		if !app.IsService() {
+			continue
+		}
+		appHealths, ok := healths[app.Snap]
+		if !ok {
+			var err error
+			appHealths, err = healthstate.AppHealth(st, app.Snap)
+			if err != nil {
+				return err
+			}
+			healths[app.Snap] = appHealths
+		}
+		app.Health = clientHealthFromHealthstate(appHealths[app.Name])
+	}
+	return nil
+}
+
+// decorateWithRestarts sets the restart history of the services, as
+// tracked by the service manager.
+func decorateWithRestarts(st *state.State, appInfos []client.AppInfo) error {
+	histories := make(map[string]map[string]*servicestate.RestartHistory)
+	for i := range appInfos {
+		app := &appInfos[i]
+		if !app.IsService() {
+			continue
+		}
+		appHistories, ok := histories[app.Snap]
+		if !ok {
+			var err error
+			appHistories, err = servicestate.RestartHistories(st, app.Snap)
+			if err != nil {
+				return err
+			}
+			histories[app.Snap] = appHistories
+		}
+		app.Restarts = clientRestartsFromHistory(appHistories[app.Name])
+	}
+	return nil
+}
+
+func clientRestartsFromHistory(h *servicestate.RestartHistory) *client.AppRestarts {
+	if h == nil {
+		return nil
+	}
+	restarts := &client.AppRestarts{
+		Count:      h.Restarts,
+		ExitCode:   h.ExitCode,
+		ExitSignal: h.ExitSignal,
+		CrashLoop:  h.CrashLoop,
+	}
+	if !h.LastFailure.IsZero() {
+		lastFailure := h.LastFailure
+		restarts.LastFailure = &lastFailure
+	}
+	return restarts
+}
+
 type appInfoOptions struct {
 	service bool
 }
@@ -215,6 +298,10 @@ func splitAppName(s string) (snap, app string) {
 func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 	query := r.URL.Query()
 	n := 10
+	if query.Get("after-cursor") != "" {
+		// resuming from a cursor returns all the lines logged since
+		n = -1
+	}
 	if s := query.Get("n"); s != "" {
 		m, err := strconv.ParseInt(s, 0, 32)
 		if err != nil {
@@ -230,6 +317,10 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 		}
 		follow = f
 	}
+	logQuery, rspe := logQueryFromQuery(query)
+	if rspe != nil {
+		return rspe
+	}
 
 	// only services have logs for now
 	opts := appInfoOptions{service: true}
@@ -241,7 +332,11 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 		return AppNotFound("no matching services")
 	}
 
-	reader, err := servicestate.LogReader(appInfos, n, follow)
+	if err := logQuery.Validate(); err != nil {
+		return BadRequest("invalid log query: %v", err)
+	}
+
+	reader, err := servicestate.LogReader(appInfos, n, follow, logQuery)
 	if err != nil {
 		return InternalError("cannot get logs: %v", err)
 	}
@@ -252,6 +347,33 @@ func getLogs(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 }
 
+func logQueryFromQuery(query url.Values) (*systemd.LogQuery, *apiError) {
+	logQuery := &systemd.LogQuery{
+		Priority:    query.Get("priority"),
+		Grep:        query.Get("grep"),
+		Boot:        query.Get("boot"),
+		AfterCursor: query.Get("after-cursor"),
+	}
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{
+		{"since", &logQuery.Since},
+		{"until", &logQuery.Until},
+	} {
+		s := query.Get(param.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, BadRequest(`invalid value for %s: %q: %v`, param.name, s, err)
+		}
+		*param.t = t
+	}
+	return logQuery, nil
+}
+
 var servicestateControl = servicestate.Control
 
 func decodeServiceInstruction(body io.ReadCloser, u *user.User) (*servicestate.Instruction, error) {
@@ -297,6 +419,11 @@ func postApps(c *Command, r *http.Request, user *auth.UserState) Response {
 	}
 
 	st := c.d.overlord.State()
+	names, rspe := expandInstanceWildcards(st, inst.Names)
+	if rspe != nil {
+		return rspe
+	}
+	inst.Names = names
 	appInfos, rspe := appInfosFor(st, inst.Names, appInfoOptions{service: true})
 	if rspe != nil {
 		return rspe
@@ -333,6 +460,54 @@ func postApps(c *Command, r *http.Request, user *auth.UserState) Response {
 	return AsyncResponse(nil, chg.ID())
 }
 
+// expandInstanceWildcards expands names with a shell pattern in their snap
+// part, like "foo_*" or "foo_*.app", into the matching installed snap
+// instances, keeping other names as they are.
+func expandInstanceWildcards(st *state.State, names []string) ([]string, *apiError) {
+	var instanceNames []string
+	expanded := make([]string, 0, len(names))
+	for _, name := range names {
+		snapName, appName := splitAppName(name)
+		if !strings.ContainsAny(snapName, "*?[") {
+			expanded = append(expanded, name)
+			continue
+		}
+		if instanceNames == nil {
+			st.Lock()
+			snapStates, err := snapstate.All(st)
+			st.Unlock()
+			if err != nil {
+				return nil, InternalError("cannot list local snaps! %v", err)
+			}
+			instanceNames = make([]string, 0, len(snapStates))
+			for instanceName := range snapStates {
+				instanceNames = append(instanceNames, instanceName)
+			}
+			sort.Strings(instanceNames)
+		}
+		var matched bool
+		for _, instanceName := range instanceNames {
+			ok, err := filepath.Match(snapName, instanceName)
+			if err != nil {
+				return nil, BadRequest("invalid snap name pattern %q: %v", snapName, err)
+			}
+			if !ok {
+				continue
+			}
+			matched = true
+			if appName != "" {
+				expanded = append(expanded, instanceName+"."+appName)
+			} else {
+				expanded = append(expanded, instanceName)
+			}
+		}
+		if !matched {
+			return nil, SnapNotFound(snapName, fmt.Errorf("no snap matches %q", snapName))
+		}
+	}
+	return expanded, nil
+}
+
 func namesToSnapNames(inst *servicestate.Instruction) []string {
 	seen := make(map[string]struct{}, len(inst.Names))
 	for _, snapOrSnapDotApp := range inst.Names {
@@ -33,12 +33,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -66,6 +68,7 @@ type appsSuite struct {
 	jctlNamespaces     []bool
 	jctlRCs            []io.ReadCloser
 	jctlErrs           []error
+	jctlQueries        []*systemd.LogQuery
 	decoratorResults   map[string]appsSuiteDecoratorResult
 
 	serviceControlError error
@@ -74,8 +77,9 @@ type appsSuite struct {
 	infoA, infoB, infoC, infoD, infoE *snap.Info
 }
 
-func (s *appsSuite) journalctl(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+func (s *appsSuite) journalctl(svcs []string, n int, follow, namespaces bool, query *systemd.LogQuery) (rc io.ReadCloser, err error) {
 	s.jctlSvcses = append(s.jctlSvcses, svcs)
+	s.jctlQueries = append(s.jctlQueries, query)
 	s.jctlNs = append(s.jctlNs, n)
 	s.jctlFollows = append(s.jctlFollows, follow)
 	s.jctlNamespaces = append(s.jctlNamespaces, namespaces)
@@ -116,6 +120,9 @@ func (s *appsSuite) fakeServiceControl(st *state.State, appInfos []*snap.AppInfo
 	if inst.RestartOptions.Reload {
 		serviceCommand.options = "reload"
 	}
+	if inst.RestartOptions.Rolling {
+		serviceCommand.options += "rolling"
+	}
 	// only one flag should ever be set (depending on Action), but appending
 	// them below acts as an extra validity check.
 	if inst.StartOptions.Enable {
@@ -155,6 +162,7 @@ func (s *appsSuite) SetUpTest(c *check.C) {
 	s.jctlNamespaces = nil
 	s.jctlRCs = nil
 	s.jctlErrs = nil
+	s.jctlQueries = nil
 
 	d := s.daemon(c)
 
@@ -365,6 +373,98 @@ func (s *appsSuite) TestGetAppsInfoServices(c *check.C) {
 	c.Check(sort.StringsAreSorted(appNames), check.Equals, true)
 }
 
+func (s *appsSuite) TestGetAppsInfoServicesHealth(c *check.C) {
+	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
+		return s
+	})
+	defer r()
+	s.decoratorResults = map[string]appsSuiteDecoratorResult{
+		"snap-a.svc1": {daemonType: "simple", active: true, enabled: true},
+		"snap-a.svc2": {daemonType: "simple", active: true, enabled: true},
+	}
+
+	timestamp := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	st := s.d.Overlord().State()
+	st.Lock()
+	st.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"snap-a": {
+			"svc1": {
+				Revision:  snap.R(1),
+				Timestamp: timestamp,
+				Status:    healthstate.ErrorStatus,
+				Code:      "snapd-probe-failed",
+				Message:   "health probe failed 3 times: connection refused",
+			},
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/apps?select=service&names=snap-a", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	svcs := rsp.Result.([]client.AppInfo)
+	c.Assert(svcs, check.HasLen, 2)
+	c.Check(svcs[0].Name, check.Equals, "svc1")
+	c.Check(svcs[0].Health, check.DeepEquals, &client.SnapHealth{
+		Revision:  snap.R(1),
+		Timestamp: timestamp,
+		Status:    "error",
+		Code:      "snapd-probe-failed",
+		Message:   "health probe failed 3 times: connection refused",
+	})
+	c.Check(svcs[1].Name, check.Equals, "svc2")
+	c.Check(svcs[1].Health, check.IsNil)
+}
+
+func (s *appsSuite) TestGetAppsInfoServicesRestarts(c *check.C) {
+	r := daemon.MockNewStatusDecorator(func(ctx context.Context, isGlobal bool, uid string) clientutil.StatusDecorator {
+		return s
+	})
+	defer r()
+	s.decoratorResults = map[string]appsSuiteDecoratorResult{
+		"snap-a.svc1": {daemonType: "simple", active: true, enabled: true},
+		"snap-a.svc2": {daemonType: "simple", active: true, enabled: true},
+	}
+
+	lastFailure := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	st := s.d.Overlord().State()
+	st.Lock()
+	st.Set("service-restarts", map[string]map[string]*servicestate.RestartHistory{
+		"snap-a": {
+			"svc1": {
+				Revision:    snap.R(1),
+				Restarts:    7,
+				ExitSignal:  "SIGSEGV",
+				LastFailure: lastFailure,
+				CrashLoop:   true,
+			},
+			"svc2": {
+				Revision: snap.R(1),
+			},
+		},
+	})
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/apps?select=service&names=snap-a", nil)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Status, check.Equals, 200)
+	svcs := rsp.Result.([]client.AppInfo)
+	c.Assert(svcs, check.HasLen, 2)
+	c.Check(svcs[0].Name, check.Equals, "svc1")
+	c.Check(svcs[0].Restarts, check.DeepEquals, &client.AppRestarts{
+		Count:       7,
+		ExitSignal:  "SIGSEGV",
+		LastFailure: &lastFailure,
+		CrashLoop:   true,
+	})
+	c.Check(svcs[1].Name, check.Equals, "svc2")
+	c.Check(svcs[1].Restarts, check.DeepEquals, &client.AppRestarts{})
+}
+
 func (s *appsSuite) TestGetAppsInfoServicesWithGlobal(c *check.C) {
 	// System services from active snaps
 	svcNames := []string{"snap-a.svc1", "snap-a.svc2"}
@@ -771,6 +871,46 @@ func (s *appsSuite) TestPostAppsReload(c *check.C) {
 	s.testPostApps(c, inst, expected)
 }
 
+func (s *appsSuite) TestPostAppsRollingRestartWildcard(c *check.C) {
+	inst := servicestate.Instruction{Action: "restart", Names: []string{"snap-[ab]"}}
+	inst.Rolling = true
+	expected := []serviceControlArgs{
+		{action: "restart", options: "rolling", names: []string{"snap-a.svc1", "snap-a.svc2", "snap-b.svc3"}, scope: client.ScopeSelector{"system", "user"}},
+	}
+	chg := s.testPostApps(c, inst, expected)
+	chg.State().Lock()
+	defer chg.State().Unlock()
+
+	var names []string
+	err := chg.Get("snap-names", &names)
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"snap-a", "snap-b"})
+}
+
+func (s *appsSuite) TestPostAppsWildcardApp(c *check.C) {
+	inst := servicestate.Instruction{Action: "restart", Names: []string{"snap-a*.svc2"}}
+	expected := []serviceControlArgs{
+		{action: "restart", names: []string{"snap-a.svc2"}, scope: client.ScopeSelector{"system", "user"}},
+	}
+	s.testPostApps(c, inst, expected)
+}
+
+func (s *appsSuite) TestPostAppsWildcardNoMatch(c *check.C) {
+	req, err := http.NewRequest("POST", "/v2/apps", bytes.NewBufferString(`{"action": "restart", "names": ["foo_*"]}`))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Message, check.Equals, `no snap matches "foo_*"`)
+}
+
+func (s *appsSuite) TestPostAppsWildcardBadPattern(c *check.C) {
+	req, err := http.NewRequest("POST", "/v2/apps", bytes.NewBufferString(`{"action": "restart", "names": ["snap-["]}`))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Matches, `invalid snap name pattern "snap-\[": .*`)
+}
+
 func (s *appsSuite) TestPostAppsEnableNow(c *check.C) {
 	inst := servicestate.Instruction{Action: "start", Names: []string{"snap-a.svc2"}}
 	inst.Enable = true
@@ -1078,6 +1218,83 @@ func (s *appsSuite) TestLogsN(c *check.C) {
 	}
 }
 
+func (s *appsSuite) TestLogsQuery(c *check.C) {
+	s.expectLogsAccess()
+
+	s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(`
+{"MESSAGE": "hello1", "SYSLOG_IDENTIFIER": "xyzzy", "_PID": "42", "__REALTIME_TIMESTAMP": "42", "_SYSTEMD_UNIT": "snap.snap-a.svc2.service", "PRIORITY": "3", "__CURSOR": "s=abc;i=2"}
+	`))}
+
+	req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2&n=-1&since=2024-03-01T10:00:00Z&until=2024-03-01T11:30:00.5Z&priority=err..warning&grep=oops&boot=-1&after-cursor=s%3Dabc%3Bi%3D1", nil)
+	c.Assert(err, check.IsNil)
+
+	rec := httptest.NewRecorder()
+	s.req(c, req, nil).ServeHTTP(rec, req)
+
+	c.Check(s.jctlSvcses, check.DeepEquals, [][]string{{"snap.snap-a.svc2.service"}})
+	c.Check(s.jctlNs, check.DeepEquals, []int{-1})
+	c.Assert(s.jctlQueries, check.HasLen, 1)
+	q := s.jctlQueries[0]
+	c.Check(q.Since.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)), check.Equals, true)
+	c.Check(q.Until.Equal(time.Date(2024, 3, 1, 11, 30, 0, 5e8, time.UTC)), check.Equals, true)
+	c.Check(q.Priority, check.Equals, "err..warning")
+	c.Check(q.Grep, check.Equals, "oops")
+	c.Check(q.Boot, check.Equals, "-1")
+	c.Check(q.AfterCursor, check.Equals, "s=abc;i=1")
+
+	c.Check(rec.Code, check.Equals, 200)
+	c.Check(rec.Body.String(), check.Equals, "\x1e"+`{"timestamp":"1970-01-01T00:00:00.000042Z","message":"hello1","sid":"xyzzy","pid":"42","unit":"snap.snap-a.svc2.service","priority":3,"cursor":"s=abc;i=2"}`+"\n")
+}
+
+func (s *appsSuite) TestLogsAfterCursorNoTail(c *check.C) {
+	s.expectLogsAccess()
+
+	for _, t := range []struct {
+		query string
+		n     int
+	}{
+		// all the lines after the cursor by default
+		{"after-cursor=s%3Dabc%3Bi%3D1", -1},
+		{"after-cursor=s%3Dabc%3Bi%3D1&n=5", 5},
+		{"", 10},
+	} {
+		s.jctlNs = nil
+		s.jctlRCs = []io.ReadCloser{io.NopCloser(strings.NewReader(""))}
+
+		req, err := http.NewRequest("GET", "/v2/logs?names=snap-a.svc2&"+t.query, nil)
+		c.Assert(err, check.IsNil)
+
+		rec := httptest.NewRecorder()
+		s.req(c, req, nil).ServeHTTP(rec, req)
+
+		c.Check(rec.Code, check.Equals, 200, check.Commentf("%q", t.query))
+		c.Check(s.jctlNs, check.DeepEquals, []int{t.n}, check.Commentf("%q", t.query))
+	}
+}
+
+func (s *appsSuite) TestLogsBadQuery(c *check.C) {
+	s.expectLogsAccess()
+
+	for _, t := range []struct {
+		query string
+		err   string
+	}{
+		{"since=yesterday", `invalid value for since: "yesterday": .*`},
+		{"until=10:00", `invalid value for until: "10:00": .*`},
+		{"since=2024-03-01T10:00:00Z&until=2024-03-01T09:00:00Z", `invalid log query: log query end time is before its start time`},
+		{"priority=loud", `invalid log query: invalid log priority "loud"`},
+		{"boot=last", `invalid log query: invalid boot "last": expected an offset or a boot ID`},
+	} {
+		req, err := http.NewRequest("GET", "/v2/logs?"+t.query, nil)
+		c.Assert(err, check.IsNil)
+
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400, check.Commentf(t.query))
+		c.Check(rspe.Message, check.Matches, t.err, check.Commentf(t.query))
+	}
+	c.Check(s.jctlQueries, check.HasLen, 0)
+}
+
 func (s *appsSuite) TestLogsBadN(c *check.C) {
 	s.expectLogsAccess()
 
@@ -52,6 +52,10 @@ type debugAction struct {
 		ChgID string `json:"chg-id"`
 
 		RecoverySystemLabel string `json:"recovery-system-label"`
+
+		Digests []string `json:"digests"`
+
+		checkConnectionParams
 	} `json:"params"`
 	Snaps []string `json:"snaps"`
 }
@@ -370,6 +374,10 @@ func getDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return getGadgetDiskMapping(st)
 	case "disks":
 		return getDisks(st)
+	case "denials":
+		return getDenials(c, query.Get("snap"))
+	case "download-cache":
+		return getDownloadCache(st)
 	default:
 		return BadRequest("unknown debug aspect %q", aspect)
 	}
@@ -382,6 +390,12 @@ func postDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("cannot decode request body into a debug action: %v", err)
 	}
 
+	// hashing the download cache can take a while and needs nothing from
+	// the state, so it happens without holding the lock
+	if a.Action == "verify-download-cache" {
+		return verifyDownloadCache()
+	}
+
 	st := c.d.overlord.State()
 	st.Lock()
 	defer st.Unlock()
@@ -411,6 +425,10 @@ func postDebug(c *Command, r *http.Request, user *auth.UserState) Response {
 		return createRecovery(st, a.Params.RecoverySystemLabel)
 	case "migrate-home":
 		return migrateHome(st, a.Snaps)
+	case "check-connection":
+		return checkConnection(st, &a.Params.checkConnectionParams)
+	case "purge-download-cache":
+		return purgeDownloadCache(st, a.Params.Digests)
 	default:
 		return BadRequest("unknown debug action: %v", a.Action)
 	}
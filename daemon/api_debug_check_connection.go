@@ -0,0 +1,99 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+var ifacestateCheckConnectionPolicy = ifacestate.CheckConnectionPolicy
+
+// checkConnectionParams are the parameters of the check-connection debug
+// action.
+type checkConnectionParams struct {
+	PlugSnap  string `json:"plug-snap"`
+	Plug      string `json:"plug"`
+	SlotSnap  string `json:"slot-snap"`
+	Slot      string `json:"slot"`
+	Interface string `json:"interface"`
+
+	// PlugSnapYaml and SlotSnapYaml optionally hold snap.yaml contents to
+	// use instead of the installed snaps.
+	PlugSnapYaml string `json:"plug-snap-yaml"`
+	SlotSnapYaml string `json:"slot-snap-yaml"`
+	// Assertions optionally holds a stream of assertions to use instead of
+	// the ones known to the system.
+	Assertions string `json:"assertions"`
+}
+
+func checkConnection(st *state.State, params *checkConnectionParams) Response {
+	if params.PlugSnap == "" || params.SlotSnap == "" {
+		return BadRequest("cannot check connection: plug and slot snaps must be provided")
+	}
+
+	opts := &ifacestate.ConnectionPolicyCheckOptions{}
+	if params.PlugSnapYaml != "" {
+		info, err := snap.InfoFromSnapYaml([]byte(params.PlugSnapYaml))
+		if err != nil {
+			return BadRequest("cannot read plug snap.yaml: %v", err)
+		}
+		opts.PlugSnapInfo = info
+	}
+	if params.SlotSnapYaml != "" {
+		info, err := snap.InfoFromSnapYaml([]byte(params.SlotSnapYaml))
+		if err != nil {
+			return BadRequest("cannot read slot snap.yaml: %v", err)
+		}
+		opts.SlotSnapInfo = info
+	}
+	if params.Assertions != "" {
+		dec := asserts.NewDecoder(strings.NewReader(params.Assertions))
+		for {
+			a, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return BadRequest("cannot decode assertions: %v", err)
+			}
+			opts.Assertions = append(opts.Assertions, a)
+		}
+	}
+
+	plugRef := interfaces.PlugRef{Snap: params.PlugSnap, Name: params.Plug}
+	slotRef := interfaces.SlotRef{Snap: params.SlotSnap, Name: params.Slot}
+	check, err := ifacestateCheckConnectionPolicy(st, plugRef, slotRef, params.Interface, opts)
+	if err != nil {
+		var nie *snap.NotInstalledError
+		if errors.As(err, &nie) {
+			return SnapNotFound(nie.Snap, err)
+		}
+		return BadRequest("cannot check connection: %v", err)
+	}
+	return SyncResponse(check)
+}
@@ -0,0 +1,42 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package daemon
+
+import (
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
+)
+
+var ifacestateInterfaceManagerDenials = (*ifacestate.InterfaceManager).Denials
+
+// getDenials returns the denials collected by the interface manager,
+// optionally only the ones of the given snap.
+func getDenials(c *Command, snapName string) Response {
+	all, running := ifacestateInterfaceManagerDenials(c.d.overlord.InterfaceManager())
+	if !running {
+		return BadRequest(`denial monitoring is not enabled, set "experimental.denial-monitoring" to true and restart snapd`)
+	}
+	result := make([]*denials.Denial, 0, len(all))
+	for _, d := range all {
+		if snapName == "" || d.Snap == snapName {
+			result = append(result, d)
+		}
+	}
+	return SyncResponse(result)
+}
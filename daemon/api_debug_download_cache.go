@@ -0,0 +1,66 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/store"
+)
+
+// downloadCache returns the download cache.
+func downloadCache() *store.CacheManager {
+	// the count limit only matters when adding to the cache
+	return store.NewCacheManager(dirs.SnapDownloadCacheDir, 0)
+}
+
+func getDownloadCache(st *state.State) Response {
+	entries, err := downloadCache().Entries(snapstate.DownloadCachePins(st))
+	if err != nil {
+		return InternalError("cannot list download cache: %v", err)
+	}
+	if entries == nil {
+		entries = []*store.CacheEntry{}
+	}
+	return SyncResponse(entries)
+}
+
+func verifyDownloadCache() Response {
+	corrupted, err := downloadCache().Verify()
+	if err != nil {
+		return InternalError("cannot verify download cache: %v", err)
+	}
+	if corrupted == nil {
+		corrupted = []string{}
+	}
+	return SyncResponse(map[string]interface{}{"corrupted": corrupted})
+}
+
+func purgeDownloadCache(st *state.State, digests []string) Response {
+	purged, err := downloadCache().Purge(digests, snapstate.DownloadCachePins(st))
+	if err != nil {
+		return BadRequest("%v", err)
+	}
+	if purged == nil {
+		purged = []string{}
+	}
+	return SyncResponse(map[string]interface{}{"purged": purged})
+}
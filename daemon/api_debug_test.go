@@ -23,15 +23,25 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"golang.org/x/crypto/sha3"
 	"gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
+	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -336,3 +346,180 @@ func (s *postDebugSuite) TestMigrateHomeInternalError(c *check.C) {
 	c.Check(apiErr.Status, check.Equals, 500)
 	c.Check(apiErr.Message, check.Equals, `boom`)
 }
+
+func (s *postDebugSuite) TestCheckConnection(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	restore := daemon.MockIfacestateCheckConnectionPolicy(func(st *state.State, plugRef interfaces.PlugRef, slotRef interfaces.SlotRef, iface string, opts *ifacestate.ConnectionPolicyCheckOptions) (*ifacestate.ConnectionPolicyCheck, error) {
+		c.Check(plugRef, check.Equals, interfaces.PlugRef{Snap: "consumer", Name: "plug"})
+		c.Check(slotRef, check.Equals, interfaces.SlotRef{Snap: "producer"})
+		c.Check(iface, check.Equals, "network")
+		c.Assert(opts.PlugSnapInfo, check.NotNil)
+		c.Check(opts.PlugSnapInfo.SnapName(), check.Equals, "consumer")
+		c.Check(opts.SlotSnapInfo, check.IsNil)
+		c.Check(opts.Assertions, check.HasLen, 0)
+		return &ifacestate.ConnectionPolicyCheck{
+			Plug:      interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+			Slot:      interfaces.SlotRef{Snap: "producer", Name: "slot"},
+			Interface: "network",
+			Connection: ifacestate.PolicyCheckResult{
+				Allowed: true,
+				Steps:   []string{"connection allowed"},
+			},
+			AutoConnection: ifacestate.PolicyCheckResult{
+				Error: "auto-connection denied",
+				Steps: []string{"auto-connection denied"},
+			},
+		}, nil
+	})
+	defer restore()
+
+	body := strings.NewReader(`{"action": "check-connection", "params": {"plug-snap": "consumer", "plug": "plug", "slot-snap": "producer", "interface": "network", "plug-snap-yaml": "name: consumer\nversion: 1\nplugs:\n plug: network\n"}}`)
+	req, err := http.NewRequest("POST", "/v2/debug", body)
+	c.Assert(err, check.IsNil)
+
+	rsp := s.syncReq(c, req, nil)
+	c.Assert(rsp.Result, check.FitsTypeOf, &ifacestate.ConnectionPolicyCheck{})
+	res := rsp.Result.(*ifacestate.ConnectionPolicyCheck)
+	c.Check(res.Connection.Allowed, check.Equals, true)
+	c.Check(res.AutoConnection.Allowed, check.Equals, false)
+}
+
+func (s *postDebugSuite) TestCheckConnectionErrors(c *check.C) {
+	s.daemonWithOverlordMock()
+	s.expectRootAccess()
+
+	restore := daemon.MockIfacestateCheckConnectionPolicy(func(st *state.State, plugRef interfaces.PlugRef, slotRef interfaces.SlotRef, iface string, opts *ifacestate.ConnectionPolicyCheckOptions) (*ifacestate.ConnectionPolicyCheck, error) {
+		if plugRef.Snap == "missing" {
+			return nil, &snap.NotInstalledError{Snap: "missing"}
+		}
+		return nil, errors.New("boom")
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		params string
+		status int
+		msg    string
+	}{
+		{`{"slot-snap": "producer"}`, 400, "cannot check connection: plug and slot snaps must be provided"},
+		{`{"plug-snap": "consumer", "slot-snap": "producer", "slot-snap-yaml": "name: [}"}`, 400, "cannot read slot snap.yaml: .*"},
+		{`{"plug-snap": "consumer", "slot-snap": "producer", "assertions": "type: foo\n\n"}`, 400, "cannot decode assertions: .*"},
+		{`{"plug-snap": "missing", "slot-snap": "producer"}`, 404, `snap "missing" is not installed`},
+		{`{"plug-snap": "consumer", "slot-snap": "producer"}`, 400, "cannot check connection: boom"},
+	} {
+		body := strings.NewReader(`{"action": "check-connection", "params": ` + tc.params + `}`)
+		req, err := http.NewRequest("POST", "/v2/debug", body)
+		c.Assert(err, check.IsNil)
+
+		apiErr := s.errorReq(c, req, nil)
+		c.Check(apiErr.Status, check.Equals, tc.status)
+		c.Check(apiErr.Message, check.Matches, tc.msg)
+	}
+}
+
+func (s *postDebugSuite) TestGetDebugDenials(c *check.C) {
+	s.daemon(c)
+
+	ds := []*denials.Denial{
+		{Kind: "apparmor", Label: "snap.foo.app", Snap: "foo", App: "app", Path: "/dev/video0", Candidates: []string{"camera"}, Count: 2},
+		{Kind: "seccomp", Label: "snap.bar.app", Snap: "bar", App: "app", Syscall: "mount", Count: 1},
+	}
+	restore := daemon.MockIfacestateInterfaceManagerDenials(func(*ifacestate.InterfaceManager) ([]*denials.Denial, bool) {
+		return ds, true
+	})
+	defer restore()
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=denials", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, ds)
+
+	req, err = http.NewRequest("GET", "/v2/debug?aspect=denials&snap=bar", nil)
+	c.Assert(err, check.IsNil)
+	rsp = s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, []*denials.Denial{ds[1]})
+}
+
+func (s *postDebugSuite) TestGetDebugDenialsNotRunning(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=denials", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `denial monitoring is not enabled, set "experimental.denial-monitoring" to true and restart snapd`)
+}
+
+// mockDownloadCache puts a valid and a corrupted entry in the download
+// cache, the first one pinned for a pending refresh.
+func (s *postDebugSuite) mockDownloadCache(c *check.C) (good, bad string) {
+	good = fmt.Sprintf("%x", sha3.Sum384([]byte("good")))
+	bad = fmt.Sprintf("%x", sha3.Sum384([]byte("bad")))
+	c.Assert(os.MkdirAll(dirs.SnapDownloadCacheDir, 0700), check.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapDownloadCacheDir, good), []byte("good"), 0600), check.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dirs.SnapDownloadCacheDir, bad), []byte("corrupted"), 0600), check.IsNil)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	st.Set("refresh-candidates", map[string]*snapstate.SnapSetup{
+		"foo": {DownloadInfo: &snap.DownloadInfo{Sha3_384: good}},
+	})
+	return good, bad
+}
+
+func (s *postDebugSuite) TestGetDebugDownloadCache(c *check.C) {
+	s.daemon(c)
+
+	req, err := http.NewRequest("GET", "/v2/debug?aspect=download-cache", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.HasLen, 0)
+
+	good, bad := s.mockDownloadCache(c)
+	rsp = s.syncReq(c, req, nil)
+	entries, ok := rsp.Result.([]*store.CacheEntry)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(entries, check.HasLen, 2)
+	byDigest := map[string]*store.CacheEntry{}
+	for _, entry := range entries {
+		byDigest[entry.Digest] = entry
+	}
+	c.Check(byDigest[good].Size, check.Equals, int64(4))
+	c.Check(byDigest[good].Pinned, check.Equals, `pending refresh of "foo"`)
+	c.Check(byDigest[bad].Pinned, check.Equals, "")
+}
+
+func (s *postDebugSuite) TestPostDebugVerifyDownloadCache(c *check.C) {
+	s.daemon(c)
+	s.expectRootAccess()
+	good, bad := s.mockDownloadCache(c)
+
+	req, err := http.NewRequest("POST", "/v2/debug", strings.NewReader(`{"action": "verify-download-cache"}`))
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, map[string]interface{}{"corrupted": []string{bad}})
+	c.Check(filepath.Join(dirs.SnapDownloadCacheDir, good), testutil.FilePresent)
+	c.Check(filepath.Join(dirs.SnapDownloadCacheDir, bad), testutil.FileAbsent)
+}
+
+func (s *postDebugSuite) TestPostDebugPurgeDownloadCache(c *check.C) {
+	s.daemon(c)
+	s.expectRootAccess()
+	good, bad := s.mockDownloadCache(c)
+
+	req, err := http.NewRequest("POST", "/v2/debug", strings.NewReader(fmt.Sprintf(`{"action": "purge-download-cache", "params": {"digests": [%q]}}`, good)))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, fmt.Sprintf(`cannot purge %q: pinned for pending refresh of "foo"`, good))
+
+	req, err = http.NewRequest("POST", "/v2/debug", strings.NewReader(`{"action": "purge-download-cache"}`))
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Result, check.DeepEquals, map[string]interface{}{"purged": []string{bad}})
+	c.Check(filepath.Join(dirs.SnapDownloadCacheDir, good), testutil.FilePresent)
+	c.Check(filepath.Join(dirs.SnapDownloadCacheDir, bad), testutil.FileAbsent)
+}
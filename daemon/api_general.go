@@ -116,6 +116,10 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	if err != nil {
 		return InternalError("cannot get refresh schedule: %s", err)
 	}
+	refreshWindows, err := snapstate.RefreshWindowsInfo(st)
+	if err != nil {
+		return InternalError("cannot get refresh windows: %s", err)
+	}
 	users, err := auth.Users(st)
 	if err != nil && !errors.Is(err, state.ErrNoState) {
 		return InternalError("cannot get user auth data: %s", err)
@@ -131,6 +135,15 @@ func sysInfo(c *Command, r *http.Request, user *auth.UserState) Response {
 	} else {
 		refreshInfo.Schedule = refreshScheduleStr
 	}
+	for _, w := range refreshWindows {
+		refreshInfo.Windows = append(refreshInfo.Windows, client.RefreshWindowInfo{
+			Name:     w.Name,
+			Snaps:    w.Snaps,
+			Schedule: w.Schedule,
+			Open:     w.Open,
+			Next:     formatRefreshTime(w.Next),
+		})
+	}
 
 	m := map[string]interface{}{
 		"series":         release.Series,
@@ -33,6 +33,7 @@ import (
 
 	"github.com/snapcore/snapd/arch"
 	"github.com/snapcore/snapd/boot"
+	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/daemon"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/features"
@@ -410,6 +411,39 @@ func (s *generalSuite) TestSysInfoWorksDegraded(c *check.C) {
 	c.Check(rsp.Status, check.Equals, 200)
 }
 
+func (s *generalSuite) TestSysInfoRefreshWindows(c *check.C) {
+	s.expectSystemInfoReadAccess()
+	d := s.daemon(c)
+
+	st := d.Overlord().State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	tr.Set("core", "refresh.windows", map[string]interface{}{
+		"web":       map[string]interface{}{"snaps": []string{"nginx"}, "schedule": "00:00-24:00"},
+		"databases": map[string]interface{}{"snaps": []string{"postgres", "redis"}, "schedule": "sun,02:00-04:00"},
+	})
+	tr.Commit()
+	st.Unlock()
+
+	req, err := http.NewRequest("GET", "/v2/system-info", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	c.Check(rsp.Status, check.Equals, 200)
+
+	refreshInfo := rsp.Result.(map[string]interface{})["refresh"].(client.RefreshInfo)
+	c.Assert(refreshInfo.Windows, check.HasLen, 2)
+	databases, web := refreshInfo.Windows[0], refreshInfo.Windows[1]
+	c.Check(databases.Name, check.Equals, "databases")
+	c.Check(databases.Snaps, check.DeepEquals, []string{"postgres", "redis"})
+	c.Check(databases.Schedule, check.Equals, "sun,02:00-04:00")
+	next, err := time.Parse(time.RFC3339, databases.Next)
+	c.Assert(err, check.IsNil)
+	c.Check(next.Weekday(), check.Equals, time.Sunday)
+	c.Check(web.Name, check.Equals, "web")
+	c.Check(web.Snaps, check.DeepEquals, []string{"nginx"})
+	c.Check(web.Open, check.Equals, true)
+}
+
 func setupChanges(st *state.State) []string {
 	chg1 := st.NewChange("install", "install...")
 	chg1.Set("snap-names", []string{"funky-snap-name"})
@@ -141,6 +141,9 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 	if len(a.Plugs) == 0 || len(a.Slots) == 0 {
 		return BadRequest("at least one plug and slot is required")
 	}
+	if a.DryRun && a.Action != "connect" {
+		return BadRequest("dry-run is only supported for the connect action")
+	}
 
 	var summary string
 	var err error
@@ -186,6 +189,15 @@ func changeInterfaces(c *Command, r *http.Request, user *auth.UserState) Respons
 		var connRef *interfaces.ConnRef
 		repo := c.d.overlord.InterfaceManager().Repository()
 		connRef, err = repo.ResolveConnect(a.Plugs[0].Snap, a.Plugs[0].Name, a.Slots[0].Snap, a.Slots[0].Name)
+		if err == nil && a.DryRun {
+			// build the security specifications without setting up
+			// anything, nor creating a change
+			preview, err := c.d.overlord.InterfaceManager().PreviewConnect(connRef)
+			if err != nil {
+				return errToResponse(err, nil, BadRequest, "%v")
+			}
+			return SyncResponse(preview)
+		}
 		if err == nil {
 			var ts *state.TaskSet
 			affected = snapNamesFromConns([]*interfaces.ConnRef{connRef})
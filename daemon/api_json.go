@@ -60,6 +60,7 @@ type interfaceJSON struct {
 type interfaceAction struct {
 	Action string     `json:"action"`
 	Forget bool       `json:"forget,omitempty"`
+	DryRun bool       `json:"dry-run,omitempty"`
 	Plugs  []plugJSON `json:"plugs,omitempty"`
 	Slots  []slotJSON `json:"slots,omitempty"`
 }
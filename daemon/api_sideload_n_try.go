@@ -41,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/delta"
 	"github.com/snapcore/snapd/snap/snapfile"
 	"github.com/snapcore/snapd/strutil"
 )
@@ -140,8 +141,18 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 
 	// we are in charge of the temp files, until they're handed off to the change
 	var pathsToNotRemove []string
+	// snaps reconstructed from uploaded deltas, not part of the form
+	var reconstructed []string
 	defer func() {
 		form.RemoveAllExcept(pathsToNotRemove)
+		for _, p := range reconstructed {
+			if strutil.ListContains(pathsToNotRemove, p) {
+				continue
+			}
+			if err := os.Remove(p); err != nil {
+				logger.Noticef("cannot remove temporary file: %v", err)
+			}
+		}
 	}()
 
 	flags, err := modeFlags(isTrue(form, "devmode"), isTrue(form, "jailmode"), isTrue(form, "classic"))
@@ -189,6 +200,19 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	}
 
 	st := c.d.overlord.State()
+
+	if isTrue(form, "delta") {
+		// the reconstructed snaps are trusted only through their
+		// assertions
+		if sideloadFlags.dangerousOK || flags.DevMode {
+			return BadRequest("cannot install deltas in devmode or without signatures")
+		}
+		reconstructed, errRsp = applyUploadedDeltas(st, snapFiles)
+		if errRsp != nil {
+			return errRsp
+		}
+	}
+
 	st.Lock()
 	defer st.Unlock()
 
@@ -216,6 +240,116 @@ func sideloadOrTrySnap(ctx context.Context, c *Command, body io.ReadCloser, boun
 	return AsyncResponse(nil, chg.ID())
 }
 
+var deltaApply = delta.Apply
+
+// applyUploadedDeltas reconstructs the snaps described by the uploaded
+// delta files against the installed revisions of their snaps, and
+// points snapFiles at the reconstructed snaps. Delta files are not signed
+// themselves: a delta is only applied if the target it describes matches
+// a known snap-revision assertion. It returns the paths of the
+// reconstructed snaps, which are the responsibility of the caller.
+func applyUploadedDeltas(st *state.State, snapFiles []*uploadedSnap) (reconstructed []string, errRsp *apiError) {
+	defer func() {
+		if errRsp == nil {
+			return
+		}
+		for _, p := range reconstructed {
+			os.Remove(p)
+		}
+		reconstructed = nil
+	}()
+
+	for _, snapFile := range snapFiles {
+		hdr, err := delta.ReadHeader(snapFile.tmpPath)
+		if err != nil {
+			return reconstructed, BadRequest("cannot read delta %q: %v", snapFile.filename, err)
+		}
+		instanceName := hdr.SnapName
+		if snapFile.instanceName != "" {
+			if snap.InstanceSnap(snapFile.instanceName) != hdr.SnapName {
+				return reconstructed, BadRequest("instance name %q does not match snap name %q of delta", snapFile.instanceName, hdr.SnapName)
+			}
+			instanceName = snapFile.instanceName
+		}
+
+		if errRsp := checkDeltaTarget(st, hdr, snapFile.filename); errRsp != nil {
+			return reconstructed, errRsp
+		}
+		source, errRsp := deltaSource(st, instanceName)
+		if errRsp != nil {
+			return reconstructed, errRsp
+		}
+
+		target, err := writeToTempFile(bytes.NewReader(nil))
+		if target != "" {
+			reconstructed = append(reconstructed, target)
+		}
+		if err != nil {
+			return reconstructed, InternalError(err.Error())
+		}
+		if _, err := deltaApply(source, snapFile.tmpPath, target); err != nil {
+			var mismatch *delta.SourceMismatchError
+			if errors.As(err, &mismatch) {
+				return reconstructed, BadRequest("cannot apply delta %q: installed revision of %q is not the one the delta was generated from", snapFile.filename, instanceName)
+			}
+			return reconstructed, BadRequest("cannot apply delta %q: %v", snapFile.filename, err)
+		}
+		snapFile.tmpPath = target
+	}
+
+	return reconstructed, nil
+}
+
+// checkDeltaTarget checks that the snap the delta reconstructs is an
+// asserted revision of the snap named in its header, before anything is
+// done with the payload of the delta. The reconstructed snap goes through
+// the usual checks of asserted local snaps afterwards.
+func checkDeltaTarget(st *state.State, hdr *delta.Header, filename string) *apiError {
+	st.Lock()
+	defer st.Unlock()
+
+	revs, err := assertstate.DB(st).FindMany(asserts.SnapRevisionType, map[string]string{
+		"snap-sha3-384": hdr.TargetSha3_384,
+	})
+	if err != nil {
+		if errors.Is(err, &asserts.NotFoundError{}) {
+			return BadRequest("cannot find signatures with metadata for snap %q", filename)
+		}
+		return InternalError(err.Error())
+	}
+	for _, a := range revs {
+		snapRev := a.(*asserts.SnapRevision)
+		if snapRev.SnapSize() != hdr.TargetSize {
+			continue
+		}
+		snapDecl, err := assertstate.SnapDeclaration(st, snapRev.SnapID())
+		if err == nil && snapDecl.SnapName() == hdr.SnapName {
+			return nil
+		}
+	}
+	return BadRequest("cannot apply delta %q: it does not reconstruct an asserted revision of %q", filename, hdr.SnapName)
+}
+
+// deltaSource returns the path of the snap file of the current revision
+// of the given snap instance.
+func deltaSource(st *state.State, instanceName string) (string, *apiError) {
+	st.Lock()
+	defer st.Unlock()
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return "", SnapNotInstalled(instanceName, fmt.Errorf("cannot apply delta: snap %q is not installed", instanceName))
+		}
+		return "", InternalError(err.Error())
+	}
+	info, err := snapst.CurrentInfo()
+	if err != nil {
+		return "", InternalError(err.Error())
+	}
+	return info.MountFile(), nil
+}
+
 // sideloadedInfo contains information from a bunch of sideloaded snaps
 type sideloadedInfo struct {
 	sideInfos                  []*snap.SideInfo
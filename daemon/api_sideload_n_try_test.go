@@ -46,6 +46,7 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/sandbox"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/delta"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/snap/snaptest"
 	"github.com/snapcore/snapd/strutil"
@@ -1417,3 +1418,201 @@ func (s *sideloadSuite) TestSideloadSnapInvalidTransaction(c *check.C) {
 	rspe := s.errorReq(c, req, nil)
 	c.Assert(rspe.Message, check.Matches, `transaction must be either "per-snap" or "all-snaps"`)
 }
+
+func (s *sideloadSuite) setupDeltaSideload(c *check.C, installedContent, target string, asserted bool) (deltaBytes []byte) {
+	d := s.daemonWithOverlordMockAndStore()
+	s.markSeeded(d)
+	st := d.Overlord().State()
+
+	// the mocked xdelta3 copies its input to its output
+	xdelta3 := testutil.MockCommand(c, "xdelta3", `cp "${@: -2:1}" "${@: -1}"`)
+	s.AddCleanup(xdelta3.Restore)
+
+	si := &snap.SideInfo{RealName: "foo", SnapID: "foo-id", Revision: snap.R(40)}
+	info := snaptest.MockSnap(c, "name: foo\nversion: 1", si)
+	c.Assert(os.MkdirAll(filepath.Dir(info.MountFile()), 0755), check.IsNil)
+	c.Assert(os.WriteFile(info.MountFile(), []byte(installedContent), 0644), check.IsNil)
+
+	st.Lock()
+	snapstate.Set(st, "foo", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromRevisionSideInfos(
+			[]*sequence.RevisionSideState{
+				sequence.NewRevisionSideState(si, nil)}),
+		Current: si.Revision,
+	})
+	st.Unlock()
+
+	dir := c.MkDir()
+	source := filepath.Join(dir, "foo_40.snap")
+	c.Assert(os.WriteFile(source, []byte("installed snap"), 0644), check.IsNil)
+	deltaPath := filepath.Join(dir, "foo_41.delta")
+	_, err := delta.Generate("foo", source, target, deltaPath)
+	c.Assert(err, check.IsNil)
+
+	if asserted {
+		digest, size, err := asserts.SnapFileSHA3_384(target)
+		c.Assert(err, check.IsNil)
+		dev1Acct := assertstest.NewAccount(s.StoreSigning, "devel1", nil, "")
+		snapDecl, err := s.StoreSigning.Sign(asserts.SnapDeclarationType, map[string]interface{}{
+			"series":       "16",
+			"snap-id":      "foo-id",
+			"snap-name":    "foo",
+			"publisher-id": dev1Acct.AccountID(),
+			"timestamp":    time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, check.IsNil)
+		snapRev, err := s.StoreSigning.Sign(asserts.SnapRevisionType, map[string]interface{}{
+			"snap-sha3-384": digest,
+			"snap-size":     fmt.Sprintf("%d", size),
+			"snap-id":       "foo-id",
+			"snap-revision": "41",
+			"developer-id":  dev1Acct.AccountID(),
+			"timestamp":     time.Now().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, check.IsNil)
+		st.Lock()
+		assertstatetest.AddMany(st, s.StoreSigning.StoreAccountKey(""), dev1Acct, snapDecl, snapRev)
+		st.Unlock()
+	}
+
+	deltaBytes, err = os.ReadFile(deltaPath)
+	c.Assert(err, check.IsNil)
+	return deltaBytes
+}
+
+func deltaSideloadRequest(c *check.C, deltaBytes []byte, extraFields ...string) *http.Request {
+	bodyBuf := new(bytes.Buffer)
+	for _, field := range append([]string{"delta"}, extraFields...) {
+		bodyBuf.WriteString("----hello--\r\n" +
+			"Content-Disposition: form-data; name=\"" + field + "\"\r\n\r\ntrue\r\n")
+	}
+	bodyBuf.WriteString("----hello--\r\n" +
+		"Content-Disposition: form-data; name=\"snap\"; filename=\"foo_41.delta\"\r\n\r\n")
+	bodyBuf.Write(deltaBytes)
+	bodyBuf.WriteString("\r\n----hello--\r\n")
+	req, err := http.NewRequest("POST", "/v2/snaps", bodyBuf)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "multipart/thing; boundary=--hello--")
+	return req
+}
+
+func (s *sideloadSuite) unassertedDeltaTarget(c *check.C) string {
+	target := filepath.Join(c.MkDir(), "foo_41.snap")
+	c.Assert(os.WriteFile(target, []byte("new snap"), 0644), check.IsNil)
+	return target
+}
+
+func (s *sideloadSuite) checkNoLocalInstallLeftovers(c *check.C) {
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+	c.Assert(err, check.IsNil)
+	c.Check(matches, check.HasLen, 0)
+}
+
+func (s *sideloadSuite) TestSideloadDelta(c *check.C) {
+	target := snaptest.MakeTestSnapWithFiles(c, "name: foo\nversion: 2", nil)
+	targetBytes, err := os.ReadFile(target)
+	c.Assert(err, check.IsNil)
+	deltaBytes := s.setupDeltaSideload(c, "installed snap", target, true)
+
+	var installPath string
+	defer daemon.MockSnapstateInstallPath(func(s *state.State, si *snap.SideInfo, path, name, channel string, flags snapstate.Flags, prqt snapstate.PrereqTracker) (*state.TaskSet, *snap.Info, error) {
+		c.Check(si, check.DeepEquals, &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "foo-id",
+			Revision: snap.R(41),
+		})
+		c.Check(path, testutil.FileEquals, targetBytes)
+		installPath = path
+		return state.NewTaskSet(), &snap.Info{SuggestedName: "foo"}, nil
+	})()
+
+	rsp := s.asyncReq(c, deltaSideloadRequest(c, deltaBytes), nil)
+
+	st := s.d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	chg := st.Change(rsp.Change)
+	c.Assert(chg, check.NotNil)
+	c.Check(chg.Summary(), check.Equals, `Install "foo" snap from file "foo_41.delta"`)
+
+	// the reconstructed snap was handed off to the change, the delta
+	// itself is gone
+	c.Check(installPath, testutil.FilePresent)
+	matches, err := filepath.Glob(filepath.Join(dirs.SnapBlobDir, dirs.LocalInstallBlobTempPrefix+"*"))
+	c.Assert(err, check.IsNil)
+	c.Check(matches, check.DeepEquals, []string{installPath})
+}
+
+func (s *sideloadSuite) TestSideloadDeltaNoSignatures(c *check.C) {
+	// the target is checked before the installed revision is used
+	deltaBytes := s.setupDeltaSideload(c, "some other revision", s.unassertedDeltaTarget(c), false)
+
+	rspe := s.errorReq(c, deltaSideloadRequest(c, deltaBytes), nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot find signatures with metadata for snap "foo_41.delta"`)
+	s.checkNoLocalInstallLeftovers(c)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaWrongInstalledRevision(c *check.C) {
+	deltaBytes := s.setupDeltaSideload(c, "some other revision", s.unassertedDeltaTarget(c), true)
+
+	rspe := s.errorReq(c, deltaSideloadRequest(c, deltaBytes), nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot apply delta "foo_41.delta": installed revision of "foo" is not the one the delta was generated from`)
+	s.checkNoLocalInstallLeftovers(c)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaNotInstalled(c *check.C) {
+	deltaBytes := s.setupDeltaSideload(c, "installed snap", s.unassertedDeltaTarget(c), true)
+	st := s.d.Overlord().State()
+	st.Lock()
+	snapstate.Set(st, "foo", nil)
+	st.Unlock()
+
+	rspe := s.errorReq(c, deltaSideloadRequest(c, deltaBytes), nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Kind, check.Equals, client.ErrorKindSnapNotInstalled)
+	c.Check(rspe.Message, check.Equals, `cannot apply delta: snap "foo" is not installed`)
+	s.checkNoLocalInstallLeftovers(c)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaTargetOfOtherSnap(c *check.C) {
+	target := s.unassertedDeltaTarget(c)
+	s.setupDeltaSideload(c, "installed snap", target, true)
+
+	// a delta claiming to reconstruct foo into the asserted revision of
+	// another snap
+	source := filepath.Join(c.MkDir(), "bar_1.snap")
+	c.Assert(os.WriteFile(source, []byte("installed snap"), 0644), check.IsNil)
+	deltaPath := filepath.Join(c.MkDir(), "bar_2.delta")
+	_, err := delta.Generate("bar", source, target, deltaPath)
+	c.Assert(err, check.IsNil)
+	deltaBytes, err := os.ReadFile(deltaPath)
+	c.Assert(err, check.IsNil)
+
+	rspe := s.errorReq(c, deltaSideloadRequest(c, deltaBytes), nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot apply delta "foo_41.delta": it does not reconstruct an asserted revision of "bar"`)
+	s.checkNoLocalInstallLeftovers(c)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaNotADelta(c *check.C) {
+	s.setupDeltaSideload(c, "installed snap", s.unassertedDeltaTarget(c), false)
+
+	rspe := s.errorReq(c, deltaSideloadRequest(c, []byte("xyzzy")), nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `cannot read delta "foo_41.delta": not a snap delta`)
+	s.checkNoLocalInstallLeftovers(c)
+}
+
+func (s *sideloadSuite) TestSideloadDeltaDangerous(c *check.C) {
+	deltaBytes := s.setupDeltaSideload(c, "installed snap", s.unassertedDeltaTarget(c), false)
+
+	for _, field := range []string{"dangerous", "devmode"} {
+		rspe := s.errorReq(c, deltaSideloadRequest(c, deltaBytes, field), nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, "cannot install deltas in devmode or without signatures")
+	}
+	s.checkNoLocalInstallLeftovers(c)
+}
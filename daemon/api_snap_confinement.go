@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	snapConfinementCmd = &Command{
+		Path:       "/v2/snaps/{name}/confinement",
+		GET:        getSnapConfinement,
+		ReadAccess: openAccess{},
+	}
+)
+
+// getSnapConfinement returns the policy generated by the security backends
+// for the snap, optionally restricted to a single app with the app query
+// parameter.
+func getSnapConfinement(c *Command, r *http.Request, user *auth.UserState) Response {
+	vars := muxVars(r)
+	name := ifacestate.RemapSnapFromRequest(vars["name"])
+	app := r.URL.Query().Get("app")
+
+	st := c.d.overlord.State()
+	st.Lock()
+	defer st.Unlock()
+
+	confinement, err := c.d.overlord.InterfaceManager().SnapConfinement(name)
+	if err != nil {
+		var notInstalled *snap.NotInstalledError
+		if errors.As(err, &notInstalled) {
+			return SnapNotFound(name, err)
+		}
+		return InternalError("cannot get confinement of snap %q: %v", name, err)
+	}
+	if app == "" {
+		return SyncResponse(confinement)
+	}
+
+	info, err := snapstate.CurrentInfo(st, name)
+	if err != nil {
+		return InternalError("cannot get confinement of snap %q: %v", name, err)
+	}
+	appInfo := info.Apps[app]
+	if appInfo == nil {
+		return AppNotFound("snap %q has no app %q", name, app)
+	}
+	// drop the profiles of the other apps and of the hooks, keep the ones
+	// of the app and the ones applying to the whole snap
+	var otherTags []string
+	for _, other := range info.Apps {
+		if other != appInfo {
+			otherTags = append(otherTags, other.SecurityTag())
+		}
+	}
+	for _, hook := range info.Hooks {
+		otherTags = append(otherTags, hook.SecurityTag())
+	}
+	profiles := confinement.Profiles[:0]
+	for _, profile := range confinement.Profiles {
+		if !profileOfTags(filepath.Base(profile.Path), otherTags) {
+			profiles = append(profiles, profile)
+		}
+	}
+	confinement.Profiles = profiles
+	return SyncResponse(confinement)
+}
+
+// profileOfTags returns whether the named profile is specific to one of the
+// given security tags.
+func profileOfTags(name string, tags []string) bool {
+	for _, tag := range tags {
+		if name == tag || strings.HasPrefix(name, tag+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package daemon_test
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+var _ = check.Suite(&snapConfinementSuite{})
+
+type snapConfinementSuite struct {
+	apiBaseSuite
+}
+
+type kmodTestInterface struct {
+	ifacetest.TestInterface
+}
+
+func (iface *kmodTestInterface) KModPermanentPlug(spec *kmod.Specification, plug *snap.PlugInfo) error {
+	return spec.AddModule("mod-" + plug.Name)
+}
+
+func (iface *kmodTestInterface) DBusPermanentPlug(spec *dbus.Specification, plug *snap.PlugInfo) error {
+	spec.AddSnippet("\n<policy context=\"default\"/>\n")
+	return nil
+}
+
+const confinementConsumerYaml = `
+name: consumer
+version: 1
+apps:
+  app:
+  other:
+hooks:
+  install:
+plugs:
+  plug:
+    interface: test
+`
+
+func (s *snapConfinementSuite) SetUpTest(c *check.C) {
+	s.apiBaseSuite.SetUpTest(c)
+
+	s.AddCleanup(ifacestate.MockSecurityBackends([]interfaces.SecurityBackend{&kmod.Backend{}, &dbus.Backend{}}))
+	d := s.daemon(c)
+	mockIface(c, d, &kmodTestInterface{ifacetest.TestInterface{InterfaceName: "test"}})
+	s.mockSnap(c, confinementConsumerYaml)
+}
+
+func (s *snapConfinementSuite) TestGetSnapConfinement(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/snaps/consumer/confinement", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	confinement := rsp.Result.(*client.SnapConfinement)
+	c.Check(confinement.Snap, check.Equals, "consumer")
+	c.Check(confinement.Confinement, check.Equals, "strict")
+	c.Assert(confinement.Profiles, check.HasLen, 4)
+	c.Check(confinement.Profiles[0], check.DeepEquals, client.ConfinementProfile{
+		Backend: "kmod",
+		Path:    filepath.Join(dirs.SnapKModModulesDir, "snap.consumer.conf"),
+		Content: "# This file is automatically generated.\nmod-plug\n",
+		Provenance: []client.ProfileProvenance{{
+			Source: "plug consumer:plug (test interface)",
+			Lines:  []int{2},
+		}},
+	})
+	// a DBus policy is generated for each app and hook
+	for i, tag := range []string{"snap.consumer.app", "snap.consumer.hook.install", "snap.consumer.other"} {
+		profile := confinement.Profiles[i+1]
+		c.Check(profile.Backend, check.Equals, "dbus")
+		c.Check(profile.Path, check.Equals, filepath.Join(dirs.SnapDBusSystemPolicyDir, tag+".conf"))
+		c.Check(profile.Provenance, check.DeepEquals, []client.ProfileProvenance{{
+			Source: "plug consumer:plug (test interface)",
+			Lines:  []int{6},
+		}})
+	}
+}
+
+func (s *snapConfinementSuite) TestGetSnapConfinementApp(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/snaps/consumer/confinement?app=app", nil)
+	c.Assert(err, check.IsNil)
+	rsp := s.syncReq(c, req, nil)
+	confinement := rsp.Result.(*client.SnapConfinement)
+	// the kernel modules apply to the whole snap, the profiles of the
+	// other app and of the hook are dropped
+	c.Assert(confinement.Profiles, check.HasLen, 2)
+	c.Check(confinement.Profiles[0].Backend, check.Equals, "kmod")
+	c.Check(confinement.Profiles[1].Path, check.Equals, filepath.Join(dirs.SnapDBusSystemPolicyDir, "snap.consumer.app.conf"))
+
+	req, err = http.NewRequest("GET", "/v2/snaps/consumer/confinement?app=nope", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+	c.Check(rspe.Message, check.Equals, `snap "consumer" has no app "nope"`)
+}
+
+func (s *snapConfinementSuite) TestGetSnapConfinementNotInstalled(c *check.C) {
+	req, err := http.NewRequest("GET", "/v2/snaps/missing/confinement", nil)
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 404)
+}
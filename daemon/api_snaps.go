@@ -146,6 +146,10 @@ func postSnap(c *Command, r *http.Request, user *auth.UserState) Response {
 		return BadRequest("%s", err)
 	}
 
+	if inst.DryRun {
+		return snapRefreshDryRun(r.Context(), &inst, st)
+	}
+
 	impl := inst.dispatch()
 	if impl == nil {
 		return BadRequest("unknown action %s", inst.Action)
@@ -233,6 +237,7 @@ type snapInstruction struct {
 	QuotaGroupName         string                           `json:"quota-group"`
 	Time                   string                           `json:"time"`
 	HoldLevel              string                           `json:"hold-level"`
+	DryRun                 bool                             `json:"dry-run"`
 
 	// The fields below should not be unmarshalled into. Do not export them.
 	userID int
@@ -368,6 +373,18 @@ func (inst *snapInstruction) validate() error {
 		return fmt.Errorf("quota-group can only be specified on install")
 	}
 
+	if inst.DryRun {
+		if inst.Action != "refresh" {
+			return fmt.Errorf(`dry-run can only be specified for the "refresh" action`)
+		}
+		if inst.Channel != "" || !inst.Revision.Unset() || inst.CohortKey != "" || inst.LeaveCohort {
+			return fmt.Errorf("dry-run cannot be combined with channel, revision or cohort options")
+		}
+		if len(inst.ValidationSets) > 0 {
+			return fmt.Errorf("dry-run cannot be combined with validation sets")
+		}
+	}
+
 	if inst.Action == "hold" {
 		if inst.Time == "" {
 			return errors.New("hold action requires a non-empty time value")
@@ -795,6 +812,10 @@ func snapOpMany(c *Command, r *http.Request, user *auth.UserState) Response {
 		inst.userID = user.ID
 	}
 
+	if inst.DryRun {
+		return snapRefreshDryRun(r.Context(), &inst, st)
+	}
+
 	op := inst.dispatchForMany()
 	if op == nil {
 		return BadRequest("unsupported multi-snap operation %q", inst.Action)
@@ -1006,6 +1027,18 @@ func snapUpdateMany(ctx context.Context, inst *snapInstruction, st *state.State)
 	}, nil
 }
 
+// snapRefreshDryRun reports what refreshing the snaps of the instruction, or
+// all snaps if none are given, would do without creating a change.
+func snapRefreshDryRun(ctx context.Context, inst *snapInstruction, st *state.State) Response {
+	report, err := snapstateRefreshDryRun(ctx, st, inst.Snaps, inst.userID, &snapstate.Flags{
+		IgnoreRunning: inst.IgnoreRunning,
+	})
+	if err != nil {
+		return inst.errToResponse(err)
+	}
+	return SyncResponse(report)
+}
+
 func snapEnforceValidationSets(ctx context.Context, inst *snapInstruction, st *state.State) (*snapInstructionResult, error) {
 	if len(inst.ValidationSets) > 0 && len(inst.Snaps) != 0 {
 		return nil, fmt.Errorf("snap names cannot be specified with validation sets to enforce")
@@ -703,6 +703,80 @@ func (s *snapsSuite) testPostSnapsOp(c *check.C, extraJSON, contentType string)
 	return systemRestartImmediate
 }
 
+func (s *snapsSuite) TestPostSnapsOpRefreshDryRun(c *check.C) {
+	defer daemon.MockSnapstateUpdateMany(func(context.Context, *state.State, []string, []*snapstate.RevisionOptions, int, *snapstate.Flags) ([]string, []*state.TaskSet, error) {
+		c.Fatalf("unexpected refresh")
+		return nil, nil, nil
+	})()
+	var calledNames []string
+	defer daemon.MockSnapstateRefreshDryRun(func(_ context.Context, _ *state.State, names []string, _ int, flags *snapstate.Flags) (*snapstate.RefreshReport, error) {
+		calledNames = names
+		c.Check(flags.IgnoreRunning, check.Equals, true)
+		return &snapstate.RefreshReport{
+			Snaps: []snapstate.RefreshReportSnap{
+				{Name: "foo", Revision: snap.R(2), CurrentRevision: snap.R(1), RunningApps: []string{"app"}},
+			},
+			Skipped: []snapstate.RefreshReportSkipped{
+				{Name: "bar", Reason: "held"},
+			},
+		}, nil
+	})()
+
+	d := s.daemonWithOverlordMockAndStore()
+
+	for _, t := range []struct {
+		path  string
+		body  string
+		names []string
+	}{
+		{"/v2/snaps", `{"action": "refresh", "dry-run": true, "ignore-running": true}`, nil},
+		{"/v2/snaps", `{"action": "refresh", "dry-run": true, "ignore-running": true, "snaps": ["foo", "bar"]}`, []string{"foo", "bar"}},
+		{"/v2/snaps/foo", `{"action": "refresh", "dry-run": true, "ignore-running": true}`, []string{"foo"}},
+	} {
+		req, err := http.NewRequest("POST", t.path, strings.NewReader(t.body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/json")
+
+		rsp := s.syncReq(c, req, nil)
+		c.Check(calledNames, check.DeepEquals, t.names)
+		report, ok := rsp.Result.(*snapstate.RefreshReport)
+		c.Assert(ok, check.Equals, true)
+		c.Check(report.Snaps[0].Name, check.Equals, "foo")
+		c.Check(report.Skipped[0].Reason, check.Equals, "held")
+	}
+
+	st := d.Overlord().State()
+	st.Lock()
+	defer st.Unlock()
+	c.Check(st.Changes(), check.HasLen, 0)
+}
+
+func (s *snapsSuite) TestPostSnapsOpRefreshDryRunErrors(c *check.C) {
+	s.daemon(c)
+
+	for _, t := range []struct {
+		body string
+		err  string
+	}{
+		{`{"action": "remove", "dry-run": true}`, `dry-run can only be specified for the "refresh" action`},
+		{`{"action": "refresh", "dry-run": true, "validation-sets": ["foo/bar"]}`, `dry-run cannot be combined with validation sets`},
+	} {
+		req, err := http.NewRequest("POST", "/v2/snaps", strings.NewReader(t.body))
+		c.Assert(err, check.IsNil)
+		req.Header.Set("Content-Type", "application/json")
+
+		rspe := s.errorReq(c, req, nil)
+		c.Check(rspe.Status, check.Equals, 400)
+		c.Check(rspe.Message, check.Equals, t.err)
+	}
+
+	req, err := http.NewRequest("POST", "/v2/snaps/foo", strings.NewReader(`{"action": "refresh", "dry-run": true, "channel": "edge"}`))
+	c.Assert(err, check.IsNil)
+	rspe := s.errorReq(c, req, nil)
+	c.Check(rspe.Status, check.Equals, 400)
+	c.Check(rspe.Message, check.Equals, `dry-run cannot be combined with channel, revision or cohort options`)
+}
+
 func (s *snapsSuite) TestPostSnapsOpInvalidCharset(c *check.C) {
 	s.daemon(c)
 
@@ -30,8 +30,11 @@ import (
 	"github.com/snapcore/snapd/asserts/snapasserts"
 	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/client/clientutil"
+	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
 	"github.com/snapcore/snapd/overlord/restart"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -219,6 +222,10 @@ func MockSnapstateUpdateMany(mock func(context.Context, *state.State, []string,
 	}
 }
 
+func MockSnapstateRefreshDryRun(mock func(context.Context, *state.State, []string, int, *snapstate.Flags) (*snapstate.RefreshReport, error)) (restore func()) {
+	return testutil.Mock(&snapstateRefreshDryRun, mock)
+}
+
 func MockSnapstateRemove(mock func(st *state.State, name string, revision snap.Revision, flags *snapstate.RemoveFlags) (*state.TaskSet, error)) (restore func()) {
 	oldSnapstateRemove := snapstateRemove
 	snapstateRemove = mock
@@ -259,6 +266,14 @@ func MockSnapstateResolveValSetEnforcementError(f func(context.Context, *state.S
 	}
 }
 
+func MockIfacestateCheckConnectionPolicy(mock func(*state.State, interfaces.PlugRef, interfaces.SlotRef, string, *ifacestate.ConnectionPolicyCheckOptions) (*ifacestate.ConnectionPolicyCheck, error)) (restore func()) {
+	return testutil.Mock(&ifacestateCheckConnectionPolicy, mock)
+}
+
+func MockIfacestateInterfaceManagerDenials(mock func(*ifacestate.InterfaceManager) ([]*denials.Denial, bool)) (restore func()) {
+	return testutil.Mock(&ifacestateInterfaceManagerDenials, mock)
+}
+
 func MockSnapstateMigrate(mock func(*state.State, []string) ([]*state.TaskSet, error)) (restore func()) {
 	oldSnapstateMigrate := snapstateMigrateHome
 	snapstateMigrateHome = mock
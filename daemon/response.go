@@ -304,12 +304,18 @@ func (rr *journalLineReaderSeqResponse) ServeHTTP(w http.ResponseWriter, r *http
 
 		// ignore the error...
 		t, _ := log.Time()
-		if err = enc.Encode(client.Log{
+		entry := client.Log{
 			Timestamp: t,
 			Message:   log.Message(),
 			SID:       log.SID(),
 			PID:       log.PID(),
-		}); err != nil {
+			Unit:      log.Unit(),
+			Cursor:    log.Cursor(),
+		}
+		if prio := log.Priority(); prio >= 0 {
+			entry.Priority = &prio
+		}
+		if err = enc.Encode(entry); err != nil {
 			break
 		}
 
@@ -77,6 +77,8 @@ const (
 	Registries
 	// AppArmorPrompting enables AppArmor to prompt the user for permission when apps perform certain operations.
 	AppArmorPrompting
+	// DenialMonitoring enables correlating AppArmor and seccomp denials with snap apps and missing interface connections.
+	DenialMonitoring
 
 	// lastFeature is the final known feature, it is only used for testing.
 	lastFeature
@@ -122,6 +124,8 @@ var featureNames = map[SnapdFeature]string{
 	Registries:            "registries",
 
 	AppArmorPrompting: "apparmor-prompting",
+
+	DenialMonitoring: "denial-monitoring",
 }
 
 // featuresEnabledWhenUnset contains a set of features that are enabled when not explicitly configured.
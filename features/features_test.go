@@ -67,6 +67,7 @@ func (*featureSuite) TestName(c *C) {
 	check(features.RefreshAppAwarenessUX, "refresh-app-awareness-ux")
 	check(features.Registries, "registries")
 	check(features.AppArmorPrompting, "apparmor-prompting")
+	check(features.DenialMonitoring, "denial-monitoring")
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 	c.Check(func() { _ = features.SnapdFeature(1000).String() }, PanicMatches, "unknown feature flag code 1000")
@@ -109,6 +110,7 @@ func (*featureSuite) TestIsExported(c *C) {
 	check(features.RefreshAppAwarenessUX, true)
 	check(features.Registries, true)
 	check(features.AppArmorPrompting, true)
+	check(features.DenialMonitoring, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
@@ -307,6 +309,7 @@ func (*featureSuite) TestIsEnabledWhenUnset(c *C) {
 	check(features.RefreshAppAwarenessUX, false)
 	check(features.Registries, false)
 	check(features.AppArmorPrompting, false)
+	check(features.DenialMonitoring, false)
 
 	c.Check(tested, Equals, features.NumberOfFeatures())
 }
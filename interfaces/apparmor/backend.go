@@ -352,15 +352,7 @@ func (b *Backend) prepareProfiles(appSet *interfaces.SnapAppSet, opts interfaces
 	}
 
 	snapInfo := appSet.Info()
-
-	// Add snippets for parallel snap installation mapping
-	spec.(*Specification).AddOvername(snapInfo)
-
-	// Add snippets derived from the layout definition.
-	spec.(*Specification).AddLayout(appSet)
-
-	// Add additional mount layouts rules for the snap.
-	spec.(*Specification).AddExtraLayouts(snapInfo, opts.ExtraLayouts)
+	addSnapSnippets(spec.(*Specification), appSet, opts)
 
 	// core on classic is special
 	if snapName == "core" && release.OnClassic && apparmor_sandbox.ProbedLevel() != apparmor_sandbox.Unsupported {
@@ -436,6 +428,30 @@ func (b *Backend) prepareProfiles(appSet *interfaces.SnapAppSet, opts interfaces
 	return &profilePathsResults{changed: changedPaths, removed: removedPaths, unchanged: unchangedPaths}, nil
 }
 
+// addSnapSnippets adds to the specification the snippets which do not come
+// from interfaces but from the snap itself.
+func addSnapSnippets(spec *Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) {
+	snapInfo := appSet.Info()
+
+	// Add snippets for parallel snap installation mapping
+	spec.AddOvername(snapInfo)
+
+	// Add snippets derived from the layout definition.
+	spec.AddLayout(appSet)
+
+	// Add additional mount layouts rules for the snap.
+	spec.AddExtraLayouts(snapInfo, opts.ExtraLayouts)
+}
+
+// RenderFiles returns the apparmor profiles that Setup would write for a snap
+// with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	addSnapSnippets(spec.(*Specification), appSet, opts)
+	files := make(map[string][]byte)
+	interfaces.AddRenderedFiles(files, dirs.SnapAppArmorDir, b.deriveContent(spec.(*Specification), appSet, opts))
+	return files, nil
+}
+
 // Setup creates and loads apparmor profiles specific to a given snap.
 // The snap can be in developer mode to make security violations non-fatal to
 // the offending application process.
@@ -20,6 +20,9 @@
 package interfaces
 
 import (
+	"path/filepath"
+
+	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/timings"
 )
@@ -136,3 +139,22 @@ type SecurityBackendDiscardingLate interface {
 	// step of the remove change.
 	RemoveLate(snapName string, rev snap.Revision, typ snap.Type) error
 }
+
+// SecurityBackendRenderer interface may be implemented by backends that can
+// render the security artefacts of a snap without writing them to disk.
+type SecurityBackendRenderer interface {
+	// RenderFiles returns the content of the files that Setup would write
+	// for a snap with the given specification, keyed by their path. The
+	// specification may be modified in the process.
+	RenderFiles(spec Specification, appSet *SnapAppSet, opts ConfinementOptions) (map[string][]byte, error)
+}
+
+// AddRenderedFiles adds the in-memory file states of the given content,
+// keyed by the name of a file in dir, to the rendered files.
+func AddRenderedFiles(files map[string][]byte, dir string, content map[string]osutil.FileState) {
+	for name, fileState := range content {
+		if mfs, ok := fileState.(*osutil.MemoryFileState); ok {
+			files[filepath.Join(dir, name)] = mfs.Content
+		}
+	}
+}
@@ -22,7 +22,13 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/backends"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/systemd"
+	"github.com/snapcore/snapd/osutil"
 	apparmor_sandbox "github.com/snapcore/snapd/sandbox/apparmor"
 	"github.com/snapcore/snapd/testutil"
 )
@@ -74,3 +80,42 @@ func (s *backendsSuite) TestEssentialOrdering(c *C) {
 	c.Assert(sdIndex, testutil.IntNotEqual, -1)
 	c.Assert(sdIndex, testutil.IntLessThan, aaIndex)
 }
+
+func (s *backendsSuite) TestAllRenderFiles(c *C) {
+	for _, backend := range backends.All() {
+		_, ok := backend.(interfaces.SecurityBackendRenderer)
+		c.Check(ok, Equals, true, Commentf("backend %s cannot render files", backend.Name()))
+	}
+}
+
+func (s *backendsSuite) TestSpecificationSnippets(c *C) {
+	kmodSpec := &kmod.Specification{}
+	c.Assert(kmodSpec.AddModule("mod2"), IsNil)
+	c.Assert(kmodSpec.AddModule("mod1"), IsNil)
+	c.Assert(kmodSpec.SetModuleOptions("mod1", "opt=1"), IsNil)
+	snippets, err := backends.SpecificationSnippets(kmodSpec)
+	c.Assert(err, IsNil)
+	c.Check(snippets, DeepEquals, map[string][]string{
+		"modules":        {"mod1", "mod2"},
+		"module-options": {"options mod1 opt=1"},
+	})
+
+	mountSpec := &mount.Specification{}
+	c.Assert(mountSpec.AddMountEntry(osutil.MountEntry{Name: "/src", Dir: "/dst", Type: "none", Options: []string{"bind"}}), IsNil)
+	snippets, err = backends.SpecificationSnippets(mountSpec)
+	c.Assert(err, IsNil)
+	c.Check(snippets, DeepEquals, map[string][]string{
+		"fstab": {"/src /dst none bind 0 0"},
+	})
+
+	systemdSpec := &systemd.Specification{}
+	c.Assert(systemdSpec.AddService("foo", &systemd.Service{ExecStart: "/bin/true"}), IsNil)
+	snippets, err = backends.SpecificationSnippets(systemdSpec)
+	c.Assert(err, IsNil)
+	c.Check(snippets, DeepEquals, map[string][]string{
+		"foo": {"[Service]", "ExecStart=/bin/true", "", "[Install]", "WantedBy=multi-user.target", ""},
+	})
+
+	_, err = backends.SpecificationSnippets(&ifacetest.Specification{})
+	c.Assert(err, ErrorMatches, `internal error: cannot describe specification of type \*ifacetest.Specification`)
+}
@@ -0,0 +1,101 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package backends
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/dbus"
+	"github.com/snapcore/snapd/interfaces/kmod"
+	"github.com/snapcore/snapd/interfaces/mount"
+	"github.com/snapcore/snapd/interfaces/polkit"
+	"github.com/snapcore/snapd/interfaces/seccomp"
+	"github.com/snapcore/snapd/interfaces/systemd"
+	"github.com/snapcore/snapd/interfaces/udev"
+)
+
+// SpecificationSnippets returns the content of a backend specification as
+// lines of text. Lines are grouped by security tag for backends which
+// generate per-application policy, and by a backend specific key, such as
+// "fstab" or "modules", otherwise.
+func SpecificationSnippets(spec interfaces.Specification) (map[string][]string, error) {
+	result := make(map[string][]string)
+	add := func(key string, snippets ...string) {
+		for _, snippet := range snippets {
+			result[key] = append(result[key], strings.Split(snippet, "\n")...)
+		}
+	}
+	addAll := func(snippets map[string][]string) {
+		for tag, tagSnippets := range snippets {
+			add(tag, tagSnippets...)
+		}
+	}
+
+	switch spec := spec.(type) {
+	case *apparmor.Specification:
+		addAll(spec.Snippets())
+		add("snap-update-ns", spec.UpdateNS()...)
+	case *seccomp.Specification:
+		addAll(spec.Snippets())
+	case *dbus.Specification:
+		addAll(spec.Snippets())
+	case *udev.Specification:
+		add("rules", spec.Snippets()...)
+	case *mount.Specification:
+		for _, entry := range spec.MountEntries() {
+			add("fstab", entry.String())
+		}
+		for _, entry := range spec.UserMountEntries() {
+			add("user-fstab", entry.String())
+		}
+	case *kmod.Specification:
+		modules := make([]string, 0, len(spec.Modules()))
+		for module := range spec.Modules() {
+			modules = append(modules, module)
+		}
+		sort.Strings(modules)
+		add("modules", modules...)
+		options := spec.ModuleOptions()
+		optionModules := make([]string, 0, len(options))
+		for module := range options {
+			optionModules = append(optionModules, module)
+		}
+		sort.Strings(optionModules)
+		for _, module := range optionModules {
+			add("module-options", fmt.Sprintf("options %s %s", module, options[module]))
+		}
+		add("disallowed-modules", spec.DisallowedModules()...)
+	case *polkit.Specification:
+		for suffix, policy := range spec.Policies() {
+			add(suffix, string(policy))
+		}
+	case *systemd.Specification:
+		for suffix, service := range spec.Services() {
+			add(suffix, service.String())
+		}
+	default:
+		return nil, fmt.Errorf("internal error: cannot describe specification of type %T", spec)
+	}
+	return result, nil
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 )
@@ -193,6 +194,57 @@ func (iface *hidrawInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) bool {
 	return true
 }
 
+func (iface *hidrawInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	if di.Subsystem() != "hidraw" || !hidrawDeviceNodePattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	return &hotplug.ProposedSlot{
+		Attrs: map[string]interface{}{
+			"path": di.DeviceName(),
+		},
+	}, nil
+}
+
+// Pattern to match the HID device in the device path of a hidraw device,
+// eg. /devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0001/hidraw/hidraw0
+var hidrawHIDDevicePattern = regexp.MustCompile(`^(.+)/([0-9A-F]{4}):([0-9A-F]{4}):([0-9A-F]{4})\.[0-9A-F]{4}/hidraw/hidraw[0-9]+$`)
+
+// HotplugKey identifies a hidraw device by the bus, vendor and product of
+// the HID device, together with the serial number of the device or, if it
+// has none, the port it is plugged into. The number the kernel gives to the
+// HID device is not stable.
+func (iface *hidrawInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	devPath, _ := di.Attribute("DEVPATH")
+	match := hidrawHIDDevicePattern.FindStringSubmatch(devPath)
+	if match == nil {
+		// use the default key
+		return "", nil
+	}
+	id, _ := di.Attribute("ID_SERIAL")
+	if id == "" {
+		id = match[1]
+	}
+	return hotplugKeyFromValues(iface.Name(), match[2], match[3], match[4], id), nil
+}
+
+// HandledByGadget matches a hidraw device against a slot of the gadget,
+// either by the USB vendor and product of the slot or by its path.
+func (iface *hidrawInterface) HandledByGadget(di *hotplug.HotplugDeviceInfo, slot *snap.SlotInfo) bool {
+	var usbVendor, usbProduct int64
+	if err := slot.Attr("usb-vendor", &usbVendor); err == nil {
+		if err := slot.Attr("usb-product", &usbProduct); err != nil {
+			return false
+		}
+		return slotDeviceAttrEqual(di, "ID_VENDOR_ID", usbVendor) && slotDeviceAttrEqual(di, "ID_MODEL_ID", usbProduct)
+	}
+
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return false
+	}
+	return di.DeviceName() == path
+}
+
 func (iface *hidrawInterface) hasUsbAttrs(attrs interfaces.Attrer) bool {
 	var v int64
 	if err := attrs.Attr("usb-vendor", &v); err == nil {
@@ -28,6 +28,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
@@ -374,6 +375,61 @@ SUBSYSTEM=="hidraw", SUBSYSTEMS=="usb", ATTRS{idVendor}=="ffff", ATTRS{idProduct
 	c.Assert(extraSnippet, Equals, expectedExtraSnippet3)
 }
 
+func (s *HidrawInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0001/hidraw/hidraw0", "DEVNAME": "/dev/hidraw0", "SUBSYSTEM": "hidraw"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]interface{}{"path": "/dev/hidraw0"}})
+
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0001/input/input5", "DEVNAME": "/dev/input/event5", "SUBSYSTEM": "input"})
+	c.Assert(err, IsNil)
+	proposedSlot, err = hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, IsNil)
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	key := func(env map[string]string) snap.HotplugKey {
+		env["SUBSYSTEM"] = "hidraw"
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		key, err := keyHandler.HotplugKey(di)
+		c.Assert(err, IsNil)
+		return key
+	}
+
+	key1 := key(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0001/hidraw/hidraw0"})
+	c.Check(key1, HasLen, 65)
+	// the kernel gave another number to the same device in the same port
+	c.Check(key(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0007/hidraw/hidraw3"}), Equals, key1)
+	// the same device in another port
+	c.Check(key(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-2/1-2:1.0/0003:046D:C52B.0002/hidraw/hidraw0"}), Not(Equals), key1)
+
+	// devices with a serial number are identified by it, in any port
+	key2 := key(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:046D:C52B.0001/hidraw/hidraw0", "ID_SERIAL": "Logitech_1234"})
+	c.Check(key2, Not(Equals), key1)
+	c.Check(key(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-2/1-2:1.0/0003:046D:C52B.0002/hidraw/hidraw0", "ID_SERIAL": "Logitech_1234"}), Equals, key2)
+
+	// unexpected device path, the default key is used
+	c.Check(key(map[string]string{"DEVPATH": "/devices/virtual/misc/uhid/hidraw0"}), Equals, snap.HotplugKey(""))
+}
+
+func (s *HidrawInterfaceSuite) TestHotplugHandledByGadget(c *C) {
+	byGadgetPred := s.iface.(hotplug.HandledByGadgetPredicate)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/0003:0001:0001.0001/hidraw/hidraw0", "DEVNAME": "/dev/hidraw0", "SUBSYSTEM": "hidraw", "ID_VENDOR_ID": "0001", "ID_MODEL_ID": "0001"})
+	c.Assert(err, IsNil)
+	// matched by the usb vendor and product of the slot
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev1Info), Equals, true)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev2Info), Equals, false)
+	// matched by the path of the slot
+	c.Check(byGadgetPred.HandledByGadget(di, s.testSlot1Info), Equals, true)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testSlot2Info), Equals, false)
+	c.Check(byGadgetPred.HandledByGadget(di, s.missingPathSlotInfo), Equals, false)
+}
+
 func (s *HidrawInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -0,0 +1,153 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/snap"
+)
+
+const networkAdapterSummary = `allows access to the settings of a specific network adapter`
+
+// network-adapter slots are created for USB network adapters as they are
+// plugged in, or declared by the gadget for built-in ones. As the adapter is
+// device-specific, connecting it is left to the operator.
+const networkAdapterBaseDeclarationSlots = `
+  network-adapter:
+    allow-installation:
+      slot-snap-type:
+        - core
+        - gadget
+    deny-auto-connection: true
+`
+
+// Network adapters have no device node, so there is nothing to tag in udev:
+// access is granted to the sysfs and procfs entries of the network interface
+// of the adapter instead.
+const networkAdapterConnectedPlugAppArmor = `
+# Description: can observe the network interface %[1]s and change its per
+# interface kernel settings
+/sys/class/net/ r,
+/sys/class/net/%[1]s r,
+/sys/devices/**/net/%[1]s/ r,
+/sys/devices/**/net/%[1]s/** r,
+/run/udev/data/n[0-9]* r,
+
+@{PROC}/sys/net/ipv{4,6}/conf/%[1]s/ r,
+@{PROC}/sys/net/ipv{4,6}/conf/%[1]s/* rw,
+@{PROC}/sys/net/ipv{4,6}/neigh/%[1]s/ r,
+@{PROC}/sys/net/ipv{4,6}/neigh/%[1]s/* rw,
+`
+
+// Pattern that is considered valid for the name of a network interface,
+// which the kernel limits to 15 characters.
+var networkAdapterInterfacePattern = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]{0,14}$`)
+
+type networkAdapterInterface struct{}
+
+func (iface *networkAdapterInterface) Name() string {
+	return "network-adapter"
+}
+
+func (iface *networkAdapterInterface) StaticInfo() interfaces.StaticInfo {
+	return interfaces.StaticInfo{
+		Summary:              networkAdapterSummary,
+		BaseDeclarationSlots: networkAdapterBaseDeclarationSlots,
+	}
+}
+
+func (iface *networkAdapterInterface) String() string {
+	return iface.Name()
+}
+
+func networkAdapterSlotInterface(slotRef *interfaces.SlotRef, attrs interfaces.Attrer) (string, error) {
+	var netIface string
+	if err := attrs.Attr("ifname", &netIface); err != nil || netIface == "" {
+		return "", fmt.Errorf("slot %q must have an ifname attribute", slotRef)
+	}
+	if !networkAdapterInterfacePattern.MatchString(netIface) {
+		return "", fmt.Errorf("slot %q ifname attribute must be a valid network interface name", slotRef)
+	}
+	return netIface, nil
+}
+
+func (iface *networkAdapterInterface) BeforePrepareSlot(slot *snap.SlotInfo) error {
+	_, err := networkAdapterSlotInterface(&interfaces.SlotRef{Snap: slot.Snap.InstanceName(), Name: slot.Name}, slot)
+	return err
+}
+
+func (iface *networkAdapterInterface) AppArmorConnectedPlug(spec *apparmor.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+	netIface, err := networkAdapterSlotInterface(slot.Ref(), slot)
+	if err != nil {
+		return nil
+	}
+	spec.AddSnippet(fmt.Sprintf(networkAdapterConnectedPlugAppArmor, netIface))
+	return nil
+}
+
+func (iface *networkAdapterInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) bool {
+	// Allow what is allowed in the declarations
+	return true
+}
+
+// HotplugDeviceDetected proposes a slot for each USB network adapter, with
+// the name of its network interface.
+func (iface *networkAdapterInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	bus, _ := di.Attribute("ID_BUS")
+	netIface, _ := di.Attribute("INTERFACE")
+	if di.Subsystem() != "net" || bus != "usb" || !networkAdapterInterfacePattern.MatchString(netIface) {
+		return nil, nil
+	}
+	return &hotplug.ProposedSlot{
+		Attrs: map[string]interface{}{
+			"ifname": netIface,
+		},
+	}, nil
+}
+
+// HotplugKey identifies a network adapter by its MAC address, falling back
+// to the serial number of the USB device and the number of its interface, as
+// the default key would be the same for all the adapters of a model.
+func (iface *networkAdapterInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	if mac, _ := di.Attribute("ID_NET_NAME_MAC"); mac != "" {
+		return hotplugKeyFromValues(iface.Name(), mac), nil
+	}
+	serial, _ := di.Attribute("ID_SERIAL")
+	usbIface, _ := di.Attribute("ID_USB_INTERFACE_NUM")
+	return hotplugKeyFromValues(iface.Name(), serial, usbIface), nil
+}
+
+func (iface *networkAdapterInterface) HandledByGadget(di *hotplug.HotplugDeviceInfo, slot *snap.SlotInfo) bool {
+	var netIface string
+	if err := slot.Attr("ifname", &netIface); err != nil {
+		return false
+	}
+	devIface, _ := di.Attribute("INTERFACE")
+	return devIface == netIface
+}
+
+func init() {
+	registerIface(&networkAdapterInterface{})
+}
@@ -0,0 +1,175 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package builtin_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type NetworkAdapterInterfaceSuite struct {
+	iface    interfaces.Interface
+	slotInfo *snap.SlotInfo
+	slot     *interfaces.ConnectedSlot
+	plugInfo *snap.PlugInfo
+	plug     *interfaces.ConnectedPlug
+}
+
+var _ = Suite(&NetworkAdapterInterfaceSuite{
+	iface: builtin.MustInterface("network-adapter"),
+})
+
+const networkAdapterConsumerYaml = `name: consumer
+version: 0
+apps:
+ app:
+  plugs: [network-adapter]
+`
+
+const networkAdapterGadgetYaml = `name: gadget
+version: 0
+type: gadget
+slots:
+  usb-nic:
+    interface: network-adapter
+    ifname: enx001122334455
+`
+
+func (s *NetworkAdapterInterfaceSuite) SetUpTest(c *C) {
+	s.plug, s.plugInfo = MockConnectedPlug(c, networkAdapterConsumerYaml, nil, "network-adapter")
+	s.slot, s.slotInfo = MockConnectedSlot(c, networkAdapterGadgetYaml, nil, "usb-nic")
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestName(c *C) {
+	c.Assert(s.iface.Name(), Equals, "network-adapter")
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestSanitizeSlot(c *C) {
+	c.Assert(interfaces.BeforePrepareSlot(s.iface, s.slotInfo), IsNil)
+
+	for _, netIface := range []string{"", "../lo", "eth0/x", "enx0011223344556677", ".hidden", "eth0,", "eth{0,1}"} {
+		info := snaptest.MockInfo(c, `name: gadget
+version: 0
+type: gadget
+slots:
+  usb-nic:
+    interface: network-adapter
+`, nil)
+		slot := info.Slots["usb-nic"]
+		slot.Attrs = map[string]interface{}{"ifname": netIface}
+		err := interfaces.BeforePrepareSlot(s.iface, slot)
+		c.Check(err, ErrorMatches, `slot "gadget:usb-nic" (must have an ifname attribute|ifname attribute must be a valid network interface name)`, Commentf("%q", netIface))
+	}
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestSanitizePlug(c *C) {
+	c.Assert(interfaces.BeforePreparePlug(s.iface, s.plugInfo), IsNil)
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestAppArmorSpec(c *C) {
+	appSet, err := interfaces.NewSnapAppSet(s.plug.Snap(), nil)
+	c.Assert(err, IsNil)
+	spec := apparmor.NewSpecification(appSet)
+	c.Assert(spec.AddConnectedPlug(s.iface, s.plug, s.slot), IsNil)
+	c.Assert(spec.SecurityTags(), DeepEquals, []string{"snap.consumer.app"})
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "/sys/devices/**/net/enx001122334455/** r,\n")
+	c.Check(spec.SnippetForTag("snap.consumer.app"), testutil.Contains, "@{PROC}/sys/net/ipv{4,6}/conf/enx001122334455/* rw,\n")
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestStaticInfo(c *C) {
+	si := interfaces.StaticInfoOf(s.iface)
+	c.Assert(si.ImplicitOnCore, Equals, false)
+	c.Assert(si.ImplicitOnClassic, Equals, false)
+	c.Assert(si.Summary, Equals, `allows access to the settings of a specific network adapter`)
+	c.Assert(si.BaseDeclarationSlots, testutil.Contains, "network-adapter")
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestAutoConnect(c *C) {
+	c.Assert(s.iface.AutoConnect(s.plugInfo, s.slotInfo), Equals, true)
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/net/enx001122334455", "INTERFACE": "enx001122334455", "SUBSYSTEM": "net", "ID_BUS": "usb"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]interface{}{"ifname": "enx001122334455"}})
+
+	for _, env := range []map[string]string{
+		// built-in adapter
+		{"DEVPATH": "/devices/pci0000:00/0000:00:1f.6/net/eno1", "INTERFACE": "eno1", "SUBSYSTEM": "net", "ID_BUS": "pci"},
+		// not a network adapter
+		{"DEVPATH": "/devices/pci0000:00/usb2/2-1/block/sdb/sdb1", "DEVNAME": "/dev/sdb1", "DEVTYPE": "partition", "SUBSYSTEM": "block", "ID_BUS": "usb"},
+	} {
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+		c.Assert(err, IsNil)
+		c.Check(proposedSlot, IsNil, Commentf("%v", env))
+	}
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	key := func(env map[string]string) snap.HotplugKey {
+		env["DEVPATH"] = "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/net/eth1"
+		env["SUBSYSTEM"] = "net"
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		key, err := keyHandler.HotplugKey(di)
+		c.Assert(err, IsNil)
+		return key
+	}
+
+	macKey := key(map[string]string{"ID_NET_NAME_MAC": "enx001122334455", "INTERFACE": "eth1"})
+	c.Check(macKey, HasLen, 65)
+	// the same adapter plugged in again, under another name
+	c.Check(key(map[string]string{"ID_NET_NAME_MAC": "enx001122334455", "INTERFACE": "eth2"}), Equals, macKey)
+	// another adapter of the same model
+	c.Check(key(map[string]string{"ID_NET_NAME_MAC": "enx001122334466", "INTERFACE": "eth1"}), Not(Equals), macKey)
+	// without a MAC address the USB serial number and interface are used
+	serialKey := key(map[string]string{"ID_SERIAL": "NIC_1234", "ID_USB_INTERFACE_NUM": "00"})
+	c.Check(serialKey, HasLen, 65)
+	c.Check(key(map[string]string{"ID_SERIAL": "NIC_1234", "ID_USB_INTERFACE_NUM": "02"}), Not(Equals), serialKey)
+	// and otherwise the default key
+	c.Check(key(map[string]string{"ID_SERIAL": "NIC_1234"}), Equals, snap.HotplugKey(""))
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestHotplugHandledByGadget(c *C) {
+	byGadgetPred := s.iface.(hotplug.HandledByGadgetPredicate)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-1/1-1:1.0/net/enx001122334455", "INTERFACE": "enx001122334455", "SUBSYSTEM": "net"})
+	c.Assert(err, IsNil)
+	c.Check(byGadgetPred.HandledByGadget(di, s.slotInfo), Equals, true)
+	di, err = hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/0000:00:14.0/usb1/1-2/1-2:1.0/net/enx001122334466", "INTERFACE": "enx001122334466", "SUBSYSTEM": "net"})
+	c.Assert(err, IsNil)
+	c.Check(byGadgetPred.HandledByGadget(di, s.slotInfo), Equals, false)
+}
+
+func (s *NetworkAdapterInterfaceSuite) TestInterfaces(c *C) {
+	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 )
@@ -154,6 +155,44 @@ func (iface *rawVolumeInterface) AutoConnect(*snap.PlugInfo, *snap.SlotInfo) boo
 	return true
 }
 
+// HotplugDeviceDetected proposes a slot for each partition of a removable
+// USB disk.
+func (iface *rawVolumeInterface) HotplugDeviceDetected(di *hotplug.HotplugDeviceInfo) (*hotplug.ProposedSlot, error) {
+	bus, _ := di.Attribute("ID_BUS")
+	if di.Subsystem() != "block" || di.DeviceType() != "partition" || bus != "usb" || !rawVolumePartitionPattern.MatchString(di.DeviceName()) {
+		return nil, nil
+	}
+	return &hotplug.ProposedSlot{
+		Attrs: map[string]interface{}{
+			"path": di.DeviceName(),
+		},
+	}, nil
+}
+
+// HotplugKey identifies a partition by its UUID from the partition table,
+// falling back to the serial number of the disk and the partition number,
+// and finally to the device path, as the default key would be the same for
+// all the partitions of a disk.
+func (iface *rawVolumeInterface) HotplugKey(di *hotplug.HotplugDeviceInfo) (snap.HotplugKey, error) {
+	if uuid, _ := di.Attribute("ID_PART_ENTRY_UUID"); uuid != "" {
+		return hotplugKeyFromValues(iface.Name(), uuid), nil
+	}
+	serial, _ := di.Attribute("ID_SERIAL")
+	partition, _ := di.Attribute("ID_PART_ENTRY_NUMBER")
+	if key := hotplugKeyFromValues(iface.Name(), serial, partition); key != "" {
+		return key, nil
+	}
+	return hotplugKeyFromValues(iface.Name(), di.DevicePath()), nil
+}
+
+func (iface *rawVolumeInterface) HandledByGadget(di *hotplug.HotplugDeviceInfo, slot *snap.SlotInfo) bool {
+	var path string
+	if err := slot.Attr("path", &path); err != nil {
+		return false
+	}
+	return di.DeviceName() == path
+}
+
 func init() {
 	registerIface(&rawVolumeInterface{})
 }
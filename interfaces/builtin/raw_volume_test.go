@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/interfaces/apparmor"
 	"github.com/snapcore/snapd/interfaces/builtin"
+	"github.com/snapcore/snapd/interfaces/hotplug"
 	"github.com/snapcore/snapd/interfaces/udev"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/snaptest"
@@ -351,6 +352,68 @@ func (s *rawVolumeInterfaceSuite) TestAutoConnect(c *C) {
 	c.Check(s.iface.AutoConnect(nil, nil), Equals, true)
 }
 
+func (s *rawVolumeInterfaceSuite) TestHotplugDeviceDetected(c *C) {
+	hotplugIface := s.iface.(hotplug.Definer)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/pci0000:00/usb2/2-1/block/sdb/sdb1", "DEVNAME": "/dev/sdb1", "DEVTYPE": "partition", "SUBSYSTEM": "block", "ID_BUS": "usb", "ID_SERIAL": "Disk_1234", "ID_PART_ENTRY_NUMBER": "1"})
+	c.Assert(err, IsNil)
+	proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+	c.Assert(err, IsNil)
+	c.Check(proposedSlot, DeepEquals, &hotplug.ProposedSlot{Attrs: map[string]interface{}{"path": "/dev/sdb1"}})
+
+	for _, env := range []map[string]string{
+		// whole disk
+		{"DEVPATH": "/devices/pci0000:00/usb2/2-1/block/sdb", "DEVNAME": "/dev/sdb", "DEVTYPE": "disk", "SUBSYSTEM": "block", "ID_BUS": "usb"},
+		// not on USB
+		{"DEVPATH": "/devices/pci0000:00/ata1/block/sda/sda1", "DEVNAME": "/dev/sda1", "DEVTYPE": "partition", "SUBSYSTEM": "block", "ID_BUS": "ata"},
+		// not a disk partition
+		{"DEVPATH": "/devices/virtual/block/loop0/loop0p1", "DEVNAME": "/dev/loop0p1", "DEVTYPE": "partition", "SUBSYSTEM": "block", "ID_BUS": "usb"},
+	} {
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		proposedSlot, err := hotplugIface.HotplugDeviceDetected(di)
+		c.Assert(err, IsNil)
+		c.Check(proposedSlot, IsNil, Commentf("%v", env))
+	}
+}
+
+func (s *rawVolumeInterfaceSuite) TestHotplugKey(c *C) {
+	keyHandler := s.iface.(hotplug.HotplugKeyHandler)
+	key := func(env map[string]string) snap.HotplugKey {
+		env["DEVPATH"] = "/devices/pci0000:00/usb2/2-1/block/sdb/sdb1"
+		di, err := hotplug.NewHotplugDeviceInfo(env)
+		c.Assert(err, IsNil)
+		key, err := keyHandler.HotplugKey(di)
+		c.Assert(err, IsNil)
+		return key
+	}
+
+	key1 := key(map[string]string{"ID_SERIAL": "Disk_1234", "ID_PART_ENTRY_NUMBER": "1"})
+	c.Check(key1, HasLen, 65)
+	// the same partition plugged in again, under another name
+	c.Check(key(map[string]string{"ID_SERIAL": "Disk_1234", "ID_PART_ENTRY_NUMBER": "1", "DEVNAME": "/dev/sdc1"}), Equals, key1)
+	// another partition of the same disk
+	c.Check(key(map[string]string{"ID_SERIAL": "Disk_1234", "ID_PART_ENTRY_NUMBER": "2"}), Not(Equals), key1)
+	// the partition UUID is preferred when known
+	uuidKey := key(map[string]string{"ID_SERIAL": "Disk_1234", "ID_PART_ENTRY_NUMBER": "1", "ID_PART_ENTRY_UUID": "0e1d6a1f-01"})
+	c.Check(uuidKey, HasLen, 65)
+	c.Check(uuidKey, Not(Equals), key1)
+	c.Check(key(map[string]string{"ID_PART_ENTRY_NUMBER": "2", "ID_PART_ENTRY_UUID": "0e1d6a1f-01"}), Equals, uuidKey)
+	c.Check(key(map[string]string{"ID_PART_ENTRY_UUID": "0e1d6a1f-02"}), Not(Equals), uuidKey)
+	// without a serial number the device path is used
+	pathKey := key(map[string]string{"ID_PART_ENTRY_NUMBER": "1"})
+	c.Check(pathKey, HasLen, 65)
+	c.Check(pathKey, Not(Equals), key1)
+	c.Check(key(map[string]string{}), Equals, pathKey)
+}
+
+func (s *rawVolumeInterfaceSuite) TestHotplugHandledByGadget(c *C) {
+	byGadgetPred := s.iface.(hotplug.HandledByGadgetPredicate)
+	di, err := hotplug.NewHotplugDeviceInfo(map[string]string{"DEVPATH": "/devices/virtio/block/vda/vda1", "DEVNAME": "/dev/vda1", "DEVTYPE": "partition", "SUBSYSTEM": "block"})
+	c.Assert(err, IsNil)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev1Info), Equals, true)
+	c.Check(byGadgetPred.HandledByGadget(di, s.testUDev2Info), Equals, false)
+}
+
 func (s *rawVolumeInterfaceSuite) TestInterfaces(c *C) {
 	c.Check(builtin.Interfaces(), testutil.DeepContains, s.iface)
 }
@@ -20,6 +20,7 @@
 package builtin
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -76,6 +77,25 @@ func verifySlotPathAttribute(slotRef *interfaces.SlotRef, attrs interfaces.Attre
 	return cleanPath, nil
 }
 
+// hotplugKeyFromValues computes the hotplug key of a device out of the given
+// values, for interfaces where the attributes used for the default key do
+// not tell devices apart. Like the default keys, the result is a version
+// number followed by a sha256 checksum. An empty key is returned if any of
+// the values is empty.
+func hotplugKeyFromValues(ifaceName string, values ...string) snap.HotplugKey {
+	key := sha256.New()
+	key.Write([]byte(ifaceName))
+	key.Write([]byte{0})
+	for _, val := range values {
+		if val == "" {
+			return ""
+		}
+		key.Write([]byte(val))
+		key.Write([]byte{0})
+	}
+	return snap.HotplugKey(fmt.Sprintf("0%x", key.Sum(nil)))
+}
+
 // aareExclusivePatterns takes a string and generates deny alternations. Eg,
 // aareExclusivePatterns("foo") returns:
 //
@@ -38,6 +38,8 @@ type TestSecurityBackend struct {
 	RemoveCallback func(snapName string) error
 	// SandboxFeaturesCallback is a callback that is optionally called in SandboxFeatures
 	SandboxFeaturesCallback func() []string
+	// RenderFilesCallback is a callback that is optionally called in RenderFiles
+	RenderFilesCallback func(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error)
 }
 
 // TestSetupCall stores details about calls to TestSecurityBackend.Setup
@@ -87,6 +89,14 @@ func (b *TestSecurityBackend) SandboxFeatures() []string {
 	return b.SandboxFeaturesCallback()
 }
 
+// RenderFiles calls the render files callback if one is defined.
+func (b *TestSecurityBackend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	if b.RenderFilesCallback == nil {
+		return nil, nil
+	}
+	return b.RenderFilesCallback(spec, appSet, opts)
+}
+
 // TestSecurityBackendSetupMany is a security backend that implements SetupMany on top of TestSecurityBackend.
 type TestSecurityBackendSetupMany struct {
 	TestSecurityBackend
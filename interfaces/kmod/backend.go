@@ -163,6 +163,17 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// RenderFiles returns the kernel module and modprobe configuration files that
+// Setup would write for a snap with the given specification, keyed by their
+// path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	content, _ := deriveContent(spec.(*Specification), appSet)
+	interfaces.AddRenderedFiles(files, dirs.SnapKModModulesDir, content)
+	interfaces.AddRenderedFiles(files, dirs.SnapKModModprobeDir, prepareModprobeDirContents(spec.(*Specification), appSet))
+	return files, nil
+}
+
 func deriveContent(spec *Specification, appSet *interfaces.SnapAppSet) (map[string]osutil.FileState, []string) {
 	if len(spec.modules) == 0 {
 		return nil, nil
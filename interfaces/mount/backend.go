@@ -69,9 +69,7 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 
 	snapInfo := appSet.Info()
 
-	spec.(*Specification).AddOvername(snapInfo)
-	spec.(*Specification).AddLayout(snapInfo)
-	spec.(*Specification).AddExtraLayouts(opts.ExtraLayouts)
+	addSnapEntries(spec.(*Specification), snapInfo, opts)
 	content := deriveContent(spec.(*Specification), snapInfo)
 	// synchronize the content with the filesystem
 	glob := fmt.Sprintf("snap.%s.*fstab", snapName)
@@ -126,6 +124,24 @@ func addMountProfile(content map[string]osutil.FileState, fname string, entries
 }
 
 // deriveContent computes .fstab tables based on requests made to the specification.
+// addSnapEntries adds to the specification the mount entries which do not
+// come from interfaces but from the snap itself.
+func addSnapEntries(spec *Specification, snapInfo *snap.Info, opts interfaces.ConfinementOptions) {
+	spec.AddOvername(snapInfo)
+	spec.AddLayout(snapInfo)
+	spec.AddExtraLayouts(opts.ExtraLayouts)
+}
+
+// RenderFiles returns the mount profiles that Setup would write for a snap
+// with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	snapInfo := appSet.Info()
+	addSnapEntries(spec.(*Specification), snapInfo, opts)
+	files := make(map[string][]byte)
+	interfaces.AddRenderedFiles(files, dirs.SnapMountPolicyDir, deriveContent(spec.(*Specification), snapInfo))
+	return files, nil
+}
+
 func deriveContent(spec *Specification, snapInfo *snap.Info) map[string]osutil.FileState {
 	content := make(map[string]osutil.FileState, 2)
 	snapName := snapInfo.InstanceName()
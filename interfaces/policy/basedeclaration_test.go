@@ -836,6 +836,7 @@ var (
 		"mount-control":             {"core"},
 		"mpris":                     {"app"},
 		"netlink-driver":            {"core", "gadget"},
+		"network-adapter":           {"core", "gadget"},
 		"network-manager":           {"app", "core"},
 		"network-manager-observe":   {"app", "core"},
 		"network-status":            {"core"},
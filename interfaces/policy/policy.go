@@ -137,6 +137,16 @@ type ConnectCandidate struct {
 
 	Model *asserts.Model
 	Store *asserts.Store
+
+	// steps collects an account of the checks performed, see Explain.
+	steps []string
+}
+
+// tracef records a step of the checks performed, if Explain was used.
+func (connc *ConnectCandidate) tracef(format string, args ...interface{}) {
+	if connc.steps != nil {
+		connc.steps = append(connc.steps, fmt.Sprintf(format, args...))
+	}
 }
 
 func nestedGet(which string, attrs interfaces.Attrer, path string) (interface{}, error) {
@@ -195,13 +205,17 @@ func (connc *ConnectCandidate) checkPlugRule(kind string, rule *asserts.PlugRule
 		allowConst = rule.AllowAutoConnection
 	}
 	if _, err := checkPlugConnectionAltConstraints(connc, denyConst); err == nil {
+		connc.tracef("deny-%s constraints of the plug rule match", kind)
 		return nil, fmt.Errorf("%s denied by plug rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
+	connc.tracef("deny-%s constraints of the plug rule do not match", kind)
 
 	allowedConstraints, err := checkPlugConnectionAltConstraints(connc, allowConst)
 	if err != nil {
+		connc.tracef("allow-%s constraints of the plug rule do not match: %v", kind, err)
 		return nil, fmt.Errorf("%s not allowed by plug rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
+	connc.tracef("allow-%s constraints of the plug rule match", kind)
 	return sideArity{allowedConstraints.SlotsPerPlug}, nil
 }
 
@@ -217,13 +231,17 @@ func (connc *ConnectCandidate) checkSlotRule(kind string, rule *asserts.SlotRule
 		allowConst = rule.AllowAutoConnection
 	}
 	if _, err := checkSlotConnectionAltConstraints(connc, denyConst); err == nil {
+		connc.tracef("deny-%s constraints of the slot rule match", kind)
 		return nil, fmt.Errorf("%s denied by slot rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
+	connc.tracef("deny-%s constraints of the slot rule do not match", kind)
 
 	allowedConstraints, err := checkSlotConnectionAltConstraints(connc, allowConst)
 	if err != nil {
+		connc.tracef("allow-%s constraints of the slot rule do not match: %v", kind, err)
 		return nil, fmt.Errorf("%s not allowed by slot rule of interface %q%s", kind, connc.Plug.Interface(), context)
 	}
+	connc.tracef("allow-%s constraints of the slot rule match", kind)
 	return sideArity{allowedConstraints.SlotsPerPlug}, nil
 }
 
@@ -241,20 +259,32 @@ func (connc *ConnectCandidate) check(kind string) (interfaces.SideArity, error)
 
 	if plugDecl := connc.PlugSnapDeclaration; plugDecl != nil {
 		if rule := plugDecl.PlugRule(iface); rule != nil {
+			connc.tracef("using plug rule of interface %q from the snap-declaration of %q", iface, plugDecl.SnapName())
 			return connc.checkPlugRule(kind, rule, true)
 		}
+		connc.tracef("snap-declaration of %q has no plug rule for interface %q", plugDecl.SnapName(), iface)
+	} else {
+		connc.tracef("plug snap %q has no snap-declaration", connc.Plug.Snap().InstanceName())
 	}
 	if slotDecl := connc.SlotSnapDeclaration; slotDecl != nil {
 		if rule := slotDecl.SlotRule(iface); rule != nil {
+			connc.tracef("using slot rule of interface %q from the snap-declaration of %q", iface, slotDecl.SnapName())
 			return connc.checkSlotRule(kind, rule, true)
 		}
+		connc.tracef("snap-declaration of %q has no slot rule for interface %q", slotDecl.SnapName(), iface)
+	} else {
+		connc.tracef("slot snap %q has no snap-declaration", connc.Slot.Snap().InstanceName())
 	}
 	if rule := baseDecl.PlugRule(iface); rule != nil {
+		connc.tracef("using plug rule of interface %q from the base-declaration", iface)
 		return connc.checkPlugRule(kind, rule, false)
 	}
+	connc.tracef("base-declaration has no plug rule for interface %q", iface)
 	if rule := baseDecl.SlotRule(iface); rule != nil {
+		connc.tracef("using slot rule of interface %q from the base-declaration", iface)
 		return connc.checkSlotRule(kind, rule, false)
 	}
+	connc.tracef("base-declaration has no slot rule for interface %q", iface)
 	return nil, nil
 }
 
@@ -278,6 +308,27 @@ func (connc *ConnectCandidate) CheckAutoConnect() (interfaces.SideArity, error)
 	return arity, nil
 }
 
+// Explain checks whether the connection, or the auto-connection if
+// autoConnect is set, is allowed like Check and CheckAutoConnect do. Along
+// with the result of the check it returns a step-by-step account of the
+// declaration rules and constraints that were considered.
+func (connc *ConnectCandidate) Explain(autoConnect bool) (steps []string, err error) {
+	kind := "connection"
+	if autoConnect {
+		kind = "auto-connection"
+	}
+	connc.steps = []string{}
+	defer func() { connc.steps = nil }()
+
+	connc.tracef("checking %s of plug %s:%s to slot %s:%s", kind, connc.Plug.Snap().InstanceName(), connc.Plug.Name(), connc.Slot.Snap().InstanceName(), connc.Slot.Name())
+	if _, err = connc.check(kind); err != nil {
+		connc.tracef("%s denied", kind)
+	} else {
+		connc.tracef("%s allowed", kind)
+	}
+	return connc.steps, err
+}
+
 // InstallCandidateMinimalCheck represents a candidate snap installed with --dangerous flag that should pass minimum checks
 // against snap type (if present). It doesn't check interface attributes.
 type InstallCandidateMinimalCheck struct {
@@ -1146,6 +1146,83 @@ func (s *policySuite) TestSnapDeclAllowDenyAutoConnection(c *C) {
 	}
 }
 
+func (s *policySuite) TestExplainBaselineDefaultIsAllow(c *C) {
+	cand := policy.ConnectCandidate{
+		Plug:            interfaces.NewConnectedPlug(s.plugSnap.Plugs["random"], s.plugAppSet, nil, nil),
+		Slot:            interfaces.NewConnectedSlot(s.slotSnap.Slots["random"], s.slotAppSet, nil, nil),
+		BaseDeclaration: s.baseDecl,
+	}
+
+	steps, err := cand.Explain(false)
+	c.Check(err, IsNil)
+	c.Check(steps, DeepEquals, []string{
+		"checking connection of plug plug-snap:random to slot slot-snap:random",
+		`plug snap "plug-snap" has no snap-declaration`,
+		`slot snap "slot-snap" has no snap-declaration`,
+		`base-declaration has no plug rule for interface "random"`,
+		`base-declaration has no slot rule for interface "random"`,
+		"connection allowed",
+	})
+}
+
+func (s *policySuite) TestExplainSnapDeclDenyConnection(c *C) {
+	cand := policy.ConnectCandidate{
+		Plug:                interfaces.NewConnectedPlug(s.plugSnap.Plugs["snap-slot-deny"], s.plugAppSet, nil, nil),
+		Slot:                interfaces.NewConnectedSlot(s.slotSnap.Slots["snap-slot-deny"], s.slotAppSet, nil, nil),
+		PlugSnapDeclaration: s.plugDecl,
+		SlotSnapDeclaration: s.slotDecl,
+		BaseDeclaration:     s.baseDecl,
+	}
+
+	steps, err := cand.Explain(false)
+	c.Check(err, ErrorMatches, `connection denied by slot rule of interface "snap-slot-deny" for "slot-snap" snap`)
+	c.Check(steps, DeepEquals, []string{
+		"checking connection of plug plug-snap:snap-slot-deny to slot slot-snap:snap-slot-deny",
+		`snap-declaration of "plug-snap" has no plug rule for interface "snap-slot-deny"`,
+		`using slot rule of interface "snap-slot-deny" from the snap-declaration of "slot-snap"`,
+		"deny-connection constraints of the slot rule match",
+		"connection denied",
+	})
+
+	// Explain does not leave a trace behind for the regular checks
+	c.Check(cand.Check(), ErrorMatches, `connection denied by slot rule.*`)
+	steps, err = cand.Explain(false)
+	c.Check(err, NotNil)
+	c.Check(steps, HasLen, 5)
+}
+
+func (s *policySuite) TestExplainBaseDeclAutoConnection(c *C) {
+	tests := []struct {
+		iface    string
+		expected string // "" => no error
+		lastRule string
+	}{
+		{"auto-base-plug-allow", "", "allow-auto-connection constraints of the plug rule match"},
+		{"auto-base-plug-deny", `auto-connection denied by plug rule.*`, "deny-auto-connection constraints of the plug rule match"},
+		{"auto-base-slot-not-allow", `auto-connection not allowed by slot rule.*`, "allow-auto-connection constraints of the slot rule do not match: .*"},
+	}
+
+	for _, t := range tests {
+		cand := policy.ConnectCandidate{
+			Plug:            interfaces.NewConnectedPlug(s.plugSnap.Plugs[t.iface], s.plugAppSet, nil, nil),
+			Slot:            interfaces.NewConnectedSlot(s.slotSnap.Slots[t.iface], s.slotAppSet, nil, nil),
+			BaseDeclaration: s.baseDecl,
+		}
+
+		steps, err := cand.Explain(true)
+		c.Assert(len(steps) > 2, Equals, true)
+		c.Check(steps[0], Equals, fmt.Sprintf("checking auto-connection of plug plug-snap:%s to slot slot-snap:%s", t.iface, t.iface))
+		c.Check(steps[len(steps)-2], Matches, t.lastRule)
+		if t.expected == "" {
+			c.Check(err, IsNil)
+			c.Check(steps[len(steps)-1], Equals, "auto-connection allowed")
+		} else {
+			c.Check(err, ErrorMatches, t.expected)
+			c.Check(steps[len(steps)-1], Equals, "auto-connection denied")
+		}
+	}
+}
+
 func (s *policySuite) TestSnapTypeCheckConnection(c *C) {
 	gadgetAppSet := ifacetest.MockInfoAndAppSet(c, `
 name: gadget
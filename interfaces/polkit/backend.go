@@ -99,6 +99,14 @@ func (b *Backend) Remove(snapName string) error {
 
 // deriveContent combines security snippets collected from all the interfaces
 // affecting a given snap into a content map applicable to EnsureDirState.
+// RenderFiles returns the polkit policy files that Setup would write for a
+// snap with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	interfaces.AddRenderedFiles(files, dirs.SnapPolkitPolicyDir, deriveContent(spec.(*Specification), appSet))
+	return files, nil
+}
+
 func deriveContent(spec *Specification, appSet *interfaces.SnapAppSet) map[string]osutil.FileState {
 	policies := spec.Policies()
 	if len(policies) == 0 {
@@ -22,6 +22,7 @@ package prompting
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/snapcore/snapd/interfaces/prompting/patterns"
 	"github.com/snapcore/snapd/logger"
@@ -149,6 +150,17 @@ var (
 	}
 )
 
+// SupportedInterfaces returns the sorted list of interfaces for which
+// prompting is supported.
+func SupportedInterfaces() []string {
+	ifaces := make([]string, 0, len(interfacePermissionsAvailable))
+	for iface := range interfacePermissionsAvailable {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+	return ifaces
+}
+
 // AvailablePermissions returns the list of available permissions for the given
 // interface.
 func AvailablePermissions(iface string) ([]string, error) {
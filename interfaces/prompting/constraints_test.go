@@ -384,6 +384,16 @@ func (s *constraintsSuite) TestInterfaceFilePermissionsMapsCorrectness(c *C) {
 	}
 }
 
+func (s *constraintsSuite) TestSupportedInterfaces(c *C) {
+	ifaces := prompting.SupportedInterfaces()
+	c.Check(ifaces, HasLen, len(prompting.InterfacePermissionsAvailable))
+	for _, iface := range ifaces {
+		_, ok := prompting.InterfacePermissionsAvailable[iface]
+		c.Check(ok, Equals, true, Commentf("unexpected interface: %s", iface))
+	}
+	c.Check(ifaces, testutil.Contains, "home")
+}
+
 func (s *constraintsSuite) TestAvailablePermissions(c *C) {
 	for iface, perms := range prompting.InterfacePermissionsAvailable {
 		available, err := prompting.AvailablePermissions(iface)
@@ -33,6 +33,16 @@ var (
 	ErrExpirationInThePast = fmt.Errorf("cannot have expiration time in the past")
 )
 
+const (
+	// TimeoutOption is the system option holding, per interface, the
+	// duration after which an unanswered prompt is resolved automatically.
+	TimeoutOption = "experimental.apparmor-prompting-timeout"
+	// TimeoutOutcomeOption is the system option holding, per interface, the
+	// outcome used to resolve prompts which timed out, either "deny" (the
+	// default) or "allow-once".
+	TimeoutOutcomeOption = "experimental.apparmor-prompting-timeout-outcome"
+)
+
 // Metadata stores information about the origin or applicability of a prompt or
 // rule.
 type Metadata struct {
@@ -410,6 +410,22 @@ func (pdb *PromptDB) promptWithID(user uint32, id prompting.IDType) (*userPrompt
 //
 // Records a notice for the prompt, and returns the prompt's former contents.
 func (pdb *PromptDB) Reply(user uint32, id prompting.IDType, outcome prompting.OutcomeType) (*Prompt, error) {
+	return pdb.resolve(user, id, outcome, "replied")
+}
+
+// Timeout resolves the prompt with the given ID using the given default
+// outcome, as no reply was received for it in time. A reply is sent to all
+// associated listener requests and the prompt is removed from the prompt DB.
+//
+// Records a notice for the prompt, and returns the prompt's former contents.
+func (pdb *PromptDB) Timeout(user uint32, id prompting.IDType, outcome prompting.OutcomeType) (*Prompt, error) {
+	return pdb.resolve(user, id, outcome, "timeout")
+}
+
+// resolve sends a reply with the given outcome to all listener requests
+// associated with the prompt with the given ID, removes the prompt from the
+// prompt DB, and records a notice with the given resolution.
+func (pdb *PromptDB) resolve(user uint32, id prompting.IDType, outcome prompting.OutcomeType, resolution string) (*Prompt, error) {
 	pdb.mutex.Lock()
 	defer pdb.mutex.Unlock()
 	userEntry, prompt, err := pdb.promptWithID(user, id)
@@ -427,7 +443,7 @@ func (pdb *PromptDB) Reply(user uint32, id prompting.IDType, outcome prompting.O
 		}
 	}
 	userEntry.remove(id)
-	data := map[string]string{"resolved": "replied"}
+	data := map[string]string{"resolved": resolution}
 	pdb.notifyPrompt(user, id, data)
 	return prompt, nil
 }
@@ -604,6 +604,63 @@ func (s *requestpromptsSuite) TestReplyErrors(c *C) {
 	s.checkNewNoticesSimple(c, []prompting.IDType{}, nil)
 }
 
+func (s *requestpromptsSuite) TestTimeout(c *C) {
+	listenerReqChan := make(chan *listener.Request, 1)
+	replyChan := make(chan any, 1)
+	restore := requestprompts.MockSendReply(func(listenerReq *listener.Request, allowedPermission any) error {
+		listenerReqChan <- listenerReq
+		replyChan <- allowedPermission
+		return nil
+	})
+	defer restore()
+
+	pdb, err := requestprompts.New(s.defaultNotifyPrompt)
+	c.Assert(err, IsNil)
+	defer pdb.Close()
+
+	metadata := &prompting.Metadata{
+		User:      s.defaultUser,
+		Snap:      "nextcloud",
+		Interface: "home",
+	}
+	path := "/home/test/Documents/foo.txt"
+	permissions := []string{"read", "write"}
+
+	for _, outcome := range []prompting.OutcomeType{prompting.OutcomeAllow, prompting.OutcomeDeny} {
+		listenerReq := &listener.Request{}
+
+		prompt, merged, err := pdb.AddOrMerge(metadata, path, permissions, permissions, listenerReq)
+		c.Assert(err, IsNil)
+		c.Check(merged, Equals, false)
+
+		s.checkNewNoticesSimple(c, []prompting.IDType{prompt.ID}, nil)
+
+		timedOutPrompt, err := pdb.Timeout(metadata.User, prompt.ID, outcome)
+		c.Check(err, IsNil)
+		c.Check(timedOutPrompt, Equals, prompt)
+
+		receivedReq, allowedPermission, err := s.waitForListenerReqAndReply(c, listenerReqChan, replyChan)
+		c.Check(err, IsNil)
+		c.Check(receivedReq, Equals, listenerReq)
+		if outcome == prompting.OutcomeAllow {
+			expectedPerm, err := prompting.AbstractPermissionsToAppArmorPermissions("home", permissions)
+			c.Check(err, IsNil)
+			c.Check(allowedPermission, DeepEquals, expectedPerm)
+		} else {
+			c.Check(allowedPermission, DeepEquals, notify.FilePermission(0))
+		}
+
+		expectedData := map[string]string{"resolved": "timeout"}
+		s.checkNewNoticesSimple(c, []prompting.IDType{prompt.ID}, expectedData)
+
+		// The prompt is gone, so a reply or second timeout fails
+		_, err = pdb.Timeout(metadata.User, prompt.ID, outcome)
+		c.Check(err, Equals, requestprompts.ErrNotFound)
+		_, err = pdb.Reply(metadata.User, prompt.ID, outcome)
+		c.Check(err, Equals, requestprompts.ErrNotFound)
+	}
+}
+
 func (s *requestpromptsSuite) TestHandleNewRuleAllowPermissions(c *C) {
 	listenerReqChan := make(chan *listener.Request, 2)
 	replyChan := make(chan any, 2)
@@ -893,6 +893,29 @@ func (r *Repository) Interfaces() *Interfaces {
 
 // SnapSpecification returns the specification of a given snap in a given security system.
 func (r *Repository) SnapSpecification(securitySystem SecuritySystem, appSet *SnapAppSet, opts ConfinementOptions) (Specification, error) {
+	return r.snapSpecification(securitySystem, appSet, opts, nil)
+}
+
+// SpecificationSource holds what a permanent plug or slot, or a connection,
+// contributes to the specification of a snap.
+type SpecificationSource struct {
+	// Source is a short description of the plug, slot or connection.
+	Source string
+	Spec   Specification
+}
+
+// SnapSpecificationSources returns, for each permanent plug or slot and
+// each connection of a given snap, a specification of the given security
+// system holding only what it contributes to the specification of the snap.
+func (r *Repository) SnapSpecificationSources(securitySystem SecuritySystem, appSet *SnapAppSet, opts ConfinementOptions) ([]SpecificationSource, error) {
+	sources := []SpecificationSource{}
+	if _, err := r.snapSpecification(securitySystem, appSet, opts, &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (r *Repository) snapSpecification(securitySystem SecuritySystem, appSet *SnapAppSet, opts ConfinementOptions, sources *[]SpecificationSource) (Specification, error) {
 	r.m.Lock()
 	defer r.m.Unlock()
 
@@ -910,6 +933,19 @@ func (r *Repository) SnapSpecification(securitySystem SecuritySystem, appSet *Sn
 	}
 
 	spec := backend.NewSpecification(appSet, opts)
+	// specFor returns the specification the described source contributes
+	// to, a specification of its own if sources are being collected
+	specFor := func(format string, args ...interface{}) Specification {
+		if sources == nil {
+			return spec
+		}
+		source := SpecificationSource{
+			Source: fmt.Sprintf(format, args...),
+			Spec:   backend.NewSpecification(appSet, opts),
+		}
+		*sources = append(*sources, source)
+		return source.Spec
+	}
 
 	// XXX: If either of the AddConnected{Plug,Slot} methods for a connection
 	// fail resiliently as-in they can never succeed (such as the case where a
@@ -929,11 +965,11 @@ func (r *Repository) SnapSpecification(securitySystem SecuritySystem, appSet *Sn
 	// slot side
 	for _, slotInfo := range r.slots[snapName] {
 		iface := r.ifaces[slotInfo.Interface]
-		if err := spec.AddPermanentSlot(iface, slotInfo); err != nil {
+		if err := specFor("slot %s:%s (%s interface)", snapName, slotInfo.Name, slotInfo.Interface).AddPermanentSlot(iface, slotInfo); err != nil {
 			return nil, err
 		}
 		for _, conn := range r.slotPlugs[slotInfo] {
-			if err := spec.AddConnectedSlot(iface, conn.Plug, conn.Slot); err != nil {
+			if err := specFor("slot %s:%s connected to plug %s:%s (%s interface)", snapName, slotInfo.Name, conn.Plug.Snap().InstanceName(), conn.Plug.Name(), slotInfo.Interface).AddConnectedSlot(iface, conn.Plug, conn.Slot); err != nil {
 				return nil, err
 			}
 		}
@@ -941,11 +977,11 @@ func (r *Repository) SnapSpecification(securitySystem SecuritySystem, appSet *Sn
 	// plug side
 	for _, plugInfo := range r.plugs[snapName] {
 		iface := r.ifaces[plugInfo.Interface]
-		if err := spec.AddPermanentPlug(iface, plugInfo); err != nil {
+		if err := specFor("plug %s:%s (%s interface)", snapName, plugInfo.Name, plugInfo.Interface).AddPermanentPlug(iface, plugInfo); err != nil {
 			return nil, err
 		}
 		for _, conn := range r.plugSlots[plugInfo] {
-			if err := spec.AddConnectedPlug(iface, conn.Plug, conn.Slot); err != nil {
+			if err := specFor("plug %s:%s connected to slot %s:%s (%s interface)", snapName, plugInfo.Name, conn.Slot.Snap().InstanceName(), conn.Slot.Name(), plugInfo.Interface).AddConnectedPlug(iface, conn.Plug, conn.Slot); err != nil {
 				return nil, err
 			}
 		}
@@ -1108,6 +1144,31 @@ func (r *Repository) AutoConnectCandidateSlots(plugSnapName, plugName string, po
 		return nil, nil
 	}
 
+	return r.autoConnectCandidateSlots(plugInfo, plugAppSet, policyCheck)
+}
+
+// AutoConnectCandidateSlotsForPlug finds and returns viable auto-connection
+// candidates for a plug of a snap revision that is not in the repository,
+// e.g. one a refresh would install. Slots of the same snap are not
+// considered as they belong to another revision.
+func (r *Repository) AutoConnectCandidateSlotsForPlug(plugInfo *snap.PlugInfo, plugAppSet *SnapAppSet, policyCheck func(*ConnectedPlug, *ConnectedSlot) (bool, SideArity, error)) ([]*snap.SlotInfo, []SideArity) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	var candidates []*snap.SlotInfo
+	var arities []SideArity
+	slots, slotArities := r.autoConnectCandidateSlots(plugInfo, plugAppSet, policyCheck)
+	for i, slotInfo := range slots {
+		if slotInfo.Snap.InstanceName() == plugInfo.Snap.InstanceName() {
+			continue
+		}
+		candidates = append(candidates, slotInfo)
+		arities = append(arities, slotArities[i])
+	}
+	return candidates, arities
+}
+
+func (r *Repository) autoConnectCandidateSlots(plugInfo *snap.PlugInfo, plugAppSet *SnapAppSet, policyCheck func(*ConnectedPlug, *ConnectedSlot) (bool, SideArity, error)) ([]*snap.SlotInfo, []SideArity) {
 	var candidates []*snap.SlotInfo
 	var arities []SideArity
 	for _, slotsForSnap := range r.slots {
@@ -1156,7 +1217,35 @@ func (r *Repository) AutoConnectCandidatePlugs(slotSnapName, slotName string, po
 		return nil
 	}
 
+	candidates, _ := r.autoConnectCandidatePlugs(slotInfo, slotAppSet, policyCheck)
+	return candidates
+}
+
+// AutoConnectCandidatePlugsForSlot finds and returns viable auto-connection
+// candidates, with the arity of each connection, for a slot of a snap
+// revision that is not in the repository, e.g. one a refresh would
+// install. Plugs of the same snap are not considered as they belong to
+// another revision.
+func (r *Repository) AutoConnectCandidatePlugsForSlot(slotInfo *snap.SlotInfo, slotAppSet *SnapAppSet, policyCheck func(*ConnectedPlug, *ConnectedSlot) (bool, SideArity, error)) ([]*snap.PlugInfo, []SideArity) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
 	var candidates []*snap.PlugInfo
+	var arities []SideArity
+	plugs, plugArities := r.autoConnectCandidatePlugs(slotInfo, slotAppSet, policyCheck)
+	for i, plugInfo := range plugs {
+		if plugInfo.Snap.InstanceName() == slotInfo.Snap.InstanceName() {
+			continue
+		}
+		candidates = append(candidates, plugInfo)
+		arities = append(arities, plugArities[i])
+	}
+	return candidates, arities
+}
+
+func (r *Repository) autoConnectCandidatePlugs(slotInfo *snap.SlotInfo, slotAppSet *SnapAppSet, policyCheck func(*ConnectedPlug, *ConnectedSlot) (bool, SideArity, error)) ([]*snap.PlugInfo, []SideArity) {
+	var candidates []*snap.PlugInfo
+	var arities []SideArity
 	for _, plugsForSnap := range r.plugs {
 		for _, plugInfo := range plugsForSnap {
 			if slotInfo.Interface != plugInfo.Interface {
@@ -1173,15 +1262,16 @@ func (r *Repository) AutoConnectCandidatePlugs(slotSnapName, slotName string, po
 			connectedSlot := NewConnectedSlot(slotInfo, slotAppSet, nil, nil)
 
 			// declaration based checks disallow
-			ok, _, err := policyCheck(connectedPlug, connectedSlot)
+			ok, arity, err := policyCheck(connectedPlug, connectedSlot)
 			if !ok || err != nil {
 				continue
 			}
 
 			if r.ifaces[iface].AutoConnect(plugInfo, slotInfo) {
 				candidates = append(candidates, plugInfo)
+				arities = append(arities, arity)
 			}
 		}
 	}
-	return candidates
+	return candidates, arities
 }
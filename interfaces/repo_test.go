@@ -1307,6 +1307,33 @@ func (s *RepositorySuite) TestSnapSpecification(c *C) {
 	})
 }
 
+func (s *RepositorySuite) TestSnapSpecificationSources(c *C) {
+	repo := s.emptyRepo
+	backend := &ifacetest.TestSecurityBackend{BackendName: testSecurity}
+	c.Assert(repo.AddBackend(backend), IsNil)
+	c.Assert(repo.AddInterface(testInterface), IsNil)
+	c.Assert(repo.AddAppSet(s.consumer), IsNil)
+	c.Assert(repo.AddAppSet(s.producer), IsNil)
+	connRef := NewConnRef(s.consumerPlug, s.producerSlot)
+	_, err := repo.Connect(connRef, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	sources, err := repo.SnapSpecificationSources(testSecurity, s.consumer, interfaces.ConfinementOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sources, HasLen, 2)
+	c.Check(sources[0].Source, Equals, "plug consumer:plug (interface interface)")
+	c.Check(sources[0].Spec.(*ifacetest.Specification).Snippets, DeepEquals, []string{"static plug snippet"})
+	c.Check(sources[1].Source, Equals, "plug consumer:plug connected to slot producer:slot (interface interface)")
+	c.Check(sources[1].Spec.(*ifacetest.Specification).Snippets, DeepEquals, []string{"connection-specific plug snippet"})
+
+	sources, err = repo.SnapSpecificationSources(testSecurity, s.producer, interfaces.ConfinementOptions{})
+	c.Assert(err, IsNil)
+	c.Assert(sources, HasLen, 3)
+	c.Check(sources[0].Source, Equals, "slot producer:slot (interface interface)")
+	c.Check(sources[1].Source, Equals, "slot producer:slot connected to plug consumer:plug (interface interface)")
+	c.Check(sources[2].Source, Equals, "plug producer:self (interface interface)")
+}
+
 func (s *RepositorySuite) TestSnapSpecificationFailureWithConnectionSnippets(c *C) {
 	var testSecurity SecuritySystem = "security"
 	backend := &ifacetest.TestSecurityBackend{BackendName: testSecurity}
@@ -1440,6 +1467,60 @@ slots:
 	c.Check(candidatePlugs[0].Name, Equals, "auto")
 }
 
+func (s *RepositorySuite) TestAutoConnectCandidatesOfNewRevision(c *C) {
+	repo := s.emptyRepo
+	err := repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "auto"})
+	c.Assert(err, IsNil)
+
+	policyCheck := func(plug *ConnectedPlug, slot *ConnectedSlot) (bool, SideArity, error) {
+		return true, &testSideArity{plug.Snap().InstanceName()}, nil
+	}
+
+	consumer := ifacetest.MockInfoAndAppSet(c, `
+name: consumer
+version: 0
+plugs:
+    auto:
+slots:
+    own-auto:
+        interface: auto
+`, nil, nil)
+	producer := ifacetest.MockInfoAndAppSet(c, `
+name: producer
+version: 0
+type: os
+slots:
+    auto:
+`, nil, nil)
+	c.Assert(repo.AddAppSet(producer), IsNil)
+	c.Assert(repo.AddAppSet(consumer), IsNil)
+
+	// a new revision of the consumer, not in the repository
+	newConsumer := ifacetest.MockInfoAndAppSet(c, `
+name: consumer
+version: 1
+plugs:
+    new-auto:
+        interface: auto
+slots:
+    new-own-auto:
+        interface: auto
+`, nil, nil)
+	newInfo := newConsumer.Info()
+
+	// the slots of the current revision are not candidates
+	candidateSlots, arities := repo.AutoConnectCandidateSlotsForPlug(newInfo.Plugs["new-auto"], newConsumer, policyCheck)
+	c.Assert(candidateSlots, HasLen, 1)
+	c.Check(candidateSlots[0].String(), Equals, "producer:auto")
+	c.Assert(arities, HasLen, 1)
+	c.Check(arities[0].SlotsPerPlugAny(), Equals, false)
+
+	// and neither are its plugs
+	candidatePlugs, arities := repo.AutoConnectCandidatePlugsForSlot(newInfo.Slots["new-own-auto"], newConsumer, policyCheck)
+	c.Check(candidatePlugs, HasLen, 0)
+	c.Check(arities, HasLen, 0)
+}
+
 func (s *RepositorySuite) TestAutoConnectCandidatePlugsAndSlotsSymmetry(c *C) {
 	repo := s.emptyRepo
 	// Add a "auto" interface
@@ -245,6 +245,18 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 	return parallelCompile(b.snapSeccomp, changed)
 }
 
+// RenderFiles returns the seccomp profile sources that Setup would write for
+// a snap with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	content, err := b.deriveContent(spec.(*Specification), opts, appSet)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	interfaces.AddRenderedFiles(files, dirs.SnapSeccompDir, content)
+	return files, nil
+}
+
 // Remove removes seccomp profiles of a given snap.
 func (b *Backend) Remove(snapName string) error {
 	globs := interfaces.SecurityTagGlobs(snapName)
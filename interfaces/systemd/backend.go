@@ -165,6 +165,14 @@ func (b *Backend) SandboxFeatures() []string {
 }
 
 // deriveContent computes .service files based on requests made to the specification.
+// RenderFiles returns the systemd service units that Setup would write for a
+// snap with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	interfaces.AddRenderedFiles(files, dirs.SnapServicesDir, deriveContent(spec.(*Specification), appSet))
+	return files, nil
+}
+
 func deriveContent(spec *Specification, appSet *interfaces.SnapAppSet) map[string]osutil.FileState {
 	services := spec.Services()
 	if len(services) == 0 {
@@ -110,22 +110,8 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 			needReload = true
 		}
 	} else {
-		var rulesBuf bytes.Buffer
-		rulesBuf.WriteString("# This file is automatically generated.\n")
-		if (opts.DevMode || opts.Classic) && !opts.JailMode {
-			rulesBuf.WriteString("# udev tagging/device cgroups disabled with non-strict mode snaps\n")
-		}
-		for _, snippet := range content {
-			if (opts.DevMode || opts.Classic) && !opts.JailMode {
-				rulesBuf.WriteRune('#')
-				snippet = strings.Replace(snippet, "\n", "\n#", -1)
-			}
-			rulesBuf.WriteString(snippet)
-			rulesBuf.WriteByte('\n')
-		}
-
 		rulesFileState := &osutil.MemoryFileState{
-			Content: rulesBuf.Bytes(),
+			Content: rulesFileContent(content, opts),
 			Mode:    0644,
 		}
 
@@ -151,25 +137,9 @@ func (b *Backend) Setup(appSet *interfaces.SnapAppSet, opts interfaces.Confineme
 		}
 	}
 
-	var deviceBuf bytes.Buffer
-	deviceBuf.WriteString("# This file is automatically generated.\n")
-
-	if udevSpec.ControlsDeviceCgroup() {
-		// The spec states that the snap can manage its own device
-		// cgroup (typically applies to container-like snaps), in which
-		// case leave a flag for snap-confine in at a known location.
-		deviceBuf.WriteString("# snap is allowed to manage own device cgroup.\n")
-		deviceBuf.WriteString("self-managed=true\n")
-	}
-	if (opts.DevMode || opts.Classic) && !opts.JailMode {
-		// Allow devmode
-		deviceBuf.WriteString("# snap uses non-strict confinement.\n")
-		deviceBuf.WriteString("non-strict=true\n")
-	}
-
 	// the file serves as a checkpoint that udev backend was set up
 	err = osutil.EnsureFileState(selfManageDeviceCgroupPath, &osutil.MemoryFileState{
-		Content: deviceBuf.Bytes(),
+		Content: deviceCgroupFileContent(udevSpec, opts),
 		Mode:    0644,
 	})
 	if err != nil && !errors.Is(err, osutil.ErrSameState) {
@@ -209,6 +179,59 @@ func (b *Backend) Remove(snapName string) error {
 	return nil
 }
 
+// rulesFileContent returns the content of the udev rules file of a snap
+// with the given rule snippets.
+func rulesFileContent(content []string, opts interfaces.ConfinementOptions) []byte {
+	var rulesBuf bytes.Buffer
+	rulesBuf.WriteString("# This file is automatically generated.\n")
+	if (opts.DevMode || opts.Classic) && !opts.JailMode {
+		rulesBuf.WriteString("# udev tagging/device cgroups disabled with non-strict mode snaps\n")
+	}
+	for _, snippet := range content {
+		if (opts.DevMode || opts.Classic) && !opts.JailMode {
+			rulesBuf.WriteRune('#')
+			snippet = strings.Replace(snippet, "\n", "\n#", -1)
+		}
+		rulesBuf.WriteString(snippet)
+		rulesBuf.WriteByte('\n')
+	}
+	return rulesBuf.Bytes()
+}
+
+// deviceCgroupFileContent returns the content of the file telling
+// snap-confine how to handle the device cgroup of a snap.
+func deviceCgroupFileContent(spec *Specification, opts interfaces.ConfinementOptions) []byte {
+	var deviceBuf bytes.Buffer
+	deviceBuf.WriteString("# This file is automatically generated.\n")
+
+	if spec.ControlsDeviceCgroup() {
+		// The spec states that the snap can manage its own device
+		// cgroup (typically applies to container-like snaps), in which
+		// case leave a flag for snap-confine in at a known location.
+		deviceBuf.WriteString("# snap is allowed to manage own device cgroup.\n")
+		deviceBuf.WriteString("self-managed=true\n")
+	}
+	if (opts.DevMode || opts.Classic) && !opts.JailMode {
+		// Allow devmode
+		deviceBuf.WriteString("# snap uses non-strict confinement.\n")
+		deviceBuf.WriteString("non-strict=true\n")
+	}
+	return deviceBuf.Bytes()
+}
+
+// RenderFiles returns the udev rules and device cgroup files that Setup would
+// write for a snap with the given specification, keyed by their path.
+func (b *Backend) RenderFiles(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+	udevSpec := spec.(*Specification)
+	snapName := appSet.InstanceName()
+	files := make(map[string][]byte, 2)
+	if content := b.deriveContent(udevSpec); len(content) > 0 && !udevSpec.ControlsDeviceCgroup() {
+		files[snapRulesFilePath(snapName)] = rulesFileContent(content, opts)
+	}
+	files[snapDeviceCgroupSelfManageFilePath(snapName)] = deviceCgroupFileContent(udevSpec, opts)
+	return files, nil
+}
+
 func (b *Backend) deriveContent(spec *Specification) (content []string) {
 	content = append(content, spec.Snippets()...)
 	return content
@@ -616,6 +616,31 @@ func (s *backendSuite) TestInstallingSnapWritesAndLoadsRulesWithInputJoystickSub
 	}
 }
 
+func (s *backendSuite) TestRenderFilesMatchesSetup(c *C) {
+	s.Iface.UDevPermanentSlotCallback = func(spec *udev.Specification, slot *snap.SlotInfo) error {
+		spec.AddSnippet("sample")
+		return nil
+	}
+	for _, opts := range testedConfinementOpts {
+		snapInfo := s.InstallSnap(c, opts, "", ifacetest.SambaYamlV1, 0)
+		appSet, err := interfaces.NewSnapAppSet(snapInfo, nil)
+		c.Assert(err, IsNil)
+		spec, err := s.Repo.SnapSpecification(s.Backend.Name(), appSet, opts)
+		c.Assert(err, IsNil)
+
+		files, err := s.Backend.(interfaces.SecurityBackendRenderer).RenderFiles(spec, appSet, opts)
+		c.Assert(err, IsNil)
+		c.Check(files, HasLen, 2)
+		for _, fname := range []string{
+			filepath.Join(dirs.SnapUdevRulesDir, "70-snap.samba.rules"),
+			filepath.Join(dirs.SnapCgroupPolicyDir, "snap.samba.device"),
+		} {
+			c.Check(fname, testutil.FileEquals, string(files[fname]))
+		}
+		s.RemoveSnap(c, snapInfo)
+	}
+}
+
 func (s *backendSuite) TestSandboxFeatures(c *C) {
 	restore := cgroup.MockVersion(cgroup.V1, nil)
 	defer restore()
@@ -45,11 +45,11 @@ func earlyExperimentalSettingsFilter(values, early map[string]interface{}) {
 }
 
 func validateExperimentalSettings(tr ConfGetter) error {
-	for k := range supportedConfigurations {
-		if !strings.HasPrefix(k, "core.experimental.") {
-			continue
-		}
-		if err := validateBoolFlag(tr, strings.TrimPrefix(k, "core.")); err != nil {
+	// only feature flags are booleans, other experimental.* options are
+	// validated by their own handlers
+	for _, feature := range features.KnownFeatures() {
+		_, confName := feature.ConfigOption()
+		if err := validateBoolFlag(tr, confName); err != nil {
 			return err
 		}
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/features"
 	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/prompting"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/restart"
@@ -37,6 +38,45 @@ import (
 	"github.com/snapcore/snapd/snap"
 )
 
+func init() {
+	for _, iface := range prompting.SupportedInterfaces() {
+		supportedConfigurations["core."+prompting.TimeoutOption+"."+iface] = true
+		supportedConfigurations["core."+prompting.TimeoutOutcomeOption+"."+iface] = true
+	}
+}
+
+func validatePromptingTimeoutSettings(tr RunTransaction) error {
+	for _, iface := range prompting.SupportedInterfaces() {
+		timeoutOpt := prompting.TimeoutOption + "." + iface
+		timeoutStr, err := coreCfg(tr, timeoutOpt)
+		if err != nil {
+			return err
+		}
+		if timeoutStr != "" {
+			timeout, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return fmt.Errorf("%s cannot be parsed: %v", timeoutOpt, err)
+			}
+			if timeout < 0 {
+				return fmt.Errorf("%s cannot be negative: %q", timeoutOpt, timeoutStr)
+			}
+		}
+
+		outcomeOpt := prompting.TimeoutOutcomeOption + "." + iface
+		outcomeStr, err := coreCfg(tr, outcomeOpt)
+		if err != nil {
+			return err
+		}
+		switch outcomeStr {
+		case "", "deny", "allow-once":
+			// noop
+		default:
+			return fmt.Errorf("%s value %q is invalid, must be \"deny\" or \"allow-once\"", outcomeOpt, outcomeStr)
+		}
+	}
+	return nil
+}
+
 var restartRequest = restart.Request
 
 var servicestateControl = servicestate.Control
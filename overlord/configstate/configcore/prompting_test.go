@@ -638,3 +638,46 @@ plugs:
 
 	s.state.Set("conns", conns)
 }
+
+func (s *promptingSuite) TestPromptingTimeoutSettingsHappy(c *C) {
+	for _, outcome := range []string{"", "deny", "allow-once"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"experimental.apparmor-prompting-timeout.home":         "30s",
+				"experimental.apparmor-prompting-timeout-outcome.home": outcome,
+			},
+		})
+		c.Check(err, IsNil)
+	}
+}
+
+func (s *promptingSuite) TestPromptingTimeoutSettingsInvalid(c *C) {
+	for _, tc := range []struct {
+		changes map[string]interface{}
+		err     string
+	}{
+		{
+			changes: map[string]interface{}{"experimental.apparmor-prompting-timeout.home": "soon"},
+			err:     `experimental.apparmor-prompting-timeout.home cannot be parsed: .*`,
+		},
+		{
+			changes: map[string]interface{}{"experimental.apparmor-prompting-timeout.home": "-5m"},
+			err:     `experimental.apparmor-prompting-timeout.home cannot be negative: "-5m"`,
+		},
+		{
+			changes: map[string]interface{}{"experimental.apparmor-prompting-timeout-outcome.home": "allow"},
+			err:     `experimental.apparmor-prompting-timeout-outcome.home value "allow" is invalid, must be "deny" or "allow-once"`,
+		},
+		{
+			changes: map[string]interface{}{"experimental.apparmor-prompting-timeout.camera": "5m"},
+			err:     `cannot set "core.experimental.apparmor-prompting-timeout.camera": unsupported system option`,
+		},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state:   s.state,
+			changes: tc.changes,
+		})
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
@@ -0,0 +1,32 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package configcore
+
+func init() {
+	// add supported configuration of this module
+	supportedConfigurations["core.quota.resource-hints"] = true
+}
+
+// quota.resource-hints allows snapd to create default quota sub-groups for
+// services from the resources declared in their snap.yaml. Gadgets opt in
+// to it via their defaults for the system.
+func validateQuotaResourceHints(tr RunTransaction) error {
+	return validateBoolFlag(tr, "quota.resource-hints")
+}
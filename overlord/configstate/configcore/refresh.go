@@ -23,9 +23,13 @@ package configcore
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeutil"
 )
@@ -42,7 +46,13 @@ func init() {
 	supportedConfigurations["core.refresh.metered"] = true
 	supportedConfigurations["core.refresh.retain"] = true
 	supportedConfigurations["core.refresh.rate-limit"] = true
+	supportedConfigurations["core.refresh.rate-limit-schedule"] = true
 	supportedConfigurations["core.refresh.max-inhibition-days"] = true
+	supportedConfigurations["core.refresh.windows"] = true
+	supportedConfigurations["core.refresh.rollout"] = true
+	supportedConfigurations["core.refresh.rollout-halt"] = true
+	supportedConfigurations["core.refresh.auto-revert-on-unhealthy"] = true
+	supportedConfigurations["core.refresh.auto-revert-grace-period"] = true
 }
 
 func reportOrIgnoreInvalidManageRefreshes(tr RunTransaction, optName string) error {
@@ -163,3 +173,63 @@ func validateRefreshRateLimit(tr RunTransaction) error {
 	}
 	return nil
 }
+
+func validateRefreshRateLimitSchedule(tr RunTransaction) error {
+	rateSchedule, err := coreCfg(tr, "refresh.rate-limit-schedule")
+	if err != nil {
+		return err
+	}
+	_, err = store.ParseRateSchedule(rateSchedule)
+	return err
+}
+
+func validateRefreshRollout(tr RunTransaction) error {
+	rollout, err := coreCfg(tr, "refresh.rollout")
+	if err != nil {
+		return err
+	}
+	if _, err := snapstate.ParseRefreshRollout(rollout); err != nil {
+		return fmt.Errorf("cannot set refresh.rollout: %v", err)
+	}
+	return validateBoolFlag(tr, "refresh.rollout-halt")
+}
+
+func validateRefreshAutoRevert(tr RunTransaction) error {
+	if err := validateBoolFlag(tr, "refresh.auto-revert-on-unhealthy"); err != nil {
+		return err
+	}
+	grace, err := coreCfg(tr, "refresh.auto-revert-grace-period")
+	if err != nil {
+		return err
+	}
+	if grace == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(grace)
+	if err != nil {
+		return fmt.Errorf("refresh.auto-revert-grace-period cannot be parsed: %v", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("refresh.auto-revert-grace-period must be positive")
+	}
+	return nil
+}
+
+// validRefreshWindowsOption returns whether the given refresh.windows.*
+// option names a valid refresh window.
+func validRefreshWindowsOption(optionName string) bool {
+	name := strings.TrimPrefix(optionName, "core.refresh.windows.")
+	name, _, _ = strings.Cut(name, ".")
+	return snapstate.ValidateRefreshWindowName(name) == nil
+}
+
+func validateRefreshWindows(tr RunTransaction) error {
+	var windows map[string]*snapstate.RefreshWindow
+	if err := tr.Get("core", "refresh.windows", &windows); err != nil && !config.IsNoOption(err) {
+		return fmt.Errorf("cannot set refresh.windows: %v", err)
+	}
+	if err := snapstate.ValidateRefreshWindows(windows); err != nil {
+		return fmt.Errorf("cannot set refresh.windows: %v", err)
+	}
+	return nil
+}
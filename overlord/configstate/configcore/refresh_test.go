@@ -26,6 +26,7 @@ import (
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/snapstate"
 )
 
 type refreshSuite struct {
@@ -123,6 +124,26 @@ func (s *refreshSuite) TestConfigureLegacyRefreshScheduleRejected(c *C) {
 	c.Assert(err, ErrorMatches, `cannot parse "8:00~12:00": not a valid interval`)
 }
 
+func (s *refreshSuite) TestConfigureRefreshRateLimitScheduleHappy(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"refresh.rate-limit-schedule": "9:00-17:00=125kB,22:00-6:00=unlimited",
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *refreshSuite) TestConfigureRefreshRateLimitScheduleRejected(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"refresh.rate-limit-schedule": "9:00-17:00",
+		},
+	})
+	c.Assert(err, ErrorMatches, `cannot parse rate window "9:00-17:00": missing rate`)
+}
+
 func (s *refreshSuite) TestConfigureRefreshHoldHappy(c *C) {
 	err := configcore.Run(classicDev, &mockConf{
 		state: s.state,
@@ -252,3 +273,124 @@ func (s *refreshSuite) TestConfigureRefreshMaxInhibitionDays(c *C) {
 		}
 	}
 }
+
+func (s *refreshSuite) TestConfigureRefreshWindowsHappy(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"refresh.windows": map[string]*snapstate.RefreshWindow{
+				"databases": {Snaps: []string{"postgres", "redis_foo"}, Schedule: "sun,02:00-04:00"},
+				"web":       {Snaps: []string{"nginx"}, Schedule: "mon-fri,22:00-23:00"},
+			},
+			"refresh.windows.databases": &snapstate.RefreshWindow{},
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *refreshSuite) TestConfigureRefreshWindowsRejected(c *C) {
+	for _, tc := range []struct {
+		window *snapstate.RefreshWindow
+		err    string
+	}{
+		{&snapstate.RefreshWindow{Schedule: "sun,02:00-04:00"}, `cannot set refresh.windows: refresh window "databases" has no snaps`},
+		{&snapstate.RefreshWindow{Snaps: []string{"postgres"}}, `cannot set refresh.windows: refresh window "databases" has no schedule`},
+		{&snapstate.RefreshWindow{Snaps: []string{"postgres"}, Schedule: "invalid"}, `cannot set refresh.windows: cannot parse schedule of refresh window "databases": cannot parse "invalid": .*`},
+		{&snapstate.RefreshWindow{Snaps: []string{"Postgres"}, Schedule: "sun"}, `cannot set refresh.windows: invalid snap in refresh window "databases": .*`},
+		{&snapstate.RefreshWindow{Snaps: []string{"nginx"}, Schedule: "sun"}, `cannot set refresh.windows: snap "nginx" cannot be in both refresh windows "databases" and "web"`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"refresh.windows": map[string]*snapstate.RefreshWindow{
+					"databases": tc.window,
+					"web":       {Snaps: []string{"nginx"}, Schedule: "mon-fri,22:00-23:00"},
+				},
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err, Commentf("%+v", tc.window))
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshWindowsInvalidName(c *C) {
+	for _, opt := range []string{"refresh.windows.Databases", "refresh.windows.data_bases.schedule", "refresh.windows.-db"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				opt: "sun",
+			},
+		})
+		c.Check(err, ErrorMatches, `cannot set "core\.refresh\.windows\..*": refresh window names must only contain lowercase letters, digits and dashes`, Commentf(opt))
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshRolloutHappy(c *C) {
+	for _, rollout := range []string{"", "100", "10,50,100", "10%,50%,100%", "5, 5, 100"} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"refresh.rollout":      rollout,
+				"refresh.rollout-halt": "true",
+			},
+		})
+		c.Check(err, IsNil, Commentf(rollout))
+	}
+}
+
+func (s *refreshSuite) TestConfigureRefreshRolloutRejected(c *C) {
+	for _, tc := range []struct {
+		rollout string
+		err     string
+	}{
+		{"10,50", `cannot set refresh.rollout: invalid rollout "10,50": must end with 100`},
+		{"50,10,100", `cannot set refresh.rollout: invalid rollout "50,10,100": percentages cannot decrease`},
+		{"0,100", `cannot set refresh.rollout: invalid rollout percentage "0": must be between 1 and 100`},
+		{"10,200", `cannot set refresh.rollout: invalid rollout percentage "200": must be between 1 and 100`},
+		{"ten,100", `cannot set refresh.rollout: invalid rollout percentage "ten": must be between 1 and 100`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"refresh.rollout": tc.rollout,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err)
+	}
+
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"refresh.rollout-halt": "yes",
+		},
+	})
+	c.Check(err, ErrorMatches, `refresh.rollout-halt can only be set to 'true' or 'false'`)
+}
+
+func (s *refreshSuite) TestConfigureRefreshAutoRevertHappy(c *C) {
+	err := configcore.Run(classicDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"refresh.auto-revert-on-unhealthy": "true",
+			"refresh.auto-revert-grace-period": "10m",
+		},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *refreshSuite) TestConfigureRefreshAutoRevertRejected(c *C) {
+	for _, tc := range []struct {
+		opt, value, err string
+	}{
+		{"refresh.auto-revert-on-unhealthy", "maybe", `refresh.auto-revert-on-unhealthy can only be set to 'true' or 'false'`},
+		{"refresh.auto-revert-grace-period", "soon", `refresh.auto-revert-grace-period cannot be parsed: .*`},
+		{"refresh.auto-revert-grace-period", "-5m", `refresh.auto-revert-grace-period must be positive`},
+	} {
+		err := configcore.Run(classicDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				tc.opt: tc.value,
+			},
+		})
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
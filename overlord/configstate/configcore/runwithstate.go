@@ -45,6 +45,9 @@ func init() {
 	// proxy.store
 	addWithStateHandler(validateProxyStore, handleProxyStore, nil)
 
+	// store.{backend,peers,peer-listen,cache.max-size,cache.min-free}
+	addWithStateHandler(validateStoreSettings, handleStoreStartupOptions, nil)
+
 	// resilience.vitality-hint
 	addWithStateHandler(validateVitalitySettings, handleVitalityConfiguration, nil)
 
@@ -59,7 +62,15 @@ func init() {
 	validateOnly := &flags{validatedOnlyStateConfig: true}
 	addWithStateHandler(validateRefreshSchedule, nil, validateOnly)
 	addWithStateHandler(validateRefreshRateLimit, nil, validateOnly)
+	addWithStateHandler(validateRefreshRateLimitSchedule, nil, validateOnly)
+	// refresh.windows.*
+	addWithStateHandler(validateRefreshWindows, nil, validateOnly)
+	addWithStateHandler(validateRefreshRollout, nil, validateOnly)
+	addWithStateHandler(validateRefreshAutoRevert, nil, validateOnly)
 	addWithStateHandler(validateAutomaticSnapshotsExpiration, nil, validateOnly)
+	addWithStateHandler(validateQuotaResourceHints, nil, validateOnly)
+	// experimental.apparmor-prompting-timeout{,-outcome}.*
+	addWithStateHandler(validatePromptingTimeoutSettings, nil, validateOnly)
 
 	// netplan.*
 	addWithStateHandler(validateNetplanSettings, handleNetplanConfiguration, coreOnly)
@@ -165,6 +176,10 @@ func applyHandlers(dev sysconfig.Device, cfg RunTransaction, handlers []configHa
 			if !validCertOption(k) {
 				return fmt.Errorf("cannot set store ssl certificate under name %q: name must only contain word characters or a dash", k)
 			}
+		case strings.HasPrefix(k, "core.refresh.windows."):
+			if !validRefreshWindowsOption(k) {
+				return fmt.Errorf("cannot set %q: refresh window names must only contain lowercase letters, digits and dashes", k)
+			}
 		case isNetplanChange(k):
 			if release.OnClassic {
 				return fmt.Errorf("cannot set netplan configuration on classic")
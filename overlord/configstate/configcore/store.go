@@ -22,16 +22,27 @@ package configcore
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/dirstore"
 	"github.com/snapcore/snapd/sysconfig"
 )
 
 func init() {
 	supportedConfigurations["core.store.access"] = true
+	supportedConfigurations["core.store.backend"] = true
+	supportedConfigurations["core.store.peers"] = true
+	supportedConfigurations["core.store.peer-listen"] = true
+	supportedConfigurations["core.store.cache.max-size"] = true
+	supportedConfigurations["core.store.cache.min-free"] = true
 }
 
 func validateStoreAccess(cfg ConfGetter) error {
@@ -80,3 +91,102 @@ func handleStoreAccess(_ sysconfig.Device, cfg ConfGetter, opts *fsOnlyContext)
 
 	return osutil.AtomicWriteFile(configFilePath, data, 0644, 0)
 }
+
+func validateStoreBackend(tr RunTransaction) error {
+	backend, err := coreCfg(tr, "store.backend")
+	if err != nil {
+		return err
+	}
+	dir, err := dirstore.ParseBackend(backend)
+	if err != nil {
+		return err
+	}
+	if dir != "" && !osutil.IsDirectory(dir) {
+		return fmt.Errorf("cannot use %q as store backend: not a directory", dir)
+	}
+	return nil
+}
+
+func validateStorePeers(tr RunTransaction) error {
+	peers, err := coreCfg(tr, "store.peers")
+	if err != nil {
+		return err
+	}
+	if _, err := store.ParsePeers(peers); err != nil {
+		return err
+	}
+
+	listen, err := coreCfg(tr, "store.peer-listen")
+	if err != nil {
+		return err
+	}
+	if listen == "" {
+		return nil
+	}
+	if _, port, err := net.SplitHostPort(listen); err != nil || port == "" {
+		return fmt.Errorf("store peer listen address %q must be of the form [host]:port", listen)
+	}
+	return nil
+}
+
+func validateStoreCacheLimits(tr RunTransaction) error {
+	maxSize, err := coreCfg(tr, "store.cache.max-size")
+	if err != nil {
+		return err
+	}
+	minFree, err := coreCfg(tr, "store.cache.min-free")
+	if err != nil {
+		return err
+	}
+	_, _, err = store.ParseCacheLimits(maxSize, minFree)
+	return err
+}
+
+func validateStoreSettings(tr RunTransaction) error {
+	if err := validateStoreBackend(tr); err != nil {
+		return err
+	}
+	if err := validateStorePeers(tr); err != nil {
+		return err
+	}
+	return validateStoreCacheLimits(tr)
+}
+
+// storeStartupOptions are the store options which are only read when snapd
+// starts.
+var storeStartupOptions = []string{
+	"store.backend",
+	"store.peers",
+	"store.peer-listen",
+	"store.cache.max-size",
+	"store.cache.min-free",
+}
+
+// handleStoreStartupOptions restarts snapd when one of the options read on
+// startup changes.
+func handleStoreStartupOptions(tr RunTransaction, opts *fsOnlyContext) error {
+	changed := false
+	for _, key := range storeStartupOptions {
+		value, err := coreCfg(tr, key)
+		if err != nil {
+			return err
+		}
+		var prevValue string
+		if err := tr.GetPristine("core", key, &prevValue); err != nil && !config.IsNoOption(err) {
+			return err
+		}
+		if value != prevValue {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	st := tr.State()
+	st.Lock()
+	defer st.Unlock()
+	restartRequest(st, restart.RestartDaemon, nil)
+	return nil
+}
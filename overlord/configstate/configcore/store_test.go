@@ -28,8 +28,11 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/boot"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord/configstate/configcore"
+	"github.com/snapcore/snapd/overlord/restart"
+	"github.com/snapcore/snapd/overlord/state"
 )
 
 type storeSuite struct {
@@ -96,3 +99,144 @@ func (s *storeSuite) TestFilesystemOnlyApply(c *C) {
 
 	c.Check(repairConfig.StoreOffline, Equals, true)
 }
+
+func (s *storeSuite) TestStoreBackendHappy(c *C) {
+	var restartRequested bool
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Check(t, Equals, restart.RestartDaemon)
+		restartRequested = true
+	})
+	defer restore()
+
+	dir := c.MkDir()
+	conf := &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"store.backend": "dir:" + dir,
+		},
+	}
+	err := configcore.Run(coreDev, conf)
+	c.Assert(err, IsNil)
+	c.Check(restartRequested, Equals, true)
+
+	// no restart if unchanged
+	restartRequested = false
+	conf = &mockConf{
+		state: s.state,
+		conf: map[string]interface{}{
+			"store.backend": "dir:" + dir,
+		},
+		changes: map[string]interface{}{
+			"store.backend": "dir:" + dir,
+		},
+	}
+	err = configcore.Run(coreDev, conf)
+	c.Assert(err, IsNil)
+	c.Check(restartRequested, Equals, false)
+}
+
+func (s *storeSuite) TestStoreBackendUnhappy(c *C) {
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Errorf("unexpected restart requested")
+	})
+	defer restore()
+
+	for _, t := range []struct {
+		backend, err string
+	}{
+		{"http://example.com", `store backend must be of the form dir:<path>`},
+		{"dir:relative", `store backend directory "relative" must be absolute`},
+		{"dir:/does/not/exist", `cannot use "/does/not/exist" as store backend: not a directory`},
+	} {
+		err := configcore.Run(coreDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				"store.backend": t.backend,
+			},
+		})
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *storeSuite) TestStorePeersHappy(c *C) {
+	restartRequests := 0
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Check(t, Equals, restart.RestartDaemon)
+		restartRequests++
+	})
+	defer restore()
+
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"store.peers":       "10.0.0.2:8999, peer.lan:8999",
+			"store.peer-listen": ":8999",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(restartRequests, Equals, 1)
+}
+
+func (s *storeSuite) TestStorePeersUnhappy(c *C) {
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Errorf("unexpected restart requested")
+	})
+	defer restore()
+
+	for _, t := range []struct {
+		key, value, err string
+	}{
+		{"store.peers", "10.0.0.2", `store peer "10.0.0.2" must be of the form host:port`},
+		{"store.peers", "10.0.0.2:8999,:8999", `store peer ":8999" must be of the form host:port`},
+		{"store.peer-listen", "8999", `store peer listen address "8999" must be of the form \[host\]:port`},
+	} {
+		err := configcore.Run(coreDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				t.key: t.value,
+			},
+		})
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *storeSuite) TestStoreCacheLimitsHappy(c *C) {
+	restartRequests := 0
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Check(t, Equals, restart.RestartDaemon)
+		restartRequests++
+	})
+	defer restore()
+
+	err := configcore.Run(coreDev, &mockConf{
+		state: s.state,
+		changes: map[string]interface{}{
+			"store.cache.max-size": "2GB",
+			"store.cache.min-free": "10%",
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(restartRequests, Equals, 1)
+}
+
+func (s *storeSuite) TestStoreCacheLimitsUnhappy(c *C) {
+	restore := configcore.MockRestartRequest(func(st *state.State, t restart.RestartType, rebootInfo *boot.RebootInfo) {
+		c.Errorf("unexpected restart requested")
+	})
+	defer restore()
+
+	for _, t := range []struct {
+		key, value, err string
+	}{
+		{"store.cache.max-size", "lots", `cannot parse download cache maximum size: cannot parse "lots": .*`},
+		{"store.cache.min-free", "10", `download cache minimum free space "10" must be a percentage between 0% and 99%`},
+	} {
+		err := configcore.Run(coreDev, &mockConf{
+			state: s.state,
+			changes: map[string]interface{}{
+				t.key: t.value,
+			},
+		})
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
@@ -137,6 +137,16 @@ func findKnownRevisionOfModel(st *state.State, mod *asserts.Model) (modRevision
 	return a.Revision(), nil
 }
 
+// deviceSerial returns the serial of the device, or the empty string
+// if it has none yet.
+func deviceSerial(st *state.State) (string, error) {
+	device, err := internal.Device(st)
+	if err != nil {
+		return "", err
+	}
+	return device.Serial, nil
+}
+
 // auto-refresh
 func canAutoRefresh(st *state.State) (bool, error) {
 	// we need to be seeded first
@@ -271,6 +281,7 @@ func delayedCrossMgrInit() {
 	})
 	snapstate.CanAutoRefresh = canAutoRefresh
 	snapstate.IsOnMeteredConnection = netutil.IsOnMeteredConnection
+	snapstate.DeviceSerial = deviceSerial
 	snapstate.DeviceCtx = DeviceCtx
 	snapstate.RemodelingChange = RemodelingChange
 }
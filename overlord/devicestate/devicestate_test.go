@@ -1034,6 +1034,24 @@ func (s *deviceMgrSuite) TestCanAutoRefreshOnCore(c *C) {
 	c.Check(canAutoRefresh(), Equals, false)
 }
 
+func (s *deviceMgrSuite) TestDeviceSerial(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	serial, err := snapstate.DeviceSerial(s.state)
+	c.Assert(err, IsNil)
+	c.Check(serial, Equals, "")
+
+	devicestatetest.SetDevice(s.state, &auth.DeviceState{
+		Brand:  "canonical",
+		Model:  "pc",
+		Serial: "8989",
+	})
+	serial, err = snapstate.DeviceSerial(s.state)
+	c.Assert(err, IsNil)
+	c.Check(serial, Equals, "8989")
+}
+
 func (s *deviceMgrSuite) TestCanAutoRefreshNoSerialFallback(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -94,6 +94,10 @@ func MockStoreNew(new func(*store.Config, store.DeviceAndAuthContext) *store.Sto
 	}
 }
 
+func MockStoreNewPeerServer(new func(addr, cacheDir string) (*store.PeerServer, error)) (restore func()) {
+	return testutil.Mock(&storeNewPeerServer, new)
+}
+
 func MockConfigstateInit(new func(*state.State, *hookstate.HookManager) error) (restore func()) {
 	configstateInit = new
 	return func() {
@@ -0,0 +1,270 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+)
+
+const defaultAutoRevertGracePeriod = 5 * time.Minute
+
+var (
+	// autoRevertPollInterval is how often the health of a refreshed
+	// snap is checked during the grace period
+	autoRevertPollInterval = 15 * time.Second
+
+	timeNow = time.Now
+
+	servicesStatus = func(units []string) ([]*systemd.UnitStatus, error) {
+		return systemd.New(systemd.SystemMode, nil).Status(units)
+	}
+)
+
+// refreshHealthWatch holds what is needed to watch the health of a snap
+// after its refresh.
+type refreshHealthWatch struct {
+	InstanceName string        `json:"instance-name"`
+	Revision     snap.Revision `json:"revision"`
+	Deadline     time.Time     `json:"deadline"`
+}
+
+// autoRevertGracePeriod returns for how long the health of refreshed
+// snaps is watched, or zero if refresh.auto-revert-on-unhealthy is not
+// set.
+func autoRevertGracePeriod(st *state.State) (time.Duration, error) {
+	tr := config.NewTransaction(st)
+	var enabled interface{}
+	if err := tr.GetMaybe("core", "refresh.auto-revert-on-unhealthy", &enabled); err != nil {
+		return 0, err
+	}
+	// the value is validated to be either true or false, possibly as a
+	// string
+	if fmt.Sprint(enabled) != "true" {
+		return 0, nil
+	}
+	var grace string
+	if err := tr.GetMaybe("core", "refresh.auto-revert-grace-period", &grace); err != nil {
+		return 0, err
+	}
+	if grace == "" {
+		return defaultAutoRevertGracePeriod, nil
+	}
+	return time.ParseDuration(grace)
+}
+
+// autoRevertApplies returns whether the health of the given revision of
+// a snap will be watched after it is linked, that is if it's a new
+// revision of an installed app snap and refresh.auto-revert-on-unhealthy
+// is set.
+func autoRevertApplies(st *state.State, instanceName string, rev snap.Revision) bool {
+	grace, err := autoRevertGracePeriod(st)
+	if err != nil {
+		logger.Noticef("cannot get auto-revert settings: %v", err)
+		return false
+	}
+	if grace == 0 {
+		return false
+	}
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		return false
+	}
+	if !snapst.IsInstalled() || snapst.Current == rev {
+		return false
+	}
+	info, err := snapst.CurrentInfo()
+	if err != nil {
+		return false
+	}
+	return info.Type() == snap.TypeApp
+}
+
+// refreshedSnapSetup returns the setup of the snap the given check-health
+// hook runs for, if the hook is part of the refresh of an app snap.
+func refreshedSnapSetup(hookTask *state.Task, instanceName string) (*snapstate.SnapSetup, error) {
+	chg := hookTask.Change()
+	if chg == nil {
+		return nil, nil
+	}
+	for _, t := range chg.Tasks() {
+		// only refreshes replace a current revision
+		if t.Kind() != "unlink-current-snap" {
+			continue
+		}
+		snapsup, err := snapstate.TaskSnapSetup(t)
+		if err != nil {
+			return nil, err
+		}
+		if snapsup.InstanceName() != instanceName {
+			continue
+		}
+		if snapsup.Flags.Revert || snapsup.Type != snap.TypeApp {
+			return nil, nil
+		}
+		return snapsup, nil
+	}
+	return nil, nil
+}
+
+// maybeWatchRefreshHealth starts watching the health of a snap whose
+// refresh ran the check-health hook of the given context, if
+// refresh.auto-revert-on-unhealthy is set.
+// Must be called with the state lock held.
+func maybeWatchRefreshHealth(ctx *hookstate.Context) error {
+	st := ctx.State()
+	hookTask, ok := ctx.Task()
+	if !ok {
+		return nil
+	}
+	grace, err := autoRevertGracePeriod(st)
+	if err != nil || grace == 0 {
+		return err
+	}
+	snapsup, err := refreshedSnapSetup(hookTask, ctx.InstanceName())
+	if err != nil || snapsup == nil {
+		return err
+	}
+
+	watch := &refreshHealthWatch{
+		InstanceName: snapsup.InstanceName(),
+		Revision:     snapsup.Revision(),
+		Deadline:     timeNow().Add(grace),
+	}
+	summary := fmt.Sprintf("Watch health of snap %q after its refresh", watch.InstanceName)
+	t := st.NewTask("watch-refresh-health", summary)
+	t.Set("refresh-health-watch", watch)
+	chg := st.NewChange("watch-refresh-health", summary)
+	chg.AddTask(t)
+	return nil
+}
+
+// unhealthyReason returns why the refreshed revision of a snap is
+// considered unhealthy, if it is.
+func unhealthyReason(st *state.State, watch *refreshHealthWatch) (string, error) {
+	health, err := Get(st, watch.InstanceName)
+	if err != nil {
+		return "", err
+	}
+	if health != nil && health.Revision == watch.Revision && health.Status == ErrorStatus {
+		if health.Message == "" {
+			return "it reported an error", nil
+		}
+		return fmt.Sprintf("it reported an error: %s", health.Message), nil
+	}
+
+	info, err := snapstate.CurrentInfo(st, watch.InstanceName)
+	if err != nil {
+		return "", err
+	}
+	var units []string
+	for _, app := range info.Services() {
+		// only long running system services are expected to be active
+		if app.DaemonScope != snap.SystemDaemon || app.Daemon == "oneshot" || app.Timer != nil || len(app.Sockets) > 0 || len(app.ActivatesOn) > 0 || len(app.ActivatesOnPath) > 0 {
+			continue
+		}
+		units = append(units, app.ServiceName())
+	}
+	if len(units) == 0 {
+		return "", nil
+	}
+	st.Unlock()
+	statuses, err := servicesStatus(units)
+	st.Lock()
+	if err != nil {
+		return "", err
+	}
+	for _, status := range statuses {
+		// services disabled with snapctl or snap stop --disable are
+		// not expected to run
+		if status.Enabled && !status.Active {
+			return fmt.Sprintf("its service %s is not running", status.Name), nil
+		}
+	}
+	return "", nil
+}
+
+func doWatchRefreshHealth(t *state.Task, _ *tomb.Tomb) error {
+	st := t.State()
+	st.Lock()
+	defer st.Unlock()
+
+	var watch refreshHealthWatch
+	if err := t.Get("refresh-health-watch", &watch); err != nil {
+		return err
+	}
+
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, watch.InstanceName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			t.Logf("snap %q was removed", watch.InstanceName)
+			return nil
+		}
+		return err
+	}
+	if snapst.Current != watch.Revision {
+		t.Logf("revision %s of snap %q is no longer current", watch.Revision, watch.InstanceName)
+		return nil
+	}
+
+	reason, err := unhealthyReason(st, &watch)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		now := timeNow()
+		if now.Before(watch.Deadline) {
+			after := watch.Deadline.Sub(now)
+			if after > autoRevertPollInterval {
+				after = autoRevertPollInterval
+			}
+			return &state.Retry{After: after}
+		}
+		return nil
+	}
+
+	ts, err := snapstate.Revert(st, watch.InstanceName, snapstate.Flags{}, t.Change().ID())
+	if err != nil {
+		var conflict *snapstate.ChangeConflictError
+		if errors.As(err, &conflict) {
+			// try again once the conflicting change is done
+			return &state.Retry{After: autoRevertPollInterval}
+		}
+		st.Warnf("cannot revert snap %q after its refresh to revision %s left it unhealthy: %v", watch.InstanceName, watch.Revision, err)
+		return err
+	}
+	logger.Noticef("Reverting snap %q from revision %s as %s", watch.InstanceName, watch.Revision, reason)
+	t.Logf("reverting snap %q as %s", watch.InstanceName, reason)
+	st.Warnf("snap %q was reverted after its refresh to revision %s as %s", watch.InstanceName, watch.Revision, reason)
+	ts.WaitFor(t)
+	t.Change().AddAll(ts)
+	return nil
+}
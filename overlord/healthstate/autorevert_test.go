@@ -0,0 +1,335 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate_test
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const autoRevertSnapYaml = `name: test-snap
+version: v1
+apps:
+  svc:
+    command: bin/svc
+    daemon: simple
+  oneshot:
+    command: bin/oneshot
+    daemon: oneshot
+`
+
+// mockRefreshedSnap mocks test-snap refreshed from revision 41 to 42.
+func (s *healthSuite) mockRefreshedSnap(c *check.C) {
+	si41 := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(41)}
+	si42 := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(42)}
+	snaptest.MockSnap(c, autoRevertSnapYaml, si41)
+	snaptest.MockSnap(c, autoRevertSnapYaml, si42)
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si41, si42}),
+		Current:  snap.R(42),
+		Active:   true,
+		SnapType: "app",
+	})
+}
+
+func (s *healthSuite) enableAutoRevert(grace string) {
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.auto-revert-on-unhealthy", true)
+	if grace != "" {
+		tr.Set("core", "refresh.auto-revert-grace-period", grace)
+	}
+	tr.Commit()
+}
+
+func (s *healthSuite) TestAutoRevertApplies(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// not enabled
+	c.Check(healthstate.AutoRevertApplies(s.state, "test-snap", snap.R(43)), check.Equals, false)
+
+	s.enableAutoRevert("")
+	c.Check(healthstate.AutoRevertApplies(s.state, "test-snap", snap.R(43)), check.Equals, true)
+	// not a refresh
+	c.Check(healthstate.AutoRevertApplies(s.state, "test-snap", snap.R(42)), check.Equals, false)
+	c.Check(healthstate.AutoRevertApplies(s.state, "other-snap", snap.R(1)), check.Equals, false)
+
+	task := healthstate.Hook(s.state, "test-snap", snap.R(43))
+	var hooksup hookstate.HookSetup
+	c.Assert(task.Get("hook-setup", &hooksup), check.IsNil)
+	c.Check(hooksup.Always, check.Equals, true)
+}
+
+func (s *healthSuite) TestAutoRevertWatchStartedAfterRefresh(c *check.C) {
+	s.state.Lock()
+	s.enableAutoRevert("10m")
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	restore := healthstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	// the check-health hook is created before the new revision is linked
+	task := healthstate.Hook(s.state, "test-snap", snap.R(43))
+	si43 := &snap.SideInfo{RealName: "test-snap", Revision: snap.R(43)}
+	snaptest.MockSnap(c, "{name: test-snap, version: v2}", si43)
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{{RealName: "test-snap", Revision: snap.R(42)}, si43}),
+		Current:  snap.R(43),
+		Active:   true,
+		SnapType: "app",
+	})
+	unlink := s.state.NewTask("unlink-current-snap", "")
+	unlink.Set("snap-setup", &snapstate.SnapSetup{SideInfo: si43, Type: snap.TypeApp})
+	unlink.SetStatus(state.DoneStatus)
+	chg := s.state.NewChange("refresh-snap", "")
+	chg.AddTask(unlink)
+	chg.AddTask(task)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Status(), check.Equals, state.DoneStatus)
+	// no hook -> no health
+	health, err := healthstate.Get(s.state, "test-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health, check.IsNil)
+
+	var watchChg *state.Change
+	for _, chg := range s.state.Changes() {
+		if chg.Kind() == "watch-refresh-health" {
+			watchChg = chg
+		}
+	}
+	c.Assert(watchChg, check.NotNil)
+	c.Check(watchChg.Summary(), check.Equals, `Watch health of snap "test-snap" after its refresh`)
+	c.Assert(watchChg.Tasks(), check.HasLen, 1)
+	var watch map[string]interface{}
+	c.Assert(watchChg.Tasks()[0].Get("refresh-health-watch", &watch), check.IsNil)
+	c.Check(watch, check.DeepEquals, map[string]interface{}{
+		"instance-name": "test-snap",
+		"revision":      "43",
+		"deadline":      now.Add(10 * time.Minute).Format(time.RFC3339),
+	})
+}
+
+func (s *healthSuite) TestAutoRevertNoWatchForRevert(c *check.C) {
+	s.state.Lock()
+	s.enableAutoRevert("")
+	s.mockRefreshedSnap(c)
+	task := healthstate.Hook(s.state, "test-snap", snap.R(41))
+	unlink := s.state.NewTask("unlink-current-snap", "")
+	unlink.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{RealName: "test-snap", Revision: snap.R(41)},
+		Type:     snap.TypeApp,
+		Flags:    snapstate.Flags{Revert: true},
+	})
+	unlink.SetStatus(state.DoneStatus)
+	chg := s.state.NewChange("revert-snap", "")
+	chg.AddTask(unlink)
+	chg.AddTask(task)
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(chg.Status(), check.Equals, state.DoneStatus)
+	c.Check(s.state.Changes(), check.HasLen, 1)
+}
+
+func (s *healthSuite) mockWatchTask(deadline time.Time) *state.Task {
+	t := s.state.NewTask("watch-refresh-health", "")
+	t.Set("refresh-health-watch", map[string]interface{}{
+		"instance-name": "test-snap",
+		"revision":      "42",
+		"deadline":      deadline,
+	})
+	chg := s.state.NewChange("watch-refresh-health", "")
+	chg.AddTask(t)
+	return t
+}
+
+func (s *healthSuite) TestWatchRefreshHealthHealthy(c *check.C) {
+	s.state.Lock()
+	s.mockRefreshedSnap(c)
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	restore := healthstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	var queried [][]string
+	restore = healthstate.MockServicesStatus(func(units []string) ([]*systemd.UnitStatus, error) {
+		queried = append(queried, units)
+		return []*systemd.UnitStatus{{Name: units[0], Enabled: true, Active: true}}, nil
+	})
+	defer restore()
+	t := s.mockWatchTask(now.Add(time.Minute))
+	s.state.Unlock()
+
+	err := healthstate.DoWatchRefreshHealth(t, nil)
+	c.Check(err, check.DeepEquals, &state.Retry{After: 15 * time.Second})
+	// only the long running service is checked
+	c.Check(queried, check.DeepEquals, [][]string{{"snap.test-snap.svc.service"}})
+
+	now = now.Add(50 * time.Second)
+	err = healthstate.DoWatchRefreshHealth(t, nil)
+	c.Check(err, check.DeepEquals, &state.Retry{After: 10 * time.Second})
+
+	// the grace period is over
+	now = now.Add(10 * time.Second)
+	err = healthstate.DoWatchRefreshHealth(t, nil)
+	c.Check(err, check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Change().Tasks(), check.HasLen, 1)
+	c.Check(s.state.AllWarnings(), check.HasLen, 0)
+}
+
+func (s *healthSuite) testWatchRefreshHealthReverts(c *check.C, reason string) {
+	model := assertstest.FakeAssertion(map[string]interface{}{
+		"type":         "model",
+		"authority-id": "brand",
+		"series":       "16",
+		"brand-id":     "brand",
+		"model":        "baz-3000",
+		"architecture": "amd64",
+		"gadget":       "brand-gadget",
+		"kernel":       "kernel",
+		"timestamp":    "2018-01-01T08:00:00+00:00",
+	}).(*asserts.Model)
+	restore := snapstatetest.MockDeviceModel(model)
+	defer restore()
+
+	s.state.Lock()
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	restore = healthstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	t := s.mockWatchTask(now.Add(time.Minute))
+	s.state.Unlock()
+
+	err := healthstate.DoWatchRefreshHealth(t, nil)
+	c.Assert(err, check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	chg := t.Change()
+	c.Assert(len(chg.Tasks()) > 1, check.Equals, true)
+	var revertSetup *snapstate.SnapSetup
+	for _, rt := range chg.Tasks()[1:] {
+		if snapsup, err := snapstate.TaskSnapSetup(rt); err == nil {
+			revertSetup = snapsup
+			break
+		}
+	}
+	c.Assert(revertSetup, check.NotNil)
+	c.Check(revertSetup.InstanceName(), check.Equals, "test-snap")
+	c.Check(revertSetup.Revision(), check.Equals, snap.R(41))
+	c.Check(revertSetup.Flags.Revert, check.Equals, true)
+
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, check.HasLen, 1)
+	c.Check(warnings[0].String(), check.Equals, `snap "test-snap" was reverted after its refresh to revision 42 as `+reason)
+	c.Check(t.Log(), check.HasLen, 1)
+	c.Check(t.Log()[0], testutil.Contains, `reverting snap "test-snap" as `+reason)
+}
+
+func (s *healthSuite) TestWatchRefreshHealthRevertsOnHealthError(c *check.C) {
+	s.state.Lock()
+	s.mockRefreshedSnap(c)
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(42), Status: healthstate.ErrorStatus, Message: "database unreachable"},
+	})
+	s.state.Unlock()
+
+	s.testWatchRefreshHealthReverts(c, "it reported an error: database unreachable")
+}
+
+func (s *healthSuite) TestWatchRefreshHealthRevertsOnFailedService(c *check.C) {
+	s.state.Lock()
+	s.mockRefreshedSnap(c)
+	s.state.Unlock()
+
+	restore := healthstate.MockServicesStatus(func(units []string) ([]*systemd.UnitStatus, error) {
+		return []*systemd.UnitStatus{{Name: units[0], Enabled: true, Active: false}}, nil
+	})
+	defer restore()
+
+	s.testWatchRefreshHealthReverts(c, "its service snap.test-snap.svc.service is not running")
+}
+
+func (s *healthSuite) TestWatchRefreshHealthIgnoresDisabledServices(c *check.C) {
+	s.state.Lock()
+	s.mockRefreshedSnap(c)
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	restore := healthstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	restore = healthstate.MockServicesStatus(func(units []string) ([]*systemd.UnitStatus, error) {
+		return []*systemd.UnitStatus{{Name: units[0], Enabled: false, Active: false}}, nil
+	})
+	defer restore()
+	t := s.mockWatchTask(now)
+	s.state.Unlock()
+
+	c.Check(healthstate.DoWatchRefreshHealth(t, nil), check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Change().Tasks(), check.HasLen, 1)
+}
+
+func (s *healthSuite) TestWatchRefreshHealthNoLongerCurrent(c *check.C) {
+	s.state.Lock()
+	s.mockRefreshedSnap(c)
+	// the health of the older revision doesn't matter
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(42), Status: healthstate.ErrorStatus},
+	})
+	var snapst snapstate.SnapState
+	c.Assert(snapstate.Get(s.state, "test-snap", &snapst), check.IsNil)
+	snapst.Current = snap.R(41)
+	snapstate.Set(s.state, "test-snap", &snapst)
+	t := s.mockWatchTask(time.Now().Add(time.Hour))
+	s.state.Unlock()
+
+	c.Check(healthstate.DoWatchRefreshHealth(t, nil), check.IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Change().Tasks(), check.HasLen, 1)
+	c.Check(t.Log(), check.HasLen, 1)
+	c.Check(t.Log()[0], testutil.Contains, `revision 42 of snap "test-snap" is no longer current`)
+}
@@ -20,7 +20,12 @@
 package healthstate
 
 import (
+	"context"
 	"time"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
 )
 
 func MockCheckTimeout(t time.Duration) (restore func()) {
@@ -32,3 +37,37 @@ func MockCheckTimeout(t time.Duration) (restore func()) {
 }
 
 var KnownStatuses = knownStatuses
+
+var (
+	DoWatchRefreshHealth = doWatchRefreshHealth
+	AutoRevertApplies    = autoRevertApplies
+)
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+func MockServicesStatus(f func(units []string) ([]*systemd.UnitStatus, error)) (restore func()) {
+	return testutil.Mock(&servicesStatus, f)
+}
+
+func (m *ProbeManager) WaitProbes() {
+	m.wg.Wait()
+}
+
+func MockHTTPProbe(f func(ctx context.Context, url string) error) (restore func()) {
+	return testutil.Mock(&httpProbe, f)
+}
+
+func MockTCPProbe(f func(ctx context.Context, address string) error) (restore func()) {
+	return testutil.Mock(&tcpProbe, f)
+}
+
+func MockCommandProbe(f func(ctx context.Context, app *snap.AppInfo, command string) error) (restore func()) {
+	return testutil.Mock(&commandProbe, f)
+}
+
+var (
+	HTTPProbe    = httpProbe
+	CommandProbe = commandProbe
+)
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/snapcore/snapd/logger"
@@ -47,6 +48,7 @@ func init() {
 	}
 
 	snapstate.CheckHealthHook = Hook
+	snapstate.UnhealthySnaps = unhealthySnaps
 }
 
 func Hook(st *state.State, snapName string, snapRev snap.Revision) *state.Task {
@@ -57,6 +59,9 @@ func Hook(st *state.State, snapName string, snapRev snap.Revision) *state.Task {
 		Hook:     "check-health",
 		Optional: true,
 		Timeout:  checkTimeout,
+		// the handler needs to run even without a hook to watch the
+		// health of refreshed snaps
+		Always: autoRevertApplies(st, snapName, snapRev),
 	}
 
 	return hookstate.HookTask(st, summary, hooksup, nil)
@@ -98,8 +103,9 @@ type HealthState struct {
 	Code      string        `json:"code,omitempty"`
 }
 
-func Init(hookManager *hookstate.HookManager) {
+func Init(hookManager *hookstate.HookManager, runner *state.TaskRunner) {
 	hookManager.Register(regexp.MustCompile("^check-health$"), newHealthHandler)
+	runner.AddHandler("watch-refresh-health", doWatchRefreshHealth, nil)
 }
 
 func newHealthHandler(ctx *hookstate.Context) hookstate.Handler {
@@ -132,6 +138,13 @@ func (h *healthHandler) Done() error {
 		// (but if it were, health.Timestamp would still be zero)
 		return err
 	}
+	if health.Timestamp.IsZero() && !h.hookExists() {
+		// only running to watch the health of a refreshed snap
+		st := h.context.State()
+		st.Lock()
+		defer st.Unlock()
+		return maybeWatchRefreshHealth(h.context)
+	}
 	if health.Timestamp.IsZero() {
 		// health was actually the marker (or errors.Is(err, state.ErrNoState))
 		health = HealthState{
@@ -143,7 +156,25 @@ func (h *healthHandler) Done() error {
 		}
 	}
 
-	return h.appendHealth(&health)
+	if err := h.appendHealth(&health); err != nil {
+		return err
+	}
+
+	st := h.context.State()
+	st.Lock()
+	defer st.Unlock()
+	return maybeWatchRefreshHealth(h.context)
+}
+
+func (h *healthHandler) hookExists() bool {
+	st := h.context.State()
+	st.Lock()
+	defer st.Unlock()
+	info, err := snapstate.CurrentInfo(st, h.context.InstanceName())
+	if err != nil {
+		return true
+	}
+	return info.Hooks["check-health"] != nil
 }
 
 func (h *healthHandler) Error(err error) (bool, error) {
@@ -165,8 +196,10 @@ func (h *healthHandler) appendHealth(health *HealthState) error {
 }
 
 func appendHealth(ctx *hookstate.Context, health *HealthState) error {
-	st := ctx.State()
+	return setHealth(ctx.State(), ctx.InstanceName(), health)
+}
 
+func setHealth(st *state.State, instanceName string, health *HealthState) error {
 	var hs map[string]*HealthState
 	if err := st.Get("health", &hs); err != nil {
 		if !errors.Is(err, state.ErrNoState) {
@@ -174,7 +207,7 @@ func appendHealth(ctx *hookstate.Context, health *HealthState) error {
 		}
 		hs = map[string]*HealthState{}
 	}
-	hs[ctx.InstanceName()] = health
+	hs[instanceName] = health
 	st.Set("health", hs)
 
 	return nil
@@ -204,6 +237,34 @@ func All(st *state.State) (map[string]*HealthState, error) {
 	return hs, nil
 }
 
+// unhealthySnaps returns the installed snaps whose current revision
+// reported an error in its last health check, sorted by name.
+func unhealthySnaps(st *state.State) ([]string, error) {
+	hs, err := All(st)
+	if err != nil {
+		return nil, err
+	}
+	var unhealthy []string
+	for name, health := range hs {
+		if health.Status != ErrorStatus {
+			continue
+		}
+		var snapst snapstate.SnapState
+		if err := snapstate.Get(st, name, &snapst); err != nil {
+			if errors.Is(err, state.ErrNoState) {
+				continue
+			}
+			return nil, err
+		}
+		if snapst.Current != health.Revision {
+			continue
+		}
+		unhealthy = append(unhealthy, name)
+	}
+	sort.Strings(unhealthy)
+	return unhealthy, nil
+}
+
 func Get(st *state.State, snap string) (*HealthState, error) {
 	var hs map[string]json.RawMessage
 	if err := st.Get("health", &hs); err != nil {
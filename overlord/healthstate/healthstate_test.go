@@ -68,7 +68,7 @@ func (s *healthSuite) SetUpTest(c *check.C) {
 	s.o.AddManager(s.hookMgr)
 	s.o.AddManager(s.o.TaskRunner())
 
-	healthstate.Init(s.hookMgr)
+	healthstate.Init(s.hookMgr, s.o.TaskRunner())
 
 	c.Assert(s.o.StartUp(), check.IsNil)
 
@@ -251,3 +251,36 @@ func (s *healthSuite) TestSetFromHookContextEmpty(c *check.C) {
 	// no health in the context -> no health in state
 	c.Check(s.state.Get("health", &hs), testutil.ErrorIs, state.ErrNoState)
 }
+
+func (s *healthSuite) TestUnhealthySnaps(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	unhealthy, err := snapstate.UnhealthySnaps(s.state)
+	c.Assert(err, check.IsNil)
+	c.Check(unhealthy, check.HasLen, 0)
+
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(42), Status: healthstate.ErrorStatus},
+		// not installed
+		"other-snap": {Revision: snap.R(1), Status: healthstate.ErrorStatus},
+	})
+	unhealthy, err = snapstate.UnhealthySnaps(s.state)
+	c.Assert(err, check.IsNil)
+	c.Check(unhealthy, check.DeepEquals, []string{"test-snap"})
+
+	// only the health of the current revision counts
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(41), Status: healthstate.ErrorStatus},
+	})
+	unhealthy, err = snapstate.UnhealthySnaps(s.state)
+	c.Assert(err, check.IsNil)
+	c.Check(unhealthy, check.HasLen, 0)
+
+	s.state.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(42), Status: healthstate.BlockedStatus},
+	})
+	unhealthy, err = snapstate.UnhealthySnaps(s.state)
+	c.Assert(err, check.IsNil)
+	c.Check(unhealthy, check.HasLen, 0)
+}
@@ -0,0 +1,431 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+const (
+	defaultProbeInterval  = 30 * time.Second
+	defaultProbeTimeout   = 10 * time.Second
+	defaultProbeThreshold = 3
+)
+
+var (
+	// probeClient only talks directly to the loopback hosts the probes
+	// are validated to target, it does not go through proxies nor
+	// follow redirects elsewhere
+	probeClient = &http.Client{
+		Transport: &http.Transport{Proxy: nil},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return errors.New("health probes do not follow redirects")
+		},
+	}
+
+	httpProbe = func(ctx context.Context, url string) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		rsp, err := probeClient.Do(req)
+		if err != nil {
+			return err
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode >= 400 {
+			return fmt.Errorf("got status %s", rsp.Status)
+		}
+		return nil
+	}
+
+	tcpProbe = func(ctx context.Context, address string) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	// commandProbe runs the command inside the confinement of the app,
+	// relative to the snap directory like the command of the app itself
+	commandProbe = func(ctx context.Context, app *snap.AppInfo, command string) error {
+		// do not look up snap in the $PATH of snapd
+		snapCmd := filepath.Join(dirs.GlobalRootDir, "/usr/bin/snap")
+		cmd := exec.CommandContext(ctx, snapCmd, "run", "--shell", app.Snap.InstanceName()+"."+app.Name, "-c", "exec $SNAP/"+command)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if len(out) > 0 {
+				return fmt.Errorf("%v: %s", err, out)
+			}
+			return err
+		}
+		return nil
+	}
+)
+
+// ProbeManager runs the health probes declared by snap services and
+// records their health.
+type ProbeManager struct {
+	state *state.State
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// the fields below are keyed by snap.app
+	next     map[string]time.Time
+	running  map[string]bool
+	failures map[string]int
+	// probed is when the probes last completed, it is only kept in
+	// memory as the recorded health changes only with the results
+	probed map[string]time.Time
+}
+
+// Manager returns a new ProbeManager.
+func Manager(st *state.State) *ProbeManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ProbeManager{
+		state:    st,
+		ctx:      ctx,
+		cancel:   cancel,
+		next:     make(map[string]time.Time),
+		running:  make(map[string]bool),
+		failures: make(map[string]int),
+		probed:   make(map[string]time.Time),
+	}
+}
+
+type dueProbe struct {
+	key   string
+	app   *snap.AppInfo
+	probe *snap.HealthProbeInfo
+}
+
+func probeInterval(probe *snap.HealthProbeInfo) time.Duration {
+	if probe.Interval == 0 {
+		return defaultProbeInterval
+	}
+	return time.Duration(probe.Interval)
+}
+
+func probeTimeout(probe *snap.HealthProbeInfo) time.Duration {
+	if probe.Timeout == 0 {
+		return defaultProbeTimeout
+	}
+	return time.Duration(probe.Timeout)
+}
+
+func probeThreshold(probe *snap.HealthProbeInfo) int {
+	if probe.Threshold == 0 {
+		return defaultProbeThreshold
+	}
+	return probe.Threshold
+}
+
+// Ensure starts the health probes that are due, of active services only.
+func (m *ProbeManager) Ensure() error {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	snapStates, err := snapstate.All(m.state)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	var candidates []dueProbe
+	var units []string
+	var earliest time.Time
+	m.mu.Lock()
+	for name, snapst := range snapStates {
+		if !snapst.Active {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			logger.Noticef("cannot get info of snap %q to probe its health: %v", name, err)
+			continue
+		}
+		for _, app := range info.Services() {
+			// the status of user services cannot be known here
+			if app.Health == nil || app.DaemonScope != snap.SystemDaemon {
+				continue
+			}
+			key := snap.JoinSnapApp(name, app.Name)
+			if m.running[key] {
+				continue
+			}
+			if next := m.next[key]; next.After(now) {
+				if earliest.IsZero() || next.Before(earliest) {
+					earliest = next
+				}
+				continue
+			}
+			candidates = append(candidates, dueProbe{key: key, app: app, probe: app.Health})
+			units = append(units, app.ServiceName())
+		}
+	}
+	m.mu.Unlock()
+
+	if len(candidates) > 0 {
+		m.state.Unlock()
+		statuses, err := servicesStatus(units)
+		m.state.Lock()
+		if err != nil {
+			return fmt.Errorf("cannot get status of services to probe their health: %v", err)
+		}
+
+		m.mu.Lock()
+		for i, due := range candidates {
+			next := now.Add(probeInterval(due.probe))
+			m.next[due.key] = next
+			if earliest.IsZero() || next.Before(earliest) {
+				earliest = next
+			}
+			// probing stopped services makes no sense
+			if i >= len(statuses) || !statuses[i].Active {
+				delete(m.failures, due.key)
+				continue
+			}
+			m.running[due.key] = true
+			m.wg.Add(1)
+			go m.runProbe(due)
+		}
+		m.mu.Unlock()
+	}
+
+	if !earliest.IsZero() {
+		m.state.EnsureBefore(earliest.Sub(now))
+	}
+	return nil
+}
+
+func (m *ProbeManager) runProbe(due dueProbe) {
+	defer m.wg.Done()
+
+	ctx, cancel := context.WithTimeout(m.ctx, probeTimeout(due.probe))
+	defer cancel()
+
+	var err error
+	switch {
+	case due.probe.HTTP != "":
+		err = httpProbe(ctx, due.probe.HTTP)
+	case due.probe.TCP != "":
+		err = tcpProbe(ctx, due.probe.TCP)
+	default:
+		err = commandProbe(ctx, due.app, due.probe.Command)
+	}
+	if m.ctx.Err() != nil {
+		// snapd is stopping, the result is meaningless
+		return
+	}
+
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	m.mu.Lock()
+	delete(m.running, due.key)
+	m.probed[due.key] = timeNow()
+	failures := 0
+	if err != nil {
+		m.failures[due.key]++
+		failures = m.failures[due.key]
+	} else {
+		delete(m.failures, due.key)
+	}
+	m.mu.Unlock()
+
+	threshold := probeThreshold(due.probe)
+	var health *HealthState
+	switch {
+	case err == nil:
+		health = &HealthState{Status: OkayStatus}
+	case failures >= threshold:
+		// the message does not change with further failures, so that
+		// the health is not recorded again until the error changes
+		health = &HealthState{
+			Status:  ErrorStatus,
+			Code:    "snapd-probe-failed",
+			Message: fmt.Sprintf("health probe failed %d times: %v", threshold, err),
+		}
+	default:
+		logger.Debugf("health probe of %s failed (%d/%d): %v", due.key, failures, threshold, err)
+		return
+	}
+	health.Revision = due.app.Snap.Revision
+	health.Timestamp = timeNow()
+
+	if err := setAppHealth(m.state, due.app, health); err != nil {
+		logger.Noticef("cannot record health of %s: %v", due.key, err)
+	}
+}
+
+// LastProbed returns when the health probe of the given app of the snap
+// last completed, or the zero time if it has not run since snapd started.
+func (m *ProbeManager) LastProbed(instanceName, app string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.probed[snap.JoinSnapApp(instanceName, app)]
+}
+
+// Stop stops the running probes.
+func (m *ProbeManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// sameHealth returns whether the two health states differ only in their
+// timestamps.
+func sameHealth(a, b *HealthState) bool {
+	return a.Revision == b.Revision && a.Status == b.Status && a.Code == b.Code && a.Message == b.Message
+}
+
+// setAppHealth records the health of an app and, for snaps without a
+// check-health hook, derives the health of the snap from the health of
+// its apps. The state is only modified when the health changes, as probes
+// run often.
+func setAppHealth(st *state.State, app *snap.AppInfo, health *HealthState) error {
+	var all map[string]map[string]*HealthState
+	if err := st.Get("app-health", &all); err != nil {
+		if !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		all = make(map[string]map[string]*HealthState)
+	}
+	snapName := app.Snap.InstanceName()
+	if all[snapName] == nil {
+		all[snapName] = make(map[string]*HealthState)
+	}
+	if old := all[snapName][app.Name]; old != nil && sameHealth(old, health) {
+		return nil
+	}
+	all[snapName][app.Name] = health
+	st.Set("app-health", all)
+
+	if app.Snap.Hooks["check-health"] != nil {
+		// the snap reports its own health
+		return nil
+	}
+
+	snapHealth := &HealthState{
+		Revision:  health.Revision,
+		Timestamp: health.Timestamp,
+		Status:    OkayStatus,
+	}
+	names := make([]string, 0, len(all[snapName]))
+	for name := range all[snapName] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		appHealth := all[snapName][name]
+		if appHealth.Revision != health.Revision || appHealth.Status != ErrorStatus {
+			continue
+		}
+		snapHealth.Status = ErrorStatus
+		snapHealth.Code = appHealth.Code
+		snapHealth.Message = fmt.Sprintf("service %q: %s", name, appHealth.Message)
+		break
+	}
+	old, err := Get(st, snapName)
+	if err != nil {
+		return err
+	}
+	if old != nil && sameHealth(old, snapHealth) {
+		return nil
+	}
+	return setHealth(st, snapName, snapHealth)
+}
+
+// AppHealth returns the health of the apps of the current revision of the
+// given snap, keyed by app name, as recorded by their health probes.
+func AppHealth(st *state.State, instanceName string) (map[string]*HealthState, error) {
+	var all map[string]map[string]*HealthState
+	if err := st.Get("app-health", &all); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var apps map[string]*HealthState
+	for name, health := range all[instanceName] {
+		if health.Revision != snapst.Current {
+			continue
+		}
+		if apps == nil {
+			apps = make(map[string]*HealthState)
+		}
+		apps[name] = health
+	}
+	return apps, nil
+}
+
+// ForgetAppHealth drops the recorded health of the given apps of the snap,
+// so that the next run of their probes records it afresh. This is used when
+// the services of the apps are restarted, as the health of an app is only
+// recorded again when it changes.
+func ForgetAppHealth(st *state.State, instanceName string, apps []string) error {
+	var all map[string]map[string]*HealthState
+	if err := st.Get("app-health", &all); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil
+		}
+		return err
+	}
+	changed := false
+	for _, name := range apps {
+		if _, ok := all[instanceName][name]; ok {
+			delete(all[instanceName], name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if len(all[instanceName]) == 0 {
+		delete(all, instanceName)
+	}
+	st.Set("app-health", all)
+	return nil
+}
@@ -0,0 +1,330 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package healthstate_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/healthstate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/testutil"
+)
+
+const probeSnapYaml = `name: probe-snap
+version: v1
+apps:
+  web:
+    command: bin/web
+    daemon: simple
+    health:
+      http: http://localhost:8080/healthz
+  db:
+    command: bin/db
+    daemon: simple
+    health:
+      tcp: localhost:5432
+      interval: 10s
+      threshold: 2
+  stopped:
+    command: bin/stopped
+    daemon: simple
+    health:
+      command: bin/check
+  plain:
+    command: bin/plain
+    daemon: simple
+`
+
+func (s *healthSuite) mockProbeSnap(c *check.C) {
+	si := &snap.SideInfo{RealName: "probe-snap", Revision: snap.R(7)}
+	snaptest.MockSnap(c, probeSnapYaml, si)
+	snapstate.Set(s.state, "probe-snap", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  snap.R(7),
+		Active:   true,
+		SnapType: "app",
+	})
+}
+
+func (s *healthSuite) TestProbeManager(c *check.C) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	defer healthstate.MockTimeNow(func() time.Time { return now })()
+
+	var statusCalls [][]string
+	defer healthstate.MockServicesStatus(func(units []string) ([]*systemd.UnitStatus, error) {
+		statusCalls = append(statusCalls, units)
+		statuses := make([]*systemd.UnitStatus, 0, len(units))
+		for _, unit := range units {
+			statuses = append(statuses, &systemd.UnitStatus{
+				Name:    unit,
+				Enabled: true,
+				Active:  unit != "snap.probe-snap.stopped.service",
+			})
+		}
+		return statuses, nil
+	})()
+
+	var httpCalls, tcpCalls int
+	defer healthstate.MockHTTPProbe(func(ctx context.Context, url string) error {
+		httpCalls++
+		c.Check(url, check.Equals, "http://localhost:8080/healthz")
+		return nil
+	})()
+	defer healthstate.MockTCPProbe(func(ctx context.Context, address string) error {
+		tcpCalls++
+		c.Check(address, check.Equals, "localhost:5432")
+		return errors.New("connection refused")
+	})()
+	defer healthstate.MockCommandProbe(func(ctx context.Context, app *snap.AppInfo, command string) error {
+		c.Fatalf("stopped services must not be probed")
+		return nil
+	})()
+
+	s.state.Lock()
+	s.mockProbeSnap(c)
+	s.state.Unlock()
+
+	mgr := healthstate.Manager(s.state)
+	defer mgr.Stop()
+
+	c.Assert(mgr.Ensure(), check.IsNil)
+	mgr.WaitProbes()
+	c.Check(statusCalls, check.HasLen, 1)
+	c.Check(statusCalls[0], check.HasLen, 3)
+	c.Check(httpCalls, check.Equals, 1)
+	c.Check(tcpCalls, check.Equals, 1)
+
+	s.state.Lock()
+	apps, err := healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps, check.DeepEquals, map[string]*healthstate.HealthState{
+		"web": {Revision: snap.R(7), Timestamp: now, Status: healthstate.OkayStatus},
+	})
+	health, err := healthstate.Get(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health.Status, check.Equals, healthstate.OkayStatus)
+	s.state.Unlock()
+
+	// nothing is due yet
+	c.Assert(mgr.Ensure(), check.IsNil)
+	mgr.WaitProbes()
+	c.Check(statusCalls, check.HasLen, 1)
+	c.Check(tcpCalls, check.Equals, 1)
+
+	// the db probe fails a second time and reaches its threshold
+	now = now.Add(10 * time.Second)
+	c.Assert(mgr.Ensure(), check.IsNil)
+	mgr.WaitProbes()
+	c.Check(httpCalls, check.Equals, 1)
+	c.Check(tcpCalls, check.Equals, 2)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	apps, err = healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps["db"], check.DeepEquals, &healthstate.HealthState{
+		Revision:  snap.R(7),
+		Timestamp: now,
+		Status:    healthstate.ErrorStatus,
+		Code:      "snapd-probe-failed",
+		Message:   "health probe failed 2 times: connection refused",
+	})
+	health, err = healthstate.Get(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health.Status, check.Equals, healthstate.ErrorStatus)
+	c.Check(health.Message, check.Equals, `service "db": health probe failed 2 times: connection refused`)
+}
+
+func (s *healthSuite) TestProbeManagerRecordsHealthChangesOnly(c *check.C) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	defer healthstate.MockTimeNow(func() time.Time { return now })()
+
+	defer healthstate.MockServicesStatus(func(units []string) ([]*systemd.UnitStatus, error) {
+		statuses := make([]*systemd.UnitStatus, 0, len(units))
+		for _, unit := range units {
+			statuses = append(statuses, &systemd.UnitStatus{
+				Name:    unit,
+				Enabled: true,
+				Active:  unit != "snap.probe-snap.stopped.service",
+			})
+		}
+		return statuses, nil
+	})()
+	defer healthstate.MockHTTPProbe(func(ctx context.Context, url string) error {
+		return nil
+	})()
+	tcpErr := errors.New("connection refused")
+	defer healthstate.MockTCPProbe(func(ctx context.Context, address string) error {
+		return tcpErr
+	})()
+
+	s.state.Lock()
+	s.mockProbeSnap(c)
+	s.state.Unlock()
+
+	mgr := healthstate.Manager(s.state)
+	defer mgr.Stop()
+
+	probe := func() {
+		c.Assert(mgr.Ensure(), check.IsNil)
+		mgr.WaitProbes()
+	}
+
+	// web is okay, db fails below its threshold
+	probe()
+	c.Check(mgr.LastProbed("probe-snap", "web"), check.Equals, now)
+	// db reaches its threshold
+	now = now.Add(10 * time.Second)
+	probe()
+
+	s.state.Lock()
+	recorded, err := healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	recordedSnap, err := healthstate.Get(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	c.Check(recorded["web"].Timestamp, check.Equals, now.Add(-10*time.Second))
+	c.Check(recorded["db"].Timestamp, check.Equals, now)
+	c.Check(recordedSnap.Timestamp, check.Equals, now)
+
+	// the same results again are not recorded, only remembered
+	now = now.Add(30 * time.Second)
+	probe()
+	c.Check(mgr.LastProbed("probe-snap", "web"), check.Equals, now)
+	c.Check(mgr.LastProbed("probe-snap", "db"), check.Equals, now)
+
+	s.state.Lock()
+	apps, err := healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	health, err := healthstate.Get(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	s.state.Unlock()
+	c.Check(apps, check.DeepEquals, recorded)
+	c.Check(health, check.DeepEquals, recordedSnap)
+
+	// a different error is recorded
+	tcpErr = errors.New("connection reset")
+	now = now.Add(10 * time.Second)
+	probe()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	apps, err = healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps["db"].Message, check.Equals, "health probe failed 2 times: connection reset")
+	c.Check(apps["db"].Timestamp, check.Equals, now)
+	health, err = healthstate.Get(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(health.Message, check.Equals, `service "db": health probe failed 2 times: connection reset`)
+}
+
+func (s *healthSuite) TestAppHealthIgnoresOtherRevisions(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockProbeSnap(c)
+
+	s.state.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"probe-snap": {
+			"web": {Revision: snap.R(6), Status: healthstate.ErrorStatus},
+			"db":  {Revision: snap.R(7), Status: healthstate.OkayStatus},
+		},
+	})
+
+	apps, err := healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps, check.HasLen, 1)
+	c.Check(apps["db"].Status, check.Equals, healthstate.OkayStatus)
+
+	apps, err = healthstate.AppHealth(s.state, "other-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps, check.IsNil)
+}
+
+func (s *healthSuite) TestForgetAppHealth(c *check.C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.mockProbeSnap(c)
+
+	s.state.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"probe-snap": {
+			"web": {Revision: snap.R(7), Status: healthstate.OkayStatus},
+			"db":  {Revision: snap.R(7), Status: healthstate.OkayStatus},
+		},
+	})
+
+	c.Assert(healthstate.ForgetAppHealth(s.state, "probe-snap", []string{"web", "other"}), check.IsNil)
+	apps, err := healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps, check.HasLen, 1)
+	c.Check(apps["db"], check.NotNil)
+
+	c.Assert(healthstate.ForgetAppHealth(s.state, "probe-snap", []string{"db"}), check.IsNil)
+	apps, err = healthstate.AppHealth(s.state, "probe-snap")
+	c.Assert(err, check.IsNil)
+	c.Check(apps, check.IsNil)
+
+	c.Assert(healthstate.ForgetAppHealth(s.state, "other-snap", []string{"db"}), check.IsNil)
+}
+
+func (s *healthSuite) TestHTTPProbe(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(200)
+		case "/redirect":
+			http.Redirect(w, r, "/healthz", http.StatusFound)
+		default:
+			w.WriteHeader(500)
+		}
+	}))
+	defer srv.Close()
+
+	c.Check(healthstate.HTTPProbe(context.Background(), srv.URL+"/healthz"), check.IsNil)
+	c.Check(healthstate.HTTPProbe(context.Background(), srv.URL+"/broken"), check.ErrorMatches, "got status 500 Internal Server Error")
+	c.Check(healthstate.HTTPProbe(context.Background(), srv.URL+"/redirect"), check.ErrorMatches, ".*: health probes do not follow redirects")
+}
+
+func (s *healthSuite) TestCommandProbeDoesNotUsePath(c *check.C) {
+	// the snap found in $PATH is not used
+	pathSnap := testutil.MockCommand(c, "snap", "exit 1")
+	defer pathSnap.Restore()
+	snapCmd := testutil.MockCommand(c, filepath.Join(dirs.GlobalRootDir, "/usr/bin/snap"), "")
+	defer snapCmd.Restore()
+
+	info := snaptest.MockInfo(c, probeSnapYaml, &snap.SideInfo{Revision: snap.R(7)})
+	err := healthstate.CommandProbe(context.Background(), info.Apps["stopped"], "bin/check")
+	c.Assert(err, check.IsNil)
+	c.Check(pathSnap.Calls(), check.HasLen, 0)
+	c.Check(snapCmd.Calls(), check.DeepEquals, [][]string{
+		{"snap", "run", "--shell", "probe-snap.stopped", "-c", "exec $SNAP/bin/check"},
+	})
+}
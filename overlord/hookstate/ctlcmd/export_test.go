@@ -73,6 +73,10 @@ func MockServicestateControlFunc(f func(*state.State, []*snap.AppInfo, *services
 	return func() { servicestateControl = old }
 }
 
+func MockServicestateSetServiceEnvironment(f func(*state.State, *snap.AppInfo, map[string]string, []string) error) (restore func()) {
+	return testutil.Mock(&servicestateSetServiceEnvironment, f)
+}
+
 func MockSnapstateInstallComponentsFunc(f func(ctx context.Context, st *state.State, names []string, info *snap.Info, opts snapstate.Options) ([]*state.TaskSet, error)) (restore func()) {
 	old := snapstateInstallComponents
 	snapstateInstallComponents = f
@@ -0,0 +1,102 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snapcore/snapd/i18n"
+	"github.com/snapcore/snapd/overlord/servicestate"
+)
+
+var servicestateSetServiceEnvironment = servicestate.SetServiceEnvironment
+
+var (
+	shortSetEnvHelp = i18n.G("Set environment variables of a service")
+	longSetEnvHelp  = i18n.G(`
+The set-env command sets environment variables of the given service of the
+snap, which then gets restarted to use them.
+
+$ snapctl set-env snapname.app LOG_LEVEL=debug PORT=8080
+
+A variable is unset by giving its name followed by an exclamation mark:
+
+$ snapctl set-env snapname.app LOG_LEVEL!
+
+The variables persist across restarts of the service and refreshes of the
+snap. If executed from the "configure" hook, the service will be restarted
+after the hook finishes.`)
+)
+
+func init() {
+	addCommand("set-env", shortSetEnvHelp, longSetEnvHelp, func() command { return &setEnvCommand{} })
+}
+
+type setEnvCommand struct {
+	baseCommand
+	Positional struct {
+		ServiceName string   `positional-arg-name:"<service>" required:"yes"`
+		Vars        []string `positional-arg-name:"<KEY=VALUE|KEY!>" required:"1"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *setEnvCommand) Execute(args []string) error {
+	context, err := c.ensureContext()
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]string, len(c.Positional.Vars))
+	var unset []string
+	for _, v := range c.Positional.Vars {
+		if name, value, ok := strings.Cut(v, "="); ok {
+			set[name] = value
+			continue
+		}
+		if name := strings.TrimSuffix(v, "!"); name != v {
+			unset = append(unset, name)
+			continue
+		}
+		return fmt.Errorf(i18n.G("invalid environment assignment %q, expected KEY=VALUE or KEY!"), v)
+	}
+
+	st := context.State()
+	if c.Positional.ServiceName == context.InstanceName() {
+		return fmt.Errorf(i18n.G("cannot set environment of all the services of snap %q, a single service is needed"), context.InstanceName())
+	}
+	appInfos, err := getServiceInfos(st, context.InstanceName(), []string{c.Positional.ServiceName})
+	if err != nil {
+		return err
+	}
+
+	st.Lock()
+	err = servicestateSetServiceEnvironment(st, appInfos[0], set, unset)
+	st.Unlock()
+	if err != nil {
+		return err
+	}
+
+	inst := servicestate.Instruction{
+		Action: "restart",
+		Names:  []string{c.Positional.ServiceName},
+	}
+	return runServiceCommand(context, &inst)
+}
@@ -0,0 +1,93 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ctlcmd_test
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/hookstate/ctlcmd"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (s *servicectlSuite) TestSetEnvCommand(c *C) {
+	var setEnvCalls int
+	restore := ctlcmd.MockServicestateSetServiceEnvironment(func(st *state.State, app *snap.AppInfo, set map[string]string, unset []string) error {
+		setEnvCalls++
+		c.Check(app.Name, Equals, "test-service")
+		c.Check(set, DeepEquals, map[string]string{"LOG_LEVEL": "debug", "GREETING": "a=b c"})
+		c.Check(unset, DeepEquals, []string{"PORT"})
+		return nil
+	})
+	defer restore()
+
+	var serviceChangeFuncCalled bool
+	restore = mockServiceChangeFunc(func(appInfos []*snap.AppInfo, inst *servicestate.Instruction) {
+		serviceChangeFuncCalled = true
+		c.Assert(appInfos, HasLen, 1)
+		c.Assert(appInfos[0].Name, Equals, "test-service")
+		c.Check(inst.Action, Equals, "restart")
+		c.Check(inst.Names, DeepEquals, []string{"test-snap.test-service"})
+	})
+	defer restore()
+
+	_, _, err := ctlcmd.Run(s.mockContext, []string{"set-env", "test-snap.test-service", "LOG_LEVEL=debug", "PORT!", "GREETING=a=b c"}, 0)
+	c.Check(err, ErrorMatches, "forced error")
+	c.Check(setEnvCalls, Equals, 1)
+	c.Check(serviceChangeFuncCalled, Equals, true)
+}
+
+func (s *servicectlSuite) TestSetEnvCommandErrors(c *C) {
+	restore := ctlcmd.MockServicestateSetServiceEnvironment(func(st *state.State, app *snap.AppInfo, set map[string]string, unset []string) error {
+		if _, ok := set["SNAP_DATA"]; ok {
+			return fmt.Errorf("reserved")
+		}
+		c.Fatalf("unexpected call")
+		return nil
+	})
+	defer restore()
+	restore = mockServiceChangeFunc(func(appInfos []*snap.AppInfo, inst *servicestate.Instruction) {
+		c.Fatalf("unexpected restart")
+	})
+	defer restore()
+
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"set-env", "test-snap.test-service"}, "the required argument `<KEY=VALUE|KEY!> \\(at least 1 argument\\)` was not provided"},
+		{[]string{"set-env", "test-snap.test-service", "FOO"}, `invalid environment assignment "FOO", expected KEY=VALUE or KEY!`},
+		{[]string{"set-env", "test-snap", "FOO=bar"}, `cannot set environment of all the services of snap "test-snap", a single service is needed`},
+		{[]string{"set-env", "test-snap.normal-app", "FOO=bar"}, `unknown service: "test-snap.normal-app"`},
+		{[]string{"set-env", "other-snap.test-service", "FOO=bar"}, `unknown service: "other-snap.test-service"`},
+		{[]string{"set-env", "test-snap.test-service", "SNAP_DATA=x"}, "reserved"},
+	} {
+		_, _, err := ctlcmd.Run(s.mockContext, tc.args, 0)
+		c.Check(err, ErrorMatches, tc.err, Commentf("%v", tc.args))
+	}
+}
+
+func (s *servicectlSuite) TestSetEnvCommandWithoutContext(c *C) {
+	_, _, err := ctlcmd.Run(nil, []string{"set-env", "test-snap.test-service", "FOO=bar"}, 0)
+	c.Check(err, ErrorMatches, `cannot invoke snapctl operation commands \(here "set-env"\) from outside of a snap`)
+}
@@ -20,12 +20,18 @@
 package apparmorprompting
 
 import (
+	"time"
+
 	"github.com/snapcore/snapd/interfaces/prompting/requestprompts"
 	"github.com/snapcore/snapd/interfaces/prompting/requestrules"
 	"github.com/snapcore/snapd/sandbox/apparmor/notify/listener"
 	"github.com/snapcore/snapd/testutil"
 )
 
+func MockTimeAfterFunc(f func(d time.Duration, cb func()) *time.Timer) (restore func()) {
+	return testutil.Mock(&timeAfterFunc, f)
+}
+
 func MockListenerRegister(f func() (*listener.Listener, error)) (restore func()) {
 	return testutil.Mock(&listenerRegister, f)
 }
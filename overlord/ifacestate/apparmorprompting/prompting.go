@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"gopkg.in/tomb.v2"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/prompting/requestprompts"
 	"github.com/snapcore/snapd/interfaces/prompting/requestrules"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/sandbox/apparmor/notify/listener"
 	"github.com/snapcore/snapd/snap/naming"
@@ -43,6 +45,8 @@ var (
 	listenerReqs     = func(l *listener.Listener) <-chan *listener.Request { return l.Reqs() }
 
 	requestReply = func(req *listener.Request, allowedPermission any) error { return req.Reply(allowedPermission) }
+
+	timeAfterFunc = time.AfterFunc
 )
 
 // A Manager holds outstanding prompts and mediates their replies, further it
@@ -63,7 +67,8 @@ type Manager interface {
 var _ Manager = (*InterfacesRequestsManager)(nil)
 
 type InterfacesRequestsManager struct {
-	tomb tomb.Tomb
+	tomb  tomb.Tomb
+	state *state.State
 	// The lock should be held for writing when acting on the manager in a way
 	// which requires synchronization between the prompts and rules databases,
 	// or when removing those databases. The lock can be held for reading when
@@ -72,6 +77,9 @@ type InterfacesRequestsManager struct {
 	listener *listener.Listener
 	prompts  *requestprompts.PromptDB
 	rules    *requestrules.RuleDB
+	// timers holds, by prompt ID, the timers which resolve outstanding
+	// prompts once they time out. It is protected by the lock.
+	timers map[prompting.IDType]*time.Timer
 
 	notifyPrompt func(userID uint32, promptID prompting.IDType, data map[string]string) error
 	notifyRule   func(userID uint32, ruleID prompting.IDType, data map[string]string) error
@@ -140,9 +148,11 @@ func New(s *state.State) (m *InterfacesRequestsManager, retErr error) {
 	}()
 
 	m = &InterfacesRequestsManager{
+		state:        s,
 		listener:     listenerBackend,
 		prompts:      promptsBackend,
 		rules:        rulesBackend,
+		timers:       make(map[prompting.IDType]*time.Timer),
 		notifyPrompt: notifyPrompt,
 		notifyRule:   notifyRule,
 	}
@@ -221,6 +231,11 @@ func (m *InterfacesRequestsManager) handleListenerReq(req *listener.Request) err
 	remainingPerms := make([]string, 0, len(permissions))
 	satisfiedPerms := make([]string, 0, len(permissions))
 
+	// The timeout policy is read from the system configuration, so this must
+	// happen before taking the manager lock, as callers of the manager may
+	// hold the state lock while waiting for the manager lock.
+	timeout, timeoutOutcome := m.promptTimeoutPolicy(iface)
+
 	// we're done with early checks, serious business starts now, and we can
 	// take the lock
 	m.lock.Lock()
@@ -297,13 +312,92 @@ func (m *InterfacesRequestsManager) handleListenerReq(req *listener.Request) err
 
 	logger.Debugf("adding prompt to internal storage: %+v", newPrompt)
 
+	if timeout > 0 {
+		promptID := newPrompt.ID
+		m.timers[promptID] = timeAfterFunc(timeout, func() {
+			m.handlePromptTimeout(userID, promptID, timeoutOutcome)
+		})
+	}
+
 	return nil
 }
 
+// promptTimeoutPolicy returns the duration after which an unanswered prompt
+// for the given interface should be resolved automatically, along with the
+// outcome with which it should be resolved. A zero duration means that
+// prompts wait for a reply indefinitely.
+func (m *InterfacesRequestsManager) promptTimeoutPolicy(iface string) (time.Duration, prompting.OutcomeType) {
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	tr := config.NewTransaction(m.state)
+	var timeoutStr, outcomeStr string
+	if err := tr.GetMaybe("core", prompting.TimeoutOption+"."+iface, &timeoutStr); err != nil {
+		logger.Noticef("cannot get prompt timeout for the %s interface: %v", iface, err)
+		return 0, prompting.OutcomeUnset
+	}
+	if timeoutStr == "" {
+		return 0, prompting.OutcomeUnset
+	}
+	// The value was validated when it was set
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil || timeout < 0 {
+		logger.Noticef("invalid prompt timeout for the %s interface: %q", iface, timeoutStr)
+		return 0, prompting.OutcomeUnset
+	}
+	if err := tr.GetMaybe("core", prompting.TimeoutOutcomeOption+"."+iface, &outcomeStr); err != nil {
+		logger.Noticef("cannot get prompt timeout outcome for the %s interface: %v", iface, err)
+	}
+	outcome := prompting.OutcomeDeny
+	if outcomeStr == "allow-once" {
+		outcome = prompting.OutcomeAllow
+	}
+	return timeout, outcome
+}
+
+// handlePromptTimeout resolves the prompt with the given ID with the given
+// default outcome, if it is still outstanding. The outcome is applied to the
+// prompt only and never results in a new rule.
+func (m *InterfacesRequestsManager) handlePromptTimeout(userID uint32, promptID prompting.IDType, outcome prompting.OutcomeType) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.timers, promptID)
+	if m.prompts == nil {
+		// the manager has been stopped in the meantime
+		return
+	}
+	prompt, err := m.prompts.Timeout(userID, promptID, outcome)
+	if err != nil {
+		if !errors.Is(err, requestprompts.ErrNotFound) && !errors.Is(err, requestprompts.ErrClosed) {
+			logger.Noticef("cannot resolve timed out prompt %s: %v", promptID, err)
+		}
+		// otherwise the prompt was already resolved
+		return
+	}
+	logger.Debugf("prompt timed out and was resolved with outcome %q: %+v", outcome, prompt)
+}
+
+// stopPromptTimers stops and forgets the timeout timers of the prompts with
+// the given IDs, which must no longer be outstanding. The caller must hold
+// the lock for writing.
+func (m *InterfacesRequestsManager) stopPromptTimers(promptIDs ...prompting.IDType) {
+	for _, id := range promptIDs {
+		if timer := m.timers[id]; timer != nil {
+			timer.Stop()
+		}
+		delete(m.timers, id)
+	}
+}
+
 func (m *InterfacesRequestsManager) disconnect() error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	for id := range m.timers {
+		m.stopPromptTimers(id)
+	}
+
 	var errs []error
 	if m.listener != nil {
 		errs = append(errs, listenerClose(m.listener))
@@ -435,6 +529,7 @@ func (m *InterfacesRequestsManager) HandleReply(userID uint32, promptID promptin
 		// Error should not occur unless the listener has closed
 		return nil, retErr
 	}
+	m.stopPromptTimers(promptID)
 
 	if lifespan == prompting.LifespanSingle {
 		return []prompting.IDType{}, nil
@@ -458,6 +553,7 @@ func (m *InterfacesRequestsManager) applyRuleToOutstandingPrompts(rule *requestr
 		// error should not occur here unless the prompt DB was already closed.
 		logger.Noticef("error when handling new rule: %v", err)
 	}
+	m.stopPromptTimers(satisfiedPromptIDs...)
 	return satisfiedPromptIDs
 }
 
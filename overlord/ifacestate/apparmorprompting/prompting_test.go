@@ -37,6 +37,7 @@ import (
 	"github.com/snapcore/snapd/interfaces/prompting/requestprompts"
 	"github.com/snapcore/snapd/interfaces/prompting/requestrules"
 	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/ifacestate/apparmorprompting"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/sandbox/apparmor/notify"
@@ -1105,6 +1106,153 @@ func (s *apparmorpromptingSuite) TestRequestMerged(c *C) {
 	c.Assert(mgr.Stop(), IsNil)
 }
 
+func (s *apparmorpromptingSuite) TestPromptTimeoutDeny(c *C) {
+	s.testPromptTimeout(c, "", false)
+	s.testPromptTimeout(c, "deny", false)
+}
+
+func (s *apparmorpromptingSuite) TestPromptTimeoutAllowOnce(c *C) {
+	s.testPromptTimeout(c, "allow-once", true)
+}
+
+func (s *apparmorpromptingSuite) testPromptTimeout(c *C, outcome string, allowed bool) {
+	reqChan, replyChan, restore := apparmorprompting.MockListener()
+	defer restore()
+
+	var timeouts []time.Duration
+	var callbacks []func()
+	restore = apparmorprompting.MockTimeAfterFunc(func(d time.Duration, f func()) *time.Timer {
+		timeouts = append(timeouts, d)
+		callbacks = append(callbacks, f)
+		return nil
+	})
+	defer restore()
+
+	s.st.Lock()
+	tr := config.NewTransaction(s.st)
+	tr.Set("core", "experimental.apparmor-prompting-timeout.home", "30s")
+	if outcome != "" {
+		tr.Set("core", "experimental.apparmor-prompting-timeout-outcome.home", outcome)
+	}
+	tr.Commit()
+	mgr, err := apparmorprompting.New(s.st)
+	c.Assert(err, IsNil)
+	s.st.Unlock()
+
+	req, prompt := s.simulateRequest(c, reqChan, mgr, &listener.Request{}, false)
+	c.Assert(timeouts, DeepEquals, []time.Duration{30 * time.Second})
+
+	// Merged requests do not start another timer
+	s.simulateRequest(c, reqChan, mgr, &listener.Request{}, true)
+	c.Assert(callbacks, HasLen, 1)
+
+	// Trigger the timeout
+	whenTimedOut := time.Now()
+	callbacks[0]()
+
+	// Both requests are replied to with the default outcome
+	for i := 0; i < 2; i++ {
+		resp, err := waitForReply(replyChan)
+		c.Assert(err, IsNil)
+		if i == 0 {
+			c.Check(resp.Request, Equals, req)
+		}
+		var expected any = notify.FilePermission(0)
+		if allowed {
+			expected, err = prompting.AbstractPermissionsToAppArmorPermissions("home", []string{"read"})
+			c.Assert(err, IsNil)
+		}
+		c.Check(resp.AllowedPermission, Equals, expected)
+	}
+	s.checkRecordedPromptNotices(c, whenTimedOut, 1)
+
+	_, err = mgr.PromptWithID(s.defaultUser, prompt.ID)
+	c.Check(err, Equals, requestprompts.ErrNotFound)
+
+	// Allowing once does not create a rule
+	rules, err := mgr.Rules(s.defaultUser, "", "")
+	c.Check(err, IsNil)
+	c.Check(rules, HasLen, 0)
+
+	// A timeout for an already resolved prompt is a no-op
+	callbacks[0]()
+	_, err = waitForReply(replyChan)
+	c.Check(err, Equals, errNoReply)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	c.Assert(mgr.Stop(), IsNil)
+}
+
+func (s *apparmorpromptingSuite) TestPromptTimeoutTimerStopped(c *C) {
+	reqChan, replyChan, restore := apparmorprompting.MockListener()
+	defer restore()
+
+	var timers []*time.Timer
+	restore = apparmorprompting.MockTimeAfterFunc(func(d time.Duration, f func()) *time.Timer {
+		timer := time.AfterFunc(time.Hour, func() {
+			c.Errorf("unexpected prompt timeout")
+		})
+		timers = append(timers, timer)
+		return timer
+	})
+	defer restore()
+
+	s.st.Lock()
+	tr := config.NewTransaction(s.st)
+	tr.Set("core", "experimental.apparmor-prompting-timeout.home", "30s")
+	tr.Commit()
+	mgr, err := apparmorprompting.New(s.st)
+	c.Assert(err, IsNil)
+	s.st.Unlock()
+
+	_, prompt := s.simulateRequest(c, reqChan, mgr, &listener.Request{}, false)
+	c.Assert(timers, HasLen, 1)
+
+	// Replying to the prompt stops its timer
+	constraints := prompting.Constraints{
+		PathPattern: mustParsePathPattern(c, "/home/test/**"),
+		Permissions: []string{"read"},
+	}
+	satisfied, err := mgr.HandleReply(s.defaultUser, prompt.ID, &constraints, prompting.OutcomeDeny, prompting.LifespanSingle, "")
+	c.Check(err, IsNil)
+	c.Check(satisfied, HasLen, 0)
+	_, err = waitForReply(replyChan)
+	c.Assert(err, IsNil)
+	c.Check(timers[0].Stop(), Equals, false)
+
+	// Stopping the manager stops the timers of outstanding prompts
+	s.simulateRequest(c, reqChan, mgr, &listener.Request{}, false)
+	c.Assert(timers, HasLen, 2)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	c.Assert(mgr.Stop(), IsNil)
+	c.Check(timers[1].Stop(), Equals, false)
+}
+
+func (s *apparmorpromptingSuite) TestPromptTimeoutUnset(c *C) {
+	reqChan, _, restore := apparmorprompting.MockListener()
+	defer restore()
+
+	restore = apparmorprompting.MockTimeAfterFunc(func(d time.Duration, f func()) *time.Timer {
+		c.Errorf("unexpected timer for prompt")
+		return nil
+	})
+	defer restore()
+
+	s.st.Lock()
+	mgr, err := apparmorprompting.New(s.st)
+	c.Assert(err, IsNil)
+	s.st.Unlock()
+
+	s.simulateRequest(c, reqChan, mgr, &listener.Request{}, false)
+
+	s.st.Lock()
+	defer s.st.Unlock()
+	c.Assert(mgr.Stop(), IsNil)
+}
+
 func (s *apparmorpromptingSuite) TestRules(c *C) {
 	_, _, restore := apparmorprompting.MockListener()
 	defer restore()
@@ -0,0 +1,174 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/backends"
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+var specificationSnippets = backends.SpecificationSnippets
+
+// sourceSnippets holds the lines of the snippets contributed by a plug, slot
+// or connection, by security tag or backend specific key.
+type sourceSnippets struct {
+	source string
+	lines  map[string][]string
+}
+
+// SnapConfinement returns the files that the security backends generate for
+// the given snap out of its plugs, slots and connections, along with which
+// of their lines were contributed by which plug, slot or connection. Nothing
+// is written to disk. The state must be locked by the caller.
+func (m *InterfaceManager) SnapConfinement(instanceName string) (*client.SnapConfinement, error) {
+	st := m.state
+
+	info, err := snapstate.CurrentInfo(st, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		return nil, err
+	}
+	appSet, err := appSetForSnapRevision(st, info)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := m.buildConfinementOptions(st, info, snapst.Flags)
+	if err != nil {
+		return nil, err
+	}
+
+	confinement := &client.SnapConfinement{
+		Snap:        instanceName,
+		Confinement: string(info.Confinement),
+		Profiles:    []client.ConfinementProfile{},
+	}
+	for _, backend := range m.repo.Backends() {
+		renderer, ok := backend.(interfaces.SecurityBackendRenderer)
+		if !ok {
+			continue
+		}
+		spec, err := m.repo.SnapSpecification(backend.Name(), appSet, opts)
+		if err != nil {
+			return nil, err
+		}
+		files, err := renderer.RenderFiles(spec, appSet, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		sources, err := m.repo.SnapSpecificationSources(backend.Name(), appSet, opts)
+		if err != nil {
+			return nil, err
+		}
+		snippets := make([]sourceSnippets, 0, len(sources))
+		for _, source := range sources {
+			lines, err := specificationSnippets(source.Spec)
+			if err != nil {
+				return nil, err
+			}
+			snippets = append(snippets, sourceSnippets{source: source.Source, lines: lines})
+		}
+
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			content := string(files[path])
+			confinement.Profiles = append(confinement.Profiles, client.ConfinementProfile{
+				Backend:    string(backend.Name()),
+				Path:       path,
+				Content:    content,
+				Provenance: profileProvenance(filepath.Base(path), content, snippets),
+			})
+		}
+	}
+	return confinement, nil
+}
+
+// snippetsApplyTo returns whether snippets recorded under the given key, a
+// security tag or a backend specific key, may end up in the named file.
+func snippetsApplyTo(key, name string) bool {
+	if name == key || strings.HasPrefix(name, key+".") {
+		return true
+	}
+	// snippets of other security tags, or of snap-update-ns, end up in
+	// files of their own
+	return !strings.HasPrefix(key, "snap.") && key != "snap-update-ns"
+}
+
+// profileProvenance attributes each line of the content of the named file to
+// the first of the sources whose snippets contain that line and which was
+// not attributed as many lines like it already. Lines which are not part of
+// any snippet come from the templates of the backend or from the snap
+// itself and are not attributed.
+func profileProvenance(name, content string, sources []sourceSnippets) []client.ProfileProvenance {
+	// candidates holds, for each snippet line, the indexes of the sources
+	// which contributed it, once per time they did so
+	candidates := make(map[string][]int)
+	for i, source := range sources {
+		for key, lines := range source.lines {
+			if !snippetsApplyTo(key, name) {
+				continue
+			}
+			for _, line := range lines {
+				if line = strings.TrimSpace(line); line != "" {
+					candidates[line] = append(candidates[line], i)
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var provenance []client.ProfileProvenance
+	// provenanceIndex maps the index of a source to its provenance entry
+	provenanceIndex := make(map[int]int)
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		sourceIdxs := candidates[line]
+		if line == "" || len(sourceIdxs) == 0 {
+			continue
+		}
+		candidates[line] = sourceIdxs[1:]
+		idx, ok := provenanceIndex[sourceIdxs[0]]
+		if !ok {
+			idx = len(provenance)
+			provenanceIndex[sourceIdxs[0]] = idx
+			provenance = append(provenance, client.ProfileProvenance{Source: sources[sourceIdxs[0]].source})
+		}
+		provenance[idx].Lines = append(provenance[idx].Lines, n+1)
+	}
+	return provenance
+}
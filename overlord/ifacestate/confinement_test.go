@@ -0,0 +1,107 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (s *interfaceManagerSuite) TestSnapConfinement(c *C) {
+	s.mockPreviewInterface(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.Repository().Connect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	confinement, err := mgr.SnapConfinement("consumer")
+	c.Assert(err, IsNil)
+	c.Check(confinement, DeepEquals, &client.SnapConfinement{
+		Snap:        "consumer",
+		Confinement: "strict",
+		Profiles: []client.ConfinementProfile{{
+			Backend: "test-backend",
+			Path:    previewProfilePath("consumer"),
+			Content: "permanent plug plug\nconnected plug to slot\n",
+			Provenance: []client.ProfileProvenance{{
+				Source: "plug consumer:plug (test interface)",
+				Lines:  []int{1},
+			}, {
+				Source: "plug consumer:plug connected to slot producer:slot (test interface)",
+				Lines:  []int{2},
+			}},
+		}},
+	})
+
+	confinement, err = mgr.SnapConfinement("producer")
+	c.Assert(err, IsNil)
+	c.Check(confinement.Profiles, DeepEquals, []client.ConfinementProfile{{
+		Backend: "test-backend",
+		Path:    previewProfilePath("producer"),
+		Content: "connected slot to plug\n",
+		Provenance: []client.ProfileProvenance{{
+			Source: "slot producer:slot connected to plug consumer:plug (test interface)",
+			Lines:  []int{1},
+		}},
+	}})
+}
+
+func (s *interfaceManagerSuite) TestProfileProvenanceSecurityTags(c *C) {
+	sources := []ifacestate.SourceSnippets{
+		ifacestate.NewSourceSnippets("plug foo:a", map[string][]string{
+			"snap.foo.app1": {"/a r,", "  /shared r,"},
+		}),
+		ifacestate.NewSourceSnippets("plug foo:b", map[string][]string{
+			"snap.foo.app2": {"/b r,", "/shared r,"},
+		}),
+	}
+	c.Check(ifacestate.ProfileProvenance("snap.foo.app2", "#include <tunables/global>\n/shared r,\n/b r,\n/a r,\n", sources), DeepEquals, []client.ProfileProvenance{{
+		Source: "plug foo:b",
+		Lines:  []int{2, 3},
+	}})
+	c.Check(ifacestate.ProfileProvenance("snap.foo.app1.src", "/a r,\n/shared r,\n", sources), DeepEquals, []client.ProfileProvenance{{
+		Source: "plug foo:a",
+		Lines:  []int{1, 2},
+	}})
+	c.Check(ifacestate.ProfileProvenance("snap.foo.app3", "/a r,\n", sources), HasLen, 0)
+}
+
+func (s *interfaceManagerSuite) TestSnapConfinementNotInstalled(c *C) {
+	s.mockPreviewInterface(c)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.SnapConfinement("missing")
+	c.Assert(err, FitsTypeOf, &snap.NotInstalledError{})
+}
@@ -0,0 +1,201 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/overlord/snapstate"
+)
+
+// PreviewConnect renders the security artefacts of the snaps affected by
+// connecting the given plug to the given slot, as if they were connected,
+// and returns how they differ from the ones on disk. The connection must be
+// allowed by the connection policy. Nothing is written to disk and no backend
+// Setup is performed. The connection is previewed with the static attributes
+// of the plug and slot, as interface hooks are not run. The state must be
+// locked by the caller.
+func (m *InterfaceManager) PreviewConnect(connRef *interfaces.ConnRef) (*client.ConnectPreview, error) {
+	st := m.state
+
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, err
+	}
+	if conn, ok := conns[connRef.ID()]; ok && !conn.Undesired && !conn.HotplugGone {
+		return nil, &ErrAlreadyConnected{Connection: *connRef}
+	}
+
+	plugInfo := m.repo.Plug(connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	if plugInfo == nil {
+		return nil, fmt.Errorf("snap %q has no plug named %q", connRef.PlugRef.Snap, connRef.PlugRef.Name)
+	}
+	slotInfo := m.repo.Slot(connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	if slotInfo == nil {
+		return nil, fmt.Errorf("snap %q has no slot named %q", connRef.SlotRef.Snap, connRef.SlotRef.Name)
+	}
+	iface := m.repo.Interface(plugInfo.Interface)
+	if iface == nil {
+		return nil, fmt.Errorf("internal error: unknown interface %q", plugInfo.Interface)
+	}
+
+	plugAppSet, err := appSetForSnapRevision(st, plugInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+	slotAppSet, err := appSetForSnapRevision(st, slotInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+	plug := interfaces.NewConnectedPlug(plugInfo, plugAppSet, nil, nil)
+	slot := interfaces.NewConnectedSlot(slotInfo, slotAppSet, nil, nil)
+
+	// manual connections obey the policy "connection" rules
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	policyCheck, err := newConnectChecker(st, deviceCtx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := policyCheck.check(plug, slot); err != nil {
+		return nil, err
+	}
+
+	affected := []*interfaces.SnapAppSet{plugAppSet}
+	if slotAppSet.InstanceName() != plugAppSet.InstanceName() {
+		affected = append(affected, slotAppSet)
+	}
+
+	preview := &client.ConnectPreview{
+		Plug:      client.PlugRef{Snap: connRef.PlugRef.Snap, Name: connRef.PlugRef.Name},
+		Slot:      client.SlotRef{Snap: connRef.SlotRef.Snap, Name: connRef.SlotRef.Name},
+		Interface: plugInfo.Interface,
+		Changes:   []client.SecurityChange{},
+	}
+	for _, backend := range m.repo.Backends() {
+		renderer, ok := backend.(interfaces.SecurityBackendRenderer)
+		if !ok {
+			continue
+		}
+		for _, appSet := range affected {
+			instanceName := appSet.InstanceName()
+			var snapst snapstate.SnapState
+			if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+				return nil, err
+			}
+			opts, err := m.buildConfinementOptions(st, appSet.Info(), snapst.Flags)
+			if err != nil {
+				return nil, err
+			}
+
+			spec, err := m.repo.SnapSpecification(backend.Name(), appSet, opts)
+			if err != nil {
+				return nil, err
+			}
+			if instanceName == slotAppSet.InstanceName() {
+				if err := spec.AddConnectedSlot(iface, plug, slot); err != nil {
+					return nil, err
+				}
+			}
+			if instanceName == plugAppSet.InstanceName() {
+				if err := spec.AddConnectedPlug(iface, plug, slot); err != nil {
+					return nil, err
+				}
+			}
+			files, err := renderer.RenderFiles(spec, appSet, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			changes, err := renderedFileChanges(files)
+			if err != nil {
+				return nil, err
+			}
+			for i := range changes {
+				changes[i].Backend = string(backend.Name())
+				changes[i].Snap = instanceName
+			}
+			preview.Changes = append(preview.Changes, changes...)
+		}
+	}
+	return preview, nil
+}
+
+// renderedFileChanges returns, for each of the given rendered files, the
+// lines which are present only in the file on disk or only in the rendered
+// content.
+func renderedFileChanges(files map[string][]byte) ([]client.SecurityChange, error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var changes []client.SecurityChange
+	for _, path := range paths {
+		current, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		added, removed := diffLines(strings.Split(string(current), "\n"), strings.Split(string(files[path]), "\n"))
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		changes = append(changes, client.SecurityChange{Path: path, Added: added, Removed: removed})
+	}
+	return changes, nil
+}
+
+// diffLines returns the non-blank lines which appear more often in after
+// than in before (added), and the other way around (removed), in the order
+// in which they appear.
+func diffLines(before, after []string) (added, removed []string) {
+	count := make(map[string]int, len(before))
+	for _, line := range before {
+		count[line]++
+	}
+	for _, line := range after {
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			added = append(added, line)
+		}
+	}
+	for _, line := range before {
+		if count[line] > 0 {
+			count[line]--
+			if strings.TrimSpace(line) != "" {
+				removed = append(removed, line)
+			}
+		}
+	}
+	return added, removed
+}
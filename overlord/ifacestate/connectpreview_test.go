@@ -0,0 +1,185 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func (s *interfaceManagerSuite) mockPreviewInterface(c *C) {
+	s.MockModel(c, nil)
+	s.secBackend.BackendName = "test-backend"
+	s.secBackend.RenderFilesCallback = func(spec interfaces.Specification, appSet *interfaces.SnapAppSet, opts interfaces.ConfinementOptions) (map[string][]byte, error) {
+		snippets := spec.(*ifacetest.Specification).Snippets
+		if len(snippets) == 0 {
+			return nil, nil
+		}
+		path := previewProfilePath(appSet.InstanceName())
+		return map[string][]byte{path: []byte(strings.Join(snippets, "\n") + "\n")}, nil
+	}
+	s.mockIfaces(&ifacetest.TestInterface{
+		InterfaceName: "test",
+		TestPermanentPlugCallback: func(spec *ifacetest.Specification, plug *snap.PlugInfo) error {
+			spec.AddSnippet("permanent plug " + plug.Name)
+			return nil
+		},
+		TestConnectedPlugCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected plug to " + slot.Name())
+			return nil
+		},
+		TestConnectedSlotCallback: func(spec *ifacetest.Specification, plug *interfaces.ConnectedPlug, slot *interfaces.ConnectedSlot) error {
+			spec.AddSnippet("connected slot to " + plug.Name())
+			return nil
+		},
+	}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.AddCleanup(ifacestate.MockSpecificationSnippets(func(spec interfaces.Specification) (map[string][]string, error) {
+		return map[string][]string{"snippets": spec.(*ifacetest.Specification).Snippets}, nil
+	}))
+}
+
+func previewProfilePath(instanceName string) string {
+	return filepath.Join(dirs.GlobalRootDir, "/var/lib/snapd/test-backend", instanceName)
+}
+
+func (s *interfaceManagerSuite) TestPreviewConnect(c *C) {
+	s.mockPreviewInterface(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// the profile of the consumer is on disk, with a line that setting up
+	// its security again would drop
+	consumerProfile := previewProfilePath("consumer")
+	c.Assert(os.MkdirAll(filepath.Dir(consumerProfile), 0755), IsNil)
+	c.Assert(os.WriteFile(consumerProfile, []byte("permanent plug plug\nstale\n"), 0644), IsNil)
+
+	connRef := &interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	}
+	preview, err := mgr.PreviewConnect(connRef)
+	c.Assert(err, IsNil)
+	c.Check(preview, DeepEquals, &client.ConnectPreview{
+		Plug:      client.PlugRef{Snap: "consumer", Name: "plug"},
+		Slot:      client.SlotRef{Snap: "producer", Name: "slot"},
+		Interface: "test",
+		Changes: []client.SecurityChange{{
+			Backend: "test-backend",
+			Snap:    "consumer",
+			Path:    consumerProfile,
+			Added:   []string{"connected plug to slot"},
+			Removed: []string{"stale"},
+		}, {
+			Backend: "test-backend",
+			Snap:    "producer",
+			Path:    previewProfilePath("producer"),
+			Added:   []string{"connected slot to plug"},
+		}},
+	})
+
+	// nothing was set up
+	c.Check(s.secBackend.SetupCalls, HasLen, 0)
+	var conns map[string]interface{}
+	c.Check(s.state.Get("conns", &conns), testutil.ErrorIs, state.ErrNoState)
+}
+
+func (s *interfaceManagerSuite) TestPreviewConnectAlreadyConnected(c *C) {
+	s.mockPreviewInterface(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("conns", map[string]interface{}{
+		"consumer:plug producer:slot": map[string]interface{}{
+			"interface": "test",
+		},
+	})
+
+	_, err := mgr.PreviewConnect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+	c.Assert(err, FitsTypeOf, &ifacestate.ErrAlreadyConnected{})
+}
+
+func (s *interfaceManagerSuite) TestPreviewConnectUnknownPlug(c *C) {
+	s.mockPreviewInterface(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.PreviewConnect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "nope"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+	c.Assert(err, ErrorMatches, `snap "consumer" has no plug named "nope"`)
+}
+
+func (s *interfaceManagerSuite) TestPreviewConnectNotAllowed(c *C) {
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+`))
+	defer restore()
+	s.mockPreviewInterface(c)
+	s.MockSnapDecl(c, "consumer", "consumer-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.MockSnapDecl(c, "producer", "producer-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	mgr := s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	_, err := mgr.PreviewConnect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "consumer", Name: "plug"},
+		SlotRef: interfaces.SlotRef{Snap: "producer", Name: "slot"},
+	})
+	c.Assert(err, ErrorMatches, `connection not allowed by slot rule of interface "test"`)
+}
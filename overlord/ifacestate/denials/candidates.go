@@ -0,0 +1,170 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pathCandidates maps patterns of paths to the interfaces whose rules in
+// interfaces/builtin grant access to them. A "**" component matches the
+// rest of the path.
+var pathCandidates = []struct {
+	pattern    string
+	interfaces []string
+}{
+	{"/dev/video*", []string{"camera"}},
+	{"/dev/media*", []string{"camera"}},
+	{"/dev/snd/**", []string{"alsa", "audio-playback", "audio-record"}},
+	{"/run/user/*/pulse/**", []string{"audio-playback", "audio-record"}},
+	{"/run/user/*/pipewire-*", []string{"audio-playback", "audio-record"}},
+	{"/dev/dri/**", []string{"opengl"}},
+	{"/dev/nvidia*", []string{"opengl"}},
+	{"/dev/input/js*", []string{"joystick"}},
+	{"/dev/input/**", []string{"raw-input"}},
+	{"/dev/hidraw*", []string{"hidraw", "raw-usb"}},
+	{"/dev/bus/usb/**", []string{"raw-usb"}},
+	{"/dev/tty[A-Z]*", []string{"serial-port"}},
+	{"/dev/i2c-*", []string{"i2c"}},
+	{"/dev/spidev*", []string{"spi"}},
+	{"/sys/class/gpio/**", []string{"gpio"}},
+	{"/dev/rfkill", []string{"network-control"}},
+	{"/media/**", []string{"removable-media"}},
+	{"/mnt/**", []string{"removable-media"}},
+	{"/run/media/**", []string{"removable-media"}},
+	{"/home/*/**", []string{"home", "personal-files"}},
+	{"/var/log/**", []string{"log-observe"}},
+	{"/proc/*/mountinfo", []string{"mount-observe"}},
+	{"/proc/*/mounts", []string{"mount-observe"}},
+	{"/proc/*/net/**", []string{"network-observe"}},
+	{"/sys/class/net/**", []string{"network-observe", "network-control"}},
+	{"/sys/class/power_supply/**", []string{"hardware-observe", "upower-observe"}},
+	{"/sys/devices/**", []string{"hardware-observe"}},
+	{"/run/udev/data/**", []string{"hardware-observe"}},
+	{"/etc/**", []string{"system-files"}},
+}
+
+// capabilityCandidates maps capabilities to the interfaces which grant them.
+var capabilityCandidates = map[string][]string{
+	"net_admin":    {"network-control", "firewall-control"},
+	"net_raw":      {"network-control", "network-observe"},
+	"sys_admin":    {"mount-control", "system-observe"},
+	"sys_module":   {"kernel-module-control"},
+	"sys_ptrace":   {"system-observe", "process-control"},
+	"sys_nice":     {"process-control"},
+	"sys_resource": {"process-control"},
+	"sys_time":     {"time-control"},
+	"sys_boot":     {"shutdown"},
+	"sys_rawio":    {"hardware-observe", "physical-memory-control"},
+}
+
+// syscallCandidates maps syscalls to the interfaces which allow them.
+var syscallCandidates = map[string][]string{
+	"mount":              {"mount-control"},
+	"umount2":            {"mount-control"},
+	"init_module":        {"kernel-module-control"},
+	"finit_module":       {"kernel-module-control"},
+	"delete_module":      {"kernel-module-control"},
+	"ptrace":             {"system-observe", "process-control"},
+	"setpriority":        {"process-control"},
+	"sched_setscheduler": {"process-control"},
+	"settimeofday":       {"time-control"},
+	"clock_settime":      {"time-control"},
+	"sethostname":        {"hostname-control"},
+	"reboot":             {"shutdown"},
+}
+
+// dbusCandidates maps prefixes of D-Bus interface names to the interfaces
+// which allow talking to the service behind them.
+var dbusCandidates = []struct {
+	prefix     string
+	interfaces []string
+}{
+	{"org.freedesktop.NetworkManager", []string{"network-manager"}},
+	{"org.freedesktop.ModemManager1", []string{"modem-manager"}},
+	{"org.freedesktop.login1", []string{"login-session-observe", "login-session-control", "shutdown"}},
+	{"org.freedesktop.UPower", []string{"upower-observe"}},
+	{"org.freedesktop.Avahi", []string{"avahi-observe", "avahi-control"}},
+	{"org.freedesktop.hostname1", []string{"hostname-control"}},
+	{"org.freedesktop.timedate1", []string{"time-control", "timezone-control"}},
+	{"org.freedesktop.Notifications", []string{"desktop"}},
+	{"org.freedesktop.portal", []string{"desktop"}},
+	{"org.freedesktop.systemd1", []string{"system-observe"}},
+	{"org.bluez", []string{"bluez"}},
+}
+
+// Candidates returns the sorted names of the interfaces which may allow the
+// denied operation.
+func Candidates(d *Denial) []string {
+	var found []string
+	switch {
+	case d.Path != "":
+		for _, c := range pathCandidates {
+			if matchPath(c.pattern, d.Path) {
+				found = append(found, c.interfaces...)
+			}
+		}
+	case d.Capability != "":
+		found = capabilityCandidates[d.Capability]
+	case d.Syscall != "":
+		found = syscallCandidates[d.Syscall]
+	case d.DBusInterface != "":
+		for _, c := range dbusCandidates {
+			if strings.HasPrefix(d.DBusInterface, c.prefix) {
+				found = append(found, c.interfaces...)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(found))
+	candidates := make([]string, 0, len(found))
+	for _, iface := range found {
+		if !seen[iface] {
+			seen[iface] = true
+			candidates = append(candidates, iface)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// matchPath matches a path against a pattern, component by component, with
+// "**" matching the rest of the path.
+func matchPath(pattern, path string) bool {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+	for i, part := range patternParts {
+		if part == "**" {
+			return i < len(pathParts)
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if ok, _ := filepath.Match(part, pathParts[i]); !ok {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
@@ -0,0 +1,275 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package denials attributes the AppArmor and seccomp denials reported by
+// the kernel to snap applications and hooks, and relates them to the
+// interfaces which would allow the denied operation.
+package denials
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/snap/naming"
+)
+
+const (
+	KindAppArmor = "apparmor"
+	KindSeccomp  = "seccomp"
+)
+
+// Denial describes an operation of a snap application or hook which was
+// denied by AppArmor or seccomp.
+type Denial struct {
+	// Time is when the denial was last seen.
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// Label is the AppArmor label or seccomp subject of the process, that
+	// is, the security tag of the application or hook.
+	Label string `json:"label"`
+	Snap  string `json:"snap"`
+	App   string `json:"app,omitempty"`
+	Hook  string `json:"hook,omitempty"`
+
+	Operation     string `json:"operation,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Mask          string `json:"mask,omitempty"`
+	Capability    string `json:"capability,omitempty"`
+	Syscall       string `json:"syscall,omitempty"`
+	DBusInterface string `json:"dbus-interface,omitempty"`
+	DBusMember    string `json:"dbus-member,omitempty"`
+
+	// Candidates holds the interfaces which may allow the operation.
+	Candidates []string `json:"candidates,omitempty"`
+	// Suggestions holds the candidate interfaces for which the snap has a
+	// plug which is not connected.
+	Suggestions []string `json:"suggestions,omitempty"`
+	// Count is how many times the denial was seen.
+	Count int `json:"count"`
+}
+
+// Source returns the name of the application or hook which was denied, as
+// in "snap.app" or "snap (configure hook)".
+func (d *Denial) Source() string {
+	if d.Hook != "" {
+		return fmt.Sprintf("%s (%s hook)", d.Snap, d.Hook)
+	}
+	return d.Snap + "." + d.App
+}
+
+// Target returns a short description of what was denied.
+func (d *Denial) Target() string {
+	switch {
+	case d.Path != "":
+		return d.Path
+	case d.Capability != "":
+		return "capability " + d.Capability
+	case d.Syscall != "":
+		return "syscall " + d.Syscall
+	case d.DBusInterface != "":
+		return fmt.Sprintf("dbus %s.%s", d.DBusInterface, d.DBusMember)
+	case d.Operation != "":
+		return d.Operation
+	}
+	return "an operation"
+}
+
+// key identifies denials which are the same but for when they happened.
+func (d *Denial) key() string {
+	return strings.Join([]string{d.Kind, d.Label, d.Operation, d.Target(), d.Mask}, "\x00")
+}
+
+// Parse parses a kernel or audit log message and returns the denial it
+// reports, or nil if the message is not about a denial of a snap
+// application or hook.
+func Parse(message string) *Denial {
+	fields := parseFields(message)
+	// denials reported by dbus-daemon wrap the details in msg='...'
+	if inner, ok := fields["msg"]; ok && fields["apparmor"] == "" {
+		for k, v := range parseFields(inner) {
+			fields[k] = v
+		}
+	}
+
+	var d *Denial
+	switch {
+	case fields["apparmor"] == "DENIED":
+		d = parseAppArmor(fields)
+	case fields["type"] == "1326":
+		d = parseSeccomp(fields)
+	}
+	if d == nil {
+		return nil
+	}
+	tag, err := naming.ParseSecurityTag(d.Label)
+	if err != nil {
+		// not a snap application or hook
+		return nil
+	}
+	d.Snap = tag.InstanceName()
+	switch tag := tag.(type) {
+	case naming.AppSecurityTag:
+		d.App = tag.AppName()
+	case naming.HookSecurityTag:
+		d.Hook = tag.HookName()
+	}
+	d.Candidates = Candidates(d)
+	d.Count = 1
+	return d
+}
+
+func parseAppArmor(fields map[string]string) *Denial {
+	label := fields["profile"]
+	if label == "" {
+		label = fields["label"]
+	}
+	d := &Denial{
+		Kind:      KindAppArmor,
+		Label:     cleanLabel(label),
+		Operation: fields["operation"],
+	}
+	switch {
+	case fields["class"] == "cap" || fields["capname"] != "":
+		d.Capability = fields["capname"]
+	case fields["interface"] != "" || strings.HasPrefix(d.Operation, "dbus"):
+		d.DBusInterface = fields["interface"]
+		d.DBusMember = fields["member"]
+		d.Mask = fields["mask"]
+	default:
+		d.Path = fields["name"]
+		d.Mask = fields["denied_mask"]
+	}
+	return d
+}
+
+func parseSeccomp(fields map[string]string) *Denial {
+	d := &Denial{
+		Kind:  KindSeccomp,
+		Label: cleanLabel(fields["subj"]),
+	}
+	d.Syscall = syscallName(fields["arch"], fields["syscall"])
+	return d
+}
+
+// cleanLabel removes the mode AppArmor appends to labels, as in
+// "snap.foo.app (enforce)".
+func cleanLabel(label string) string {
+	label = strings.TrimPrefix(label, "=")
+	if idx := strings.IndexByte(label, ' '); idx >= 0 {
+		label = label[:idx]
+	}
+	return label
+}
+
+// parseFields splits an audit message into its key=value fields. Values may
+// be quoted with double or single quotes.
+func parseFields(message string) map[string]string {
+	fields := make(map[string]string)
+	i := 0
+	for i < len(message) {
+		for i < len(message) && message[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(message) && message[i] != '=' && message[i] != ' ' {
+			i++
+		}
+		if i >= len(message) || message[i] == ' ' {
+			// not a key=value field
+			continue
+		}
+		key := message[start:i]
+		i++
+		var value string
+		if i < len(message) && (message[i] == '"' || message[i] == '\'') {
+			quote := message[i]
+			i++
+			end := strings.IndexByte(message[i:], quote)
+			if end < 0 {
+				end = len(message) - i
+			}
+			value = message[i : i+end]
+			i += end + 1
+		} else {
+			start := i
+			for i < len(message) && message[i] != ' ' {
+				i++
+			}
+			value = message[start:i]
+		}
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+const (
+	auditArchX86_64  = "c000003e"
+	auditArchAarch64 = "c00000b7"
+)
+
+// syscallNames maps the syscall numbers of the architectures snaps most
+// commonly run on to the names of the syscalls which are relevant to
+// interfaces.
+var syscallNames = map[string]map[int]string{
+	auditArchX86_64: {
+		101: "ptrace",
+		141: "setpriority",
+		144: "sched_setscheduler",
+		164: "settimeofday",
+		165: "mount",
+		166: "umount2",
+		169: "reboot",
+		170: "sethostname",
+		175: "init_module",
+		176: "delete_module",
+		227: "clock_settime",
+		313: "finit_module",
+	},
+	auditArchAarch64: {
+		39:  "umount2",
+		40:  "mount",
+		105: "init_module",
+		106: "delete_module",
+		112: "clock_settime",
+		117: "ptrace",
+		119: "sched_setscheduler",
+		140: "setpriority",
+		142: "reboot",
+		161: "sethostname",
+		170: "settimeofday",
+		273: "finit_module",
+	},
+}
+
+// syscallName returns the name of the syscall with the given number on the
+// given audit architecture, or the number if it is not known.
+func syscallName(arch, number string) string {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return number
+	}
+	if name, ok := syscallNames[arch][n]; ok {
+		return name
+	}
+	return number
+}
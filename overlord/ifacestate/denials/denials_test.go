@@ -0,0 +1,122 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials_test
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type denialsSuite struct{}
+
+var _ = Suite(&denialsSuite{})
+
+func (s *denialsSuite) TestParseAppArmorFile(c *C) {
+	d := denials.Parse(`audit: type=1400 audit(1700000000.123:46): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0`)
+	c.Assert(d, NotNil)
+	c.Check(d, DeepEquals, &denials.Denial{
+		Kind:       denials.KindAppArmor,
+		Label:      "snap.foo.app",
+		Snap:       "foo",
+		App:        "app",
+		Operation:  "open",
+		Path:       "/dev/video0",
+		Mask:       "r",
+		Candidates: []string{"camera"},
+		Count:      1,
+	})
+	c.Check(d.Source(), Equals, "foo.app")
+	c.Check(d.Target(), Equals, "/dev/video0")
+}
+
+func (s *denialsSuite) TestParseAppArmorCapabilityHook(c *C) {
+	d := denials.Parse(`audit: type=1400 audit(1700000000.123:47): apparmor="DENIED" operation="capable" class="cap" profile="snap.foo.hook.configure" pid=1234 comm="ip" capability=12 capname="net_admin"`)
+	c.Assert(d, NotNil)
+	c.Check(d.Snap, Equals, "foo")
+	c.Check(d.Hook, Equals, "configure")
+	c.Check(d.Capability, Equals, "net_admin")
+	c.Check(d.Candidates, DeepEquals, []string{"firewall-control", "network-control"})
+	c.Check(d.Source(), Equals, "foo (configure hook)")
+	c.Check(d.Target(), Equals, "capability net_admin")
+}
+
+func (s *denialsSuite) TestParseAppArmorDBus(c *C) {
+	d := denials.Parse(`type=USER_AVC msg='apparmor="DENIED" operation="dbus_method_call" bus="system" path="/org/freedesktop/NetworkManager" interface="org.freedesktop.NetworkManager" member="GetDevices" mask="send" name="org.freedesktop.NetworkManager" pid=1234 label="snap.foo.app" peer_pid=1 peer_label="unconfined" exe="/usr/bin/dbus-daemon"'`)
+	c.Assert(d, NotNil)
+	c.Check(d.Label, Equals, "snap.foo.app")
+	c.Check(d.DBusInterface, Equals, "org.freedesktop.NetworkManager")
+	c.Check(d.DBusMember, Equals, "GetDevices")
+	c.Check(d.Mask, Equals, "send")
+	c.Check(d.Candidates, DeepEquals, []string{"network-manager"})
+	c.Check(d.Target(), Equals, "dbus org.freedesktop.NetworkManager.GetDevices")
+}
+
+func (s *denialsSuite) TestParseSeccomp(c *C) {
+	d := denials.Parse(`audit: type=1326 audit(1700000000.123:45): auid=1000 uid=1000 gid=1000 ses=2 subj=snap.foo.app (enforce) pid=1234 comm="foo" exe="/snap/foo/1/bin/foo" sig=0 arch=c000003e syscall=165 compat=0 ip=0x7f code=0x50000`)
+	c.Assert(d, NotNil)
+	c.Check(d.Kind, Equals, denials.KindSeccomp)
+	c.Check(d.Label, Equals, "snap.foo.app")
+	c.Check(d.Syscall, Equals, "mount")
+	c.Check(d.Candidates, DeepEquals, []string{"mount-control"})
+
+	// unknown architectures keep the syscall number
+	d = denials.Parse(`audit: type=1326 audit(1700000000.123:45): subj=snap.foo.app pid=1234 arch=40000028 syscall=21`)
+	c.Assert(d, NotNil)
+	c.Check(d.Syscall, Equals, "21")
+	c.Check(d.Candidates, IsNil)
+}
+
+func (s *denialsSuite) TestParseIgnored(c *C) {
+	for _, msg := range []string{
+		"",
+		"random kernel message",
+		`audit: type=1400 audit(1700000000.123:46): apparmor="ALLOWED" operation="open" profile="snap.foo.app" name="/dev/video0"`,
+		`audit: type=1400 audit(1700000000.123:46): apparmor="DENIED" operation="open" profile="/usr/bin/foo" name="/dev/video0"`,
+		`audit: type=1400 audit(1700000000.123:46): apparmor="DENIED" operation="open" profile="snap-update-ns.foo" name="/dev/video0"`,
+		`audit: type=1326 audit(1700000000.123:45): subj=unconfined arch=c000003e syscall=165`,
+	} {
+		c.Check(denials.Parse(msg), IsNil, Commentf("%q", msg))
+	}
+}
+
+func (s *denialsSuite) TestCandidates(c *C) {
+	for _, t := range []struct {
+		path       string
+		candidates []string
+	}{
+		{"/dev/video2", []string{"camera"}},
+		{"/dev/snd/pcmC0D0p", []string{"alsa", "audio-playback", "audio-record"}},
+		{"/dev/ttyUSB0", []string{"serial-port"}},
+		{"/dev/input/js0", []string{"joystick", "raw-input"}},
+		{"/dev/bus/usb/001/002", []string{"raw-usb"}},
+		{"/media/user/disk/file", []string{"removable-media"}},
+		{"/home/user/.config/foo", []string{"home", "personal-files"}},
+		{"/home", nil},
+		{"/snap/foo/1/bin/foo", nil},
+	} {
+		d := &denials.Denial{Path: t.path}
+		c.Check(denials.Candidates(d), DeepEquals, t.candidates, Commentf("%s", t.path))
+	}
+}
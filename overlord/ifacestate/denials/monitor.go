@@ -0,0 +1,172 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package denials
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/systemd"
+)
+
+var auditLogReader = systemd.AuditLogReader
+
+// maxDenials is how many distinct denials are kept, older ones are dropped
+// first.
+const maxDenials = 100
+
+// Monitor follows the kernel and audit log, collects the denials of snap
+// applications and hooks, and warns about those which connecting one of the
+// unconnected plugs of the snap may fix.
+type Monitor struct {
+	state  *state.State
+	repo   *interfaces.Repository
+	reader io.ReadCloser
+	tomb   tomb.Tomb
+
+	mu      sync.Mutex
+	denials []*Denial
+}
+
+// New starts a monitor of the denials reported from now on. The repository
+// is used to find the unconnected plugs of the denied snaps.
+func New(st *state.State, repo *interfaces.Repository) (*Monitor, error) {
+	reader, err := auditLogReader(0, true)
+	if err != nil {
+		return nil, err
+	}
+	m := &Monitor{
+		state:  st,
+		repo:   repo,
+		reader: reader,
+	}
+	m.tomb.Go(m.run)
+	return m, nil
+}
+
+// Stop stops following the log.
+func (m *Monitor) Stop() error {
+	m.tomb.Kill(nil)
+	m.reader.Close()
+	return m.tomb.Wait()
+}
+
+// Denials returns the collected denials, the most recently seen first.
+func (m *Monitor) Denials() []*Denial {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	denials := make([]*Denial, 0, len(m.denials))
+	for i := len(m.denials) - 1; i >= 0; i-- {
+		d := *m.denials[i]
+		denials = append(denials, &d)
+	}
+	return denials
+}
+
+func (m *Monitor) run() error {
+	decoder := json.NewDecoder(m.reader)
+	for {
+		var log systemd.Log
+		if err := decoder.Decode(&log); err != nil {
+			if err != io.EOF && m.tomb.Alive() {
+				logger.Noticef("cannot read denials from the journal: %v", err)
+			}
+			return nil
+		}
+		d := Parse(log.Message())
+		if d == nil {
+			continue
+		}
+		if t, err := log.Time(); err == nil {
+			d.Time = t
+		} else {
+			d.Time = time.Now()
+		}
+		m.record(d)
+	}
+}
+
+// record adds the denial to the collected ones and, the first time it is
+// seen, warns if the snap has unconnected plugs of candidate interfaces.
+func (m *Monitor) record(d *Denial) {
+	m.mu.Lock()
+	key := d.key()
+	for i, seen := range m.denials {
+		if seen.key() == key {
+			seen.Count++
+			seen.Time = d.Time
+			// move it to the end, as the most recently seen
+			m.denials = append(append(m.denials[:i], m.denials[i+1:]...), seen)
+			m.mu.Unlock()
+			return
+		}
+	}
+	m.mu.Unlock()
+
+	d.Suggestions = suggestions(m.repo, d)
+	if len(d.Suggestions) > 0 {
+		m.state.Lock()
+		m.state.Warnf("%s was denied %s; consider connecting %s", d.Source(), d.Target(), strings.Join(d.Suggestions, " or "))
+		m.state.Unlock()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.denials = append(m.denials, d)
+	if len(m.denials) > maxDenials {
+		m.denials = m.denials[len(m.denials)-maxDenials:]
+	}
+}
+
+// suggestions returns the candidate interfaces of the denial for which the
+// snap has a plug which is not connected.
+func suggestions(repo *interfaces.Repository, d *Denial) []string {
+	if repo == nil || len(d.Candidates) == 0 {
+		return nil
+	}
+	candidates := make(map[string]bool, len(d.Candidates))
+	for _, iface := range d.Candidates {
+		candidates[iface] = true
+	}
+	var suggested []string
+	seen := make(map[string]bool)
+	for _, plug := range repo.Plugs(d.Snap) {
+		if !candidates[plug.Interface] || seen[plug.Interface] {
+			continue
+		}
+		conns, err := repo.Connected(d.Snap, plug.Name)
+		if err != nil || len(conns) > 0 {
+			continue
+		}
+		seen[plug.Interface] = true
+		suggested = append(suggested, plug.Interface)
+	}
+	sort.Strings(suggested)
+	return suggested
+}
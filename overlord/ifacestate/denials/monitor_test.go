@@ -0,0 +1,167 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package denials_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type monitorSuite struct {
+	testutil.BaseTest
+
+	state *state.State
+	repo  *interfaces.Repository
+}
+
+var _ = Suite(&monitorSuite{})
+
+const fooYaml = `
+name: foo
+version: 1
+apps:
+  app:
+plugs:
+  camera:
+  network-control:
+`
+
+func (s *monitorSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.state = state.New(nil)
+	s.repo = interfaces.NewRepository()
+	for _, name := range []string{"camera", "network-control"} {
+		c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: name}), IsNil)
+	}
+	c.Assert(s.repo.AddAppSet(ifacetest.MockInfoAndAppSet(c, fooYaml, nil, nil)), IsNil)
+}
+
+func journalLines(c *C, messages ...string) string {
+	var buf strings.Builder
+	for i, msg := range messages {
+		line, err := json.Marshal(map[string]string{
+			"MESSAGE":              msg,
+			"__REALTIME_TIMESTAMP": fmt.Sprintf("%d", 1704164645000000+i),
+		})
+		c.Assert(err, IsNil)
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (s *monitorSuite) monitor(c *C, messages ...string) *denials.Monitor {
+	s.AddCleanup(denials.MockAuditLogReader(func(n int, follow bool) (io.ReadCloser, error) {
+		c.Check(n, Equals, 0)
+		c.Check(follow, Equals, true)
+		return io.NopCloser(strings.NewReader(journalLines(c, messages...))), nil
+	}))
+	m, err := denials.New(s.state, s.repo)
+	c.Assert(err, IsNil)
+	// the reader returns EOF once all messages are read, which stops the
+	// monitor
+	c.Assert(m.Stop(), IsNil)
+	return m
+}
+
+const videoDenial = `audit: type=1400 audit(1704164645.000:1): apparmor="DENIED" operation="open" class="file" profile="snap.foo.app" name="/dev/video0" pid=1234 comm="foo" requested_mask="r" denied_mask="r" fsuid=1000 ouid=0`
+
+func (s *monitorSuite) TestMonitorCollectsAndWarns(c *C) {
+	m := s.monitor(c,
+		videoDenial,
+		"unrelated kernel message",
+		videoDenial,
+		`audit: type=1326 audit(1704164645.000:2): subj=snap.foo.app pid=1234 arch=c000003e syscall=165`,
+	)
+
+	ds := m.Denials()
+	c.Assert(ds, HasLen, 2)
+	// the most recently seen first
+	c.Check(ds[0].Syscall, Equals, "mount")
+	c.Check(ds[0].Suggestions, IsNil)
+	c.Check(ds[1].Path, Equals, "/dev/video0")
+	c.Check(ds[1].Count, Equals, 2)
+	c.Check(ds[1].Time, Equals, time.Unix(1704164645, 2000).UTC())
+	c.Check(ds[1].Suggestions, DeepEquals, []string{"camera"})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	warnings := s.state.AllWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, "foo.app was denied /dev/video0; consider connecting camera")
+}
+
+func (s *monitorSuite) TestMonitorNoSuggestionWhenConnected(c *C) {
+	c.Assert(s.repo.AddInterface(&ifacetest.TestInterface{InterfaceName: "test"}), IsNil)
+	c.Assert(s.repo.AddAppSet(ifacetest.MockInfoAndAppSet(c, `
+name: core
+version: 1
+type: os
+slots:
+  camera:
+`, nil, nil)), IsNil)
+	_, err := s.repo.Connect(&interfaces.ConnRef{
+		PlugRef: interfaces.PlugRef{Snap: "foo", Name: "camera"},
+		SlotRef: interfaces.SlotRef{Snap: "core", Name: "camera"},
+	}, nil, nil, nil, nil, nil)
+	c.Assert(err, IsNil)
+
+	m := s.monitor(c, videoDenial)
+
+	ds := m.Denials()
+	c.Assert(ds, HasLen, 1)
+	c.Check(ds[0].Candidates, DeepEquals, []string{"camera"})
+	c.Check(ds[0].Suggestions, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(s.state.AllWarnings(), HasLen, 0)
+}
+
+func (s *monitorSuite) TestMonitorKeepsRecentDenials(c *C) {
+	var messages []string
+	for i := 0; i < 150; i++ {
+		messages = append(messages, fmt.Sprintf(`apparmor="DENIED" operation="open" profile="snap.foo.app" name="/tmp/file-%d" denied_mask="r"`, i))
+	}
+	m := s.monitor(c, messages...)
+
+	ds := m.Denials()
+	c.Assert(ds, HasLen, 100)
+	c.Check(ds[0].Path, Equals, "/tmp/file-149")
+	c.Check(ds[99].Path, Equals, "/tmp/file-50")
+}
+
+func (s *monitorSuite) TestNewError(c *C) {
+	s.AddCleanup(denials.MockAuditLogReader(func(n int, follow bool) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("boom")
+	}))
+	_, err := denials.New(s.state, s.repo)
+	c.Assert(err, ErrorMatches, "boom")
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord/ifacestate/apparmorprompting"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
 	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/ifacestate/udevmonitor"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -62,6 +63,7 @@ var (
 	AddHotplugSeqWaitTask        = addHotplugSeqWaitTask
 	AddHotplugSlot               = addHotplugSlot
 	HasActiveConnection          = hasActiveConnection
+	RefreshConnectionChanges     = refreshConnectionChanges
 
 	BatchConnectTasks                = batchConnectTasks
 	FirstTaskAfterBootWhenPreseeding = firstTaskAfterBootWhenPreseeding
@@ -136,6 +138,14 @@ func MockInterfacesRequestsManagerStop(new func(m *apparmorprompting.InterfacesR
 	return testutil.Mock(&interfacesRequestsManagerStop, new)
 }
 
+func MockCreateDenialMonitor(new func(s *state.State, repo *interfaces.Repository) (*denials.Monitor, error)) (restore func()) {
+	return testutil.Mock(&createDenialMonitor, new)
+}
+
+func MockDenialMonitorStop(new func(m *denials.Monitor) error) (restore func()) {
+	return testutil.Mock(&denialMonitorStop, new)
+}
+
 func MockAssessAppArmorPrompting(new func(m *InterfaceManager) bool) (restore func()) {
 	return testutil.Mock(&assessAppArmorPrompting, new)
 }
@@ -210,6 +220,14 @@ func MockSnapstateFinishRestart(f func(task *state.Task, snapsup *snapstate.Snap
 	}
 }
 
+func MockServicestateEnsureSnapServiceDependencies(f func(st *state.State, info *snap.Info) error) (restore func()) {
+	old := servicestateEnsureSnapServiceDependencies
+	servicestateEnsureSnapServiceDependencies = f
+	return func() {
+		servicestateEnsureSnapServiceDependencies = old
+	}
+}
+
 func (m *InterfaceManager) TransitionConnectionsCoreMigration(st *state.State, oldName, newName string) error {
 	return m.transitionConnectionsCoreMigration(st, oldName, newName)
 }
@@ -217,3 +235,15 @@ func (m *InterfaceManager) TransitionConnectionsCoreMigration(st *state.State, o
 func (m *InterfaceManager) SetupSecurityByBackend(task *state.Task, appSets []*interfaces.SnapAppSet, opts []interfaces.ConfinementOptions, tm timings.Measurer) error {
 	return m.setupSecurityByBackend(task, appSets, opts, tm)
 }
+
+func MockSpecificationSnippets(f func(spec interfaces.Specification) (map[string][]string, error)) (restore func()) {
+	return testutil.Mock(&specificationSnippets, f)
+}
+
+type SourceSnippets = sourceSnippets
+
+func NewSourceSnippets(source string, lines map[string][]string) SourceSnippets {
+	return sourceSnippets{source: source, lines: lines}
+}
+
+var ProfileProvenance = profileProvenance
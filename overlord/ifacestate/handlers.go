@@ -42,10 +42,41 @@ import (
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/quota"
+	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timings"
 )
 
-var snapstateFinishRestart = snapstate.FinishRestart
+var (
+	snapstateFinishRestart = snapstate.FinishRestart
+
+	servicestateEnsureSnapServiceDependencies = servicestate.EnsureSnapServiceDependencies
+)
+
+// ensureServiceDependencies regenerates the service units of the snap owning
+// the given plug if any of its services are ordered after the services of
+// the providers connected to that plug.
+func ensureServiceDependencies(st *state.State, plugRef interfaces.PlugRef) error {
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, plugRef.Snap, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil
+		}
+		return err
+	}
+	if !snapst.Active {
+		return nil
+	}
+	info, err := snapst.CurrentInfo()
+	if err != nil {
+		return err
+	}
+	for _, app := range info.Apps {
+		if app.IsService() && strutil.ListContains(app.AfterPlugs, plugRef.Name) {
+			return servicestateEnsureSnapServiceDependencies(st, info)
+		}
+	}
+	return nil
+}
 
 // journalQuotaLayout returns the necessary journal quota mount layouts
 // to mimick what systemd does for services with log namespaces.
@@ -699,6 +730,10 @@ func (m *InterfaceManager) doConnect(task *state.Task, _ *tomb.Tomb) (err error)
 	}
 	setConns(st, conns)
 
+	if err := ensureServiceDependencies(st, plugRef); err != nil {
+		return err
+	}
+
 	// the dynamic attributes might have been updated by the interface's BeforeConnectPlug/Slot code,
 	// so we need to update the task for connect-plug- and connect-slot- hooks to see new values.
 	setDynamicHookAttributes(task, conn.Plug.DynamicAttrs(), conn.Slot.DynamicAttrs())
@@ -818,6 +853,10 @@ func (m *InterfaceManager) doDisconnect(task *state.Task, _ *tomb.Tomb) error {
 	}
 	setConns(st, conns)
 
+	if err := ensureServiceDependencies(st, plugRef); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -924,6 +963,10 @@ func (m *InterfaceManager) undoDisconnect(task *state.Task, _ *tomb.Tomb) error
 	conns[connRef.ID()] = &oldconn
 	setConns(st, conns)
 
+	if err := ensureServiceDependencies(st, plugRef); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -957,6 +1000,10 @@ func (m *InterfaceManager) undoConnect(task *state.Task, _ *tomb.Tomb) error {
 	}
 	setConns(st, conns)
 
+	if err := ensureServiceDependencies(st, plugRef); err != nil {
+		return err
+	}
+
 	if err := m.repo.Disconnect(connRef.PlugRef.Snap, connRef.PlugRef.Name, connRef.SlotRef.Snap, connRef.SlotRef.Name); err != nil {
 		return err
 	}
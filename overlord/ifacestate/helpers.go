@@ -39,6 +39,7 @@ import (
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
 	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
@@ -50,6 +51,7 @@ import (
 
 func init() {
 	snapstate.HasActiveConnection = hasActiveConnection
+	snapstate.RefreshConnectionChanges = refreshConnectionChanges
 }
 
 var (
@@ -174,6 +176,14 @@ func (m *InterfaceManager) assesAppArmorPrompting() bool {
 	return false
 }
 
+// denialMonitoringEnabled returns true if the denial-monitoring feature is
+// enabled.
+func (m *InterfaceManager) denialMonitoringEnabled() bool {
+	tr := config.NewTransaction(m.state)
+	enabled, err := features.Flag(tr, features.DenialMonitoring)
+	return err == nil && enabled
+}
+
 // snapdAppArmorServiceIsDisabledImpl returns true if the snapd.apparmor
 // service unit exists but is disabled
 func snapdAppArmorServiceIsDisabledImpl() bool {
@@ -889,18 +899,7 @@ func (c *autoConnectChecker) addAutoConnections(task *state.Task, newconns map[s
 		// simply go with those from the new core snap.
 		candSlots, arities = filterUbuntuCoreSlots(candSlots, arities)
 
-		applicable := candSlots
-		// candidate arity check
-		for _, arity := range arities {
-			if !arity.SlotsPerPlugAny() {
-				// ATM not any (*) => none or exactly one
-				if len(candSlots) != 1 {
-					applicable = nil
-				}
-				break
-			}
-		}
-
+		applicable := applicableAutoConnectSlots(candSlots, arities)
 		if filter != nil {
 			applicable = filter(applicable)
 		}
@@ -924,6 +923,21 @@ func (c *autoConnectChecker) addAutoConnections(task *state.Task, newconns map[s
 	return nil
 }
 
+// applicableAutoConnectSlots returns the candidate slots a plug would be
+// auto-connected to, given the arity of each candidate connection.
+func applicableAutoConnectSlots(candSlots []*snap.SlotInfo, arities []interfaces.SideArity) []*snap.SlotInfo {
+	for _, arity := range arities {
+		if !arity.SlotsPerPlugAny() {
+			// ATM not any (*) => none or exactly one
+			if len(candSlots) != 1 {
+				return nil
+			}
+			break
+		}
+	}
+	return candSlots
+}
+
 type connectChecker struct {
 	st        *state.State
 	deviceCtx snapstate.DeviceContext
@@ -1487,6 +1501,125 @@ func hasActiveConnection(st *state.State, iface string) (bool, error) {
 	return false, nil
 }
 
+// refreshConnectionChanges returns the connections that refreshing to the
+// given revision of a snap would auto-connect for the plugs and slots the
+// revision adds, and the ones it would disconnect along with the plugs and
+// slots it removes. Only the auto-connection candidates are considered,
+// connections from the gadget or done manually are not.
+func refreshConnectionChanges(st *state.State, info *snap.Info) (connect, disconnect []snapstate.RefreshReportConnection, err error) {
+	repo := ifacerepo.Get(st)
+	instanceName := info.InstanceName()
+
+	// work on a copy as implicit slots get added to it
+	newInfo := *info
+	newInfo.Slots = make(map[string]*snap.SlotInfo, len(info.Slots))
+	for name, slot := range info.Slots {
+		newInfo.Slots[name] = slot
+	}
+	if err := addImplicitSlots(st, &newInfo); err != nil {
+		return nil, nil, err
+	}
+
+	conns, err := getConns(st)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, cstate := range conns {
+		if cstate.Undesired || cstate.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		plugGone := connRef.PlugRef.Snap == instanceName && newInfo.Plugs[connRef.PlugRef.Name] == nil
+		slotGone := connRef.SlotRef.Snap == instanceName && newInfo.Slots[connRef.SlotRef.Name] == nil
+		if plugGone || slotGone {
+			disconnect = append(disconnect, snapstate.RefreshReportConnection{
+				Plug:      connRef.PlugRef.String(),
+				Slot:      connRef.SlotRef.String(),
+				Interface: cstate.Interface,
+			})
+		}
+	}
+
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	autochecker, err := newAutoConnectChecker(st, repo, deviceCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	appSet, err := interfaces.NewSnapAppSet(&newInfo, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[string]bool)
+	addConnection := func(plug *snap.PlugInfo, slot *snap.SlotInfo) {
+		connRef := interfaces.NewConnRef(plug, slot)
+		// existing connections, or undesired ones, are left alone
+		if _, ok := conns[connRef.ID()]; ok || seen[connRef.ID()] {
+			return
+		}
+		seen[connRef.ID()] = true
+		connect = append(connect, snapstate.RefreshReportConnection{
+			Plug:      connRef.PlugRef.String(),
+			Slot:      connRef.SlotRef.String(),
+			Interface: plug.Interface,
+		})
+	}
+
+	for name, plug := range newInfo.Plugs {
+		if repo.Plug(instanceName, name) != nil {
+			continue
+		}
+		candSlots, arities := repo.AutoConnectCandidateSlotsForPlug(plug, appSet, autochecker.check)
+		candSlots, arities = filterUbuntuCoreSlots(candSlots, arities)
+		for _, slot := range applicableAutoConnectSlots(candSlots, arities) {
+			addConnection(plug, slot)
+		}
+	}
+	for name, slot := range newInfo.Slots {
+		if repo.Slot(instanceName, name) != nil {
+			continue
+		}
+		candPlugs, slotArities := repo.AutoConnectCandidatePlugsForSlot(slot, appSet, autochecker.check)
+		for i, plug := range candPlugs {
+			// the new slot competes with the other candidates of
+			// the plug, apart from the slots of the current revision
+			candSlots, arities := repo.AutoConnectCandidateSlots(plug.Snap.InstanceName(), plug.Name, autochecker.check)
+			var otherSlots []*snap.SlotInfo
+			var otherArities []interfaces.SideArity
+			for j, candSlot := range candSlots {
+				if candSlot.Snap.InstanceName() != instanceName {
+					otherSlots = append(otherSlots, candSlot)
+					otherArities = append(otherArities, arities[j])
+				}
+			}
+			otherSlots = append(otherSlots, slot)
+			otherArities = append(otherArities, slotArities[i])
+			candSlots, arities = filterUbuntuCoreSlots(otherSlots, otherArities)
+			for _, candSlot := range applicableAutoConnectSlots(candSlots, arities) {
+				if candSlot == slot {
+					addConnection(plug, slot)
+				}
+			}
+		}
+	}
+
+	for _, l := range [][]snapstate.RefreshReportConnection{connect, disconnect} {
+		sort.Slice(l, func(i, j int) bool {
+			if l[i].Plug != l[j].Plug {
+				return l[i].Plug < l[j].Plug
+			}
+			return l[i].Slot < l[j].Slot
+		})
+	}
+	return connect, disconnect, nil
+}
+
 func appSetForTask(t *state.Task, info *snap.Info) (*interfaces.SnapAppSet, error) {
 	compsups, err := snapstate.ComponentSetupsForTask(t)
 	if err != nil {
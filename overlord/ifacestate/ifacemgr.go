@@ -29,6 +29,7 @@ import (
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate/apparmorprompting"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
 	"github.com/snapcore/snapd/overlord/ifacestate/udevmonitor"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -69,6 +70,10 @@ type InterfaceManager struct {
 	interfacesRequestsManagerMu sync.Mutex
 	interfacesRequestsManager   *apparmorprompting.InterfacesRequestsManager
 
+	// Denial monitoring
+	denialMonitorMu sync.Mutex
+	denialMonitor   *denials.Monitor
+
 	preseed bool
 }
 
@@ -226,6 +231,14 @@ Run "systemctl enable --now snapd.apparmor" to correct this.`)
 
 	ifacerepo.Replace(s, m.repo)
 
+	// Like prompting, toggling denial monitoring takes effect once snapd
+	// is restarted.
+	if !m.preseed && m.denialMonitoringEnabled() {
+		if err := m.initDenialMonitor(); err != nil {
+			logger.Noticef("cannot start denial monitor: %v", err)
+		}
+	}
+
 	// wire late profile removal support into snapstate
 	snapstate.SecurityProfilesRemoveLate = m.discardSecurityProfilesLate
 
@@ -269,11 +282,12 @@ func (m *InterfaceManager) Ensure() error {
 	return nil
 }
 
-// Stop implements StateStopper. It stops the udev monitor and prompting,
-// if running.
+// Stop implements StateStopper. It stops the udev monitor, prompting and
+// the denial monitor, if running.
 func (m *InterfaceManager) Stop() {
 	m.stopUDevMon()
 	m.stopInterfacesRequestsManager()
+	m.stopDenialMonitor()
 }
 
 func (m *InterfaceManager) stopUDevMon() {
@@ -311,6 +325,35 @@ func (m *InterfaceManager) stopInterfacesRequestsManager() {
 	}
 }
 
+var denialMonitorStop = func(denialMonitor *denials.Monitor) error {
+	return denialMonitor.Stop()
+}
+
+func (m *InterfaceManager) stopDenialMonitor() {
+	m.denialMonitorMu.Lock()
+	defer m.denialMonitorMu.Unlock()
+	denialMonitor := m.denialMonitor
+	m.denialMonitor = nil
+	if denialMonitor == nil {
+		return
+	}
+	if err := denialMonitorStop(denialMonitor); err != nil {
+		logger.Noticef("Cannot stop denial monitor: %s", err)
+	}
+}
+
+// Denials returns the AppArmor and seccomp denials of snap applications and
+// hooks seen since snapd started, the most recently seen first. The second
+// return value is false if denial monitoring is not running.
+func (m *InterfaceManager) Denials() ([]*denials.Denial, bool) {
+	m.denialMonitorMu.Lock()
+	defer m.denialMonitorMu.Unlock()
+	if m.denialMonitor == nil {
+		return nil, false
+	}
+	return m.denialMonitor.Denials(), true
+}
+
 // Repository returns the interface repository used internally by the manager.
 //
 // This method has two use-cases:
@@ -521,6 +564,7 @@ var (
 	udevInitRetryTimeout            = time.Minute * 5
 	createUDevMonitor               = udevmonitor.New
 	createInterfacesRequestsManager = apparmorprompting.New
+	createDenialMonitor             = denials.New
 )
 
 func (m *InterfaceManager) initUDevMonitor() error {
@@ -551,6 +595,17 @@ func (m *InterfaceManager) initInterfacesRequestsManager() error {
 	return nil
 }
 
+func (m *InterfaceManager) initDenialMonitor() error {
+	m.denialMonitorMu.Lock()
+	defer m.denialMonitorMu.Unlock()
+	denialMonitor, err := createDenialMonitor(m.state, m.repo)
+	if err != nil {
+		return err
+	}
+	m.denialMonitor = denialMonitor
+	return nil
+}
+
 var securityBackendsOverride []interfaces.SecurityBackend
 
 // allSecurityBackends returns a set of the available security backends or the mocked ones, ready to be initialized.
@@ -43,10 +43,12 @@ import (
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
 	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
 	"github.com/snapcore/snapd/overlord/ifacestate/apparmorprompting"
+	"github.com/snapcore/snapd/overlord/ifacestate/denials"
 	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
 	"github.com/snapcore/snapd/overlord/ifacestate/udevmonitor"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -425,6 +427,79 @@ func (s *interfaceManagerSuite) TestSmokeAppArmorPromptingDisabled(c *C) {
 	c.Check(stopCount, Equals, 0)
 }
 
+func (s *interfaceManagerSuite) TestSmokeDenialMonitoringEnabled(c *C) {
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.denial-monitoring", true)
+	tr.Commit()
+	s.state.Unlock()
+
+	createCount := 0
+	fakeMonitor := &denials.Monitor{}
+	restore := ifacestate.MockCreateDenialMonitor(func(st *state.State, repo *interfaces.Repository) (*denials.Monitor, error) {
+		createCount++
+		c.Check(st, Equals, s.state)
+		c.Check(repo, NotNil)
+		return fakeMonitor, nil
+	})
+	defer restore()
+	stopCount := 0
+	restore = ifacestate.MockDenialMonitorStop(func(m *denials.Monitor) error {
+		c.Check(m, Equals, fakeMonitor)
+		stopCount++
+		return nil
+	})
+	defer restore()
+
+	mgr := s.manager(c)
+	c.Check(createCount, Equals, 1)
+	ds, running := mgr.Denials()
+	c.Check(running, Equals, true)
+	c.Check(ds, HasLen, 0)
+	mgr.Stop()
+	c.Check(stopCount, Equals, 1)
+	_, running = mgr.Denials()
+	c.Check(running, Equals, false)
+}
+
+func (s *interfaceManagerSuite) TestSmokeDenialMonitoringDisabled(c *C) {
+	createCount := 0
+	restore := ifacestate.MockCreateDenialMonitor(func(st *state.State, repo *interfaces.Repository) (*denials.Monitor, error) {
+		createCount++
+		return nil, fmt.Errorf("should not have been called")
+	})
+	defer restore()
+
+	mgr := s.manager(c)
+	c.Check(createCount, Equals, 0)
+	_, running := mgr.Denials()
+	c.Check(running, Equals, false)
+}
+
+func (s *interfaceManagerSuite) TestInitDenialMonitorError(c *C) {
+	s.state.Lock()
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "experimental.denial-monitoring", true)
+	tr.Commit()
+	s.state.Unlock()
+
+	restore := ifacestate.MockCreateDenialMonitor(func(st *state.State, repo *interfaces.Repository) (*denials.Monitor, error) {
+		return nil, fmt.Errorf("no journal")
+	})
+	defer restore()
+
+	mgr, err := ifacestate.Manager(s.state, nil, s.o.TaskRunner(), nil, nil)
+	c.Assert(err, IsNil)
+
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	c.Check(mgr.StartUp(), IsNil)
+	c.Check(logbuf.String(), testutil.Contains, "cannot start denial monitor: no journal")
+	_, running := mgr.Denials()
+	c.Check(running, Equals, false)
+}
+
 func (s *interfaceManagerSuite) TestRepoAvailable(c *C) {
 	_ = s.manager(c)
 	s.state.Lock()
@@ -5301,6 +5376,54 @@ func (s *interfaceManagerSuite) TestConnectSetsUpSecurity(c *C) {
 	c.Check(s.secBackend.SetupCalls[1].Options, DeepEquals, interfaces.ConfinementOptions{})
 }
 
+func (s *interfaceManagerSuite) TestConnectDisconnectEnsuresServiceDependencies(c *C) {
+	s.MockModel(c, nil)
+
+	var ensured []string
+	restore := ifacestate.MockServicestateEnsureSnapServiceDependencies(func(st *state.State, info *snap.Info) error {
+		ensured = append(ensured, info.InstanceName())
+		return nil
+	})
+	defer restore()
+
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+	s.mockSnap(c, consumerYaml+`
+apps:
+ svc:
+  daemon: simple
+  after-plugs: [plug]
+`)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	s.state.Lock()
+	ts, err := ifacestate.Connect(s.state, "consumer", "plug", "producer", "slot")
+	c.Assert(err, IsNil)
+	change := s.state.NewChange("connect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	c.Assert(change.Err(), IsNil)
+	c.Check(ensured, DeepEquals, []string{"consumer"})
+
+	conn := s.getConnection(c, "consumer", "plug", "producer", "slot")
+	ts, err = ifacestate.Disconnect(s.state, conn)
+	c.Assert(err, IsNil)
+	change = s.state.NewChange("disconnect", "")
+	change.AddAll(ts)
+	s.state.Unlock()
+
+	s.settle(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Assert(change.Err(), IsNil)
+	c.Check(ensured, DeepEquals, []string{"consumer", "consumer"})
+}
+
 func (s *interfaceManagerSuite) TestConnectWithComponentsSetsUpSecurity(c *C) {
 	s.MockModel(c, nil)
 
@@ -6536,6 +6659,53 @@ func (s *interfaceManagerSuite) TestAutoConnectDuringCoreTransition(c *C) {
 		SlotRef: interfaces.SlotRef{Snap: "core", Name: "network"}}})
 }
 
+func (s *interfaceManagerSuite) TestRefreshConnectionChanges(c *C) {
+	s.MockModel(c, nil)
+
+	s.mockSnap(c, coreSnapYaml)
+	s.mockSnap(c, sampleSnapYaml)
+
+	s.state.Lock()
+	s.state.Set("conns", map[string]interface{}{
+		"snap:network core:network": map[string]interface{}{"interface": "network", "auto": true},
+	})
+	s.state.Unlock()
+
+	s.manager(c)
+
+	// the new revision drops the network plug and adds a network-bind one
+	newInfo := snaptest.MockInfo(c, `
+name: snap
+version: 2
+apps:
+ app:
+   command: foo
+plugs:
+ network-bind:
+  interface: network-bind
+ unrelated:
+  interface: unrelated
+`, &snap.SideInfo{Revision: snap.R(2)})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	connect, disconnect, err := ifacestate.RefreshConnectionChanges(s.state, newInfo)
+	c.Assert(err, IsNil)
+	c.Check(connect, DeepEquals, []snapstate.RefreshReportConnection{
+		{Plug: "snap:network-bind", Slot: "core:network-bind", Interface: "network-bind"},
+	})
+	c.Check(disconnect, DeepEquals, []snapstate.RefreshReportConnection{
+		{Plug: "snap:network", Slot: "core:network", Interface: "network"},
+	})
+
+	// nothing was changed
+	var conns map[string]interface{}
+	c.Assert(s.state.Get("conns", &conns), IsNil)
+	c.Check(conns, HasLen, 1)
+	c.Check(s.state.Changes(), HasLen, 0)
+}
+
 func (s *interfaceManagerSuite) TestAutoConnectSnapdAndCore(c *C) {
 	s.MockModel(c, nil)
 
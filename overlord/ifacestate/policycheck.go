@@ -0,0 +1,327 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/policy"
+	"github.com/snapcore/snapd/overlord/assertstate"
+	"github.com/snapcore/snapd/overlord/ifacestate/ifacerepo"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// PolicyCheckResult holds the outcome of checking a connection against the
+// interface policy, along with an account of the steps taken to reach it.
+type PolicyCheckResult struct {
+	Allowed bool     `json:"allowed"`
+	Error   string   `json:"error,omitempty"`
+	Steps   []string `json:"steps"`
+}
+
+// ConnectionPolicyCheck holds the outcome of checking whether a plug can be
+// connected, manually and automatically, to a slot.
+type ConnectionPolicyCheck struct {
+	Plug           interfaces.PlugRef `json:"plug"`
+	Slot           interfaces.SlotRef `json:"slot"`
+	Interface      string             `json:"interface"`
+	Connection     PolicyCheckResult  `json:"connection"`
+	AutoConnection PolicyCheckResult  `json:"auto-connection"`
+}
+
+// ConnectionPolicyCheckOptions allows to replace what is known to the system
+// with local data when checking a connection against the interface policy.
+type ConnectionPolicyCheckOptions struct {
+	// PlugSnapInfo and SlotSnapInfo, if set, are used instead of the
+	// information of the installed snaps.
+	PlugSnapInfo *snap.Info
+	SlotSnapInfo *snap.Info
+	// Assertions are snap-declaration, base-declaration, model or store
+	// assertions which take precedence over the ones in the system
+	// assertion database. They are used as given, without checking their
+	// signatures.
+	Assertions []asserts.Assertion
+}
+
+// CheckConnectionPolicy checks whether the plug of the given snap can be
+// connected, both manually and automatically, to the slot of the other snap
+// according to the base declaration, the snap declarations and the model.
+// If the plug or slot name are not given, the only plug or slot of the given
+// interface is used. No change is made to the system.
+func CheckConnectionPolicy(st *state.State, plugRef interfaces.PlugRef, slotRef interfaces.SlotRef, ifaceName string, opts *ConnectionPolicyCheckOptions) (*ConnectionPolicyCheck, error) {
+	if opts == nil {
+		opts = &ConnectionPolicyCheckOptions{}
+	}
+	repo := ifacerepo.Get(st)
+
+	slotRef.Snap = RemapSnapFromRequest(slotRef.Snap)
+
+	var plugs []*snap.PlugInfo
+	if opts.PlugSnapInfo != nil {
+		plugs = sortedPlugs(opts.PlugSnapInfo)
+	} else {
+		if _, err := snapstate.CurrentInfo(st, plugRef.Snap); err != nil {
+			return nil, err
+		}
+		plugs = repo.Plugs(plugRef.Snap)
+	}
+	plugInfo, err := pickPlug(plugRef, ifaceName, plugs)
+	if err != nil {
+		return nil, err
+	}
+	ifaceName = plugInfo.Interface
+
+	var slots []*snap.SlotInfo
+	if opts.SlotSnapInfo != nil {
+		slots = sortedSlots(opts.SlotSnapInfo)
+	} else {
+		if _, err := snapstate.CurrentInfo(st, slotRef.Snap); err != nil {
+			return nil, err
+		}
+		slots = repo.Slots(slotRef.Snap)
+	}
+	slotInfo, err := pickSlot(slotRef, ifaceName, slots)
+	if err != nil {
+		return nil, err
+	}
+
+	iface := repo.Interface(ifaceName)
+	if iface == nil {
+		return nil, fmt.Errorf("unknown interface %q", ifaceName)
+	}
+
+	cand, err := policyCandidate(st, plugInfo, slotInfo, opts.Assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &ConnectionPolicyCheck{
+		Plug:      interfaces.PlugRef{Snap: plugInfo.Snap.InstanceName(), Name: plugInfo.Name},
+		Slot:      interfaces.SlotRef{Snap: slotInfo.Snap.InstanceName(), Name: slotInfo.Name},
+		Interface: ifaceName,
+	}
+
+	// manual connections are checked like connectChecker does
+	if cand.PlugSnapDeclaration == nil || cand.SlotSnapDeclaration == nil {
+		check.Connection = PolicyCheckResult{
+			Allowed: true,
+			Steps: []string{
+				"plug or slot snap has no snap-declaration (installed with --dangerous), connection policy checks are skipped",
+				"connection allowed",
+			},
+		}
+	} else {
+		steps, err := cand.Explain(false)
+		check.Connection = policyCheckResult(steps, err)
+	}
+
+	// auto-connections are checked like autoConnectChecker does
+	if !iface.AutoConnect(plugInfo, slotInfo) {
+		check.AutoConnection = PolicyCheckResult{
+			Allowed: false,
+			Error:   fmt.Sprintf("auto-connection not supported by interface %q", ifaceName),
+			Steps: []string{
+				fmt.Sprintf("interface %q does not support auto-connection of this plug and slot", ifaceName),
+				"auto-connection denied",
+			},
+		}
+	} else {
+		steps, err := cand.Explain(true)
+		check.AutoConnection = policyCheckResult(steps, err)
+	}
+
+	return check, nil
+}
+
+func policyCheckResult(steps []string, err error) PolicyCheckResult {
+	res := PolicyCheckResult{Allowed: err == nil, Steps: steps}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func sortedPlugs(info *snap.Info) []*snap.PlugInfo {
+	plugs := make([]*snap.PlugInfo, 0, len(info.Plugs))
+	for _, plug := range info.Plugs {
+		plugs = append(plugs, plug)
+	}
+	sort.Slice(plugs, func(i, j int) bool { return plugs[i].Name < plugs[j].Name })
+	return plugs
+}
+
+func sortedSlots(info *snap.Info) []*snap.SlotInfo {
+	slots := make([]*snap.SlotInfo, 0, len(info.Slots))
+	for _, slot := range info.Slots {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Name < slots[j].Name })
+	return slots
+}
+
+func pickPlug(ref interfaces.PlugRef, ifaceName string, plugs []*snap.PlugInfo) (*snap.PlugInfo, error) {
+	if ref.Name == "" && ifaceName == "" {
+		return nil, fmt.Errorf("cannot pick a plug of snap %q without a plug name or interface", ref.Snap)
+	}
+	var candidates []*snap.PlugInfo
+	var names []string
+	for _, plug := range plugs {
+		if ref.Name != "" && plug.Name != ref.Name {
+			continue
+		}
+		if ifaceName != "" && plug.Interface != ifaceName {
+			continue
+		}
+		candidates = append(candidates, plug)
+		names = append(names, plug.Name)
+	}
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case len(candidates) > 1:
+		return nil, fmt.Errorf("snap %q has multiple plugs for interface %q (%s), please specify one", ref.Snap, ifaceName, strings.Join(names, ", "))
+	case ref.Name != "":
+		if ifaceName != "" {
+			return nil, fmt.Errorf("snap %q has no plug named %q for interface %q", ref.Snap, ref.Name, ifaceName)
+		}
+		return nil, fmt.Errorf("snap %q has no plug named %q", ref.Snap, ref.Name)
+	default:
+		return nil, fmt.Errorf("snap %q has no plug for interface %q", ref.Snap, ifaceName)
+	}
+}
+
+func pickSlot(ref interfaces.SlotRef, ifaceName string, slots []*snap.SlotInfo) (*snap.SlotInfo, error) {
+	var candidates []*snap.SlotInfo
+	var names []string
+	for _, slot := range slots {
+		if ref.Name != "" && slot.Name != ref.Name {
+			continue
+		}
+		if slot.Interface != ifaceName {
+			continue
+		}
+		candidates = append(candidates, slot)
+		names = append(names, slot.Name)
+	}
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case len(candidates) > 1:
+		return nil, fmt.Errorf("snap %q has multiple slots for interface %q (%s), please specify one", ref.Snap, ifaceName, strings.Join(names, ", "))
+	case ref.Name != "":
+		return nil, fmt.Errorf("snap %q has no slot named %q for interface %q", ref.Snap, ref.Name, ifaceName)
+	default:
+		return nil, fmt.Errorf("snap %q has no slot for interface %q", ref.Snap, ifaceName)
+	}
+}
+
+// policyCandidate builds the policy.ConnectCandidate for the given plug and
+// slot, preferring the given assertions over the ones in the system
+// assertion database.
+func policyCandidate(st *state.State, plugInfo *snap.PlugInfo, slotInfo *snap.SlotInfo, local []asserts.Assertion) (*policy.ConnectCandidate, error) {
+	var baseDecl *asserts.BaseDeclaration
+	var modelAs *asserts.Model
+	var storeAs *asserts.Store
+	localDecls := make(map[string]*asserts.SnapDeclaration)
+	for _, a := range local {
+		switch a := a.(type) {
+		case *asserts.BaseDeclaration:
+			baseDecl = a
+		case *asserts.Model:
+			modelAs = a
+		case *asserts.Store:
+			storeAs = a
+		case *asserts.SnapDeclaration:
+			localDecls[a.SnapName()] = a
+		default:
+			return nil, fmt.Errorf("cannot use %q assertion to check the interface policy", a.Type().Name)
+		}
+	}
+
+	if baseDecl == nil {
+		var err error
+		baseDecl, err = assertstate.BaseDeclaration(st)
+		if err != nil {
+			return nil, fmt.Errorf("internal error: cannot find base declaration: %v", err)
+		}
+	}
+	if modelAs == nil {
+		deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		modelAs = deviceCtx.Model()
+	}
+	if storeAs == nil && modelAs.Store() != "" {
+		var err error
+		storeAs, err = assertstate.Store(st, modelAs.Store())
+		if err != nil && !errors.Is(err, &asserts.NotFoundError{}) {
+			return nil, err
+		}
+	}
+
+	snapDecl := func(info *snap.Info) (*asserts.SnapDeclaration, error) {
+		if decl := localDecls[info.SnapName()]; decl != nil {
+			return decl, nil
+		}
+		if info.SnapID == "" {
+			return nil, nil
+		}
+		decl, err := assertstate.SnapDeclaration(st, info.SnapID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find snap declaration for %q: %v", info.InstanceName(), err)
+		}
+		return decl, nil
+	}
+	plugDecl, err := snapDecl(plugInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+	slotDecl, err := snapDecl(slotInfo.Snap)
+	if err != nil {
+		return nil, err
+	}
+
+	plugAppSet, err := interfaces.NewSnapAppSet(plugInfo.Snap, nil)
+	if err != nil {
+		return nil, err
+	}
+	slotAppSet, err := interfaces.NewSnapAppSet(slotInfo.Snap, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy.ConnectCandidate{
+		Plug:                interfaces.NewConnectedPlug(plugInfo, plugAppSet, nil, nil),
+		PlugSnapDeclaration: plugDecl,
+		Slot:                interfaces.NewConnectedSlot(slotInfo, slotAppSet, nil, nil),
+		SlotSnapDeclaration: slotDecl,
+		BaseDeclaration:     baseDecl,
+		Model:               modelAs,
+		Store:               storeAs,
+	}, nil
+}
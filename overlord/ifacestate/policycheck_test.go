@@ -0,0 +1,172 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ifacestate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/interfaces"
+	"github.com/snapcore/snapd/interfaces/ifacetest"
+	"github.com/snapcore/snapd/overlord/ifacestate"
+	"github.com/snapcore/snapd/snap"
+)
+
+func (s *interfaceManagerSuite) mockPolicyCheckBaseDecl(c *C) {
+	restore := assertstest.MockBuiltinBaseDeclaration([]byte(`
+type: base-declaration
+authority-id: canonical
+series: 16
+slots:
+  test:
+    allow-connection:
+      plug-publisher-id:
+        - $SLOT_PUBLISHER_ID
+    allow-auto-connection: false
+`))
+	s.AddCleanup(restore)
+	s.mockIfaces(&ifacetest.TestInterface{InterfaceName: "test"}, &ifacetest.TestInterface{InterfaceName: "test2"})
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectionPolicyDenied(c *C) {
+	s.MockModel(c, nil)
+	s.mockPolicyCheckBaseDecl(c)
+	s.MockSnapDecl(c, "consumer", "consumer-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.MockSnapDecl(c, "producer", "producer-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	check, err := ifacestate.CheckConnectionPolicy(s.state, interfaces.PlugRef{Snap: "consumer"}, interfaces.SlotRef{Snap: "producer"}, "test", nil)
+	c.Assert(err, IsNil)
+	c.Check(check.Plug, Equals, interfaces.PlugRef{Snap: "consumer", Name: "plug"})
+	c.Check(check.Slot, Equals, interfaces.SlotRef{Snap: "producer", Name: "slot"})
+	c.Check(check.Interface, Equals, "test")
+
+	c.Check(check.Connection.Allowed, Equals, false)
+	c.Check(check.Connection.Error, Equals, `connection not allowed by slot rule of interface "test"`)
+	c.Check(check.Connection.Steps, DeepEquals, []string{
+		"checking connection of plug consumer:plug to slot producer:slot",
+		`snap-declaration of "consumer" has no plug rule for interface "test"`,
+		`snap-declaration of "producer" has no slot rule for interface "test"`,
+		`base-declaration has no plug rule for interface "test"`,
+		`using slot rule of interface "test" from the base-declaration`,
+		"deny-connection constraints of the slot rule do not match",
+		"allow-connection constraints of the slot rule do not match: publisher id does not match",
+		"connection denied",
+	})
+
+	c.Check(check.AutoConnection.Allowed, Equals, false)
+	c.Check(check.AutoConnection.Error, Equals, `auto-connection not allowed by slot rule of interface "test"`)
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectionPolicyLocalSnapDeclaration(c *C) {
+	s.MockModel(c, nil)
+	s.mockPolicyCheckBaseDecl(c)
+	s.MockSnapDecl(c, "consumer", "consumer-publisher", nil)
+	s.mockSnap(c, consumerYaml)
+	s.MockSnapDecl(c, "producer", "producer-publisher", nil)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	// a draft snap-declaration granting the connection, used as is
+	draftDecl := assertstest.FakeAssertion(map[string]interface{}{
+		"type":         "snap-declaration",
+		"authority-id": "canonical",
+		"series":       "16",
+		"snap-name":    "consumer",
+		"snap-id":      "consumeridididididididididididid",
+		"publisher-id": "consumer-publisher",
+		"plugs": map[string]interface{}{
+			"test": map[string]interface{}{
+				"allow-connection":      "true",
+				"allow-auto-connection": "true",
+			},
+		},
+	})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	check, err := ifacestate.CheckConnectionPolicy(s.state, interfaces.PlugRef{Snap: "consumer", Name: "plug"}, interfaces.SlotRef{Snap: "producer", Name: "slot"}, "", &ifacestate.ConnectionPolicyCheckOptions{
+		Assertions: []asserts.Assertion{draftDecl},
+	})
+	c.Assert(err, IsNil)
+	c.Check(check.Interface, Equals, "test")
+	c.Check(check.Connection.Allowed, Equals, true)
+	c.Check(check.Connection.Steps[1], Equals, `using plug rule of interface "test" from the snap-declaration of "consumer"`)
+	c.Check(check.AutoConnection.Allowed, Equals, true)
+	c.Check(check.AutoConnection.Error, Equals, "")
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectionPolicyLocalSnapYamlNoDecl(c *C) {
+	s.MockModel(c, nil)
+	s.mockPolicyCheckBaseDecl(c)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	info, err := snap.InfoFromSnapYaml([]byte(consumerYaml))
+	c.Assert(err, IsNil)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	check, err := ifacestate.CheckConnectionPolicy(s.state, interfaces.PlugRef{Snap: "consumer"}, interfaces.SlotRef{Snap: "producer"}, "test", &ifacestate.ConnectionPolicyCheckOptions{
+		PlugSnapInfo: info,
+	})
+	c.Assert(err, IsNil)
+	// manual connections of snaps without declarations are not checked
+	c.Check(check.Connection.Allowed, Equals, true)
+	c.Check(check.Connection.Steps, HasLen, 2)
+	// but auto-connections are
+	c.Check(check.AutoConnection.Allowed, Equals, false)
+	c.Check(check.AutoConnection.Steps[1], Equals, `plug snap "consumer" has no snap-declaration`)
+}
+
+func (s *interfaceManagerSuite) TestCheckConnectionPolicyErrors(c *C) {
+	s.MockModel(c, nil)
+	s.mockPolicyCheckBaseDecl(c)
+	s.mockSnap(c, consumerYaml)
+	s.mockSnap(c, producerYaml)
+	_ = s.manager(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	for _, tc := range []struct {
+		plug  interfaces.PlugRef
+		slot  interfaces.SlotRef
+		iface string
+		err   string
+	}{
+		{interfaces.PlugRef{Snap: "missing"}, interfaces.SlotRef{Snap: "producer"}, "test", `snap "missing" is not installed`},
+		{interfaces.PlugRef{Snap: "consumer"}, interfaces.SlotRef{Snap: "producer"}, "", `cannot pick a plug of snap "consumer" without a plug name or interface`},
+		{interfaces.PlugRef{Snap: "consumer", Name: "nope"}, interfaces.SlotRef{Snap: "producer"}, "", `snap "consumer" has no plug named "nope"`},
+		{interfaces.PlugRef{Snap: "consumer"}, interfaces.SlotRef{Snap: "producer"}, "test2", `snap "producer" has no slot for interface "test2"`},
+		{interfaces.PlugRef{Snap: "consumer"}, interfaces.SlotRef{Snap: "producer", Name: "other"}, "test", `snap "producer" has no slot named "other" for interface "test"`},
+	} {
+		_, err := ifacestate.CheckConnectionPolicy(s.state, tc.plug, tc.slot, tc.iface, nil)
+		c.Check(err, ErrorMatches, tc.err)
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/snapcore/snapd/overlord/assertstate"
 	"github.com/snapcore/snapd/overlord/cmdstate"
 	"github.com/snapcore/snapd/overlord/configstate"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/configstate/proxyconf"
 	"github.com/snapcore/snapd/overlord/devicestate"
 	"github.com/snapcore/snapd/overlord/healthstate"
@@ -57,6 +58,7 @@ import (
 	"github.com/snapcore/snapd/overlord/storecontext"
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/dirstore"
 	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/timings"
 )
@@ -113,9 +115,14 @@ type Overlord struct {
 	shotMgr    *snapshotstate.SnapshotManager
 	// proxyConf mediates the http proxy config
 	proxyConf func(req *http.Request) (*url.URL, error)
+	// peerServer shares the download cache with the peers of the network
+	peerServer *store.PeerServer
 }
 
-var storeNew = store.New
+var (
+	storeNew           = store.New
+	storeNewPeerServer = store.NewPeerServer
+)
 
 // New creates a new Overlord with all its state managers.
 // It can be provided with an optional restart.Handler.
@@ -183,7 +190,8 @@ func New(restartHandler restart.Handler) (*Overlord, error) {
 	if err := configstateInit(s, hookMgr); err != nil {
 		return nil, err
 	}
-	healthstate.Init(hookMgr)
+	healthstate.Init(hookMgr, o.runner)
+	o.addManager(healthstate.Manager(s))
 
 	// the shared task runner should be added last!
 	o.stateEng.AddManager(o.runner)
@@ -335,13 +343,103 @@ func initRestart(s *state.State, curBootID string, restartHandler restart.Handle
 }
 
 func (o *Overlord) newStoreWithContext(storeCtx store.DeviceAndAuthContext) snapstate.StoreService {
+	if sto := o.newDirStore(); sto != nil {
+		return sto
+	}
 	cfg := store.DefaultConfig()
 	cfg.Proxy = o.proxyConf
+	cfg.Peers = o.storePeers()
+	cfg.CacheMaxSize, cfg.CacheMinFreePercent = o.storeCacheLimits()
 	sto := storeNew(cfg, storeCtx)
 	sto.SetCacheDownloads(defaultCachedDownloads)
 	return sto
 }
 
+// newDirStore returns a store serving from the directory set with
+// store.backend, or nil if it is unset. It must be called with the state
+// locked.
+func (o *Overlord) newDirStore() snapstate.StoreService {
+	tr := config.NewTransaction(o.State())
+	var backend string
+	if err := tr.Get("core", "store.backend", &backend); err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get store backend: %v", err)
+		return nil
+	}
+	dir, err := dirstore.ParseBackend(backend)
+	if err != nil {
+		logger.Noticef("cannot use store backend: %v", err)
+		return nil
+	}
+	if dir == "" {
+		return nil
+	}
+	logger.Noticef("using snaps and assertions from %q as store", dir)
+	return dirstore.New(dir)
+}
+
+// storePeers returns the peers set with store.peers. It must be called with
+// the state locked.
+func (o *Overlord) storePeers() []string {
+	tr := config.NewTransaction(o.State())
+	var peers string
+	if err := tr.Get("core", "store.peers", &peers); err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get store peers: %v", err)
+		return nil
+	}
+	addrs, err := store.ParsePeers(peers)
+	if err != nil {
+		logger.Noticef("cannot use store peers: %v", err)
+		return nil
+	}
+	return addrs
+}
+
+// storeCacheLimits returns the limits of the download cache set with
+// store.cache.max-size and store.cache.min-free. It must be called with the
+// state locked.
+func (o *Overlord) storeCacheLimits() (maxSize int64, minFreePercent int) {
+	tr := config.NewTransaction(o.State())
+	var maxSizeOpt, minFreeOpt string
+	if err := tr.Get("core", "store.cache.max-size", &maxSizeOpt); err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get download cache maximum size: %v", err)
+	}
+	if err := tr.Get("core", "store.cache.min-free", &minFreeOpt); err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get download cache minimum free space: %v", err)
+	}
+	maxSize, minFreePercent, err := store.ParseCacheLimits(maxSizeOpt, minFreeOpt)
+	if err != nil {
+		logger.Noticef("cannot use download cache limits: %v", err)
+		return 0, 0
+	}
+	return maxSize, minFreePercent
+}
+
+// startPeerServer starts sharing the download cache on the address set with
+// store.peer-listen, if any. Anyone who can reach the address can download
+// the cached snaps, so sharing is off unless the option is set.
+func (o *Overlord) startPeerServer() {
+	st := o.State()
+	st.Lock()
+	tr := config.NewTransaction(st)
+	var addr string
+	err := tr.Get("core", "store.peer-listen", &addr)
+	st.Unlock()
+	if err != nil && !config.IsNoOption(err) {
+		logger.Noticef("cannot get store peer listen address: %v", err)
+		return
+	}
+	if addr == "" {
+		return
+	}
+	ps, err := storeNewPeerServer(addr, dirs.SnapDownloadCacheDir)
+	if err != nil {
+		logger.Noticef("cannot share downloads with peers: %v", err)
+		return
+	}
+	logger.Noticef("sharing downloads, without authentication, with peers on %s", ps.Addr())
+	o.peerServer = ps
+}
+
 // newStore can make new stores for use during remodeling.
 // The device backend will tie them to the remodeling device state.
 func (o *Overlord) newStore(devBE storecontext.DeviceBackend) snapstate.StoreService {
@@ -379,6 +477,10 @@ func (o *Overlord) StartUp() error {
 		}
 	}
 
+	if !snapdenv.Preseeding() {
+		o.startPeerServer()
+	}
+
 	return o.stateEng.StartUp()
 }
 
@@ -509,6 +611,10 @@ func (o *Overlord) Stop() error {
 		err = o.loopTomb.Wait()
 	}
 	o.stateEng.Stop()
+	if o.peerServer != nil {
+		o.peerServer.Stop()
+		o.peerServer = nil
+	}
 	if o.stateFLock != nil {
 		// This will also unlock the file
 		o.stateFLock.Close()
@@ -24,10 +24,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -39,6 +41,7 @@ import (
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/devicestate/devicestatetest"
 	"github.com/snapcore/snapd/overlord/hookstate"
 	"github.com/snapcore/snapd/overlord/ifacestate"
@@ -51,6 +54,7 @@ import (
 	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/snapdtool"
 	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/dirstore"
 	"github.com/snapcore/snapd/testutil"
 	"github.com/snapcore/snapd/timings"
 )
@@ -157,11 +161,116 @@ func (ovs *overlordSuite) TestNewStore(c *C) {
 
 	devBE := o.DeviceManager().StoreContextBackend()
 
+	st := o.State()
+	st.Lock()
+	defer st.Unlock()
 	sto := o.NewStore(devBE)
 	c.Check(sto, FitsTypeOf, &store.Store{})
 	c.Check(sto.(*store.Store).CacheDownloads(), Equals, 5)
 }
 
+func (ovs *overlordSuite) TestNewStoreDirBackend(c *C) {
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	dir := c.MkDir()
+	st := o.State()
+	st.Lock()
+	defer st.Unlock()
+	tr := config.NewTransaction(st)
+	c.Assert(tr.Set("core", "store.backend", "dir:"+dir), IsNil)
+	tr.Commit()
+
+	sto := o.NewStore(o.DeviceManager().StoreContextBackend())
+	c.Assert(sto, FitsTypeOf, &dirstore.Store{})
+	c.Check(sto.(*dirstore.Store).Dir(), Equals, dir)
+}
+
+func (ovs *overlordSuite) TestNewWithDirStoreBackend(c *C) {
+	dir := c.MkDir()
+	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"patch-sublevel":%d,"config":{"core":{"store":{"backend":"dir:%s"}}}},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level, patch.Sublevel, dir))
+	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
+	c.Assert(err, IsNil)
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+
+	st := o.State()
+	st.Lock()
+	defer st.Unlock()
+	sto := snapstate.Store(st, nil)
+	c.Assert(sto, FitsTypeOf, &dirstore.Store{})
+	c.Check(sto.(*dirstore.Store).Dir(), Equals, dir)
+}
+
+func (ovs *overlordSuite) TestNewWithStorePeers(c *C) {
+	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"patch-sublevel":%d,"config":{"core":{"store":{"peers":"10.0.0.2:8999, peer.lan:8999"}}}},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level, patch.Sublevel))
+	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
+	c.Assert(err, IsNil)
+
+	var peers []string
+	restore := overlord.MockStoreNew(func(cfg *store.Config, dac store.DeviceAndAuthContext) *store.Store {
+		peers = cfg.Peers
+		return store.New(cfg, dac)
+	})
+	defer restore()
+
+	_, err = overlord.New(nil)
+	c.Assert(err, IsNil)
+	c.Check(peers, DeepEquals, []string{"10.0.0.2:8999", "peer.lan:8999"})
+}
+
+func (ovs *overlordSuite) TestNewWithStoreCacheLimits(c *C) {
+	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"patch-sublevel":%d,"config":{"core":{"store":{"cache":{"max-size":"2GB","min-free":"10%%"}}}}},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level, patch.Sublevel))
+	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
+	c.Assert(err, IsNil)
+
+	var cfg *store.Config
+	restore := overlord.MockStoreNew(func(storeCfg *store.Config, dac store.DeviceAndAuthContext) *store.Store {
+		cfg = storeCfg
+		return store.New(storeCfg, dac)
+	})
+	defer restore()
+
+	_, err = overlord.New(nil)
+	c.Assert(err, IsNil)
+	c.Check(cfg.CacheMaxSize, Equals, int64(2*1000*1000*1000))
+	c.Check(cfg.CacheMinFreePercent, Equals, 10)
+}
+
+func (ovs *overlordSuite) TestStartUpPeerServer(c *C) {
+	fakeState := []byte(fmt.Sprintf(`{"data":{"patch-level":%d,"patch-sublevel":%d,"config":{"core":{"store":{"peer-listen":"127.0.0.1:0"}}}},"changes":null,"tasks":null,"last-change-id":0,"last-task-id":0,"last-lane-id":0}`, patch.Level, patch.Sublevel))
+	err := os.WriteFile(dirs.SnapStateFile, fakeState, 0600)
+	c.Assert(err, IsNil)
+
+	var peerServer *store.PeerServer
+	restore := overlord.MockStoreNewPeerServer(func(addr, cacheDir string) (*store.PeerServer, error) {
+		c.Check(addr, Equals, "127.0.0.1:0")
+		c.Check(cacheDir, Equals, dirs.SnapDownloadCacheDir)
+		ps, err := store.NewPeerServer(addr, cacheDir)
+		peerServer = ps
+		return ps, err
+	})
+	defer restore()
+
+	o, err := overlord.New(nil)
+	c.Assert(err, IsNil)
+	markSeeded(o)
+	snapstate.CanAutoRefresh = nil
+
+	c.Assert(o.StartUp(), IsNil)
+	c.Assert(peerServer, NotNil)
+	addr := peerServer.Addr().String()
+	resp, err := http.Get("http://" + addr + "/v1/snap-blobs/" + strings.Repeat("0", 96))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Check(resp.StatusCode, Equals, 404)
+
+	c.Assert(o.Stop(), IsNil)
+	_, err = http.Get("http://" + addr + "/")
+	c.Check(err, NotNil)
+}
+
 func (ovs *overlordSuite) TestNewWithGoodState(c *C) {
 	// ensure we don't write state load timing in the state on really
 	// slow architectures (e.g. risc-v)
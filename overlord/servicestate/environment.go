@@ -0,0 +1,159 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+)
+
+var validEnvironmentName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedEnvironmentPrefixes are the prefixes of the variables set up by
+// snapd and snap run, or that change how the dynamic linker loads the
+// service.
+var reservedEnvironmentPrefixes = []string{"SNAP_", "SNAPD_", "LD_"}
+
+// reservedEnvironmentNames are the variables set up by snap run, and the
+// ones glibc considers unsafe as they change what files get loaded.
+var reservedEnvironmentNames = []string{
+	"PATH", "HOME", "TMPDIR", "XDG_RUNTIME_DIR",
+	"GCONV_PATH", "GETCONF_DIR", "HOSTALIASES", "LOCALDOMAIN", "LOCPATH",
+	"MALLOC_TRACE", "NIS_PATH", "NLSPATH", "RESOLV_HOST_CONF", "RES_OPTIONS", "TZDIR",
+}
+
+func validateEnvironmentName(name string) error {
+	if !validEnvironmentName.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	for _, prefix := range reservedEnvironmentPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("cannot set environment variable %q: the %s prefix is reserved", name, prefix)
+		}
+	}
+	if strutil.ListContains(reservedEnvironmentNames, name) {
+		return fmt.Errorf("cannot set environment variable %q: the name is reserved", name)
+	}
+	return nil
+}
+
+func allServiceEnvironments(st *state.State) (map[string]map[string]map[string]string, error) {
+	var all map[string]map[string]map[string]string
+	if err := st.Get("service-environment", &all); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ServiceEnvironment returns the environment overrides of the services of
+// the given snap, keyed by app name.
+func ServiceEnvironment(st *state.State, instanceName string) (map[string]map[string]string, error) {
+	all, err := allServiceEnvironments(st)
+	if err != nil {
+		return nil, err
+	}
+	return all[instanceName], nil
+}
+
+// SetServiceEnvironment sets and unsets the given environment variables of
+// the service and regenerates the service units of its snap accordingly.
+// Running services are not restarted, the new environment takes effect the
+// next time they are started.
+func SetServiceEnvironment(st *state.State, app *snap.AppInfo, set map[string]string, unset []string) error {
+	if !app.IsService() {
+		return fmt.Errorf("cannot set environment of %q: not a service", app)
+	}
+	for name, value := range set {
+		if err := validateEnvironmentName(name); err != nil {
+			return err
+		}
+		if strings.ContainsAny(value, "\n\x00") {
+			return fmt.Errorf("cannot set environment variable %q: value cannot contain newlines or NUL characters", name)
+		}
+	}
+	for _, name := range unset {
+		if err := validateEnvironmentName(name); err != nil {
+			return err
+		}
+	}
+
+	all, err := allServiceEnvironments(st)
+	if err != nil {
+		return err
+	}
+	instanceName := app.Snap.InstanceName()
+	env := make(map[string]string, len(all[instanceName][app.Name])+len(set))
+	for name, value := range all[instanceName][app.Name] {
+		env[name] = value
+	}
+	for name, value := range set {
+		env[name] = value
+	}
+	for _, name := range unset {
+		delete(env, name)
+	}
+
+	prev := all[instanceName][app.Name]
+	updateServiceEnvironment(st, all, instanceName, app.Name, env)
+	if err := ensureSnapServiceUnits(st, app.Snap); err != nil {
+		updateServiceEnvironment(st, all, instanceName, app.Name, prev)
+		return err
+	}
+	return nil
+}
+
+func updateServiceEnvironment(st *state.State, all map[string]map[string]map[string]string, instanceName, appName string, env map[string]string) {
+	if all == nil {
+		all = make(map[string]map[string]map[string]string)
+	}
+	if all[instanceName] == nil {
+		all[instanceName] = make(map[string]map[string]string)
+	}
+	if len(env) == 0 {
+		delete(all[instanceName], appName)
+	} else {
+		all[instanceName][appName] = env
+	}
+	if len(all[instanceName]) == 0 {
+		delete(all, instanceName)
+	}
+	st.Set("service-environment", all)
+}
+
+// DiscardServiceEnvironment removes the environment overrides of the
+// services of the given snap, once it is removed from the system.
+func DiscardServiceEnvironment(st *state.State, instanceName string) error {
+	all, err := allServiceEnvironments(st)
+	if err != nil {
+		return err
+	}
+	if _, ok := all[instanceName]; !ok {
+		return nil
+	}
+	delete(all, instanceName)
+	st.Set("service-environment", all)
+	return nil
+}
@@ -0,0 +1,187 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate_test
+
+import (
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type environmentSuite struct {
+	baseServiceMgrTestSuite
+
+	info *snap.Info
+}
+
+var _ = Suite(&environmentSuite{})
+
+func (s *environmentSuite) SetUpTest(c *C) {
+	s.baseServiceMgrTestSuite.SetUpTest(c)
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "test-snap", s.testSnapState)
+	s.info = snaptest.MockSnapCurrent(c, testYaml, s.testSnapSideInfo)
+}
+
+func (s *environmentSuite) dropInFile() string {
+	return filepath.Join(dirs.SnapServicesDir, "snap.test-snap.svc1.service.d", "snap-environment.conf")
+}
+
+func (s *environmentSuite) TestSetServiceEnvironment(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{
+		{expArgs: []string{"daemon-reload"}},
+		{expArgs: []string{"daemon-reload"}},
+		{expArgs: []string{"daemon-reload"}},
+	})
+	defer r()
+
+	app := s.info.Apps["svc1"]
+	err := servicestate.SetServiceEnvironment(s.state, app, map[string]string{"LOG_LEVEL": "debug", "PORT": "8080"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.dropInFile(), testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Environment="LOG_LEVEL=debug"
+Environment="PORT=8080"
+`)
+
+	err = servicestate.SetServiceEnvironment(s.state, app, map[string]string{"PORT": "9090"}, []string{"LOG_LEVEL"})
+	c.Assert(err, IsNil)
+	env, err := servicestate.ServiceEnvironment(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, DeepEquals, map[string]map[string]string{
+		"svc1": {"PORT": "9090"},
+	})
+	c.Check(s.dropInFile(), testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Environment="PORT=9090"
+`)
+
+	// unsetting the last variable removes the drop-in
+	err = servicestate.SetServiceEnvironment(s.state, app, nil, []string{"PORT"})
+	c.Assert(err, IsNil)
+	env, err = servicestate.ServiceEnvironment(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, HasLen, 0)
+	c.Check(osutil.FileExists(s.dropInFile()), Equals, false)
+}
+
+func (s *environmentSuite) TestSetServiceEnvironmentInvalid(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	app := s.info.Apps["svc1"]
+	for _, tc := range []struct {
+		set   map[string]string
+		unset []string
+		err   string
+	}{
+		{set: map[string]string{"1FOO": "x"}, err: `invalid environment variable name "1FOO"`},
+		{set: map[string]string{"FOO-BAR": "x"}, err: `invalid environment variable name "FOO-BAR"`},
+		{unset: []string{""}, err: `invalid environment variable name ""`},
+		{set: map[string]string{"SNAP_DATA": "x"}, err: `cannot set environment variable "SNAP_DATA": the SNAP_ prefix is reserved`},
+		{set: map[string]string{"FOO": "a\nb"}, err: `cannot set environment variable "FOO": value cannot contain newlines or NUL characters`},
+	} {
+		err := servicestate.SetServiceEnvironment(s.state, app, tc.set, tc.unset)
+		c.Check(err, ErrorMatches, tc.err)
+	}
+
+	env, err := servicestate.ServiceEnvironment(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, IsNil)
+}
+
+func (s *environmentSuite) TestSetServiceEnvironmentReserved(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	app := s.info.Apps["svc1"]
+	for _, tc := range []struct {
+		name string
+		err  string
+	}{
+		{"SNAP_DATA", `cannot set environment variable "SNAP_DATA": the SNAP_ prefix is reserved`},
+		{"SNAPD_DEBUG", `cannot set environment variable "SNAPD_DEBUG": the SNAPD_ prefix is reserved`},
+		{"LD_PRELOAD", `cannot set environment variable "LD_PRELOAD": the LD_ prefix is reserved`},
+		{"LD_LIBRARY_PATH", `cannot set environment variable "LD_LIBRARY_PATH": the LD_ prefix is reserved`},
+		{"PATH", `cannot set environment variable "PATH": the name is reserved`},
+		{"HOME", `cannot set environment variable "HOME": the name is reserved`},
+		{"TMPDIR", `cannot set environment variable "TMPDIR": the name is reserved`},
+		{"XDG_RUNTIME_DIR", `cannot set environment variable "XDG_RUNTIME_DIR": the name is reserved`},
+		{"GCONV_PATH", `cannot set environment variable "GCONV_PATH": the name is reserved`},
+	} {
+		err := servicestate.SetServiceEnvironment(s.state, app, map[string]string{tc.name: "x"}, nil)
+		c.Check(err, ErrorMatches, tc.err)
+		err = servicestate.SetServiceEnvironment(s.state, app, nil, []string{tc.name})
+		c.Check(err, ErrorMatches, tc.err)
+	}
+
+	env, err := servicestate.ServiceEnvironment(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, IsNil)
+}
+
+func (s *environmentSuite) TestSnapServiceOptionsEnvironmentOverrides(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("service-environment", map[string]map[string]map[string]string{
+		"test-snap": {"svc1": {"FOO": "bar"}},
+	})
+
+	opts, err := servicestate.SnapServiceOptions(s.state, s.info, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts.EnvironmentOverrides, DeepEquals, map[string]map[string]string{
+		"svc1": {"FOO": "bar"},
+	})
+}
+
+func (s *environmentSuite) TestDiscardServiceEnvironment(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.state.Set("service-environment", map[string]map[string]map[string]string{
+		"test-snap":  {"svc1": {"FOO": "bar"}},
+		"other-snap": {"svc": {"BAZ": "1"}},
+	})
+
+	c.Assert(servicestate.DiscardServiceEnvironment(s.state, "test-snap"), IsNil)
+	env, err := servicestate.ServiceEnvironment(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, IsNil)
+	env, err = servicestate.ServiceEnvironment(s.state, "other-snap")
+	c.Assert(err, IsNil)
+	c.Check(env, HasLen, 1)
+
+	// discarding a snap without overrides is fine
+	c.Assert(servicestate.DiscardServiceEnvironment(s.state, "test-snap"), IsNil)
+}
@@ -20,6 +20,8 @@
 package servicestate
 
 import (
+	"time"
+
 	tomb "gopkg.in/tomb.v2"
 
 	"github.com/snapcore/snapd/overlord/state"
@@ -34,8 +36,14 @@ var (
 	ServiceControlTs                     = serviceControlTs
 	ValidateSnapServicesForAddingToGroup = validateSnapServicesForAddingToGroup
 	AffectedSnapServices                 = affectedSnapServices
+	ApplyResourceHints                   = applyResourceHints
+	UndoResourceHints                    = undoResourceHints
 )
 
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
 type QuotaStateUpdated = quotaStateUpdated
 
 func (m *ServiceManager) DoQuotaControl(t *state.Task, to *tomb.Tomb) error {
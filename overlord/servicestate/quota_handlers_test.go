@@ -31,6 +31,7 @@ import (
 
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/servicestate/servicestatetest"
 	"github.com/snapcore/snapd/overlord/snapstate"
@@ -2862,6 +2863,286 @@ func (s *quotaHandlersSuite) TestUndoQuotaAddSnap(c *C) {
 	})
 }
 
+const testYamlResources = `name: test-snap
+version: v1
+apps:
+  svc1:
+    command: bin.sh
+    daemon: simple
+    resources:
+      memory: 256MB
+      cpu: 2x25%
+`
+
+func (s *quotaHandlersSuite) applySnapResourceHints(c *C, yaml string, limits quota.Resources, allowed interface{}) (*state.Task, *snap.Info) {
+	st := s.state
+
+	snapstate.Set(st, "test-snap", s.testSnapState)
+	info := snaptest.MockSnapCurrent(c, yaml, s.testSnapSideInfo)
+
+	if allowed != nil {
+		tr := config.NewTransaction(st)
+		tr.Set("core", "quota.resource-hints", allowed)
+		tr.Commit()
+	}
+
+	if limits.Memory != nil {
+		err := servicestatetest.MockQuotaInState(st, "foo", "", []string{"test-snap"}, nil, limits)
+		c.Assert(err, IsNil)
+	}
+
+	task := st.NewTask("start-snap-services", "test")
+	err := servicestate.ApplyResourceHints(task, info)
+	c.Assert(err, IsNil)
+	return task, info
+}
+
+func (s *quotaHandlersSuite) TestApplyResourceHints(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		systemctlCallsForSliceStart("foo/test-snap-svc1"),
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(), true)
+
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(),
+			Snaps:          []string{"test-snap"},
+			SubGroups:      []string{"test-snap-svc1"},
+		},
+		"test-snap-svc1": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(256 * 1000 * 1000).WithCPUCount(2).WithCPUPercentage(25).Build(),
+			ParentGroup:    "foo",
+			Services:       []string{"test-snap.svc1"},
+		},
+	})
+
+	var hintsGrps []string
+	c.Assert(task.Get("resource-hints-groups", &hintsGrps), IsNil)
+	c.Check(hintsGrps, DeepEquals, []string{"test-snap-svc1"})
+}
+
+func (s *quotaHandlersSuite) TestApplyResourceHintsNotAllowed(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(), nil)
+
+	// no sub-group was created
+	allGrps, err := servicestate.AllQuotas(st)
+	c.Assert(err, IsNil)
+	c.Assert(allGrps, HasLen, 1)
+	c.Check(allGrps["foo"].SubGroups, HasLen, 0)
+	c.Assert(task.Log(), HasLen, 1)
+	c.Check(task.Log()[0], Matches, `.* Resource hints of service "test-snap.svc1" are not applied on this system, to apply them run: snap set-quota test-snap-svc1 --parent=foo --memory=256MB --cpu=2x25% test-snap.svc1`)
+	c.Check(task.Has("resource-hints-groups"), Equals, false)
+}
+
+func (s *quotaHandlersSuite) TestApplyResourceHintsNoQuotaGroup(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.Resources{}, true)
+
+	checkQuotaState(c, st, nil)
+	c.Assert(task.Log(), HasLen, 1)
+	c.Check(task.Log()[0], Matches, `.* Resource hints of service "test-snap.svc1" only apply to snaps in a quota group, to apply them add the snap to one and run: snap set-quota test-snap-svc1 --parent=<group> --memory=256MB --cpu=2x25% test-snap.svc1`)
+	c.Check(task.Has("resource-hints-groups"), Equals, false)
+}
+
+func (s *quotaHandlersSuite) TestApplyResourceHintsDoNotFit(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(100*quantity.SizeMiB).Build(), "true")
+
+	// no sub-group was created
+	allGrps, err := servicestate.AllQuotas(st)
+	c.Assert(err, IsNil)
+	c.Assert(allGrps, HasLen, 1)
+	c.Check(allGrps["foo"].SubGroups, HasLen, 0)
+	c.Assert(task.Log(), HasLen, 1)
+	c.Check(task.Log()[0], Matches, `.* Cannot apply resource hints of service "test-snap.svc1": .*, to apply them by hand run: snap set-quota test-snap-svc1 --parent=foo --memory=256MB --cpu=2x25% test-snap.svc1`)
+}
+
+func (s *quotaHandlersSuite) TestApplyResourceHintsReusesLeftoverGroup(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		systemctlCallsForSliceStart("foo/test-snap-svc1"),
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	// the sub-group is left over from a previous installation of the snap,
+	// and has limits set by the operator since
+	err := servicestatetest.MockQuotaInState(st, "foo", "", []string{"test-snap"}, nil, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build())
+	c.Assert(err, IsNil)
+	err = servicestatetest.MockQuotaInState(st, "test-snap-svc1", "foo", nil, nil, quota.NewResourcesBuilder().WithMemoryLimit(512*quantity.SizeMiB).Build())
+	c.Assert(err, IsNil)
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.Resources{}, true)
+
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(),
+			Snaps:          []string{"test-snap"},
+			SubGroups:      []string{"test-snap-svc1"},
+		},
+		"test-snap-svc1": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(512 * quantity.SizeMiB).Build(),
+			ParentGroup:    "foo",
+			Services:       []string{"test-snap.svc1"},
+		},
+	})
+	// the sub-group was not created, so undo keeps it
+	c.Check(task.Has("resource-hints-groups"), Equals, false)
+}
+
+const testYamlResourcesRefreshed = `name: test-snap
+version: v1
+apps:
+  svc1:
+    command: bin.sh
+    daemon: simple
+    resources:
+      memory: 512MB
+      cpu: 2x50%
+`
+
+func (s *quotaHandlersSuite) TestApplyResourceHintsRefresh(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		// install
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		systemctlCallsForSliceStart("foo/test-snap-svc1"),
+		// refresh
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(), true)
+
+	// the new revision comes with different hints
+	c.Assert(os.Remove(filepath.Join(dirs.SnapMountDir, "test-snap", "current")), IsNil)
+	info := snaptest.MockSnapCurrent(c, testYamlResourcesRefreshed, s.testSnapSideInfo)
+	task := st.NewTask("start-snap-services", "test")
+	err := servicestate.ApplyResourceHints(task, info)
+	c.Assert(err, IsNil)
+
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(),
+			Snaps:          []string{"test-snap"},
+			SubGroups:      []string{"test-snap-svc1"},
+		},
+		"test-snap-svc1": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(512 * 1000 * 1000).WithCPUCount(2).WithCPUPercentage(50).Build(),
+			ParentGroup:    "foo",
+			Services:       []string{"test-snap.svc1"},
+		},
+	})
+	// the sub-group already existed, so undo keeps it
+	c.Check(task.Has("resource-hints-groups"), Equals, false)
+	c.Check(task.Has("resource-hints-previous-limits"), Equals, true)
+
+	// applying the same hints again changes nothing
+	task = st.NewTask("start-snap-services", "test")
+	err = servicestate.ApplyResourceHints(task, info)
+	c.Assert(err, IsNil)
+	c.Check(task.Log(), HasLen, 0)
+	c.Check(task.Has("resource-hints-previous-limits"), Equals, false)
+}
+
+func (s *quotaHandlersSuite) TestUndoResourceHintsRefresh(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		// install
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		systemctlCallsForSliceStart("foo/test-snap-svc1"),
+		// refresh
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		// undo of the refresh
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(), true)
+
+	c.Assert(os.Remove(filepath.Join(dirs.SnapMountDir, "test-snap", "current")), IsNil)
+	info := snaptest.MockSnapCurrent(c, testYamlResourcesRefreshed, s.testSnapSideInfo)
+	task := st.NewTask("start-snap-services", "test")
+	err := servicestate.ApplyResourceHints(task, info)
+	c.Assert(err, IsNil)
+
+	err = servicestate.UndoResourceHints(task)
+	c.Assert(err, IsNil)
+
+	// the sub-group is kept with the limits of the previous revision
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(),
+			Snaps:          []string{"test-snap"},
+			SubGroups:      []string{"test-snap-svc1"},
+		},
+		"test-snap-svc1": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(256 * 1000 * 1000).WithCPUCount(2).WithCPUPercentage(25).Build(),
+			ParentGroup:    "foo",
+			Services:       []string{"test-snap.svc1"},
+		},
+	})
+}
+
+func (s *quotaHandlersSuite) TestUndoResourceHints(c *C) {
+	r := s.mockSystemctlCalls(c, join(
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStart("foo"),
+		systemctlCallsForSliceStart("foo/test-snap-svc1"),
+		// the service goes back to foo and the default sub-group is removed
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+		systemctlCallsForSliceStop("foo/test-snap-svc1"),
+		[]expectedSystemctl{{expArgs: []string{"daemon-reload"}}},
+	))
+	defer r()
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	task, _ := s.applySnapResourceHints(c, testYamlResources, quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(), true)
+
+	err := servicestate.UndoResourceHints(task)
+	c.Assert(err, IsNil)
+
+	checkQuotaState(c, st, map[string]quotaGroupState{
+		"foo": {
+			ResourceLimits: quota.NewResourcesBuilder().WithMemoryLimit(quantity.SizeGiB).Build(),
+			Snaps:          []string{"test-snap"},
+		},
+	})
+}
+
 func (s *quotaHandlersSuite) TestValidateSnapServicesForAddingToGroupCantMixGroup(c *C) {
 	st := s.state
 	st.Lock()
@@ -0,0 +1,414 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/snapcore/snapd/gadget/quantity"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/servicestate/internal"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/quota"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// resourceHintsAllowed returns whether the resource hints of services can
+// be used to create default quota sub-groups for them. This is controlled
+// by the quota.resource-hints system option, usually set by the gadget.
+func resourceHintsAllowed(st *state.State) (bool, error) {
+	var allowed interface{}
+	tr := config.NewTransaction(st)
+	if err := tr.GetMaybe("core", "quota.resource-hints", &allowed); err != nil {
+		return false, err
+	}
+	switch allowed {
+	case true, "true":
+		return true, nil
+	case nil, "", false, "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("quota.resource-hints can only be set to 'true' or 'false', got %q", allowed)
+}
+
+// resourceHintsGroupName returns the name of the default quota sub-group
+// of a service.
+func resourceHintsGroupName(app *snap.AppInfo) string {
+	name := app.Snap.InstanceName() + "-" + app.Name
+	return strings.ToLower(strings.Replace(name, "_", "-", -1))
+}
+
+func resourceHintsLimits(hints *snap.ResourceHintsInfo) quota.Resources {
+	rb := quota.NewResourcesBuilder()
+	if hints.Memory != 0 {
+		rb.WithMemoryLimit(hints.Memory)
+	}
+	if hints.CPUPercentage != 0 {
+		rb.WithCPUCount(hints.CPUCount).WithCPUPercentage(hints.CPUPercentage)
+	}
+	if hints.Threads != 0 {
+		rb.WithThreadLimit(hints.Threads)
+	}
+	return rb.Build()
+}
+
+// byteSizeString formats size in a form accepted by snap set-quota.
+func byteSizeString(size quantity.Size) string {
+	for _, unit := range []struct {
+		suffix string
+		size   quantity.Size
+	}{
+		{"GB", 1000 * 1000 * 1000},
+		{"MB", 1000 * 1000},
+		{"kB", 1000},
+	} {
+		if size%unit.size == 0 {
+			return fmt.Sprintf("%d%s", size/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+// resourceHintsCommand returns the command which creates the default quota
+// sub-group of a service by hand.
+func resourceHintsCommand(grpName, parentName string, app *snap.AppInfo) string {
+	hints := app.Resources
+	args := []string{"snap", "set-quota", grpName, "--parent=" + parentName}
+	if hints.Memory != 0 {
+		args = append(args, "--memory="+byteSizeString(hints.Memory))
+	}
+	if hints.CPUPercentage != 0 {
+		if hints.CPUCount != 0 {
+			args = append(args, fmt.Sprintf("--cpu=%dx%d%%", hints.CPUCount, hints.CPUPercentage))
+		} else {
+			args = append(args, fmt.Sprintf("--cpu=%d%%", hints.CPUPercentage))
+		}
+	}
+	if hints.Threads != 0 {
+		args = append(args, fmt.Sprintf("--threads=%d", hints.Threads))
+	}
+	args = append(args, app.Snap.InstanceName()+"."+app.Name)
+	return strings.Join(args, " ")
+}
+
+// addServiceToResourceHintsGroup puts the service into its default quota
+// sub-group under the given parent, creating the sub-group from the
+// resource hints of the service unless an unused one is left over from a
+// previous installation. It returns whether the sub-group was created.
+func addServiceToResourceHintsGroup(st *state.State, app *snap.AppInfo, parent *quota.Group, allGrps map[string]*quota.Group) (created bool, newAllGrps map[string]*quota.Group, err error) {
+	name := resourceHintsGroupName(app)
+	svc := app.Snap.InstanceName() + "." + app.Name
+
+	if err := naming.ValidateQuotaGroup(name); err != nil {
+		return false, nil, err
+	}
+	if grp, ok := allGrps[name]; ok {
+		if grp.ParentGroup != parent.Name || len(grp.Services) != 0 || len(grp.SubGroups) != 0 {
+			return false, nil, fmt.Errorf("group %q already exists", name)
+		}
+		grp.Services = []string{svc}
+		newAllGrps, err := internal.PatchQuotas(st, grp)
+		return false, newAllGrps, err
+	}
+
+	limits := resourceHintsLimits(app.Resources)
+	if err := limits.Validate(); err != nil {
+		return false, nil, err
+	}
+	if err := resourcesCheckFeatureRequirements(&limits); err != nil {
+		return false, nil, err
+	}
+	_, newAllGrps, err = internal.CreateQuotaInState(st, name, parent, nil, []string{svc}, limits, allGrps)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, newAllGrps, nil
+}
+
+// snapQuotaGroup returns the quota group the snap is in, if any.
+func snapQuotaGroup(allGrps map[string]*quota.Group, instanceName string) *quota.Group {
+	for _, grp := range allGrps {
+		if strutil.ListContains(grp.Snaps, instanceName) {
+			return grp
+		}
+	}
+	return nil
+}
+
+// updateResourceHintsGroup sets the limits of the default quota sub-group
+// of a service to its current resource hints, as they may differ from the
+// ones of the revision the sub-group was created for. It returns whether
+// the limits changed.
+func updateResourceHintsGroup(st *state.State, app *snap.AppInfo, grp *quota.Group) (updated bool, newAllGrps map[string]*quota.Group, err error) {
+	limits := resourceHintsLimits(app.Resources)
+	if err := resourcesCheckFeatureRequirements(&limits); err != nil {
+		return false, nil, err
+	}
+	oldLimits := grp.GetQuotaResources()
+	if err := quotaUpdateGroupLimits(grp, limits); err != nil {
+		return false, nil, err
+	}
+	if reflect.DeepEqual(grp.GetQuotaResources(), oldLimits) {
+		return false, nil, nil
+	}
+	newAllGrps, err = internal.PatchQuotas(st, grp)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, newAllGrps, nil
+}
+
+// applyResourceHints puts the services of the snap which declare resource
+// hints into default sub-groups of the quota group of the snap, as the snap
+// is installed or refreshed and if the system allows it. The limits of the
+// sub-groups created before for the services are set to the hints of the
+// new revision. Hints which cannot be honoured, including those of snaps
+// which are not in a quota group, are logged on the task along with the
+// commands to apply them by hand. The sub-groups created, and the previous
+// limits of the sub-groups updated, are recorded on the task so that
+// undoResourceHints can revert them.
+func applyResourceHints(t *state.Task, info *snap.Info) error {
+	var hinted []*snap.AppInfo
+	for _, app := range info.Services() {
+		if app.Resources != nil {
+			hinted = append(hinted, app)
+		}
+	}
+	if len(hinted) == 0 {
+		return nil
+	}
+	sort.Slice(hinted, func(i, j int) bool { return hinted[i].Name < hinted[j].Name })
+
+	st := t.State()
+	allowed, err := resourceHintsAllowed(st)
+	if err != nil {
+		return err
+	}
+	allGrps, err := AllQuotas(st)
+	if err != nil {
+		return err
+	}
+	parent := snapQuotaGroup(allGrps, info.InstanceName())
+
+	var created []string
+	previous := make(map[string]quota.Resources)
+	changed := false
+	for _, app := range hinted {
+		svc := info.InstanceName() + "." + app.Name
+		name := resourceHintsGroupName(app)
+		if parent == nil {
+			t.Logf("Resource hints of service %q only apply to snaps in a quota group, to apply them add the snap to one and run: %s",
+				svc, resourceHintsCommand(name, "<group>", app))
+			continue
+		}
+		if !allowed {
+			t.Logf("Resource hints of service %q are not applied on this system, to apply them run: %s",
+				svc, resourceHintsCommand(name, parent.Name, app))
+			continue
+		}
+
+		var updatedGrps map[string]*quota.Group
+		if subGrp := parent.ServiceMap()[svc]; subGrp != nil {
+			if subGrp.Name != name {
+				// the service was put in a sub-group explicitly
+				continue
+			}
+			// the sub-group was created for a previous revision
+			prevLimits := subGrp.GetQuotaResources()
+			var updated bool
+			updated, updatedGrps, err = updateResourceHintsGroup(st, app, subGrp)
+			if err == nil && !updated {
+				continue
+			}
+			if updated {
+				previous[name] = prevLimits
+			}
+		} else {
+			var grpCreated bool
+			grpCreated, updatedGrps, err = addServiceToResourceHintsGroup(st, app, parent, allGrps)
+			if grpCreated {
+				created = append(created, name)
+			}
+		}
+		if err != nil {
+			t.Logf("Cannot apply resource hints of service %q: %v, to apply them by hand run: %s",
+				svc, err, resourceHintsCommand(name, parent.Name, app))
+			continue
+		}
+		allGrps = updatedGrps
+		parent = allGrps[parent.Name]
+		changed = true
+	}
+	if len(created) > 0 {
+		t.Set("resource-hints-groups", created)
+	}
+	if len(previous) > 0 {
+		t.Set("resource-hints-previous-limits", previous)
+	}
+	if !changed {
+		return nil
+	}
+
+	// ensure service and slices on disk and their states are updated
+	opts := &ensureSnapServicesForGroupOptions{
+		allGrps: allGrps,
+	}
+	servicesAffected, err := ensureSnapServicesForGroup(st, t, parent, opts)
+	if err != nil {
+		return err
+	}
+	if len(servicesAffected) > 0 && parent.JournalLimit != nil {
+		ts := state.NewTaskSet()
+		addRefreshProfileTasks(st, func(task *state.Task) {
+			ts.AddTask(task)
+		}, servicesAffected)
+		snapstate.InjectTasks(t, ts)
+	}
+	return nil
+}
+
+// undoResourceHints restores the limits of the default quota sub-groups
+// updated by applyResourceHints for the task and removes the ones it
+// created.
+func undoResourceHints(t *state.Task) error {
+	var previous map[string]quota.Resources
+	if err := t.Get("resource-hints-previous-limits", &previous); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if err := restoreResourceHintsLimits(t.State(), t, previous); err != nil {
+		return err
+	}
+	hintsGrps, err := taskResourceHintsGroups(t)
+	if err != nil {
+		return err
+	}
+	return removeResourceHintsGroups(t.State(), t, hintsGrps)
+}
+
+// resetResourceHintsLimits sets the limits that resource hints control on
+// the group to the given ones. Unlike quota.Group.UpdateQuotaLimits it
+// lowers and removes limits as well.
+func resetResourceHintsLimits(grp *quota.Group, limits quota.Resources) {
+	grp.MemoryLimit = 0
+	if limits.Memory != nil {
+		grp.MemoryLimit = limits.Memory.Limit
+	}
+	var cpuSet []int
+	if grp.CPULimit != nil {
+		cpuSet = grp.CPULimit.CPUSet
+	}
+	grp.CPULimit = nil
+	if limits.CPU != nil || len(cpuSet) != 0 {
+		grp.CPULimit = &quota.GroupQuotaCPU{CPUSet: cpuSet}
+		if limits.CPU != nil {
+			grp.CPULimit.Count = limits.CPU.Count
+			grp.CPULimit.Percentage = limits.CPU.Percentage
+		}
+	}
+	grp.ThreadLimit = 0
+	if limits.Threads != nil {
+		grp.ThreadLimit = limits.Threads.Limit
+	}
+}
+
+// restoreResourceHintsLimits sets the limits of the given default quota
+// sub-groups back to the ones they had before the resource hints of the
+// snap were applied.
+func restoreResourceHintsLimits(st *state.State, t *state.Task, previous map[string]quota.Resources) error {
+	names := make([]string, 0, len(previous))
+	for name := range previous {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		allGrps, err := AllQuotas(st)
+		if err != nil {
+			return err
+		}
+		grp, ok := allGrps[name]
+		if !ok {
+			continue
+		}
+		resetResourceHintsLimits(grp, previous[name])
+		allGrps, err = internal.PatchQuotas(st, grp)
+		if err != nil {
+			return err
+		}
+		opts := &ensureSnapServicesForGroupOptions{
+			allGrps: allGrps,
+		}
+		if _, err := ensureSnapServicesForGroup(st, t, allGrps[name], opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeResourceHintsGroups removes the given default quota sub-groups,
+// their services go back to the parent group.
+func removeResourceHintsGroups(st *state.State, t *state.Task, names []string) error {
+	for _, name := range names {
+		allGrps, err := AllQuotas(st)
+		if err != nil {
+			return err
+		}
+		grp, ok := allGrps[name]
+		if !ok || len(grp.SubGroups) != 0 {
+			continue
+		}
+
+		qc := QuotaControlAction{
+			Action:    "remove",
+			QuotaName: name,
+		}
+		grp, _, _, err = quotaRemove(st, qc, allGrps)
+		if err != nil {
+			return err
+		}
+		// reload the groups so that the parent no longer links to the
+		// removed sub-group
+		allGrps, err = AllQuotas(st)
+		if err != nil {
+			return err
+		}
+		opts := &ensureSnapServicesForGroupOptions{
+			allGrps: allGrps,
+		}
+		if _, err := ensureSnapServicesForGroup(st, t, grp, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func taskResourceHintsGroups(t *state.Task) ([]string, error) {
+	var names []string
+	if err := t.Get("resource-hints-groups", &names); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return names, nil
+}
@@ -0,0 +1,258 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snapdenv"
+	"github.com/snapcore/snapd/systemd"
+)
+
+const (
+	restartsCheckInterval = time.Minute
+	// crashLoopRestarts is the number of automatic restarts of a service
+	// between two checks from which it is considered to be in a crash loop
+	crashLoopRestarts = 3
+)
+
+var timeNow = time.Now
+
+// RestartHistory holds the restarts and the last exit of a service, as
+// tracked by snapd.
+type RestartHistory struct {
+	// Revision is the revision of the snap the history is about.
+	Revision snap.Revision `json:"revision"`
+	// Restarts is the number of automatic restarts of the service since
+	// it was last started explicitly.
+	Restarts int `json:"restarts"`
+	// ExitCode and ExitSignal describe the last exit of the main process
+	// of the service.
+	ExitCode   int    `json:"exit-code,omitempty"`
+	ExitSignal string `json:"exit-signal,omitempty"`
+	// LastFailure is the last time the service exited with a failure.
+	LastFailure time.Time `json:"last-failure,omitempty"`
+	// CrashLoop is whether the service keeps failing and being restarted,
+	// or systemd gave up restarting it.
+	CrashLoop bool `json:"crash-loop,omitempty"`
+}
+
+// restartsSeen is the number of restarts of a service seen by a check.
+type restartsSeen struct {
+	revision snap.Revision
+	restarts int
+}
+
+// restartHistoryFromStatus returns the restart history of a service from its
+// status, the previous history and, if seen is not nil, the number of
+// restarts seen by the previous check.
+func restartHistoryFromStatus(prev *RestartHistory, seen *restartsSeen, rev snap.Revision, st *systemd.ServiceRestartStatus) *RestartHistory {
+	h := &RestartHistory{
+		Revision:   rev,
+		Restarts:   st.Restarts,
+		ExitCode:   st.ExitCode,
+		ExitSignal: st.ExitSignal,
+	}
+	if prev != nil {
+		h.LastFailure = prev.LastFailure
+	}
+	if st.Failed() && !st.ExitTime.IsZero() {
+		h.LastFailure = st.ExitTime
+	}
+	switch {
+	case st.Result == "start-limit-hit":
+		h.CrashLoop = true
+	case seen != nil && st.Restarts-seen.restarts >= crashLoopRestarts:
+		h.CrashLoop = true
+	}
+	return h
+}
+
+func (h *RestartHistory) equal(other *RestartHistory) bool {
+	return h.Revision == other.Revision && h.Restarts == other.Restarts &&
+		h.ExitCode == other.ExitCode && h.ExitSignal == other.ExitSignal &&
+		h.LastFailure.Equal(other.LastFailure) && h.CrashLoop == other.CrashLoop
+}
+
+func sameRestartHistories(a, b map[string]map[string]*RestartHistory) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for snapName, histories := range a {
+		if len(histories) != len(b[snapName]) {
+			return false
+		}
+		for app, h := range histories {
+			other := b[snapName][app]
+			if other == nil || !h.equal(other) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func allRestartHistories(st *state.State) (map[string]map[string]*RestartHistory, error) {
+	var all map[string]map[string]*RestartHistory
+	if err := st.Get("service-restarts", &all); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+	return all, nil
+}
+
+// RestartHistories returns the restart histories of the services of the
+// current revision of the given snap, keyed by app name.
+func RestartHistories(st *state.State, instanceName string) (map[string]*RestartHistory, error) {
+	all, err := allRestartHistories(st)
+	if err != nil {
+		return nil, err
+	}
+	var snapst snapstate.SnapState
+	if err := snapstate.Get(st, instanceName, &snapst); err != nil {
+		if errors.Is(err, state.ErrNoState) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	histories := make(map[string]*RestartHistory, len(all[instanceName]))
+	for name, h := range all[instanceName] {
+		if h.Revision == snapst.Current {
+			histories[name] = h
+		}
+	}
+	return histories, nil
+}
+
+func warnCrashLoop(st *state.State, app *snap.AppInfo, h *RestartHistory) {
+	lastExit := fmt.Sprintf("exit code %d", h.ExitCode)
+	if h.ExitSignal != "" {
+		lastExit = fmt.Sprintf("signal %s", h.ExitSignal)
+	}
+	msg := fmt.Sprintf("service %s.%s is in a crash loop: restarted %d times, last exited with %s", app.Snap.InstanceName(), app.Name, h.Restarts, lastExit)
+	st.Warnf("%s", msg)
+}
+
+// ensureRestartsTracked periodically records the restarts of the system
+// services of active snaps and warns about services entering a crash loop.
+// The state is only modified when the histories change.
+func (m *ServiceManager) ensureRestartsTracked() error {
+	now := timeNow()
+	if now.Before(m.nextRestartsCheck) {
+		return nil
+	}
+	m.nextRestartsCheck = now.Add(restartsCheckInterval)
+	if snapdenv.Preseeding() {
+		return nil
+	}
+
+	m.state.Lock()
+	defer m.state.Unlock()
+
+	m.state.EnsureBefore(restartsCheckInterval)
+
+	var seeded bool
+	if err := m.state.Get("seeded", &seeded); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if !seeded {
+		return nil
+	}
+
+	snapStates, err := snapstate.All(m.state)
+	if err != nil {
+		return err
+	}
+	var apps []*snap.AppInfo
+	var units []string
+	for name, snapst := range snapStates {
+		if !snapst.Active {
+			continue
+		}
+		info, err := snapst.CurrentInfo()
+		if err != nil {
+			logger.Noticef("cannot get info of snap %q to track restarts of its services: %v", name, err)
+			continue
+		}
+		for _, app := range info.Services() {
+			// the status of user services cannot be known here
+			if app.DaemonScope != snap.SystemDaemon {
+				continue
+			}
+			apps = append(apps, app)
+			units = append(units, app.ServiceName())
+		}
+	}
+
+	var sts []*systemd.ServiceRestartStatus
+	if len(units) > 0 {
+		m.state.Unlock()
+		sts, err = systemd.New(systemd.SystemMode, progress.Null).RestartStatus(units)
+		m.state.Lock()
+		if err != nil {
+			logger.Noticef("cannot track restarts of services: %v", err)
+			return nil
+		}
+	}
+
+	old, err := allRestartHistories(m.state)
+	if err != nil {
+		return err
+	}
+	all := make(map[string]map[string]*RestartHistory)
+	seen := make(map[string]restartsSeen, len(apps))
+	for i, app := range apps {
+		snapName := app.Snap.InstanceName()
+		prev := old[snapName][app.Name]
+		if prev != nil && prev.Revision != app.Snap.Revision {
+			prev = nil
+		}
+		key := snap.JoinSnapApp(snapName, app.Name)
+		var prevSeen *restartsSeen
+		if s, ok := m.restartsSeen[key]; ok && s.revision == app.Snap.Revision {
+			prevSeen = &s
+		}
+		seen[key] = restartsSeen{revision: app.Snap.Revision, restarts: sts[i].Restarts}
+		h := restartHistoryFromStatus(prev, prevSeen, app.Snap.Revision, sts[i])
+		if h.CrashLoop && (prev == nil || !prev.CrashLoop) {
+			warnCrashLoop(m.state, app, h)
+		}
+		if all[snapName] == nil {
+			all[snapName] = make(map[string]*RestartHistory)
+		}
+		all[snapName][app.Name] = h
+	}
+	m.restartsSeen = seen
+	if sameRestartHistories(all, old) {
+		return nil
+	}
+	if len(all) == 0 {
+		m.state.Set("service-restarts", nil)
+		return nil
+	}
+	m.state.Set("service-restarts", all)
+	return nil
+}
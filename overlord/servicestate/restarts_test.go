@@ -0,0 +1,268 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package servicestate_test
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/servicestate"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+type restartsSuite struct {
+	baseServiceMgrTestSuite
+
+	now time.Time
+}
+
+var _ = Suite(&restartsSuite{})
+
+func (s *restartsSuite) SetUpTest(c *C) {
+	s.baseServiceMgrTestSuite.SetUpTest(c)
+
+	// service units are up to date
+	s.AddCleanup(servicestate.MockEnsuredSnapServices(s.mgr, true))
+
+	s.now = time.Now().Add(time.Minute)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time { return s.now }))
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	snapstate.Set(s.state, "test-snap", s.testSnapState)
+	snaptest.MockSnapCurrent(c, testYaml, s.testSnapSideInfo)
+}
+
+const restartStatusArg = "--property=Id,NRestarts,ExecMainCode,ExecMainStatus,ExecMainExitTimestamp,Result"
+
+func (s *restartsSuite) restartStatus(restarts, code, status int, result string) expectedSystemctl {
+	return expectedSystemctl{
+		expArgs: []string{"show", restartStatusArg, "snap.test-snap.svc1.service"},
+		output: `Id=snap.test-snap.svc1.service
+NRestarts=` + strconv.Itoa(restarts) + `
+ExecMainCode=` + strconv.Itoa(code) + `
+ExecMainStatus=` + strconv.Itoa(status) + `
+ExecMainExitTimestamp=Fri 2021-04-16 15:32:21 UTC
+Result=` + result + `
+`,
+	}
+}
+
+func (s *restartsSuite) histories(c *C) map[string]*servicestate.RestartHistory {
+	s.state.Lock()
+	defer s.state.Unlock()
+	histories, err := servicestate.RestartHistories(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	return histories
+}
+
+func (s *restartsSuite) warnings() []*state.Warning {
+	s.state.Lock()
+	defer s.state.Unlock()
+	return s.state.AllWarnings()
+}
+
+func (s *restartsSuite) TestEnsureTracksRestartsAndCrashLoops(c *C) {
+	lastFailure := time.Date(2021, time.April, 16, 15, 32, 21, 0, time.UTC)
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{
+		// the service failed and was restarted once
+		s.restartStatus(1, 1, 2, "exit-code"),
+		// it then keeps crashing
+		s.restartStatus(4, 3, 11, "core-dump"),
+		// and is still crashing
+		s.restartStatus(9, 3, 11, "core-dump"),
+		// until it eventually recovers
+		s.restartStatus(9, 0, 0, "success"),
+	})
+	defer r()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c), DeepEquals, map[string]*servicestate.RestartHistory{
+		"svc1": {
+			Revision:    snap.R(42),
+			Restarts:    1,
+			ExitCode:    2,
+			LastFailure: lastFailure,
+		},
+	})
+	c.Check(s.warnings(), HasLen, 0)
+
+	// nothing happens before the next check is due
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(s.mgr.Ensure(), IsNil)
+
+	s.now = s.now.Add(30 * time.Second)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c), DeepEquals, map[string]*servicestate.RestartHistory{
+		"svc1": {
+			Revision:    snap.R(42),
+			Restarts:    4,
+			ExitSignal:  "SIGSEGV",
+			LastFailure: lastFailure,
+			CrashLoop:   true,
+		},
+	})
+	warnings := s.warnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, "service test-snap.svc1 is in a crash loop: restarted 4 times, last exited with signal SIGSEGV")
+
+	// still in a crash loop, no new warning
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c)["svc1"].CrashLoop, Equals, true)
+	warnings = s.warnings()
+	c.Assert(warnings, HasLen, 1)
+
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c), DeepEquals, map[string]*servicestate.RestartHistory{
+		"svc1": {
+			Revision:    snap.R(42),
+			Restarts:    9,
+			LastFailure: lastFailure,
+		},
+	})
+}
+
+func (s *restartsSuite) TestEnsureRestartsRecordsChangesOnly(c *C) {
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{
+		s.restartStatus(1, 1, 2, "exit-code"),
+		s.restartStatus(1, 1, 2, "exit-code"),
+		s.restartStatus(2, 1, 2, "exit-code"),
+	})
+	defer r()
+
+	rawHistory := func() map[string]interface{} {
+		s.state.Lock()
+		defer s.state.Unlock()
+		var all map[string]map[string]map[string]interface{}
+		c.Assert(s.state.Get("service-restarts", &all), IsNil)
+		return all["test-snap"]["svc1"]
+	}
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(rawHistory()["restarts"], Equals, float64(1))
+
+	// mark the recorded history to notice when it is written again
+	marked := rawHistory()
+	marked["marker"] = true
+	s.state.Lock()
+	s.state.Set("service-restarts", map[string]map[string]map[string]interface{}{
+		"test-snap": {"svc1": marked},
+	})
+	s.state.Unlock()
+
+	// nothing changed, the history is not written
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(rawHistory()["marker"], Equals, true)
+
+	// the service was restarted again, the history is written
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(rawHistory()["marker"], IsNil)
+	c.Check(rawHistory()["restarts"], Equals, float64(2))
+}
+
+func (s *restartsSuite) TestEnsureRestartsCrashLoopNeedsRestartsSeen(c *C) {
+	// the history was recorded before snapd restarted
+	s.state.Lock()
+	s.state.Set("service-restarts", map[string]map[string]*servicestate.RestartHistory{
+		"test-snap": {
+			"svc1": {Revision: snap.R(42), Restarts: 1},
+		},
+	})
+	s.state.Unlock()
+
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{
+		s.restartStatus(4, 1, 2, "exit-code"),
+		s.restartStatus(7, 1, 2, "exit-code"),
+	})
+	defer r()
+
+	// the restarts seen by the previous snapd are not known
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c)["svc1"].Restarts, Equals, 4)
+	c.Check(s.histories(c)["svc1"].CrashLoop, Equals, false)
+
+	s.now = s.now.Add(time.Minute)
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c)["svc1"].CrashLoop, Equals, true)
+	c.Check(s.warnings(), HasLen, 1)
+}
+
+func (s *restartsSuite) TestEnsureStartLimitHitIsCrashLoop(c *C) {
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{
+		s.restartStatus(5, 1, 1, "start-limit-hit"),
+	})
+	defer r()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c)["svc1"].CrashLoop, Equals, true)
+	warnings := s.warnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Check(warnings[0].String(), Equals, "service test-snap.svc1 is in a crash loop: restarted 5 times, last exited with exit code 1")
+}
+
+func (s *restartsSuite) TestEnsureRestartsErrorIsNotFatal(c *C) {
+	r := s.mockSystemctlCalls(c, []expectedSystemctl{{
+		expArgs: []string{"show", restartStatusArg, "snap.test-snap.svc1.service"},
+		err:     fmt.Errorf("mocked failure"),
+	}})
+	defer r()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c), HasLen, 0)
+}
+
+func (s *restartsSuite) TestEnsureRestartsNotSeeded(c *C) {
+	s.state.Lock()
+	s.state.Set("seeded", false)
+	s.state.Unlock()
+
+	r := s.mockSystemctlCalls(c, nil)
+	defer r()
+
+	c.Assert(s.mgr.Ensure(), IsNil)
+	c.Check(s.histories(c), HasLen, 0)
+}
+
+func (s *restartsSuite) TestRestartHistoriesOfOtherRevisionIgnored(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+	s.state.Set("service-restarts", map[string]map[string]*servicestate.RestartHistory{
+		"test-snap": {
+			"svc1": {Revision: snap.R(41), Restarts: 3},
+		},
+	})
+	histories, err := servicestate.RestartHistories(s.state, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(histories, HasLen, 0)
+
+	histories, err = servicestate.RestartHistories(s.state, "other-snap")
+	c.Assert(err, IsNil)
+	c.Check(histories, HasLen, 0)
+}
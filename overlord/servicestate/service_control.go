@@ -20,13 +20,20 @@
 package servicestate
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	tomb "gopkg.in/tomb.v2"
 
+	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/systemd"
 	"github.com/snapcore/snapd/wrappers"
 )
 
@@ -59,9 +66,94 @@ type ServiceAction struct {
 	// "reload-or-restart" actions, and when set it restarts also enabled
 	// non-running services, otherwise these services are left inactive.
 	RestartEnabledNonActive bool `json:"restart-enabled-non-active,omitempty"`
+	// WaitReady is only for "restart" and "reload-or-restart" actions,
+	// and when set the action completes only once the restarted system
+	// services are active again and, for those with a health probe,
+	// healthy.
+	WaitReady bool `json:"wait-ready,omitempty"`
 	wrappers.ScopeOptions
 }
 
+const (
+	waitReadyTimeout       = 5 * time.Minute
+	waitReadyRetryInterval = 2 * time.Second
+)
+
+// waitServicesReady checks whether the given services of the snap, restarted
+// at the given time, are active and healthy, and that the snap itself is
+// healthy. It returns a *state.Retry if they are not ready yet.
+func waitServicesReady(st *state.State, info *snap.Info, services []*snap.AppInfo, explicitServices []string, restartedAt time.Time) error {
+	var candidates []*snap.AppInfo
+	var units []string
+	for _, app := range services {
+		// the status of user services cannot be known here, and
+		// activated or oneshot services are not expected to stay active
+		if app.DaemonScope != snap.SystemDaemon || app.Daemon == "oneshot" {
+			continue
+		}
+		if len(app.Sockets) != 0 || app.Timer != nil || len(app.ActivatesOn) != 0 || len(app.ActivatesOnPath) != 0 {
+			continue
+		}
+		candidates = append(candidates, app)
+		units = append(units, app.ServiceName())
+	}
+	if len(units) == 0 {
+		return nil
+	}
+
+	st.Unlock()
+	sts, err := systemd.New(systemd.SystemMode, progress.Null).Status(units)
+	st.Lock()
+	if err != nil {
+		return err
+	}
+	healths, err := healthstate.AppHealth(st, info.InstanceName())
+	if err != nil {
+		return err
+	}
+	snapHealth, err := healthstate.Get(st, info.InstanceName())
+	if err != nil {
+		return err
+	}
+
+	var notReady []string
+	for i, app := range candidates {
+		// disabled services that were not running are not restarted
+		if !sts[i].Enabled && !sts[i].Active && !strutil.ListContains(explicitServices, app.Name) {
+			continue
+		}
+		if !sts[i].Active {
+			notReady = append(notReady, fmt.Sprintf("%s is not active", app.Name))
+			continue
+		}
+		if app.Health == nil {
+			continue
+		}
+		health := healths[app.Name]
+		if health == nil || health.Timestamp.Before(restartedAt) || health.Status != healthstate.OkayStatus {
+			notReady = append(notReady, fmt.Sprintf("%s is not healthy", app.Name))
+		}
+	}
+	// the health reported by the snap itself, with snapctl set-health or
+	// its check-health hook, must be okay as well
+	if snapHealth != nil && snapHealth.Revision == info.Revision && snapHealth.Status != healthstate.OkayStatus {
+		snapReason := fmt.Sprintf("snap health is %s", snapHealth.Status)
+		if snapHealth.Message != "" {
+			snapReason += ": " + snapHealth.Message
+		}
+		notReady = append(notReady, snapReason)
+	}
+	if len(notReady) == 0 {
+		return nil
+	}
+
+	reason := strings.Join(notReady, ", ")
+	if timeNow().Sub(restartedAt) >= waitReadyTimeout {
+		return fmt.Errorf("services of snap %q not ready %v after restart: %s", info.InstanceName(), waitReadyTimeout, reason)
+	}
+	return &state.Retry{After: waitReadyRetryInterval, Reason: reason}
+}
+
 func (m *ServiceManager) doServiceControl(t *state.Task, _ *tomb.Tomb) error {
 	st := t.State()
 	st.Lock()
@@ -178,23 +270,40 @@ func (m *ServiceManager) doServiceControl(t *state.Task, _ *tomb.Tomb) error {
 				snapstate.Set(st, sc.SnapName, &snapst)
 			}
 		}
-	case "restart":
-		st.Unlock()
-		err := wrappers.RestartServices(startupOrdered, explicitServicesSystemdUnits, &wrappers.RestartServicesOptions{
-			AlsoEnabledNonActive: sc.RestartEnabledNonActive,
-			ScopeOptions:         sc.ScopeOptions,
-		}, meter, perfTimings)
-		st.Lock()
-		return err
-	case "reload-or-restart":
-		st.Unlock()
-		err := wrappers.RestartServices(startupOrdered, explicitServicesSystemdUnits, &wrappers.RestartServicesOptions{
-			Reload:               true,
-			AlsoEnabledNonActive: sc.RestartEnabledNonActive,
-			ScopeOptions:         sc.ScopeOptions,
-		}, meter, perfTimings)
-		st.Lock()
-		return err
+	case "restart", "reload-or-restart":
+		// when waiting for the services to be ready, the task is retried
+		// after the restart until they are
+		var restartedAt time.Time
+		if err := t.Get("restarted-at", &restartedAt); err != nil && !errors.Is(err, state.ErrNoState) {
+			return err
+		}
+		if restartedAt.IsZero() {
+			st.Unlock()
+			err := wrappers.RestartServices(startupOrdered, explicitServicesSystemdUnits, &wrappers.RestartServicesOptions{
+				Reload:               sc.Action == "reload-or-restart",
+				AlsoEnabledNonActive: sc.RestartEnabledNonActive,
+				ScopeOptions:         sc.ScopeOptions,
+			}, meter, perfTimings)
+			st.Lock()
+			if err != nil || !sc.WaitReady {
+				return err
+			}
+			// probes only record the health of the apps when it
+			// changes, drop it to tell it apart from the health of
+			// the services before the restart
+			var probed []string
+			for _, app := range services {
+				if app.Health != nil {
+					probed = append(probed, app.Name)
+				}
+			}
+			if err := healthstate.ForgetAppHealth(st, info.InstanceName(), probed); err != nil {
+				return err
+			}
+			restartedAt = timeNow()
+			t.Set("restarted-at", restartedAt)
+		}
+		return waitServicesReady(st, info, services, sc.ExplicitServices, restartedAt)
 	default:
 		return fmt.Errorf("unhandled service action: %q", sc.Action)
 	}
@@ -20,6 +20,7 @@
 package servicestate_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -32,6 +33,7 @@ import (
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/overlord"
+	"github.com/snapcore/snapd/overlord/healthstate"
 	"github.com/snapcore/snapd/overlord/servicestate"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
@@ -427,6 +429,65 @@ func (s *serviceControlSuite) TestControlUnknownInstruction(c *C) {
 	c.Assert(err, ErrorMatches, `unknown action "boo"`)
 }
 
+func (s *serviceControlSuite) TestControlRestartRollingInstruction(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	info := s.mockTestSnap(c)
+	si := snap.SideInfo{
+		RealName: "test-snap",
+		Revision: snap.R(7),
+	}
+	otherInfo := snaptest.MockSnapInstance(c, "test-snap_other", servicesSnapYaml1, &si)
+	snapstate.Set(s.state, "test-snap_other", &snapstate.SnapState{
+		Active:      true,
+		Sequence:    snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:     snap.R(7),
+		SnapType:    "app",
+		InstanceKey: "other",
+	})
+
+	inst := &servicestate.Instruction{
+		Action:         "restart",
+		Names:          []string{"test-snap_other", "test-snap"},
+		RestartOptions: client.RestartOptions{Rolling: true},
+	}
+	apps := []*snap.AppInfo{otherInfo.Apps["foo"], info.Apps["foo"]}
+	tss, err := servicestate.Control(st, apps, inst, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(tss, HasLen, 1)
+	tasks := tss[0].Tasks()
+	c.Assert(tasks, HasLen, 2)
+
+	// the instances are restarted one after the other
+	for i, name := range []string{"test-snap", "test-snap_other"} {
+		var sa servicestate.ServiceAction
+		c.Assert(tasks[i].Get("service-action", &sa), IsNil)
+		c.Check(sa.SnapName, Equals, name)
+		c.Check(sa.Action, Equals, "restart")
+		c.Check(sa.WaitReady, Equals, true)
+		c.Check(tasks[i].Summary(), Equals, fmt.Sprintf(`Run service command "restart" for running services of snap %q and wait for them to be ready`, name))
+	}
+	c.Check(tasks[0].WaitTasks(), HasLen, 0)
+	c.Check(tasks[1].WaitTasks(), DeepEquals, []*state.Task{tasks[0]})
+}
+
+func (s *serviceControlSuite) TestControlRollingNotRestart(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	info := s.mockTestSnap(c)
+	inst := &servicestate.Instruction{
+		Action:         "start",
+		RestartOptions: client.RestartOptions{Rolling: true},
+	}
+
+	_, err := servicestate.Control(st, []*snap.AppInfo{info.Apps["foo"]}, inst, nil, nil, nil)
+	c.Assert(err, ErrorMatches, `rolling is only supported for the restart action, not "start"`)
+}
+
 func (s *serviceControlSuite) TestControlStopDisableMultipleInstruction(c *C) {
 	st := s.state
 	st.Lock()
@@ -893,6 +954,230 @@ func (s *serviceControlSuite) TestRestartServices(c *C) {
 	})
 }
 
+const healthServicesSnapYaml = `name: test-snap
+version: 1.0
+apps:
+  foo:
+    daemon: simple
+    health:
+      command: bin/check
+  bar:
+    daemon: simple
+  baz:
+    daemon: oneshot
+`
+
+func (s *serviceControlSuite) mockHealthTestSnap(c *C) *snap.Info {
+	si := snap.SideInfo{
+		RealName: "test-snap",
+		Revision: snap.R(7),
+	}
+	info := snaptest.MockSnap(c, healthServicesSnapYaml, &si)
+	snapstate.Set(s.state, "test-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{&si}),
+		Current:  snap.R(7),
+		SnapType: "app",
+	})
+	return info
+}
+
+func (s *serviceControlSuite) mockSystemctlStates(c *C, states map[string]systemdtest.ServiceState) {
+	restore := systemd.MockSystemctl(func(cmd ...string) (buf []byte, err error) {
+		if out := systemdtest.HandleMockAllUnitsActiveOutput(cmd, states); out != nil {
+			return out, nil
+		}
+		s.sysctlArgs = append(s.sysctlArgs, cmd)
+		return nil, nil
+	})
+	s.AddCleanup(restore)
+}
+
+func (s *serviceControlSuite) TestRestartServicesWaitReady(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.mockHealthTestSnap(c)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time { return now }))
+
+	// the health recorded before the restart does not count
+	st.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"test-snap": {
+			"foo": {Revision: snap.R(7), Timestamp: now.Add(-time.Minute), Status: healthstate.OkayStatus},
+		},
+	})
+
+	chg := st.NewChange("service-control", "...")
+	t := st.NewTask("service-control", "...")
+	cmd := &servicestate.ServiceAction{
+		SnapName:                "test-snap",
+		Action:                  "restart",
+		Services:                []string{"bar", "baz", "foo"},
+		RestartEnabledNonActive: true,
+		WaitReady:               true,
+	}
+	t.Set("service-action", cmd)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err := s.o.Settle(500 * time.Millisecond)
+	st.Lock()
+	c.Assert(err, NotNil)
+
+	c.Check(t.Status(), Equals, state.DoingStatus)
+	var restartedAt time.Time
+	c.Assert(t.Get("restarted-at", &restartedAt), IsNil)
+	c.Check(restartedAt.Equal(now), Equals, true)
+	restarts := len(s.sysctlArgs)
+	c.Check(restarts > 0, Equals, true)
+	healths, err := healthstate.AppHealth(st, "test-snap")
+	c.Assert(err, IsNil)
+	c.Check(healths, HasLen, 0)
+
+	st.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"test-snap": {
+			"foo": {Revision: snap.R(7), Timestamp: now.Add(time.Second), Status: healthstate.OkayStatus},
+		},
+	})
+
+	st.Unlock()
+	err = s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Assert(err, IsNil)
+
+	c.Check(t.Status(), Equals, state.DoneStatus)
+	// the services were not restarted again while waiting
+	c.Check(s.sysctlArgs, HasLen, restarts)
+}
+
+func (s *serviceControlSuite) TestRestartServicesWaitReadySnapHealth(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.mockHealthTestSnap(c)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time { return now }))
+
+	// the snap reported itself as not ready with snapctl set-health
+	st.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(7), Timestamp: now, Status: healthstate.WaitingStatus, Message: "loading the database"},
+	})
+
+	chg := st.NewChange("service-control", "...")
+	t := st.NewTask("service-control", "...")
+	cmd := &servicestate.ServiceAction{
+		SnapName:                "test-snap",
+		Action:                  "restart",
+		Services:                []string{"bar"},
+		RestartEnabledNonActive: true,
+		WaitReady:               true,
+	}
+	t.Set("service-action", cmd)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err := s.o.Settle(500 * time.Millisecond)
+	st.Lock()
+	c.Assert(err, NotNil)
+	c.Check(t.Status(), Equals, state.DoingStatus)
+
+	// the health reported for another revision does not count
+	st.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(6), Timestamp: now, Status: healthstate.ErrorStatus},
+	})
+
+	st.Unlock()
+	err = s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Assert(err, IsNil)
+	c.Check(t.Status(), Equals, state.DoneStatus)
+}
+
+func (s *serviceControlSuite) TestRestartServicesWaitReadySnapHealthTimeout(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.mockHealthTestSnap(c)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time {
+		defer func() { now = now.Add(10 * time.Minute) }()
+		return now
+	}))
+	st.Set("health", map[string]*healthstate.HealthState{
+		"test-snap": {Revision: snap.R(7), Timestamp: now, Status: healthstate.ErrorStatus, Message: "cannot open the database"},
+	})
+
+	chg := st.NewChange("service-control", "...")
+	t := st.NewTask("service-control", "...")
+	cmd := &servicestate.ServiceAction{
+		SnapName:                "test-snap",
+		Action:                  "restart",
+		Services:                []string{"bar"},
+		RestartEnabledNonActive: true,
+		WaitReady:               true,
+	}
+	t.Set("service-action", cmd)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err := s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Assert(err, IsNil)
+
+	c.Check(t.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*services of snap "test-snap" not ready 5m0s after restart: snap health is error: cannot open the database.*`)
+}
+
+func (s *serviceControlSuite) TestRestartServicesWaitReadyTimeout(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	s.mockHealthTestSnap(c)
+	s.mockSystemctlStates(c, map[string]systemdtest.ServiceState{
+		"snap.test-snap.bar.service": {ActiveState: "inactive", UnitFileState: "enabled"},
+	})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.AddCleanup(servicestate.MockTimeNow(func() time.Time {
+		defer func() { now = now.Add(10 * time.Minute) }()
+		return now
+	}))
+	st.Set("app-health", map[string]map[string]*healthstate.HealthState{
+		"test-snap": {
+			"foo": {Revision: snap.R(7), Timestamp: now.Add(time.Second), Status: healthstate.OkayStatus},
+		},
+	})
+
+	chg := st.NewChange("service-control", "...")
+	t := st.NewTask("service-control", "...")
+	cmd := &servicestate.ServiceAction{
+		SnapName:                "test-snap",
+		Action:                  "restart",
+		Services:                []string{"bar", "foo"},
+		ExplicitServices:        []string{"bar", "foo"},
+		RestartEnabledNonActive: true,
+		WaitReady:               true,
+	}
+	t.Set("service-action", cmd)
+	chg.AddTask(t)
+
+	st.Unlock()
+	defer s.se.Stop()
+	err := s.o.Settle(5 * time.Second)
+	st.Lock()
+	c.Assert(err, IsNil)
+
+	c.Check(t.Status(), Equals, state.ErrorStatus)
+	c.Check(chg.Err(), ErrorMatches, `(?s).*services of snap "test-snap" not ready 5m0s after restart: bar is not active.*`)
+}
+
 func (s *serviceControlSuite) TestRestartServicesWithScope(c *C) {
 	st := s.state
 	st.Lock()
@@ -48,6 +48,12 @@ type ServiceManager struct {
 	state *state.State
 
 	ensuredSnapSvcs bool
+
+	nextRestartsCheck time.Time
+	// restartsSeen holds the number of restarts of the services seen by
+	// the last check, keyed by snap.app, to detect crash loops without
+	// persisting it on every check
+	restartsSeen map[string]restartsSeen
 }
 
 // Manager returns a new service manager.
@@ -55,6 +61,8 @@ func Manager(st *state.State, runner *state.TaskRunner) *ServiceManager {
 	delayedCrossMgrInit()
 	m := &ServiceManager{
 		state: st,
+		// the first check happens once services had a chance to start
+		nextRestartsCheck: timeNow().Add(restartsCheckInterval),
 	}
 	// TODO: undo handler
 	runner.AddHandler("service-control", m.doServiceControl, nil)
@@ -204,6 +212,9 @@ func (m *ServiceManager) Ensure() error {
 	if err := m.ensureSnapServicesUpdated(); err != nil {
 		return err
 	}
+	if err := m.ensureRestartsTracked(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -212,6 +223,9 @@ func delayedCrossMgrInit() {
 	snapstate.RegisterAffectedSnapsByAttr("service-action", serviceControlAffectedSnaps)
 	snapstate.SnapServiceOptions = SnapServiceOptions
 	snapstate.EnsureSnapAbsentFromQuotaGroup = EnsureSnapAbsentFromQuota
+	snapstate.DiscardServiceEnvironment = DiscardServiceEnvironment
+	snapstate.ApplyResourceHints = applyResourceHints
+	snapstate.UndoResourceHints = undoResourceHints
 }
 
 func serviceControlAffectedSnaps(t *state.Task) ([]string, error) {
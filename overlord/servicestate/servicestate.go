@@ -33,14 +33,17 @@ import (
 
 	"github.com/snapcore/snapd/client"
 	"github.com/snapcore/snapd/client/clientutil"
+	"github.com/snapcore/snapd/interfaces"
 	"github.com/snapcore/snapd/overlord/cmdstate"
 	"github.com/snapcore/snapd/overlord/configstate/config"
 	"github.com/snapcore/snapd/overlord/hookstate"
+	"github.com/snapcore/snapd/overlord/ifacestate/schema"
 	"github.com/snapcore/snapd/overlord/snapstate"
 	"github.com/snapcore/snapd/overlord/state"
 	"github.com/snapcore/snapd/progress"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/quota"
+	"github.com/snapcore/snapd/snapdenv"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/systemd"
 	usc "github.com/snapcore/snapd/usersession/client"
@@ -178,6 +181,10 @@ func serviceControlTs(st *state.State, appInfos []*snap.AppInfo, inst *Instructi
 	}
 	sort.Strings(sortedNames)
 
+	if inst.Rolling && inst.Action != "restart" {
+		return nil, fmt.Errorf("rolling is only supported for the restart action, not %q", inst.Action)
+	}
+
 	ts := state.NewTaskSet()
 	var prev *state.Task
 	for _, snapName := range sortedNames {
@@ -214,6 +221,9 @@ func serviceControlTs(st *state.State, appInfos []*snap.AppInfo, inst *Instructi
 			}
 		case inst.Action == "restart":
 			cmd.RestartEnabledNonActive = true
+			// restarting one snap at a time and waiting for its
+			// services to be ready before moving on to the next one
+			cmd.WaitReady = inst.Rolling
 			if inst.Reload {
 				cmd.Action = "reload-or-restart"
 			} else {
@@ -244,6 +254,9 @@ func serviceControlTs(st *state.State, appInfos []*snap.AppInfo, inst *Instructi
 		if summary == "" {
 			summary = fmt.Sprintf("Run service command %q for services %q of snap %q", cmd.Action, svcs, cmd.SnapName)
 		}
+		if cmd.WaitReady {
+			summary += " and wait for them to be ready"
+		}
 		task := st.NewTask("service-control", summary)
 		task.Set("service-action", cmd)
 		if prev != nil {
@@ -478,6 +491,9 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 	if snapApp.Timer != nil {
 		extra++
 	}
+	if len(snapApp.ActivatesOnPath) > 0 {
+		extra++
+	}
 	serviceNames := make([]string, 0, 1+extra)
 	serviceNames = append(serviceNames, snapApp.ServiceName())
 
@@ -491,6 +507,10 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 		timerUnit := filepath.Base(snapApp.Timer.File())
 		serviceNames = append(serviceNames, timerUnit)
 	}
+	if len(snapApp.ActivatesOnPath) > 0 {
+		pathUnit := filepath.Base(snapApp.PathFile())
+		serviceNames = append(serviceNames, pathUnit)
+	}
 
 	sts, err := sd.queryServiceStatus(snapApp.DaemonScope, serviceNames)
 	if err != nil {
@@ -516,6 +536,13 @@ func (sd *StatusDecorator) DecorateWithStatus(appInfo *client.AppInfo, snapApp *
 				Active:  st.Active,
 				Type:    "socket",
 			})
+		case ".path":
+			appInfo.Activators = append(appInfo.Activators, client.AppActivator{
+				Name:    snapApp.Name,
+				Enabled: st.Enabled,
+				Active:  st.Active,
+				Type:    "path",
+			})
 		}
 	}
 	// Decorate with D-Bus names that activate this service
@@ -583,13 +610,136 @@ func SnapServiceOptions(st *state.State, snapInfo *snap.Info, quotaGroups map[st
 		}
 	}
 
+	deps, err := externalServiceDependencies(st, snapInfo)
+	if err != nil {
+		return nil, err
+	}
+	opts.ExternalDependencies = deps
+
+	env, err := ServiceEnvironment(st, snapInfo.InstanceName())
+	if err != nil {
+		return nil, err
+	}
+	opts.EnvironmentOverrides = env
+
 	return opts, nil
 }
 
+// externalServiceDependencies returns, for each service of the snap declaring
+// after-plugs, the service units of the slot providers connected to those
+// plugs. Only services of the same daemon scope are considered.
+func externalServiceDependencies(st *state.State, snapInfo *snap.Info) (map[string][]string, error) {
+	var plugsWithOrdering []string
+	for _, app := range snapInfo.Apps {
+		if app.IsService() {
+			plugsWithOrdering = append(plugsWithOrdering, app.AfterPlugs...)
+		}
+	}
+	if len(plugsWithOrdering) == 0 {
+		return nil, nil
+	}
+
+	var conns map[string]*schema.ConnState
+	if err := st.Get("conns", &conns); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, fmt.Errorf("cannot obtain data about existing connections: %v", err)
+	}
+
+	// slots connected to each plug of the snap
+	slotsByPlug := make(map[string][]interfaces.SlotRef)
+	for id, cstate := range conns {
+		if cstate.Undesired || cstate.HotplugGone {
+			continue
+		}
+		connRef, err := interfaces.ParseConnRef(id)
+		if err != nil {
+			return nil, err
+		}
+		if connRef.PlugRef.Snap != snapInfo.InstanceName() || !strutil.ListContains(plugsWithOrdering, connRef.PlugRef.Name) {
+			continue
+		}
+		slotsByPlug[connRef.PlugRef.Name] = append(slotsByPlug[connRef.PlugRef.Name], connRef.SlotRef)
+	}
+
+	var deps map[string][]string
+	for _, app := range snapInfo.Apps {
+		if !app.IsService() || len(app.AfterPlugs) == 0 {
+			continue
+		}
+		var units []string
+		for _, plugName := range app.AfterPlugs {
+			for _, slotRef := range slotsByPlug[plugName] {
+				if slotRef.Snap == snapInfo.InstanceName() {
+					// ordering within the snap is expressed with after:
+					continue
+				}
+				slotInfo, err := snapstate.CurrentInfo(st, slotRef.Snap)
+				if err != nil {
+					if _, ok := err.(*snap.NotInstalledError); ok {
+						continue
+					}
+					return nil, err
+				}
+				slot := slotInfo.Slots[slotRef.Name]
+				if slot == nil {
+					continue
+				}
+				for _, slotApp := range slot.Apps {
+					if !slotApp.IsService() || slotApp.DaemonScope != app.DaemonScope {
+						continue
+					}
+					units = append(units, slotApp.ServiceName())
+				}
+			}
+		}
+		if len(units) == 0 {
+			continue
+		}
+		sort.Strings(units)
+		if deps == nil {
+			deps = make(map[string][]string)
+		}
+		deps[app.Name] = strutil.Deduplicate(units)
+	}
+	return deps, nil
+}
+
+// EnsureSnapServiceDependencies regenerates the service units of the given
+// snap so that their ordering reflects the current connections of plugs
+// referenced by after-plugs. Running services are not restarted, the new
+// ordering takes effect the next time they are started.
+func EnsureSnapServiceDependencies(st *state.State, info *snap.Info) error {
+	return ensureSnapServiceUnits(st, info)
+}
+
+// ensureSnapServiceUnits regenerates the service units of the given snap
+// from its current service options.
+func ensureSnapServiceUnits(st *state.State, info *snap.Info) error {
+	snapSvcOpts, err := SnapServiceOptions(st, info, nil)
+	if err != nil {
+		return err
+	}
+
+	ensureOpts := &wrappers.EnsureSnapServicesOptions{
+		Preseeding: snapdenv.Preseeding(),
+	}
+
+	// set RequireMountedSnapdSnap if we are on UC18+ only
+	deviceCtx, err := snapstate.DeviceCtx(st, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !deviceCtx.Classic() && deviceCtx.Model().Base() != "" {
+		ensureOpts.RequireMountedSnapdSnap = true
+	}
+
+	snaps := map[*snap.Info]*wrappers.SnapServiceOptions{info: snapSvcOpts}
+	return wrappers.EnsureSnapServices(snaps, ensureOpts, nil, progress.Null)
+}
+
 // LogReader returns an io.ReadCloser which produce logs for the provided
-// snap AppInfo's. It is a convenience wrapper around the systemd.LogReader
-// implementation.
-func LogReader(appInfos []*snap.AppInfo, n int, follow bool) (io.ReadCloser, error) {
+// snap AppInfo's, restricted to the entries matching query if it is not nil.
+// It is a convenience wrapper around the systemd.LogReader implementation.
+func LogReader(appInfos []*snap.AppInfo, n int, follow bool, query *systemd.LogQuery) (io.ReadCloser, error) {
 	serviceNames := make([]string, len(appInfos))
 	for i, appInfo := range appInfos {
 		if !appInfo.IsService() {
@@ -608,6 +758,12 @@ func LogReader(appInfos []*snap.AppInfo, n int, follow bool) (io.ReadCloser, err
 		return nil, fmt.Errorf("cannot get systemd version: %v", err)
 	}
 
+	if query != nil {
+		if err := query.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	sysd := systemd.New(systemd.SystemMode, progress.Null)
-	return sysd.LogReader(serviceNames, n, follow, includeNamespaces)
+	return sysd.LogReader(serviceNames, n, follow, includeNamespaces, query)
 }
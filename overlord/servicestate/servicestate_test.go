@@ -103,7 +103,7 @@ func (s *statusDecoratorSuite) TestDecorateWithStatus(c *C) {
 				activeState = "inactive"
 				unitState = "disabled"
 			}
-			if strings.HasSuffix(unit, ".timer") || strings.HasSuffix(unit, ".socket") || strings.HasSuffix(unit, ".target") {
+			if strings.HasSuffix(unit, ".timer") || strings.HasSuffix(unit, ".socket") || strings.HasSuffix(unit, ".target") || strings.HasSuffix(unit, ".path") {
 				// Units using the baseProperties query
 				return []byte(fmt.Sprintf(`Id=%s
 Names=%[1]s
@@ -193,6 +193,30 @@ NeedDaemonReload=no
 			{Name: "svc", Type: "timer", Active: enabled, Enabled: enabled},
 		})
 
+		// service + path
+		app = &client.AppInfo{
+			Snap:   snp.InstanceName(),
+			Name:   "svc",
+			Daemon: "simple",
+		}
+		snapApp = &snap.AppInfo{
+			Snap:        snp,
+			Name:        "svc",
+			Daemon:      "simple",
+			DaemonScope: snap.SystemDaemon,
+		}
+		snapApp.ActivatesOnPath = []*snap.PathInfo{
+			{App: snapApp, Path: "$SNAP_COMMON/inbox", Trigger: "directory-not-empty"},
+		}
+
+		err = sd.DecorateWithStatus(app, snapApp)
+		c.Assert(err, IsNil)
+		c.Check(app.Active, Equals, enabled)
+		c.Check(app.Enabled, Equals, enabled)
+		c.Check(app.Activators, DeepEquals, []client.AppActivator{
+			{Name: "svc", Type: "path", Active: enabled, Enabled: enabled},
+		})
+
 		// service with socket
 		app = &client.AppInfo{
 			Snap:   snp.InstanceName(),
@@ -705,6 +729,83 @@ version: 0
 	})
 }
 
+func (s *snapServiceOptionsSuite) TestSnapServiceOptionsExternalDependencies(c *C) {
+	dirs.SetRootDir(c.MkDir())
+	defer dirs.SetRootDir("")
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	appInfo := snaptest.MockInfo(c, `
+name: app
+version: 0
+plugs:
+ db:
+  interface: content
+ cache:
+  interface: content
+apps:
+ web:
+  daemon: simple
+  after-plugs: [db, cache]
+ worker:
+  daemon: simple
+ cli:
+  command: bin/cli
+`, nil)
+
+	dbSideInfo := &snap.SideInfo{RealName: "db", Revision: snap.R(1)}
+	snaptest.MockSnap(c, `
+name: db
+version: 1
+slots:
+ db:
+  interface: content
+  read: [$SNAP_DATA/socket]
+apps:
+ server:
+  daemon: simple
+ user-agent:
+  daemon: simple
+  daemon-scope: user
+ cli:
+  command: bin/cli
+`, dbSideInfo)
+	snapstate.Set(st, "db", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{dbSideInfo}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	// no connections, no dependencies
+	opts, err := servicestate.SnapServiceOptions(st, appInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{})
+
+	st.Set("conns", map[string]interface{}{
+		"app:db db:db":            map[string]interface{}{"interface": "content"},
+		"app:cache missing:cache": map[string]interface{}{"interface": "content"},
+		"other:db db:db":          map[string]interface{}{"interface": "content"},
+	})
+	opts, err = servicestate.SnapServiceOptions(st, appInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{
+		ExternalDependencies: map[string][]string{
+			"web": {"snap.db.server.service"},
+		},
+	})
+
+	// undesired connections are ignored
+	st.Set("conns", map[string]interface{}{
+		"app:db db:db": map[string]interface{}{"interface": "content", "undesired": true},
+	})
+	opts, err = servicestate.SnapServiceOptions(st, appInfo, nil)
+	c.Assert(err, IsNil)
+	c.Check(opts, DeepEquals, &wrappers.SnapServiceOptions{})
+}
+
 func (s *snapServiceOptionsSuite) TestServiceControlTaskSummaries(c *C) {
 	st := s.state
 	st.Lock()
@@ -948,7 +1049,7 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 	defer restore()
 
 	var jctlCalls int
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool, query *systemd.LogQuery) (rc io.ReadCloser, err error) {
 		jctlCalls++
 		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
 		c.Check(n, Equals, 100)
@@ -958,9 +1059,37 @@ func (s *snapServiceOptionsSuite) TestLogReader(c *C) {
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, nil)
+	c.Assert(err, IsNil)
+	c.Check(jctlCalls, Equals, 1)
+}
+
+func (s *snapServiceOptionsSuite) TestLogReaderQuery(c *C) {
+	si := snap.SideInfo{RealName: "foo", Revision: snap.R(1)}
+	snp := &snap.Info{SideInfo: si}
+	appInfos := []*snap.AppInfo{{Snap: snp, Name: "svc1", Daemon: "simple"}}
+
+	restore := systemd.MockSystemdVersion(245, nil)
+	defer restore()
+
+	query := &systemd.LogQuery{Priority: "err", AfterCursor: "s=abc;i=1"}
+	var jctlCalls int
+	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool, q *systemd.LogQuery) (rc io.ReadCloser, err error) {
+		jctlCalls++
+		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service"})
+		c.Check(n, Equals, -1)
+		c.Check(q, Equals, query)
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+	defer restore()
+
+	_, err := servicestate.LogReader(appInfos, -1, false, query)
 	c.Assert(err, IsNil)
 	c.Check(jctlCalls, Equals, 1)
+
+	_, err = servicestate.LogReader(appInfos, -1, false, &systemd.LogQuery{Priority: "loud"})
+	c.Assert(err, ErrorMatches, `invalid log priority "loud"`)
+	c.Check(jctlCalls, Equals, 1)
 }
 
 func (s *snapServiceOptionsSuite) TestLogReaderFailsWithNonServices(c *C) {
@@ -990,7 +1119,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderFailsWithNonServices(c *C) {
 		},
 	}
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, nil)
 	c.Assert(err.Error(), Equals, `cannot read logs for app "app1": not a service`)
 }
 
@@ -1026,7 +1155,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 
 	restore := systemd.MockSystemdVersion(245, nil)
 	defer restore()
-	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool) (rc io.ReadCloser, err error) {
+	restore = systemd.MockJournalctl(func(svcs []string, n int, follow, namespaces bool, query *systemd.LogQuery) (rc io.ReadCloser, err error) {
 		jctlCalls++
 		c.Check(svcs, DeepEquals, []string{"snap.foo.svc1.service", "snap.foo.svc2.service"})
 		c.Check(n, Equals, 100)
@@ -1036,7 +1165,7 @@ func (s *snapServiceOptionsSuite) TestLogReaderNamespaces(c *C) {
 	})
 	defer restore()
 
-	_, err := servicestate.LogReader(appInfos, 100, false)
+	_, err := servicestate.LogReader(appInfos, 100, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(jctlCalls, Equals, 1)
 }
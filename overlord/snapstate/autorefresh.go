@@ -83,6 +83,14 @@ type refreshCandidate struct {
 	// Monitored signals whether this snap is currently being monitored for closure
 	// so its auto-refresh can be continued.
 	Monitored bool `json:"monitored,omitempty"`
+	// Window is the refresh window the auto-refresh of this snap is
+	// restricted to, if any.
+	Window string `json:"window,omitempty"`
+	// RolloutSince is when the staged rollout of this revision started
+	// for this device, if refresh.rollout is set. It is when this device
+	// first saw the revision, the store does not tell when it was
+	// released.
+	RolloutSince time.Time `json:"rollout-since,omitempty"`
 }
 
 func (rc *refreshCandidate) Type() snap.Type {
@@ -393,7 +401,12 @@ func (m *autoRefresh) Ensure() (err error) {
 				return nil
 			}
 
-			err = m.launchAutoRefresh()
+			// open refresh windows get served by this auto-refresh too
+			openWindows, err := openRefreshWindows(m.state, timeNow())
+			if err != nil {
+				return err
+			}
+			err = m.launchAutoRefresh(false)
 			if _, ok := err.(*httputil.PersistentNetworkError); ok {
 				// refresh will be retried after refreshRetryDelay
 				return err
@@ -401,15 +414,46 @@ func (m *autoRefresh) Ensure() (err error) {
 				// ignore error, retry the auto-refresh later
 				return nil
 			}
+			if markErr := markRefreshWindowsServed(m.state, openWindows, timeNow()); markErr != nil {
+				return markErr
+			}
 
 			// refreshed or hit an non-persistent network error, so reset nextRefresh
 			m.nextRefresh = time.Time{}
+			return err
 		}
+
+		return m.serveRefreshWindows(now, lastRefresh)
 	}
 
 	return err
 }
 
+// serveRefreshWindows launches an auto-refresh of the snaps in refresh
+// windows that just opened, outside of the refresh.timer schedule.
+func (m *autoRefresh) serveRefreshWindows(now, lastRefresh time.Time) error {
+	openWindows, err := openRefreshWindows(m.state, timeNow())
+	if err != nil || len(openWindows) == 0 {
+		return err
+	}
+	can, err := m.canRefreshRespectingMetered(now, lastRefresh)
+	if err != nil || !can {
+		return err
+	}
+
+	logger.Debugf("Refresh windows %s are open.", strings.Join(openWindows, ", "))
+	err = m.launchAutoRefresh(true)
+	if _, ok := err.(*httputil.PersistentNetworkError); ok {
+		return err
+	} else if errors.Is(err, tooSoonError{}) {
+		return nil
+	}
+	if markErr := markRefreshWindowsServed(m.state, openWindows, timeNow()); markErr != nil {
+		return markErr
+	}
+	return err
+}
+
 func (m *autoRefresh) restoreMonitoring() error {
 	if m.restoredMonitoring {
 		return nil
@@ -579,7 +623,9 @@ func (tooSoonError) Is(err error) bool {
 }
 
 // launchAutoRefresh creates the auto-refresh taskset and a change for it.
-func (m *autoRefresh) launchAutoRefresh() error {
+// If windowsOnly is set, only the snaps in open refresh windows are
+// considered.
+func (m *autoRefresh) launchAutoRefresh(windowsOnly bool) error {
 	// Check that we have reasonable delays between attempts.
 	// If the store is under stress we need to make sure we do not
 	// hammer it too often
@@ -597,8 +643,12 @@ func (m *autoRefresh) launchAutoRefresh() error {
 		perfTimings.Save(m.state)
 	}()
 
+	ctx := auth.EnsureContextTODO()
+	if windowsOnly {
+		ctx = withRefreshWindowsOnly(ctx)
+	}
 	// NOTE: this will unlock and re-lock state for network ops
-	updated, updateTss, err := AutoRefresh(auth.EnsureContextTODO(), m.state)
+	updated, updateTss, err := AutoRefresh(ctx, m.state)
 
 	// TODO: we should have some way to lock just creating and starting changes,
 	//       as that would alleviate this race condition we are guarding against
@@ -623,7 +673,11 @@ func (m *autoRefresh) launchAutoRefresh() error {
 		logger.Noticef("Cannot prepare auto-refresh change due to a permanent network error: %s", err)
 		return err
 	}
-	m.state.Set("last-refresh", timeNow())
+	// refreshes of refresh windows do not move the refresh.timer
+	// schedule
+	if !windowsOnly {
+		m.state.Set("last-refresh", timeNow())
+	}
 	if err != nil {
 		logger.Noticef("Cannot prepare auto-refresh change: %s", err)
 		return err
@@ -1825,6 +1825,59 @@ func (s *autorefreshGatingSuite) TestAutoRefreshPhase1ConflictsFilteredOut(c *C)
 	c.Check(candidates["snap-c"], NotNil)
 }
 
+func (s *autorefreshGatingSuite) TestAutoRefreshPhase1ClosedRefreshWindowFilteredOut(c *C) {
+	s.store.refreshedSnaps = []*snap.Info{{
+		Architectures: []string{"all"},
+		SnapType:      snap.TypeApp,
+		SideInfo: snap.SideInfo{
+			RealName: "snap-a",
+			Revision: snap.R(8),
+		},
+	}, {
+		Architectures: []string{"all"},
+		SnapType:      snap.TypeBase,
+		SideInfo: snap.SideInfo{
+			RealName: "snap-c",
+			Revision: snap.R(5),
+		},
+	}}
+
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	mockInstalledSnap(c, s.state, snapAyaml, useHook)
+	mockInstalledSnap(c, s.state, snapCyaml, noHook)
+
+	tr := config.NewTransaction(st)
+	tr.Set("core", "refresh.windows", map[string]interface{}{
+		"bases": map[string]interface{}{"snaps": []string{"snap-c"}, "schedule": "sun,02:00-04:00"},
+	})
+	tr.Commit()
+
+	// a Wednesday
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return time.Date(2024, 5, 15, 10, 0, 0, 0, time.Local)
+	})
+	defer restore()
+	restore = snapstatetest.MockDeviceModel(DefaultModel())
+	defer restore()
+
+	names, tss, err := snapstate.AutoRefreshPhase1(context.TODO(), st, "")
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"snap-a"})
+	c.Assert(tss, HasLen, 2)
+
+	// snap-c stays a candidate for when its window opens
+	var candidates map[string]*snapstate.RefreshCandidate
+	c.Assert(st.Get("refresh-candidates", &candidates), IsNil)
+	c.Assert(candidates, HasLen, 2)
+	c.Assert(candidates["snap-c"], NotNil)
+	c.Check(candidates["snap-c"].Window, Equals, "bases")
+	c.Assert(candidates["snap-a"], NotNil)
+	c.Check(candidates["snap-a"].Window, Equals, "")
+}
+
 func (s *autorefreshGatingSuite) TestAutoRefreshPhase1NoHooks(c *C) {
 	s.store.refreshedSnaps = []*snap.Info{{
 		Architectures: []string{"all"},
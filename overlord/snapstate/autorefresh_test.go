@@ -1191,6 +1191,366 @@ func (s *autoRefreshTestSuite) addRefreshableSnap(names ...string) {
 	}
 }
 
+func (s *autoRefreshTestSuite) setRefreshWindows(windows map[string]interface{}) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.windows", windows)
+	tr.Commit()
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshPostponesClosedRefreshWindows(c *C) {
+	s.addRefreshableSnap("foo", "bar")
+	s.setRefreshWindows(map[string]interface{}{
+		"databases": map[string]interface{}{"snaps": []string{"foo"}, "schedule": "sun,02:00-04:00"},
+	})
+	// a Wednesday
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return time.Date(2024, 5, 15, 10, 0, 0, 0, time.Local)
+	})
+	defer restore()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	c.Assert(chgs[0].Kind(), Equals, "auto-refresh")
+	var names []string
+	c.Assert(chgs[0].Get("snap-names", &names), IsNil)
+	c.Check(names, DeepEquals, []string{"bar"})
+
+	// foo is still a candidate, waiting for its window
+	var candidates map[string]*snapstate.RefreshCandidate
+	c.Assert(s.state.Get("refresh-candidates", &candidates), IsNil)
+	c.Assert(candidates["foo"], NotNil)
+	c.Check(candidates["foo"].Window, Equals, "databases")
+	c.Assert(candidates["bar"], NotNil)
+	c.Check(candidates["bar"].Window, Equals, "")
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshServesOpenRefreshWindowsOnce(c *C) {
+	s.addRefreshableSnap("foo", "bar")
+	s.setRefreshWindows(map[string]interface{}{
+		"databases": map[string]interface{}{"snaps": []string{"foo"}, "schedule": "sun,02:00-04:00"},
+	})
+
+	lastRefresh := time.Now()
+	s.state.Lock()
+	s.state.Set("last-refresh", lastRefresh)
+	s.state.Set("refresh-candidates", map[string]*snapstate.RefreshCandidate{
+		"foo": {SnapSetup: snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(8)}}},
+		"bar": {SnapSetup: snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "bar", Revision: snap.R(8)}}},
+	})
+	s.state.Unlock()
+
+	// a Sunday, inside the window
+	now := time.Date(2024, 5, 19, 3, 0, 0, 0, time.Local)
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	restore = timeutil.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	err := af.Ensure()
+	c.Check(err, IsNil)
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
+
+	s.state.Lock()
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	c.Assert(chgs[0].Kind(), Equals, "auto-refresh")
+	var names []string
+	c.Assert(chgs[0].Get("snap-names", &names), IsNil)
+	// only the snaps in the open window
+	c.Check(names, DeepEquals, []string{"foo"})
+	chgs[0].SetStatus(state.DoneStatus)
+
+	// the refresh.timer schedule is not affected
+	var last time.Time
+	c.Assert(s.state.Get("last-refresh", &last), IsNil)
+	c.Check(last.Equal(lastRefresh), Equals, true)
+
+	var served map[string]time.Time
+	c.Assert(s.state.Get("refresh-windows-served", &served), IsNil)
+	c.Check(served["databases"].Equal(now), Equals, true)
+
+	// foo still has an update, as the refresh was not carried out
+	s.state.Set("refresh-candidates", map[string]*snapstate.RefreshCandidate{
+		"foo": {SnapSetup: snapstate.SnapSetup{SideInfo: &snap.SideInfo{RealName: "foo", Revision: snap.R(8)}}},
+	})
+	s.state.Unlock()
+
+	// the window was served already
+	now = now.Add(30 * time.Minute)
+	af = snapstate.NewAutoRefresh(s.state)
+	c.Check(af.Ensure(), IsNil)
+	c.Check(s.store.ops, HasLen, 1)
+
+	// but it is served again when it opens the next week
+	now = now.Add(7 * 24 * time.Hour)
+	af = snapstate.NewAutoRefresh(s.state)
+	c.Check(af.Ensure(), IsNil)
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh", "list-refresh"})
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshRefreshWindowsNoCandidates(c *C) {
+	s.addRefreshableSnap("foo")
+	s.setRefreshWindows(map[string]interface{}{
+		"databases": map[string]interface{}{"snaps": []string{"foo"}, "schedule": "sun,02:00-04:00"},
+	})
+
+	// the update of foo was published after the last refresh hints
+	s.state.Lock()
+	s.state.Set("last-refresh", time.Now())
+	s.state.Unlock()
+
+	now := time.Date(2024, 5, 19, 3, 0, 0, 0, time.Local)
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	c.Check(af.Ensure(), IsNil)
+	c.Check(s.store.ops, DeepEquals, []string{"list-refresh"})
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	chgs := s.state.Changes()
+	c.Assert(chgs, HasLen, 1)
+	var names []string
+	c.Assert(chgs[0].Get("snap-names", &names), IsNil)
+	c.Check(names, DeepEquals, []string{"foo"})
+
+	// querying the store refreshed the candidates
+	var candidates map[string]*snapstate.RefreshCandidate
+	c.Assert(s.state.Get("refresh-candidates", &candidates), IsNil)
+	c.Check(candidates["foo"], NotNil)
+
+	var served map[string]time.Time
+	c.Assert(s.state.Get("refresh-windows-served", &served), IsNil)
+	c.Check(served["databases"].Equal(now), Equals, true)
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshRefreshWindowsNotInstalled(c *C) {
+	s.addRefreshableSnap("foo")
+	s.setRefreshWindows(map[string]interface{}{
+		"databases": map[string]interface{}{"snaps": []string{"bar"}, "schedule": "sun,02:00-04:00"},
+	})
+
+	s.state.Lock()
+	s.state.Set("last-refresh", time.Now())
+	s.state.Unlock()
+
+	restore := snapstate.MockTimeNow(func() time.Time {
+		return time.Date(2024, 5, 19, 3, 0, 0, 0, time.Local)
+	})
+	defer restore()
+
+	af := snapstate.NewAutoRefresh(s.state)
+	c.Check(af.Ensure(), IsNil)
+	c.Check(s.store.ops, HasLen, 0)
+}
+
+func (s *autoRefreshTestSuite) TestRefreshWindowsInfo(c *C) {
+	s.setRefreshWindows(map[string]interface{}{
+		"web":       map[string]interface{}{"snaps": []string{"nginx"}, "schedule": "mon-fri,22:00-23:00"},
+		"databases": map[string]interface{}{"snaps": []string{"postgres", "redis"}, "schedule": "sun,02:00-04:00"},
+	})
+	// a Wednesday
+	now := time.Date(2024, 5, 15, 22, 30, 0, 0, time.Local)
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+	restore = timeutil.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	infos, err := snapstate.RefreshWindowsInfo(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 2)
+	c.Check(infos[0].Name, Equals, "databases")
+	c.Check(infos[0].Snaps, DeepEquals, []string{"postgres", "redis"})
+	c.Check(infos[0].Schedule, Equals, "sun,02:00-04:00")
+	c.Check(infos[0].Open, Equals, false)
+	c.Check(infos[0].Next.Equal(time.Date(2024, 5, 19, 2, 0, 0, 0, time.Local)), Equals, true, Commentf("%v", infos[0].Next))
+	c.Check(infos[1].Name, Equals, "web")
+	c.Check(infos[1].Open, Equals, true)
+	c.Check(infos[1].Next.Equal(time.Date(2024, 5, 16, 22, 0, 0, 0, time.Local)), Equals, true, Commentf("%v", infos[1].Next))
+}
+
+func (s *autoRefreshTestSuite) TestRolloutCohort(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	serial := "serial-1"
+	restore := snapstate.MockDeviceSerial(func(*state.State) (string, error) { return serial, nil })
+	defer restore()
+
+	cohort1, err := snapstate.RolloutCohort(s.state)
+	c.Assert(err, IsNil)
+	c.Check(cohort1 >= 0 && cohort1 < 100, Equals, true)
+	again, err := snapstate.RolloutCohort(s.state)
+	c.Assert(err, IsNil)
+	c.Check(again, Equals, cohort1)
+
+	seen := map[int]bool{cohort1: true}
+	for i := 2; i < 10; i++ {
+		serial = fmt.Sprintf("serial-%d", i)
+		cohort, err := snapstate.RolloutCohort(s.state)
+		c.Assert(err, IsNil)
+		seen[cohort] = true
+	}
+	c.Check(len(seen) > 1, Equals, true)
+
+	// without a serial the refresh privacy key is used
+	serial = ""
+	cohort, err := snapstate.RolloutCohort(s.state)
+	c.Assert(err, IsNil)
+	s.state.Set("refresh-privacy-key", "other-privacy-key")
+	otherCohort, err := snapstate.RolloutCohort(s.state)
+	c.Assert(err, IsNil)
+	c.Check(otherCohort, Not(Equals), cohort)
+}
+
+func (s *autoRefreshTestSuite) setStagedRollout(c *C, rollout func(cohort int) string) {
+	restore := snapstate.MockDeviceSerial(func(*state.State) (string, error) { return "serial-1", nil })
+	s.AddCleanup(restore)
+
+	cohort, err := snapstate.RolloutCohort(s.state)
+	c.Assert(err, IsNil)
+	c.Assert(cohort > 0 && cohort < 99, Equals, true, Commentf("unsuitable cohort %d", cohort))
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rollout", rollout(cohort))
+	tr.Commit()
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshStagedRollout(c *C) {
+	s.addRefreshableSnap("foo")
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	// the first day of the rollout doesn't reach this device, the second
+	// one does
+	s.setStagedRollout(c, func(cohort int) string { return fmt.Sprintf("%d,100", cohort) })
+
+	t0 := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	now := t0
+	restore := snapstate.MockTimeNow(func() time.Time { return now })
+	defer restore()
+
+	updated, _, err := snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, HasLen, 0)
+
+	var candidates map[string]*snapstate.RefreshCandidate
+	c.Assert(s.state.Get("refresh-candidates", &candidates), IsNil)
+	c.Assert(candidates["foo"], NotNil)
+	c.Check(candidates["foo"].RolloutSince.Equal(t0), Equals, true)
+
+	// still the first day
+	now = t0.Add(23 * time.Hour)
+	updated, _, err = snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, HasLen, 0)
+
+	now = t0.Add(25 * time.Hour)
+	updated, _, err = snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, DeepEquals, []string{"foo"})
+
+	c.Assert(s.state.Get("refresh-candidates", &candidates), IsNil)
+	c.Assert(candidates["foo"], NotNil)
+	c.Check(candidates["foo"].RolloutSince.Equal(t0), Equals, true)
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshStagedRolloutReached(c *C) {
+	s.addRefreshableSnap("foo")
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setStagedRollout(c, func(cohort int) string { return fmt.Sprintf("%d,100", cohort+1) })
+
+	updated, _, err := snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, DeepEquals, []string{"foo"})
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshStagedRolloutHalted(c *C) {
+	s.addRefreshableSnap("foo")
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setStagedRollout(c, func(int) string { return "100" })
+
+	var unhealthy []string
+	restore := snapstate.MockUnhealthySnaps(func(*state.State) ([]string, error) { return unhealthy, nil })
+	defer restore()
+
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rollout-halt", true)
+	tr.Commit()
+
+	for i := 0; i < 2; i++ {
+		updated, _, err := snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+		c.Assert(err, IsNil)
+		c.Check(updated, HasLen, 0)
+	}
+	// the halt is logged only once
+	c.Check(strings.Count(logbuf.String(), `auto-refresh of snap "foo" held: staged rollout halted as refresh.rollout-halt is set`), Equals, 1)
+
+	tr = config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rollout-halt", false)
+	tr.Commit()
+	unhealthy = []string{"foo"}
+
+	updated, _, err := snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, HasLen, 0)
+	c.Check(logbuf.String(), testutil.Contains, `auto-refresh of snap "foo" held: staged rollout halted as health checks of "foo" report errors`)
+
+	// unrelated snaps being unhealthy do not halt the rollout
+	unhealthy = []string{"bar"}
+	updated, _, err = snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, DeepEquals, []string{"foo"})
+}
+
+func (s *autoRefreshTestSuite) TestAutoRefreshStagedRolloutHaltedUnhealthyBase(c *C) {
+	s.addRefreshableSnap("foo")
+	s.store.refreshable[0].Base = "core22"
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	s.setStagedRollout(c, func(int) string { return "100" })
+
+	restore := snapstate.MockUnhealthySnaps(func(*state.State) ([]string, error) { return []string{"bar", "core22"}, nil })
+	defer restore()
+
+	logbuf, restore := logger.MockLogger()
+	defer restore()
+
+	updated, _, err := snapstate.AutoRefresh(auth.EnsureContextTODO(), s.state)
+	c.Assert(err, IsNil)
+	c.Check(updated, HasLen, 0)
+	c.Check(logbuf.String(), testutil.Contains, `auto-refresh of snap "foo" held: staged rollout halted as health checks of "core22" report errors`)
+}
+
 func (s *autoRefreshTestSuite) TestTooSoonError(c *C) {
 	c.Check(snapstate.TooSoonError{}, testutil.ErrorIs, snapstate.TooSoonError{})
 	c.Check(snapstate.TooSoonError{}, Not(testutil.ErrorIs), errors.New(""))
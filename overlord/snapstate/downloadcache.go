@@ -0,0 +1,69 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+)
+
+// DownloadCachePins returns the sha3-384 digests of the snaps which must be
+// kept in the download cache, with the reason why: the snaps of pending
+// refreshes and the snaps downloaded by changes in progress, which may be
+// retried or undone. The blobs of the retained revisions, which reverts
+// use, are linked from the snaps directory and take no space in the cache.
+// It returns nil if nothing is pinned and must be called with the state
+// locked.
+func DownloadCachePins(st *state.State) map[string]string {
+	pins := make(map[string]string)
+
+	var candidates map[string]*refreshCandidate
+	if err := st.Get("refresh-candidates", &candidates); err != nil && !errors.Is(err, state.ErrNoState) {
+		logger.Noticef("cannot get refresh candidates: %v", err)
+	}
+	for name, cand := range candidates {
+		if cand.DownloadInfo != nil && cand.DownloadInfo.Sha3_384 != "" {
+			pins[cand.DownloadInfo.Sha3_384] = fmt.Sprintf("pending refresh of %q", name)
+		}
+	}
+
+	for _, chg := range st.Changes() {
+		if chg.Status().Ready() {
+			continue
+		}
+		for _, t := range chg.Tasks() {
+			if t.Kind() != "download-snap" && t.Kind() != "pre-download-snap" {
+				continue
+			}
+			snapsup, err := TaskSnapSetup(t)
+			if err != nil || snapsup.DownloadInfo == nil || snapsup.DownloadInfo.Sha3_384 == "" {
+				continue
+			}
+			pins[snapsup.DownloadInfo.Sha3_384] = fmt.Sprintf("change %s", chg.ID())
+		}
+	}
+	if len(pins) == 0 {
+		return nil
+	}
+	return pins
+}
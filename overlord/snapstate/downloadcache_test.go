@@ -0,0 +1,72 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+type downloadCacheSuite struct {
+	state *state.State
+}
+
+var _ = Suite(&downloadCacheSuite{})
+
+func (s *downloadCacheSuite) SetUpTest(c *C) {
+	s.state = state.New(nil)
+}
+
+func (s *downloadCacheSuite) TestDownloadCachePins(c *C) {
+	st := s.state
+	st.Lock()
+	defer st.Unlock()
+
+	c.Check(snapstate.DownloadCachePins(st), HasLen, 0)
+
+	candidates := map[string]*snapstate.RefreshCandidate{
+		"foo": {SnapSetup: snapstate.SnapSetup{DownloadInfo: &snap.DownloadInfo{Sha3_384: "foo-sha3"}}},
+		// no download info yet
+		"bar": {},
+	}
+	st.Set("refresh-candidates", &candidates)
+
+	chg := st.NewChange("refresh", "...")
+	t := st.NewTask("download-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{DownloadInfo: &snap.DownloadInfo{Sha3_384: "baz-sha3"}})
+	chg.AddTask(t)
+	t = st.NewTask("link-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{DownloadInfo: &snap.DownloadInfo{Sha3_384: "other-sha3"}})
+	chg.AddTask(t)
+
+	done := st.NewChange("install", "...")
+	t = st.NewTask("download-snap", "...")
+	t.Set("snap-setup", &snapstate.SnapSetup{DownloadInfo: &snap.DownloadInfo{Sha3_384: "done-sha3"}})
+	t.SetStatus(state.DoneStatus)
+	done.AddTask(t)
+
+	c.Check(snapstate.DownloadCachePins(st), DeepEquals, map[string]string{
+		"foo-sha3": `pending refresh of "foo"`,
+		"baz-sha3": "change " + chg.ID(),
+	})
+}
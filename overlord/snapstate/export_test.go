@@ -603,3 +603,25 @@ type CustomInstallGoal struct {
 func (c *CustomInstallGoal) toInstall(ctx context.Context, st *state.State, opts Options) ([]Target, error) {
 	return c.ToInstall(ctx, st, opts)
 }
+
+var RolloutCohort = rolloutCohort
+
+func MockDeviceSerial(f func(st *state.State) (string, error)) (restore func()) {
+	return testutil.Mock(&DeviceSerial, f)
+}
+
+func MockUnhealthySnaps(f func(st *state.State) ([]string, error)) (restore func()) {
+	return testutil.Mock(&UnhealthySnaps, f)
+}
+
+func MockRefreshConnectionChanges(f func(st *state.State, info *snap.Info) (connect, disconnect []RefreshReportConnection, err error)) (restore func()) {
+	return testutil.Mock(&RefreshConnectionChanges, f)
+}
+
+func MockApplyResourceHints(f func(t *state.Task, info *snap.Info) error) (restore func()) {
+	return testutil.Mock(&ApplyResourceHints, f)
+}
+
+func MockUndoResourceHints(f func(t *state.Task) error) (restore func()) {
+	return testutil.Mock(&UndoResourceHints, f)
+}
@@ -76,6 +76,11 @@ var EnsureSnapAbsentFromQuotaGroup = func(st *state.State, snap string) error {
 	panic("internal error: snapstate.EnsureSnapAbsentFromQuotaGroup is unset")
 }
 
+// DiscardServiceEnvironment is a hook set by servicestate.
+var DiscardServiceEnvironment = func(st *state.State, snap string) error {
+	panic("internal error: snapstate.DiscardServiceEnvironment is unset")
+}
+
 var SecurityProfilesRemoveLate = func(snapName string, rev snap.Revision, typ snap.Type) error {
 	panic("internal error: snapstate.SecurityProfilesRemoveLate is unset")
 }
@@ -736,6 +741,23 @@ func autoRefreshRateLimited(st *state.State) (rate int64) {
 	return val
 }
 
+// autoRefreshRateSchedule returns the windows of time overriding the rate
+// limit of auto-refreshes, if any.
+func autoRefreshRateSchedule(st *state.State) []store.RateWindow {
+	tr := config.NewTransaction(st)
+
+	var rateSchedule string
+	err := tr.Get("core", "refresh.rate-limit-schedule", &rateSchedule)
+	if err != nil {
+		return nil
+	}
+	windows, err := store.ParseRateSchedule(rateSchedule)
+	if err != nil {
+		return nil
+	}
+	return windows
+}
+
 func downloadSnapParams(st *state.State, t *state.Task) (*SnapSetup, StoreService, *auth.UserState, error) {
 	snapsup, err := TaskSnapSetup(t)
 	if err != nil {
@@ -760,6 +782,7 @@ func downloadSnapParams(st *state.State, t *state.Task) (*SnapSetup, StoreServic
 func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	st := t.State()
 	var rate int64
+	var rateSchedule []store.RateWindow
 
 	st.Lock()
 	perfTimings := state.TimingsForTask(t)
@@ -767,7 +790,9 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	if snapsup != nil && snapsup.IsAutoRefresh {
 		// NOTE rate is never negative
 		rate = autoRefreshRateLimited(st)
+		rateSchedule = autoRefreshRateSchedule(st)
 	}
+	cachePins := DownloadCachePins(st)
 	st.Unlock()
 	if err != nil {
 		return err
@@ -781,8 +806,10 @@ func (m *SnapManager) doDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	targetFn := snapsup.MountFile()
 
 	dlOpts := &store.DownloadOptions{
-		Scheduled: snapsup.IsAutoRefresh,
-		RateLimit: rate,
+		Scheduled:    snapsup.IsAutoRefresh,
+		RateLimit:    rate,
+		RateSchedule: rateSchedule,
+		CachePins:    cachePins,
 	}
 	if snapsup.DownloadInfo == nil {
 		var storeInfo store.SnapActionResult
@@ -864,8 +891,10 @@ func (m *SnapManager) doPreDownloadSnap(t *state.Task, tomb *tomb.Tomb) error {
 	targetFn := snapsup.MountFile()
 	dlOpts := &store.DownloadOptions{
 		// pre-downloads are only triggered in auto-refreshes
-		Scheduled: true,
-		RateLimit: autoRefreshRateLimited(st),
+		Scheduled:    true,
+		RateLimit:    autoRefreshRateLimited(st),
+		RateSchedule: autoRefreshRateSchedule(st),
+		CachePins:    DownloadCachePins(st),
 	}
 
 	perfTimings := state.TimingsForTask(t)
@@ -3082,6 +3111,12 @@ func (m *SnapManager) startSnapServices(t *state.Task, _ *tomb.Tomb) error {
 		return nil
 	}
 
+	if ApplyResourceHints != nil {
+		if err := ApplyResourceHints(t, currentInfo); err != nil {
+			return err
+		}
+	}
+
 	startupOrdered, err := snap.SortServices(svcs)
 	if err != nil {
 		return err
@@ -3139,6 +3174,9 @@ func (m *SnapManager) undoStartSnapServices(t *state.Task, _ *tomb.Tomb) error {
 		return err
 	}
 
+	if UndoResourceHints != nil {
+		return UndoResourceHints(t)
+	}
 	return nil
 }
 
@@ -3240,6 +3278,12 @@ func (m *SnapManager) undoStopSnapServices(t *state.Task, _ *tomb.Tomb) error {
 		return nil
 	}
 
+	if ApplyResourceHints != nil {
+		if err := ApplyResourceHints(t, currentInfo); err != nil {
+			return err
+		}
+	}
+
 	startupOrdered, err := snap.SortServices(svcs)
 	if err != nil {
 		return err
@@ -3614,6 +3658,11 @@ func (m *SnapManager) doDiscardSnap(t *state.Task, _ *tomb.Tomb) error {
 		if err := EnsureSnapAbsentFromQuotaGroup(st, snapsup.InstanceName()); err != nil {
 			return err
 		}
+
+		// forget the environment overrides of the services of the snap
+		if err := DiscardServiceEnvironment(st, snapsup.InstanceName()); err != nil {
+			return err
+		}
 	}
 	if err = config.DiscardRevisionConfig(st, snapsup.InstanceName(), snapsup.Revision()); err != nil {
 		return err
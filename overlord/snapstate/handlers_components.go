@@ -186,9 +186,12 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 	}
 
 	var rate int64
+	var rateSchedule []store.RateWindow
 	if snapsup.IsAutoRefresh {
 		rate = autoRefreshRateLimited(st)
+		rateSchedule = autoRefreshRateSchedule(st)
 	}
+	cachePins := DownloadCachePins(st)
 
 	cpi := snap.MinimalComponentContainerPlaceInfo(
 		compsup.ComponentName(), compsup.CompSideInfo.Revision,
@@ -208,8 +211,10 @@ func (m *SnapManager) doDownloadComponent(t *state.Task, tomb *tomb.Tomb) error
 	timings.Run(perf, "download", fmt.Sprintf("download component %q", compsup.ComponentName()), func(timings.Measurer) {
 		compRef := compsup.CompSideInfo.Component.String()
 		opts := &store.DownloadOptions{
-			Scheduled: snapsup.IsAutoRefresh,
-			RateLimit: rate,
+			Scheduled:    snapsup.IsAutoRefresh,
+			RateLimit:    rate,
+			RateSchedule: rateSchedule,
+			CachePins:    cachePins,
 		}
 
 		err = sto.Download(tomb.Context(nil), compRef, target, compsup.DownloadInfo, meter, user, opts)
@@ -50,6 +50,11 @@ func (s *discardSnapSuite) SetUpTest(c *C) {
 	s.AddCleanup(func() {
 		snapstate.EnsureSnapAbsentFromQuotaGroup = oldSnapStateEnsureSnapAbsentFromQuotaGroup
 	})
+	oldDiscardServiceEnvironment := snapstate.DiscardServiceEnvironment
+	snapstate.DiscardServiceEnvironment = servicestate.DiscardServiceEnvironment
+	s.AddCleanup(func() {
+		snapstate.DiscardServiceEnvironment = oldDiscardServiceEnvironment
+	})
 
 	s.AddCleanup(snapstatetest.MockDeviceModel(DefaultModel()))
 }
@@ -135,6 +140,45 @@ func (s *discardSnapSuite) TestDoDiscardSnapInQuotaGroup(c *C) {
 	c.Check(t.Status(), Equals, state.DoneStatus)
 }
 
+func (s *discardSnapSuite) TestDoDiscardSnapDiscardsServiceEnvironment(c *C) {
+	s.state.Lock()
+
+	s.state.Set("service-environment", map[string]map[string]map[string]string{
+		"foo": {"svc": {"FOO": "bar"}},
+		"bar": {"svc": {"BAZ": "1"}},
+	})
+	snapstate.Set(s.state, "foo", &snapstate.SnapState{
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "foo", Revision: snap.R(3)},
+		}),
+		Current:  snap.R(3),
+		SnapType: "app",
+	})
+	t := s.state.NewTask("discard-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			Revision: snap.R(3),
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+	c.Check(t.Status(), Equals, state.DoneStatus)
+
+	var env map[string]map[string]map[string]string
+	c.Assert(s.state.Get("service-environment", &env), IsNil)
+	c.Check(env, DeepEquals, map[string]map[string]map[string]string{
+		"bar": {"svc": {"BAZ": "1"}},
+	})
+}
+
 func (s *discardSnapSuite) TestDoDiscardSnapToEmpty(c *C) {
 	s.state.Lock()
 	snapstate.Set(s.state, "foo", &snapstate.SnapState{
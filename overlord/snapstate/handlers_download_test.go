@@ -34,6 +34,7 @@ import (
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/store"
 	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timeutil"
 )
 
 type downloadSnapSuite struct {
@@ -175,6 +176,47 @@ func (s *downloadSnapSuite) TestDoDownloadSnapNormal(c *C) {
 	})
 }
 
+func (s *downloadSnapSuite) TestDoDownloadSnapCachePins(c *C) {
+	s.state.Lock()
+
+	// the blob of a pending refresh must stay in the download cache
+	s.state.Set("refresh-candidates", map[string]*snapstate.RefreshCandidate{
+		"bar": {SnapSetup: snapstate.SnapSetup{DownloadInfo: &snap.DownloadInfo{Sha3_384: "bar-sha3"}}},
+	})
+
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: &snap.SideInfo{
+			RealName: "foo",
+			SnapID:   "mySnapID",
+			Revision: snap.R(11),
+		},
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+			Sha3_384:    "foo-sha3",
+		},
+	})
+	chg := s.state.NewChange("sample", "...")
+	chg.AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	c.Assert(chg.Err(), IsNil)
+	c.Assert(s.fakeStore.downloads, HasLen, 1)
+	c.Check(s.fakeStore.downloads[0].opts, DeepEquals, &store.DownloadOptions{
+		CachePins: map[string]string{
+			"bar-sha3": `pending refresh of "bar"`,
+			"foo-sha3": "change " + chg.ID(),
+		},
+	})
+}
+
 func (s *downloadSnapSuite) TestDoDownloadSnapWithDeviceContext(c *C) {
 	s.state.Lock()
 
@@ -303,3 +345,50 @@ func (s *downloadSnapSuite) TestDoDownloadRateLimitedIntegration(c *C) {
 	})
 
 }
+
+func (s *downloadSnapSuite) TestDoDownloadRateScheduleIntegration(c *C) {
+	s.state.Lock()
+
+	tr := config.NewTransaction(s.state)
+	tr.Set("core", "refresh.rate-limit", "1234B")
+	tr.Set("core", "refresh.rate-limit-schedule", "22:00-6:00=unlimited")
+	tr.Commit()
+
+	si := &snap.SideInfo{
+		RealName: "foo",
+		SnapID:   "foo-id",
+		Revision: snap.R(11),
+	}
+	t := s.state.NewTask("download-snap", "test")
+	t.Set("snap-setup", &snapstate.SnapSetup{
+		SideInfo: si,
+		DownloadInfo: &snap.DownloadInfo{
+			DownloadURL: "http://some-url.com/snap",
+		},
+		Flags: snapstate.Flags{
+			IsAutoRefresh: true,
+		},
+	})
+	s.state.NewChange("sample", "...").AddTask(t)
+
+	s.state.Unlock()
+
+	s.se.Ensure()
+	s.se.Wait()
+
+	// the schedule is passed on to the store
+	c.Assert(s.fakeStore.downloads, DeepEquals, []fakeDownload{
+		{
+			name:   "foo",
+			target: filepath.Join(dirs.SnapBlobDir, "foo_11.snap"),
+			opts: &store.DownloadOptions{
+				RateLimit: 1234,
+				RateSchedule: []store.RateWindow{{
+					Start: timeutil.Clock{Hour: 22},
+					End:   timeutil.Clock{Hour: 6},
+				}},
+				Scheduled: true,
+			},
+		},
+	})
+}
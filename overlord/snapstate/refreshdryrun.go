@@ -0,0 +1,292 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/snapcore/snapd/asserts/snapasserts"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+)
+
+// RefreshReport describes what a refresh would do, as computed by
+// RefreshDryRun.
+type RefreshReport struct {
+	// Snaps are the snaps that would be refreshed.
+	Snaps []RefreshReportSnap `json:"snaps,omitempty"`
+	// Skipped are the snaps that have an update available but would
+	// not be refreshed.
+	Skipped []RefreshReportSkipped `json:"skipped,omitempty"`
+	// DownloadSize is the total number of bytes that would be
+	// downloaded.
+	DownloadSize int64 `json:"download-size"`
+}
+
+// RefreshReportSnap describes the impact of refreshing a single snap.
+type RefreshReportSnap struct {
+	Name            string        `json:"name"`
+	Channel         string        `json:"channel,omitempty"`
+	Version         string        `json:"version"`
+	Revision        snap.Revision `json:"revision"`
+	CurrentVersion  string        `json:"current-version"`
+	CurrentRevision snap.Revision `json:"current-revision"`
+	DownloadSize    int64         `json:"download-size"`
+
+	// Base and CurrentBase are only set when the refresh changes the
+	// base of the snap.
+	Base        string `json:"base,omitempty"`
+	CurrentBase string `json:"current-base,omitempty"`
+	// Prerequisites are the snaps that are not installed and would be
+	// installed alongside the refresh, i.e. a new base or default
+	// content providers.
+	Prerequisites []string `json:"prerequisites,omitempty"`
+
+	// Connections are the connections that would be auto-connected for
+	// the plugs and slots added by the refresh, Disconnections the
+	// existing ones of the plugs and slots it removes.
+	Connections    []RefreshReportConnection `json:"connections,omitempty"`
+	Disconnections []RefreshReportConnection `json:"disconnections,omitempty"`
+
+	RestartedServices []string `json:"restarted-services,omitempty"`
+	AddedServices     []string `json:"added-services,omitempty"`
+	RemovedServices   []string `json:"removed-services,omitempty"`
+
+	// ValidationSets are the enforced validation sets that require the
+	// snap, RequiredRevision is set if they pin it to a revision.
+	ValidationSets   []string       `json:"validation-sets,omitempty"`
+	RequiredRevision *snap.Revision `json:"required-revision,omitempty"`
+
+	// RunningApps and RunningHooks are the apps and hooks of the
+	// current revision whose processes would inhibit the refresh.
+	RunningApps  []string `json:"running-apps,omitempty"`
+	RunningHooks []string `json:"running-hooks,omitempty"`
+}
+
+// RefreshReportConnection is a connection made or removed by a refresh.
+type RefreshReportConnection struct {
+	// Plug and Slot are references of the form <snap>:<name>.
+	Plug      string `json:"plug"`
+	Slot      string `json:"slot"`
+	Interface string `json:"interface"`
+}
+
+// RefreshReportSkipped is a snap that would not be refreshed.
+type RefreshReportSkipped struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// RefreshConnectionChanges returns the connections that refreshing to the
+// given revision of a snap would auto-connect and disconnect. It is setup
+// by ifacestate.
+var RefreshConnectionChanges func(st *state.State, info *snap.Info) (connect, disconnect []RefreshReportConnection, err error)
+
+// RefreshDryRun computes the plan of refreshing the given snaps, or all
+// snaps if names is empty, the same way UpdateMany does, and reports its
+// impact without creating any change or modifying the system.
+// Note that the state must be locked by the caller.
+func RefreshDryRun(ctx context.Context, st *state.State, names []string, userID int, flags *Flags) (*RefreshReport, error) {
+	if flags == nil {
+		flags = &Flags{}
+	}
+	opts := Options{
+		Flags:  *flags,
+		UserID: userID,
+	}
+	if err := setDefaultSnapstateOptions(st, &opts); err != nil {
+		return nil, err
+	}
+
+	updates := make([]StoreUpdate, 0, len(names))
+	for _, name := range names {
+		updates = append(updates, StoreUpdate{InstanceName: name})
+	}
+
+	plan, err := StoreUpdateGoal(updates...).toUpdate(ctx, st, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RefreshReport{}
+	skipped := func(reason string, apply func() error) error {
+		before := plan.targetInfos()
+		if err := apply(); err != nil {
+			return err
+		}
+		kept := make(map[string]bool, len(plan.targets))
+		for _, t := range plan.targets {
+			kept[t.info.InstanceName()] = true
+		}
+		for _, info := range before {
+			if !kept[info.InstanceName()] {
+				report.Skipped = append(report.Skipped, RefreshReportSkipped{
+					Name:   info.InstanceName(),
+					Reason: reason,
+				})
+			}
+		}
+		return nil
+	}
+
+	if err := skipped("held", func() error {
+		return plan.filterHeldSnaps(st, opts)
+	}); err != nil {
+		return nil, err
+	}
+	if err := skipped("not allowed by enforced validation sets", func() error {
+		return plan.validateAndFilterTargets(st, opts)
+	}); err != nil {
+		return nil, err
+	}
+
+	enforcedSets, err := EnforcedValidationSets(st)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range plan.targets {
+		rs, err := refreshReportForTarget(st, t, enforcedSets, opts)
+		if err != nil {
+			return nil, err
+		}
+		report.DownloadSize += rs.DownloadSize
+		report.Snaps = append(report.Snaps, *rs)
+	}
+
+	sort.Slice(report.Snaps, func(i, j int) bool {
+		return report.Snaps[i].Name < report.Snaps[j].Name
+	})
+	sort.Slice(report.Skipped, func(i, j int) bool {
+		return report.Skipped[i].Name < report.Skipped[j].Name
+	})
+
+	return report, nil
+}
+
+func refreshReportForTarget(st *state.State, t target, enforcedSets *snapasserts.ValidationSets, opts Options) (*RefreshReportSnap, error) {
+	info := t.info
+	curInfo, err := t.snapst.CurrentInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &RefreshReportSnap{
+		Name:            info.InstanceName(),
+		Channel:         t.setup.Channel,
+		Version:         info.Version,
+		Revision:        info.Revision,
+		CurrentVersion:  curInfo.Version,
+		CurrentRevision: curInfo.Revision,
+	}
+
+	// revisions that are still around locally need no download
+	if t.snapst.LastIndex(info.Revision) < 0 {
+		rs.DownloadSize = info.Size
+		for _, comp := range t.components {
+			if comp.DownloadInfo != nil {
+				rs.DownloadSize += comp.DownloadInfo.Size
+			}
+		}
+	}
+
+	if info.Base != curInfo.Base {
+		rs.Base = info.Base
+		rs.CurrentBase = curInfo.Base
+	}
+
+	prereqs := getKeys(defaultProviderContentAttrs(st, info, opts.PrereqTracker))
+	if info.Base != "" {
+		prereqs = append(prereqs, info.Base)
+	}
+	for _, name := range prereqs {
+		installed, err := isInstalled(st, name)
+		if err != nil {
+			return nil, err
+		}
+		if !installed {
+			rs.Prerequisites = append(rs.Prerequisites, name)
+		}
+	}
+	sort.Strings(rs.Prerequisites)
+
+	if RefreshConnectionChanges != nil {
+		rs.Connections, rs.Disconnections, err = RefreshConnectionChanges(st, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// services of the current revision are stopped and the ones of the
+	// new revision started
+	for name, app := range info.Apps {
+		if !app.IsService() {
+			continue
+		}
+		if curApp, ok := curInfo.Apps[name]; ok && curApp.IsService() {
+			rs.RestartedServices = append(rs.RestartedServices, name)
+		} else {
+			rs.AddedServices = append(rs.AddedServices, name)
+		}
+	}
+	for name, app := range curInfo.Apps {
+		if !app.IsService() {
+			continue
+		}
+		if newApp, ok := info.Apps[name]; !ok || !newApp.IsService() {
+			rs.RemovedServices = append(rs.RemovedServices, name)
+		}
+	}
+	sort.Strings(rs.RestartedServices)
+	sort.Strings(rs.AddedServices)
+	sort.Strings(rs.RemovedServices)
+
+	if enforcedSets != nil {
+		keys, rev, err := enforcedSets.CheckPresenceRequired(info)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			rs.ValidationSets = append(rs.ValidationSets, key.String())
+		}
+		sort.Strings(rs.ValidationSets)
+		if !rev.Unset() {
+			rs.RequiredRevision = &rev
+		}
+	}
+
+	if opts.Flags.IgnoreRunning {
+		return rs, nil
+	}
+	if err := refreshAppsCheck(curInfo); err != nil {
+		var busyErr *BusySnapError
+		if !errors.As(err, &busyErr) {
+			logger.Noticef("cannot check running apps of snap %q: %v", rs.Name, err)
+		} else {
+			rs.RunningApps = busyErr.busyAppNames
+			rs.RunningHooks = busyErr.busyHookNames
+		}
+	}
+
+	return rs, nil
+}
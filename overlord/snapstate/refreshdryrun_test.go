@@ -0,0 +1,193 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate_test
+
+import (
+	"context"
+	"os"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/overlord/snapstate/snapstatetest"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snaptest"
+)
+
+func (s *snapmgrTestSuite) TestRefreshDryRun(c *C) {
+	restore := snapstate.MockSnapReadInfo(func(name string, si *snap.SideInfo) (*snap.Info, error) {
+		if name != "some-snap" {
+			return s.fakeBackend.ReadInfo(name, si)
+		}
+		return snaptest.MockInfo(c, `name: some-snap
+version: 1
+apps:
+  svc:
+    daemon: simple
+  cmd:
+    command: bin/cmd
+plugs:
+  old-plug: home
+`, si), nil
+	})
+	defer restore()
+
+	restore = snapstate.MockRefreshAppsCheck(func(info *snap.Info) error {
+		if info.InstanceName() == "some-snap" {
+			return snapstate.NewBusySnapError(info, []int{123}, []string{"cmd"}, nil)
+		}
+		return nil
+	})
+	defer restore()
+
+	restore = snapstate.MockRefreshConnectionChanges(func(st *state.State, info *snap.Info) (connect, disconnect []snapstate.RefreshReportConnection, err error) {
+		if info.InstanceName() != "some-snap" {
+			return nil, nil, nil
+		}
+		c.Check(info.Revision, Equals, snap.R(11))
+		connect = []snapstate.RefreshReportConnection{{Plug: "some-snap:my-plug", Slot: "core:registry", Interface: "registry"}}
+		disconnect = []snapstate.RefreshReportConnection{{Plug: "some-snap:old-plug", Slot: "core:home", Interface: "home"}}
+		return connect, disconnect, nil
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:         snap.R(1),
+		SnapType:        "app",
+		TrackingChannel: "channel-for-registry",
+	})
+	snapstate.Set(s.state, "outdated-consumer", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "outdated-consumer", SnapID: "outdated-consumer-id", Revision: snap.R(1)},
+		}),
+		Current:         snap.R(1),
+		SnapType:        "app",
+		TrackingChannel: "latest/stable",
+	})
+	snapstate.Set(s.state, "some-other-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-other-snap", SnapID: "some-other-snap-id", Revision: snap.R(1)},
+		}),
+		Current:         snap.R(1),
+		SnapType:        "app",
+		TrackingChannel: "latest/stable",
+	})
+	// the last refresh time of held snaps comes from their blob
+	c.Assert(os.MkdirAll(dirs.SnapBlobDir, 0755), IsNil)
+	c.Assert(os.WriteFile(snap.MountFile("some-other-snap", snap.R(1)), nil, 0644), IsNil)
+	err := snapstate.HoldRefreshesBySystem(s.state, snapstate.HoldGeneral, "forever", []string{"some-other-snap"})
+	c.Assert(err, IsNil)
+
+	report, err := snapstate.RefreshDryRun(context.Background(), s.state, nil, s.user.ID, nil)
+	c.Assert(err, IsNil)
+
+	c.Check(report.Skipped, DeepEquals, []snapstate.RefreshReportSkipped{
+		{Name: "some-other-snap", Reason: "held"},
+	})
+	c.Assert(report.Snaps, HasLen, 2)
+
+	consumer := report.Snaps[0]
+	c.Check(consumer.Name, Equals, "outdated-consumer")
+	c.Check(consumer.Revision, Equals, snap.R(11))
+	c.Check(consumer.CurrentRevision, Equals, snap.R(1))
+	c.Check(consumer.Prerequisites, DeepEquals, []string{"outdated-producer"})
+	c.Check(consumer.Connections, HasLen, 0)
+	c.Check(consumer.RunningApps, HasLen, 0)
+
+	someSnap := report.Snaps[1]
+	c.Check(someSnap.Name, Equals, "some-snap")
+	c.Check(someSnap.Channel, Equals, "channel-for-registry")
+	c.Check(someSnap.Revision, Equals, snap.R(11))
+	c.Check(someSnap.CurrentVersion, Equals, "1")
+	c.Check(someSnap.Connections, DeepEquals, []snapstate.RefreshReportConnection{
+		{Plug: "some-snap:my-plug", Slot: "core:registry", Interface: "registry"},
+	})
+	c.Check(someSnap.Disconnections, DeepEquals, []snapstate.RefreshReportConnection{
+		{Plug: "some-snap:old-plug", Slot: "core:home", Interface: "home"},
+	})
+	c.Check(someSnap.RemovedServices, DeepEquals, []string{"svc"})
+	c.Check(someSnap.RunningApps, DeepEquals, []string{"cmd"})
+
+	// nothing was changed
+	c.Check(s.state.Changes(), HasLen, 0)
+	c.Check(s.state.TaskCount(), Equals, 0)
+}
+
+func (s *snapmgrTestSuite) TestRefreshDryRunIgnoreRunning(c *C) {
+	restore := snapstate.MockRefreshAppsCheck(func(info *snap.Info) error {
+		c.Fatalf("unexpected check of running apps")
+		return nil
+	})
+	defer restore()
+
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	report, err := snapstate.RefreshDryRun(context.Background(), s.state, nil, s.user.ID, &snapstate.Flags{IgnoreRunning: true})
+	c.Assert(err, IsNil)
+	c.Assert(report.Snaps, HasLen, 1)
+	c.Check(report.Snaps[0].Name, Equals, "some-snap")
+	c.Check(report.Snaps[0].RunningApps, HasLen, 0)
+}
+
+func (s *snapmgrTestSuite) TestRefreshDryRunValidationSetsSkip(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	snapstate.Set(s.state, "some-snap", &snapstate.SnapState{
+		Active: true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{
+			{RealName: "some-snap", SnapID: "some-snap-id", Revision: snap.R(1)},
+		}),
+		Current:  snap.R(1),
+		SnapType: "app",
+	})
+
+	snapstate.ValidateRefreshes = func(st *state.State, refreshes []*snap.Info, ignoreValidation map[string]bool, userID int, deviceCtx snapstate.DeviceContext) ([]*snap.Info, error) {
+		return nil, nil
+	}
+
+	report, err := snapstate.RefreshDryRun(context.Background(), s.state, nil, s.user.ID, nil)
+	c.Assert(err, IsNil)
+	c.Check(report.Snaps, HasLen, 0)
+	c.Check(report.Skipped, DeepEquals, []snapstate.RefreshReportSkipped{
+		{Name: "some-snap", Reason: "not allowed by enforced validation sets"},
+	})
+}
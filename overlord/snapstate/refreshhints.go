@@ -177,6 +177,7 @@ func refreshHintsFromUpdatePlan(st *state.State, plan updatePlan, deviceCtx Devi
 		}
 	}
 
+	windows := refreshWindows(st)
 	hints := make(map[string]*refreshCandidate, len(plan.targets))
 	for _, t := range plan.targets {
 		info := t.info
@@ -202,11 +203,18 @@ func refreshHintsFromUpdatePlan(st *state.State, plan updatePlan, deviceCtx Devi
 			continue
 		}
 
-		hints[info.InstanceName()] = &refreshCandidate{
+		hint := &refreshCandidate{
 			SnapSetup:  snapsup,
 			Components: compsups,
 			Monitored:  IsSnapMonitored(st, info.InstanceName()),
 		}
+		if w := windows[info.InstanceName()]; w != nil {
+			hint.Window = w.name
+		}
+		hints[info.InstanceName()] = hint
+	}
+	if err := setRolloutSince(st, hints); err != nil {
+		return nil, err
 	}
 	return hints, nil
 }
@@ -0,0 +1,258 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/strutil"
+)
+
+// hooks setup by devicestate and healthstate
+var (
+	// DeviceSerial returns the serial of the device, if it has one.
+	DeviceSerial func(st *state.State) (string, error)
+	// UnhealthySnaps returns the snaps whose last health check reported
+	// an error.
+	UnhealthySnaps func(st *state.State) ([]string, error)
+)
+
+// ParseRefreshRollout parses the refresh.rollout setting, a comma
+// separated list with the percentage of devices allowed to auto-refresh
+// to a new revision on each day after it was first seen, e.g. "10,50,100".
+// Days are counted from when this device first saw the revision, not from
+// its release in the store, so devices checking for updates at different
+// times progress through the stages at different times.
+func ParseRefreshRollout(rollout string) ([]int, error) {
+	if rollout == "" {
+		return nil, nil
+	}
+	var stages []int
+	for _, s := range strings.Split(rollout, ",") {
+		pct, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+		if err != nil || pct < 1 || pct > 100 {
+			return nil, fmt.Errorf("invalid rollout percentage %q: must be between 1 and 100", s)
+		}
+		if len(stages) > 0 && pct < stages[len(stages)-1] {
+			return nil, fmt.Errorf("invalid rollout %q: percentages cannot decrease", rollout)
+		}
+		stages = append(stages, pct)
+	}
+	if stages[len(stages)-1] != 100 {
+		return nil, fmt.Errorf("invalid rollout %q: must end with 100", rollout)
+	}
+	return stages, nil
+}
+
+// refreshRollout returns the stages of the staged rollout of new
+// revisions, if one is configured. Invalid settings are ignored.
+func refreshRollout(st *state.State) []int {
+	var rollout interface{}
+	if err := config.NewTransaction(st).GetMaybe("core", "refresh.rollout", &rollout); err != nil {
+		logger.Noticef("cannot get refresh.rollout: %v", err)
+		return nil
+	}
+	if rollout == nil {
+		return nil
+	}
+	stages, err := ParseRefreshRollout(fmt.Sprint(rollout))
+	if err != nil {
+		logger.Noticef("cannot use refresh.rollout: %v", err)
+		return nil
+	}
+	return stages
+}
+
+// rolloutHalt tells whether the staged rollout of new revisions is halted,
+// either locally with refresh.rollout-halt, or for the snaps whose health
+// checks, or those of their dependencies, fail on this device. Revisions
+// can also be held back for a whole fleet by enforced validation sets.
+type rolloutHalt struct {
+	local     bool
+	unhealthy map[string]bool
+}
+
+func getRolloutHalt(st *state.State) (*rolloutHalt, error) {
+	var halt interface{}
+	if err := config.NewTransaction(st).GetMaybe("core", "refresh.rollout-halt", &halt); err != nil {
+		return nil, err
+	}
+	// the value is validated to be either true or false, possibly as a
+	// string
+	h := &rolloutHalt{local: fmt.Sprint(halt) == "true"}
+	if UnhealthySnaps != nil {
+		unhealthy, err := UnhealthySnaps(st)
+		if err != nil {
+			return nil, err
+		}
+		h.unhealthy = make(map[string]bool, len(unhealthy))
+		for _, name := range unhealthy {
+			h.unhealthy[name] = true
+		}
+	}
+	return h, nil
+}
+
+// reason returns why the staged rollout of the candidate is halted, if it
+// is. Only the health of the snap itself, of its base and of its default
+// content providers is considered.
+func (h *rolloutHalt) reason(instanceName string, cand *refreshCandidate) string {
+	if h.local {
+		return "refresh.rollout-halt is set"
+	}
+	if len(h.unhealthy) == 0 {
+		return ""
+	}
+	deps := append([]string{instanceName, cand.Base}, cand.SnapSetup.Prereq...)
+	for provider := range cand.PrereqContentAttrs {
+		deps = append(deps, provider)
+	}
+	var unhealthy []string
+	for _, name := range deps {
+		if h.unhealthy[name] && !strutil.ListContains(unhealthy, name) {
+			unhealthy = append(unhealthy, name)
+		}
+	}
+	if len(unhealthy) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("health checks of %s report errors", strutil.Quoted(unhealthy))
+}
+
+// rolloutHaltLoggedKey caches, by snap, the reason its staged rollout is
+// halted for that was last logged, so that it is logged only once.
+type rolloutHaltLoggedKey struct{}
+
+// rolloutCohort returns the percentile, from 0 to 99, of this device in
+// staged rollouts. It is derived from the device serial, or from the
+// refresh privacy key for devices without one, so that the same devices
+// are always the first ones to refresh.
+func rolloutCohort(st *state.State) (int, error) {
+	var key string
+	if DeviceSerial != nil {
+		serial, err := DeviceSerial(st)
+		if err != nil {
+			return 0, err
+		}
+		key = serial
+	}
+	if key == "" {
+		if err := st.Get("refresh-privacy-key", &key); err != nil && !errors.Is(err, state.ErrNoState) {
+			return 0, err
+		}
+	}
+	h := sha256.Sum256([]byte("refresh-rollout:" + key))
+	return int(binary.BigEndian.Uint64(h[:8]) % 100), nil
+}
+
+// setRolloutSince records on the candidates when their revision was
+// first seen, which is when its staged rollout started.
+func setRolloutSince(st *state.State, hints map[string]*refreshCandidate) error {
+	if len(hints) == 0 || len(refreshRollout(st)) == 0 {
+		return nil
+	}
+	var oldHints map[string]*refreshCandidate
+	if err := st.Get("refresh-candidates", &oldHints); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	now := timeNow()
+	for name, hint := range hints {
+		old := oldHints[name]
+		if old != nil && !old.RolloutSince.IsZero() && old.Revision() == hint.Revision() {
+			hint.RolloutSince = old.RolloutSince
+		} else {
+			hint.RolloutSince = now
+		}
+	}
+	return nil
+}
+
+// rolloutAllowed returns a function telling whether the staged rollout
+// of the given candidate has reached this device.
+func rolloutAllowed(st *state.State) (func(instanceName string, cand *refreshCandidate) bool, error) {
+	stages := refreshRollout(st)
+	if len(stages) == 0 {
+		return func(string, *refreshCandidate) bool { return true }, nil
+	}
+	halt, err := getRolloutHalt(st)
+	if err != nil {
+		return nil, err
+	}
+	logged, _ := st.Cached(rolloutHaltLoggedKey{}).(map[string]string)
+	if logged == nil {
+		logged = make(map[string]string)
+		st.Cache(rolloutHaltLoggedKey{}, logged)
+	}
+	cohort, err := rolloutCohort(st)
+	if err != nil {
+		return nil, err
+	}
+	now := timeNow()
+	return func(instanceName string, cand *refreshCandidate) bool {
+		if cand == nil {
+			// not a new revision
+			return true
+		}
+		if reason := halt.reason(instanceName, cand); reason != "" {
+			if logged[instanceName] != reason {
+				logger.Noticef("auto-refresh of snap %q held: staged rollout halted as %s", instanceName, reason)
+				logged[instanceName] = reason
+			}
+			return false
+		}
+		delete(logged, instanceName)
+		since := now
+		if !cand.RolloutSince.IsZero() {
+			since = cand.RolloutSince
+		}
+		day := int(now.Sub(since) / (24 * time.Hour))
+		if day >= len(stages) {
+			return true
+		}
+		if cohort >= stages[day] {
+			logger.Debugf("auto-refresh of snap %q postponed: staged rollout at %d%% of devices", instanceName, stages[day])
+			return false
+		}
+		return true
+	}, nil
+}
+
+// filterStagedRollout removes the targets whose staged rollout has not
+// reached this device yet.
+func (p *updatePlan) filterStagedRollout(st *state.State, hints map[string]*refreshCandidate) error {
+	allowed, err := rolloutAllowed(st)
+	if err != nil {
+		return err
+	}
+	p.filter(func(t target) (bool, error) {
+		name := t.info.InstanceName()
+		return allowed(name, hints[name]), nil
+	})
+	return nil
+}
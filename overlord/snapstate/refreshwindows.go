@@ -0,0 +1,284 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package snapstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/overlord/configstate/config"
+	"github.com/snapcore/snapd/overlord/state"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+// RefreshWindow restricts the auto-refreshes of a group of snaps to the
+// times covered by a schedule, as set with refresh.windows.<name>.
+type RefreshWindow struct {
+	Snaps []string `json:"snaps"`
+	// Schedule uses the refresh.timer syntax.
+	Schedule string `json:"schedule"`
+}
+
+type refreshWindow struct {
+	name     string
+	schedule []*timeutil.Schedule
+}
+
+var validRefreshWindowName = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// ValidateRefreshWindowName checks that the name is usable as the name
+// of a refresh window.
+func ValidateRefreshWindowName(name string) error {
+	if !validRefreshWindowName.MatchString(name) {
+		return fmt.Errorf("invalid refresh window name %q: must be lowercase letters, digits and dashes", name)
+	}
+	return nil
+}
+
+// parseRefreshWindows validates the given refresh windows and returns
+// the window governing each snap.
+func parseRefreshWindows(windows map[string]*RefreshWindow) (map[string]*refreshWindow, error) {
+	names := make([]string, 0, len(windows))
+	for name := range windows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bySnap := make(map[string]*refreshWindow)
+	for _, name := range names {
+		if err := ValidateRefreshWindowName(name); err != nil {
+			return nil, err
+		}
+		w := windows[name]
+		if w == nil {
+			// unset
+			continue
+		}
+		if len(w.Snaps) == 0 {
+			return nil, fmt.Errorf("refresh window %q has no snaps", name)
+		}
+		if w.Schedule == "" {
+			return nil, fmt.Errorf("refresh window %q has no schedule", name)
+		}
+		schedule, err := timeutil.ParseSchedule(w.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse schedule of refresh window %q: %v", name, err)
+		}
+		parsed := &refreshWindow{name: name, schedule: schedule}
+		for _, instanceName := range w.Snaps {
+			if err := snap.ValidateInstanceName(instanceName); err != nil {
+				return nil, fmt.Errorf("invalid snap in refresh window %q: %v", name, err)
+			}
+			if other := bySnap[instanceName]; other != nil {
+				return nil, fmt.Errorf("snap %q cannot be in both refresh windows %q and %q", instanceName, other.name, name)
+			}
+			bySnap[instanceName] = parsed
+		}
+	}
+	return bySnap, nil
+}
+
+// ValidateRefreshWindows checks the refresh windows set with
+// refresh.windows.
+func ValidateRefreshWindows(windows map[string]*RefreshWindow) error {
+	_, err := parseRefreshWindows(windows)
+	return err
+}
+
+func getRefreshWindowsConf(st *state.State) (map[string]*RefreshWindow, error) {
+	tr := config.NewTransaction(st)
+	var windows map[string]*RefreshWindow
+	if err := tr.GetMaybe("core", "refresh.windows", &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// refreshWindows returns the refresh window governing each snap that is
+// in one. Invalid settings are ignored, so that they don't block
+// auto-refreshes altogether.
+func refreshWindows(st *state.State) map[string]*refreshWindow {
+	windows, err := getRefreshWindowsConf(st)
+	if err == nil {
+		var bySnap map[string]*refreshWindow
+		if bySnap, err = parseRefreshWindows(windows); err == nil {
+			return bySnap
+		}
+	}
+	logger.Noticef("cannot use refresh windows: %v", err)
+	return nil
+}
+
+type refreshWindowsOnlyKey struct{}
+
+// withRefreshWindowsOnly returns a context for an auto-refresh triggered
+// by the opening of refresh windows rather than by refresh.timer.
+func withRefreshWindowsOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshWindowsOnlyKey{}, true)
+}
+
+// autoRefreshAllowed returns a function telling whether the given snap
+// can be auto-refreshed now: snaps in a refresh window only while it is
+// open, others only if the auto-refresh was triggered by refresh.timer.
+func autoRefreshAllowed(ctx context.Context, st *state.State) func(instanceName string) bool {
+	windows := refreshWindows(st)
+	windowsOnly, _ := ctx.Value(refreshWindowsOnlyKey{}).(bool)
+	now := timeNow()
+	return func(instanceName string) bool {
+		w := windows[instanceName]
+		if w == nil {
+			return !windowsOnly
+		}
+		return timeutil.Includes(w.schedule, now)
+	}
+}
+
+// filterClosedRefreshWindows removes the targets that cannot be
+// auto-refreshed now because of refresh windows.
+func (p *updatePlan) filterClosedRefreshWindows(ctx context.Context, st *state.State) {
+	allowed := autoRefreshAllowed(ctx, st)
+	p.filter(func(t target) (bool, error) {
+		name := t.info.InstanceName()
+		if !allowed(name) {
+			logger.Debugf("auto-refresh of snap %q postponed to its refresh window", name)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// openRefreshWindows returns the names of the refresh windows that are
+// open now, govern installed snaps and were not served yet in this
+// occurrence of their schedule. The refresh candidates are not consulted
+// as they may predate updates published since the window last opened:
+// serving a window queries the store, which refreshes them.
+func openRefreshWindows(st *state.State, now time.Time) ([]string, error) {
+	windows := refreshWindows(st)
+	if len(windows) == 0 {
+		return nil, nil
+	}
+
+	snapStates, err := All(st)
+	if err != nil {
+		return nil, err
+	}
+	var served map[string]time.Time
+	if err := st.Get("refresh-windows-served", &served); err != nil && !errors.Is(err, state.ErrNoState) {
+		return nil, err
+	}
+
+	var open []string
+	seen := make(map[string]bool)
+	for instanceName := range snapStates {
+		w := windows[instanceName]
+		if w == nil || seen[w.name] {
+			continue
+		}
+		seen[w.name] = true
+		if !timeutil.Includes(w.schedule, now) {
+			continue
+		}
+		if last, ok := served[w.name]; ok && sameWindowOccurrence(w.schedule, last, now) {
+			continue
+		}
+		open = append(open, w.name)
+	}
+	sort.Strings(open)
+	return open, nil
+}
+
+// sameWindowOccurrence returns whether last and now fall in the same
+// occurrence of the schedule.
+func sameWindowOccurrence(schedule []*timeutil.Schedule, last, now time.Time) bool {
+	if !timeutil.Includes(schedule, last) {
+		return false
+	}
+	for _, sched := range schedule {
+		// Next skips the window including last
+		if sched.Next(last).Includes(now) {
+			return false
+		}
+	}
+	return true
+}
+
+func markRefreshWindowsServed(st *state.State, names []string, now time.Time) error {
+	var served map[string]time.Time
+	if err := st.Get("refresh-windows-served", &served); err != nil && !errors.Is(err, state.ErrNoState) {
+		return err
+	}
+	if served == nil {
+		served = make(map[string]time.Time)
+	}
+	for _, name := range names {
+		served[name] = now
+	}
+	st.Set("refresh-windows-served", served)
+	return nil
+}
+
+// RefreshWindowInfo describes a refresh window and when it next opens.
+type RefreshWindowInfo struct {
+	Name     string
+	Snaps    []string
+	Schedule string
+	// Open is set if the window is open now.
+	Open bool
+	// Next is the start of the next occurrence of the window.
+	Next time.Time
+}
+
+// RefreshWindowsInfo returns information about the configured refresh
+// windows, sorted by name.
+func RefreshWindowsInfo(st *state.State) ([]RefreshWindowInfo, error) {
+	windows, err := getRefreshWindowsConf(st)
+	if err != nil {
+		return nil, err
+	}
+	now := timeNow()
+	infos := make([]RefreshWindowInfo, 0, len(windows))
+	for name, w := range windows {
+		if w == nil {
+			continue
+		}
+		info := RefreshWindowInfo{
+			Name:     name,
+			Snaps:    w.Snaps,
+			Schedule: w.Schedule,
+		}
+		if schedule, err := timeutil.ParseSchedule(w.Schedule); err == nil {
+			info.Open = timeutil.Includes(schedule, now)
+			for _, sched := range schedule {
+				if next := sched.Next(now).Start; info.Next.IsZero() || next.Before(info.Next) {
+					info.Next = next
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
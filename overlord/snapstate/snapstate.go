@@ -881,6 +881,15 @@ var AddSnapToQuotaGroup = func(st *state.State, snapName string, quotaGroup stri
 	panic("internal error: snapstate.AddSnapToQuotaGroup is unset")
 }
 
+// ApplyResourceHints puts the services of the snap which declare resource
+// hints into default quota sub-groups, or suggests how to, before they are
+// started. It is setup by servicestate.
+var ApplyResourceHints func(t *state.Task, info *snap.Info) error
+
+// UndoResourceHints undoes ApplyResourceHints for the given task. It is
+// setup by servicestate.
+var UndoResourceHints func(t *state.Task) error
+
 var HasActiveConnection = func(st *state.State, iface string) (bool, error) {
 	panic("internal error: snapstate.HasActiveConnection is unset")
 }
@@ -2657,6 +2666,11 @@ func autoRefreshPhase1(ctx context.Context, st *state.State, forGatingSnap strin
 	}
 
 	updates := make([]string, 0, len(hints))
+	allowed := autoRefreshAllowed(ctx, st)
+	rolloutReached, err := rolloutAllowed(st)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// check conflicts
 	fromChange := ""
@@ -2666,6 +2680,13 @@ func autoRefreshPhase1(ctx context.Context, st *state.State, forGatingSnap strin
 			// filtered out by refreshHintsFromCandidates
 			continue
 		}
+		if !allowed(name) {
+			logger.Debugf("auto-refresh of snap %q postponed to its refresh window", name)
+			continue
+		}
+		if !rolloutReached(name, hints[name]) {
+			continue
+		}
 
 		if err := checkChangeConflictIgnoringOneChange(st, name, &t.snapst, fromChange); err != nil {
 			logger.Noticef("cannot refresh snap %q: %v", name, err)
@@ -191,6 +191,7 @@ func (s *snapmgrBaseTest) SetUpTest(c *C) {
 	oldSetupRemoveHook := snapstate.SetupRemoveHook
 	oldSnapServiceOptions := snapstate.SnapServiceOptions
 	oldEnsureSnapAbsentFromQuotaGroup := snapstate.EnsureSnapAbsentFromQuotaGroup
+	oldDiscardServiceEnvironment := snapstate.DiscardServiceEnvironment
 	snapstate.SetupInstallHook = hookstate.SetupInstallHook
 	snapstate.SetupInstallComponentHook = hookstate.SetupInstallComponentHook
 	snapstate.SetupPostRefreshComponentHook = hookstate.SetupPostRefreshComponentHook
@@ -200,6 +201,7 @@ func (s *snapmgrBaseTest) SetUpTest(c *C) {
 	snapstate.SetupRemoveHook = hookstate.SetupRemoveHook
 	snapstate.SnapServiceOptions = servicestate.SnapServiceOptions
 	snapstate.EnsureSnapAbsentFromQuotaGroup = servicestate.EnsureSnapAbsentFromQuota
+	snapstate.DiscardServiceEnvironment = servicestate.DiscardServiceEnvironment
 
 	restore := snapstate.MockEnforcedValidationSets(func(st *state.State, extraVss ...*asserts.ValidationSet) (*snapasserts.ValidationSets, error) {
 		return snapasserts.NewValidationSets(), nil
@@ -240,6 +242,7 @@ func (s *snapmgrBaseTest) SetUpTest(c *C) {
 		snapstate.SetupRemoveHook = oldSetupRemoveHook
 		snapstate.SnapServiceOptions = oldSnapServiceOptions
 		snapstate.EnsureSnapAbsentFromQuotaGroup = oldEnsureSnapAbsentFromQuotaGroup
+		snapstate.DiscardServiceEnvironment = oldDiscardServiceEnvironment
 
 		dirs.SetRootDir("/")
 	})
@@ -7505,6 +7508,57 @@ func (s *snapmgrTestSuite) TestStartSnapServicesUndo(c *C) {
 	c.Check(oldDisabledSvcs, DeepEquals, []string{"old-svc"})
 }
 
+func (s *snapmgrTestSuite) TestStartSnapServicesResourceHints(c *C) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	var applied []string
+	restore := snapstate.MockApplyResourceHints(func(t *state.Task, info *snap.Info) error {
+		applied = append(applied, fmt.Sprintf("%s:%s:%s", t.Kind(), info.InstanceName(), info.Revision))
+		return nil
+	})
+	defer restore()
+	undone := 0
+	restore = snapstate.MockUndoResourceHints(func(t *state.Task) error {
+		c.Check(t.Kind(), Equals, "start-snap-services")
+		undone++
+		return nil
+	})
+	defer restore()
+
+	si := &snap.SideInfo{RealName: "hello-snap", SnapID: "hello-snap-id", Revision: snap.R(1)}
+	snaptest.MockSnap(c, servicesSnap, si)
+
+	snapstate.Set(s.state, "hello-snap", &snapstate.SnapState{
+		Active:   true,
+		Sequence: snapstatetest.NewSequenceFromSnapSideInfos([]*snap.SideInfo{si}),
+		Current:  si.Revision,
+		SnapType: "app",
+	})
+
+	// using MockSnap, we want to read the bits on disk
+	snapstate.MockSnapReadInfo(snap.ReadInfo)
+
+	chg := s.state.NewChange("services..", "")
+	t := s.state.NewTask("start-snap-services", "")
+	sup := &snapstate.SnapSetup{SideInfo: si}
+	t.Set("snap-setup", sup)
+	chg.AddTask(t)
+	terr := s.state.NewTask("error-trigger", "provoking total undo")
+	terr.WaitFor(t)
+	terr.JoinLane(t.Lanes()[0])
+	chg.AddTask(terr)
+
+	s.settle(c)
+
+	c.Check(chg.Status(), Equals, state.ErrorStatus)
+	c.Check(t.Status(), Equals, state.UndoneStatus)
+	// the hints of the current revision were applied before the services
+	// were started, and undone after they were stopped
+	c.Check(applied, DeepEquals, []string{"start-snap-services:hello-snap:1"})
+	c.Check(undone, Equals, 1)
+}
+
 func (s *snapmgrTestSuite) TestStopSnapServicesUndo(c *C) {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -1024,6 +1024,16 @@ func UpdateWithGoal(ctx context.Context, st *state.State, goal UpdateGoal, filte
 
 		// TODO: why not check this error?
 		updateRefreshCandidates(st, hints, plan.requested)
+
+		// snaps in closed refresh windows stay candidates but wait
+		// for their window
+		plan.filterClosedRefreshWindows(ctx, st)
+
+		// new revisions wait for their staged rollout to reach this
+		// device
+		if err := plan.filterStagedRollout(st, hints); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// validate snaps to be refreshed against validation sets. if we are
@@ -0,0 +1,274 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package delta implements the delta artifacts used to ship updates
+// of locally built snaps over slow links.
+//
+// A delta artifact is a single file made of a magic line, a one line
+// JSON header describing the source and target snaps, and the xdelta3
+// payload turning the source into the target. The header carries the
+// sha3-384 digests of both snaps so that the source can be checked
+// before applying and the reconstructed target can be checked against
+// the snap-revision assertion it is expected to match.
+//
+// Delta artifacts are not signed. They are trusted only through the
+// snap-revision assertion of their target, which snapd requires before
+// applying them, so deltas can only be installed for snaps whose new
+// revision has been asserted.
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	_ "golang.org/x/crypto/sha3"
+
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/snapdtool"
+)
+
+// Magic is the first line of every delta artifact.
+const Magic = "snap-delta-v1\n"
+
+// FormatXdelta3 is the only payload format currently supported.
+const FormatXdelta3 = "xdelta3"
+
+// maxHeaderSize bounds the size of the JSON header line.
+const maxHeaderSize = 64 * 1024
+
+var snapdtoolCommandFromSystemSnap = snapdtool.CommandFromSystemSnap
+
+// Header describes a delta artifact.
+type Header struct {
+	Format         string `json:"format"`
+	SnapName       string `json:"snap-name"`
+	SourceSha3_384 string `json:"source-sha3-384"`
+	SourceSize     uint64 `json:"source-size"`
+	TargetSha3_384 string `json:"target-sha3-384"`
+	TargetSize     uint64 `json:"target-size"`
+}
+
+func (h *Header) validate() error {
+	if h.Format != FormatXdelta3 {
+		return fmt.Errorf("unsupported delta format %q (only %s currently)", h.Format, FormatXdelta3)
+	}
+	if h.SnapName == "" {
+		return errors.New("missing snap name")
+	}
+	if h.SourceSha3_384 == "" || h.TargetSha3_384 == "" {
+		return errors.New("missing source or target digest")
+	}
+	return nil
+}
+
+// SourceMismatchError is returned by Apply when the source snap is not
+// the one the delta was generated from.
+type SourceMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *SourceMismatchError) Error() string {
+	return fmt.Sprintf("delta was generated from a snap with sha3-384 %q, not %q", e.Expected, e.Actual)
+}
+
+func fileDigest(path string) (string, uint64, error) {
+	d, size, err := osutil.FileDigest(path, crypto.SHA3_384)
+	if err != nil {
+		return "", 0, err
+	}
+	return base64.RawURLEncoding.EncodeToString(d), size, nil
+}
+
+func xdelta3Command(args ...string) *exec.Cmd {
+	cmd, err := snapdtoolCommandFromSystemSnap("/usr/bin/xdelta3")
+	if err != nil {
+		cmd = exec.Command("xdelta3")
+	}
+	cmd.Args = append(cmd.Args, args...)
+	return cmd
+}
+
+func runXdelta3(args ...string) error {
+	cmd := xdelta3Command(args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("xdelta3 failed: %v", osutil.OutputErr(out, err))
+	}
+	return nil
+}
+
+// Generate writes to out a delta artifact turning the source snap into
+// the target snap, both named snapName, and returns its header.
+func Generate(snapName, source, target, out string) (*Header, error) {
+	hdr := &Header{
+		Format:   FormatXdelta3,
+		SnapName: snapName,
+	}
+	var err error
+	if hdr.SourceSha3_384, hdr.SourceSize, err = fileDigest(source); err != nil {
+		return nil, fmt.Errorf("cannot digest source snap: %v", err)
+	}
+	if hdr.TargetSha3_384, hdr.TargetSize, err = fileDigest(target); err != nil {
+		return nil, fmt.Errorf("cannot digest target snap: %v", err)
+	}
+	if err := hdr.validate(); err != nil {
+		return nil, fmt.Errorf("cannot generate delta: %v", err)
+	}
+
+	payload, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".payload.")
+	if err != nil {
+		return nil, err
+	}
+	payload.Close()
+	defer os.Remove(payload.Name())
+	// -f: the payload file exists already, -S none: the snaps are
+	// compressed already so secondary compression only costs time
+	if err := runXdelta3("-e", "-f", "-S", "none", "-s", source, target, payload.Name()); err != nil {
+		return nil, fmt.Errorf("cannot generate delta: %v", err)
+	}
+
+	hdrBytes, err := json.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+	f, err := osutil.NewAtomicFile(out, 0644, 0, osutil.NoChown, osutil.NoChown)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Cancel()
+	if _, err := io.WriteString(f, Magic); err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append(hdrBytes, '\n')); err != nil {
+		return nil, err
+	}
+	p, err := os.Open(payload.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	if _, err := io.Copy(f, p); err != nil {
+		return nil, err
+	}
+	if err := f.Commit(); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+func readHeader(r *bufio.Reader) (*Header, error) {
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != Magic {
+		return nil, errors.New("not a snap delta")
+	}
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		if err == bufio.ErrBufferFull {
+			return nil, errors.New("delta header too long")
+		}
+		return nil, fmt.Errorf("cannot read delta header: %v", err)
+	}
+	var hdr Header
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, fmt.Errorf("cannot decode delta header: %v", err)
+	}
+	if err := hdr.validate(); err != nil {
+		return nil, fmt.Errorf("invalid delta header: %v", err)
+	}
+	return &hdr, nil
+}
+
+// ReadHeader reads the header of the delta artifact at the given path.
+func ReadHeader(deltaPath string) (*Header, error) {
+	f, err := os.Open(deltaPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readHeader(bufio.NewReaderSize(f, maxHeaderSize))
+}
+
+// Apply reconstructs into target the snap described by the delta
+// artifact at deltaPath using source as the base. The source must be
+// the snap the delta was generated from, and the reconstructed target
+// must match the digest and size recorded in the header, otherwise
+// target is not created.
+func Apply(source, deltaPath, target string) (*Header, error) {
+	f, err := os.Open(deltaPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, maxHeaderSize)
+	hdr, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDigest, _, err := fileDigest(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot digest source snap: %v", err)
+	}
+	if sourceDigest != hdr.SourceSha3_384 {
+		return nil, &SourceMismatchError{Expected: hdr.SourceSha3_384, Actual: sourceDigest}
+	}
+
+	// xdelta3 wants a file for the payload
+	payload, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".payload.")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(payload.Name())
+	_, err = io.Copy(payload, r)
+	if cerr := payload.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract delta payload: %v", err)
+	}
+
+	partial := target + ".partial"
+	defer os.Remove(partial)
+	if err := runXdelta3("-d", "-f", "-s", source, payload.Name(), partial); err != nil {
+		return nil, fmt.Errorf("cannot apply delta: %v", err)
+	}
+
+	targetDigest, targetSize, err := fileDigest(partial)
+	if err != nil {
+		return nil, err
+	}
+	if targetDigest != hdr.TargetSha3_384 || targetSize != hdr.TargetSize {
+		return nil, fmt.Errorf("cannot apply delta: reconstructed snap has sha3-384 %q and size %d, expected %q and %d", targetDigest, targetSize, hdr.TargetSha3_384, hdr.TargetSize)
+	}
+	if err := os.Rename(partial, target); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
@@ -0,0 +1,182 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package delta_test
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap/delta"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type deltaSuite struct {
+	testutil.BaseTest
+
+	dir     string
+	xdelta3 *testutil.MockCmd
+}
+
+var _ = Suite(&deltaSuite{})
+
+// the mocked xdelta3 copies its input to its output, which makes the
+// "payload" the target itself and applying it reproduce the target
+const copyInputToOutput = `cp "${@: -2:1}" "${@: -1}"`
+
+func (s *deltaSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.AddCleanup(delta.MockCommandFromSystemSnap(func(string, ...string) (*exec.Cmd, error) {
+		return nil, errors.New("no system snap")
+	}))
+	s.xdelta3 = testutil.MockCommand(c, "xdelta3", copyInputToOutput)
+	s.AddCleanup(s.xdelta3.Restore)
+}
+
+func (s *deltaSuite) writeFile(c *C, name, content string) string {
+	p := filepath.Join(s.dir, name)
+	c.Assert(os.WriteFile(p, []byte(content), 0644), IsNil)
+	return p
+}
+
+func (s *deltaSuite) TestGenerateAndApply(c *C) {
+	source := s.writeFile(c, "foo_1.snap", "old content")
+	target := s.writeFile(c, "foo_2.snap", "new content")
+	out := filepath.Join(s.dir, "foo_2.delta")
+
+	hdr, err := delta.Generate("foo", source, target, out)
+	c.Assert(err, IsNil)
+	c.Check(hdr.Format, Equals, "xdelta3")
+	c.Check(hdr.SnapName, Equals, "foo")
+	c.Check(hdr.SourceSize, Equals, uint64(len("old content")))
+	c.Check(hdr.TargetSize, Equals, uint64(len("new content")))
+	c.Check(hdr.SourceSha3_384, Not(Equals), hdr.TargetSha3_384)
+
+	read, err := delta.ReadHeader(out)
+	c.Assert(err, IsNil)
+	c.Check(read, DeepEquals, hdr)
+
+	calls := s.xdelta3.Calls()
+	c.Assert(calls, HasLen, 1)
+	c.Check(calls[0][:6], DeepEquals, []string{"xdelta3", "-e", "-f", "-S", "none", "-s"})
+	c.Check(calls[0][6:8], DeepEquals, []string{source, target})
+
+	rebuilt := filepath.Join(s.dir, "rebuilt.snap")
+	applied, err := delta.Apply(source, out, rebuilt)
+	c.Assert(err, IsNil)
+	c.Check(applied, DeepEquals, hdr)
+	c.Check(rebuilt, testutil.FileEquals, "new content")
+	c.Check(s.xdelta3.Calls()[1][:5], DeepEquals, []string{"xdelta3", "-d", "-f", "-s", source})
+
+	// no leftovers
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.p*"))
+	c.Assert(err, IsNil)
+	c.Check(matches, HasLen, 0)
+}
+
+func (s *deltaSuite) TestApplyWrongSource(c *C) {
+	source := s.writeFile(c, "foo_1.snap", "old content")
+	target := s.writeFile(c, "foo_2.snap", "new content")
+	other := s.writeFile(c, "foo_3.snap", "other content")
+	out := filepath.Join(s.dir, "foo_2.delta")
+	_, err := delta.Generate("foo", source, target, out)
+	c.Assert(err, IsNil)
+
+	rebuilt := filepath.Join(s.dir, "rebuilt.snap")
+	_, err = delta.Apply(other, out, rebuilt)
+	c.Assert(err, FitsTypeOf, &delta.SourceMismatchError{})
+	c.Check(err, ErrorMatches, `delta was generated from a snap with sha3-384 ".*", not ".*"`)
+	c.Check(rebuilt, testutil.FileAbsent)
+	// xdelta3 was not run to apply
+	c.Check(s.xdelta3.Calls(), HasLen, 1)
+}
+
+func (s *deltaSuite) TestApplyTargetMismatch(c *C) {
+	source := s.writeFile(c, "foo_1.snap", "old content")
+	target := s.writeFile(c, "foo_2.snap", "new content")
+	out := filepath.Join(s.dir, "foo_2.delta")
+	_, err := delta.Generate("foo", source, target, out)
+	c.Assert(err, IsNil)
+
+	// a broken xdelta3 producing garbage
+	broken := testutil.MockCommand(c, "xdelta3", `echo garbage > "${@: -1}"`)
+	defer broken.Restore()
+
+	rebuilt := filepath.Join(s.dir, "rebuilt.snap")
+	_, err = delta.Apply(source, out, rebuilt)
+	c.Check(err, ErrorMatches, `cannot apply delta: reconstructed snap has sha3-384 ".*" and size 8, expected ".*" and 11`)
+	c.Check(rebuilt, testutil.FileAbsent)
+	c.Check(rebuilt+".partial", testutil.FileAbsent)
+}
+
+func (s *deltaSuite) TestXdelta3Fails(c *C) {
+	broken := testutil.MockCommand(c, "xdelta3", `echo "xdelta3: boom" >&2; exit 1`)
+	defer broken.Restore()
+
+	source := s.writeFile(c, "foo_1.snap", "old content")
+	target := s.writeFile(c, "foo_2.snap", "new content")
+	out := filepath.Join(s.dir, "foo_2.delta")
+	_, err := delta.Generate("foo", source, target, out)
+	c.Check(err, ErrorMatches, `cannot generate delta: xdelta3 failed: xdelta3: boom`)
+	c.Check(out, testutil.FileAbsent)
+}
+
+func (s *deltaSuite) TestReadHeaderErrors(c *C) {
+	for _, t := range []struct {
+		content, err string
+	}{
+		{"", "not a snap delta"},
+		{"hello world, not a delta", "not a snap delta"},
+		{delta.Magic + "{}", "cannot read delta header: EOF"},
+		{delta.Magic + "{]\n", "cannot decode delta header: .*"},
+		{delta.Magic + `{"format":"bsdiff"}` + "\n", `invalid delta header: unsupported delta format "bsdiff" \(only xdelta3 currently\)`},
+		{delta.Magic + `{"format":"xdelta3"}` + "\n", "invalid delta header: missing snap name"},
+		{delta.Magic + `{"format":"xdelta3","snap-name":"foo"}` + "\n", "invalid delta header: missing source or target digest"},
+		{delta.Magic + `{"format":"xdelta3","snap-name":"foo","what":1}` + "\n", `cannot decode delta header: json: unknown field "what"`},
+	} {
+		p := s.writeFile(c, "bad.delta", t.content)
+		_, err := delta.ReadHeader(p)
+		c.Check(err, ErrorMatches, t.err, Commentf("%q", t.content))
+	}
+}
+
+func (s *deltaSuite) TestReadHeaderTooLong(c *C) {
+	p := filepath.Join(s.dir, "long.delta")
+	f, err := os.Create(p)
+	c.Assert(err, IsNil)
+	w := bufio.NewWriter(f)
+	w.WriteString(delta.Magic)
+	for i := 0; i < 70*1024; i++ {
+		w.WriteByte(' ')
+	}
+	c.Assert(w.Flush(), IsNil)
+	c.Assert(f.Close(), IsNil)
+
+	_, err = delta.ReadHeader(p)
+	c.Check(err, ErrorMatches, "delta header too long")
+}
@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/metautil"
 	"github.com/snapcore/snapd/osutil"
 	"github.com/snapcore/snapd/osutil/sys"
@@ -1136,7 +1137,77 @@ type SocketInfo struct {
 type TimerInfo struct {
 	App *AppInfo
 
+	// Timer is the calendar schedule of the timer, in the format of
+	// timeutil.ParseSchedule. It can be empty if the timer is only
+	// relative to the boot or to the last activation of the service.
 	Timer string
+	// OnBoot is the time after boot at which the timer first elapses.
+	OnBoot timeout.Timeout
+	// OnUnitActive is the time after the last activation of the service
+	// at which the timer elapses again.
+	OnUnitActive timeout.Timeout
+	// RandomizedDelay is the maximum random delay added to each elapse of
+	// the timer.
+	RandomizedDelay timeout.Timeout
+	// Persistent is whether the service is run at startup if the timer
+	// elapsed, according to its schedule, while the system was down.
+	Persistent bool
+}
+
+// IsCalendarOnly returns whether the timer is a plain calendar schedule,
+// which elapses only within the time windows of the schedule.
+func (timer *TimerInfo) IsCalendarOnly() bool {
+	return timer.OnBoot == 0 && timer.OnUnitActive == 0 && timer.RandomizedDelay == 0 && !timer.Persistent
+}
+
+// PathInfo provides information on a path which activates a service.
+type PathInfo struct {
+	App *AppInfo
+
+	Path string
+	// Trigger is the condition on Path which activates the service, one
+	// of "path-exists", "path-exists-glob", "path-changed",
+	// "path-modified" or "directory-not-empty".
+	Trigger string
+}
+
+// ResourceHintsInfo provides information on the resources a service is
+// expected to need. They are used as the limits of a default quota sub-group
+// for the service, when the system allows it.
+type ResourceHintsInfo struct {
+	App *AppInfo
+
+	// Memory is the memory limit, if any.
+	Memory quantity.Size
+	// CPUCount and CPUPercentage form the cpu limit, as in "2x50%". The
+	// count is zero when only a percentage was given.
+	CPUCount      int
+	CPUPercentage int
+	// Threads is the limit on the number of threads, if any.
+	Threads int
+}
+
+// HealthProbeInfo provides information on the health probe of a service.
+// Exactly one of HTTP, TCP or Command is set.
+type HealthProbeInfo struct {
+	App *AppInfo
+
+	// HTTP is a URL that must answer a GET request with a 2xx or 3xx
+	// status code.
+	HTTP string
+	// TCP is a host:port address that must accept connections.
+	TCP string
+	// Command is a command run in the context of the app that must
+	// exit successfully.
+	Command string
+
+	// Interval is the time between probes.
+	Interval timeout.Timeout
+	// Timeout is the time after which a probe is considered failed.
+	Timeout timeout.Timeout
+	// Threshold is how many consecutive probes must fail for the
+	// service to be considered unhealthy.
+	Threshold int
 }
 
 // StopModeType is the type for the "stop-mode:" of a snap app
@@ -1221,8 +1292,18 @@ type AppInfo struct {
 	After  []string
 	Before []string
 
+	// AfterPlugs lists plugs of the app; the service starts after, and
+	// wants, the services bound to the slots these plugs are connected to.
+	AfterPlugs []string
+
 	Timer *TimerInfo
 
+	ActivatesOnPath []*PathInfo
+
+	Health *HealthProbeInfo
+
+	Resources *ResourceHintsInfo
+
 	Autostart string
 }
 
@@ -1323,6 +1404,11 @@ func (timer *TimerInfo) File() string {
 	return filepath.Join(timer.App.serviceDir(), timer.App.SecurityTag()+".timer")
 }
 
+// PathFile returns the path to the *.path file activating the app.
+func (app *AppInfo) PathFile() string {
+	return filepath.Join(app.serviceDir(), app.SecurityTag()+".path")
+}
+
 func (app *AppInfo) String() string {
 	return JoinSnapApp(app.Snap.InstanceName(), app.Name)
 }
@@ -1369,7 +1455,10 @@ func (app *AppInfo) launcherCommand(command string) string {
 // LauncherCommand returns the launcher command line to use when invoking the
 // app binary.
 func (app *AppInfo) LauncherCommand() string {
-	if app.Timer != nil {
+	// the runner only checks that a plain calendar timer elapsed within
+	// its schedule, runs delayed or relative to boot or to the previous
+	// run can happen outside of it
+	if app.Timer != nil && app.Timer.IsCalendarOnly() {
 		return app.launcherCommand(fmt.Sprintf("--timer=%q", app.Timer.Timer))
 	}
 	return app.launcherCommand("")
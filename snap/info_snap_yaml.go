@@ -22,12 +22,14 @@ package snap
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/metautil"
 	"github.com/snapcore/snapd/strutil"
 	"github.com/snapcore/snapd/timeout"
@@ -105,11 +107,59 @@ type appYaml struct {
 	After  []string `yaml:"after,omitempty"`
 	Before []string `yaml:"before,omitempty"`
 
-	Timer string `yaml:"timer,omitempty"`
+	AfterPlugs []string `yaml:"after-plugs,omitempty"`
+
+	Timer *timerYaml `yaml:"timer,omitempty"`
+
+	ActivatesOnPath []pathYaml `yaml:"activates-on-path,omitempty"`
+
+	Health *healthYaml `yaml:"health,omitempty"`
+
+	Resources *resourcesYaml `yaml:"resources,omitempty"`
 
 	Autostart string `yaml:"autostart,omitempty"`
 }
 
+// timerYaml is either a bare calendar schedule or a mapping with the
+// schedule and the other timer options.
+type timerYaml struct {
+	Schedule        string          `yaml:"schedule,omitempty"`
+	OnBoot          timeout.Timeout `yaml:"on-boot,omitempty"`
+	OnUnitActive    timeout.Timeout `yaml:"on-unit-active,omitempty"`
+	RandomizedDelay timeout.Timeout `yaml:"randomized-delay,omitempty"`
+	Persistent      bool            `yaml:"persistent,omitempty"`
+}
+
+func (t *timerYaml) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var schedule string
+	if err := unmarshal(&schedule); err == nil {
+		*t = timerYaml{Schedule: schedule}
+		return nil
+	}
+	type plainTimerYaml timerYaml
+	return unmarshal((*plainTimerYaml)(t))
+}
+
+type pathYaml struct {
+	Path    string `yaml:"path"`
+	Trigger string `yaml:"trigger,omitempty"`
+}
+
+type healthYaml struct {
+	HTTP      string          `yaml:"http,omitempty"`
+	TCP       string          `yaml:"tcp,omitempty"`
+	Command   string          `yaml:"command,omitempty"`
+	Interval  timeout.Timeout `yaml:"interval,omitempty"`
+	Timeout   timeout.Timeout `yaml:"timeout,omitempty"`
+	Threshold int             `yaml:"threshold,omitempty"`
+}
+
+type resourcesYaml struct {
+	Memory  string `yaml:"memory,omitempty"`
+	CPU     string `yaml:"cpu,omitempty"`
+	Threads int    `yaml:"threads,omitempty"`
+}
+
 type hookYaml struct {
 	PlugNames    []string           `yaml:"plugs,omitempty"`
 	SlotNames    []string           `yaml:"slots,omitempty"`
@@ -452,6 +502,7 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 			InstallMode:     yApp.InstallMode,
 			Before:          yApp.Before,
 			After:           yApp.After,
+			AfterPlugs:      yApp.AfterPlugs,
 			Autostart:       yApp.Autostart,
 			WatchdogTimeout: yApp.WatchdogTimeout,
 		}
@@ -532,12 +583,46 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 				SocketMode:   data.SocketMode,
 			}
 		}
-		if yApp.Timer != "" {
+		if yApp.Timer != nil && *yApp.Timer != (timerYaml{}) {
 			app.Timer = &TimerInfo{
-				App:   app,
-				Timer: yApp.Timer,
+				App:             app,
+				Timer:           yApp.Timer.Schedule,
+				OnBoot:          yApp.Timer.OnBoot,
+				OnUnitActive:    yApp.Timer.OnUnitActive,
+				RandomizedDelay: yApp.Timer.RandomizedDelay,
+				Persistent:      yApp.Timer.Persistent,
 			}
 		}
+		for _, data := range yApp.ActivatesOnPath {
+			trigger := data.Trigger
+			if trigger == "" {
+				trigger = "path-changed"
+			}
+			app.ActivatesOnPath = append(app.ActivatesOnPath, &PathInfo{
+				App:     app,
+				Path:    data.Path,
+				Trigger: trigger,
+			})
+		}
+		if yApp.Health != nil {
+			app.Health = &HealthProbeInfo{
+				App:       app,
+				HTTP:      yApp.Health.HTTP,
+				TCP:       yApp.Health.TCP,
+				Command:   yApp.Health.Command,
+				Interval:  yApp.Health.Interval,
+				Timeout:   yApp.Health.Timeout,
+				Threshold: yApp.Health.Threshold,
+			}
+		}
+		if yApp.Resources != nil {
+			resources, err := resourceHintsFromYaml(yApp.Resources)
+			if err != nil {
+				return fmt.Errorf("invalid resources on app %q: %v", appName, err)
+			}
+			resources.App = app
+			app.Resources = resources
+		}
 		// collect all common IDs
 		if app.CommonID != "" {
 			snap.CommonIDs = append(snap.CommonIDs, app.CommonID)
@@ -546,6 +631,44 @@ func setAppsFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) error {
 	return nil
 }
 
+// example cpu hint: "2x50%", "90%"
+var cpuHintMatcher = regexp.MustCompile(`^(?:([0-9]+)x)?([0-9]+)%$`)
+
+func resourceHintsFromYaml(y *resourcesYaml) (*ResourceHintsInfo, error) {
+	resources := &ResourceHintsInfo{
+		Threads: y.Threads,
+	}
+	if y.Memory != "" {
+		memory, err := strutil.ParseByteSize(y.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory: %v", err)
+		}
+		resources.Memory = quantity.Size(memory)
+	}
+	if y.CPU != "" {
+		match := cpuHintMatcher.FindStringSubmatch(y.CPU)
+		if match == nil {
+			return nil, fmt.Errorf("cannot parse cpu %q: expected a percentage like \"90%%\" or \"2x50%%\"", y.CPU)
+		}
+		if match[1] != "" {
+			count, err := strconv.Atoi(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse cpu count %q: %v", match[1], err)
+			}
+			resources.CPUCount = count
+		}
+		percentage, err := strconv.Atoi(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse cpu percentage %q: %v", match[2], err)
+		}
+		if percentage == 0 {
+			return nil, fmt.Errorf("cpu percentage cannot be zero")
+		}
+		resources.CPUPercentage = percentage
+	}
+	return resources, nil
+}
+
 func setHooksFromSnapYaml(y snapYaml, snap *Info, strk *scopedTracker) {
 	for hookName, yHook := range y.Hooks {
 		if !IsHookSupported(hookName) {
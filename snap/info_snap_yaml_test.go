@@ -28,6 +28,7 @@ import (
 
 	. "gopkg.in/check.v1"
 
+	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/snap/naming"
 	"github.com/snapcore/snapd/strutil"
@@ -1881,6 +1882,158 @@ apps:
 	c.Check(app.Timer, DeepEquals, &snap.TimerInfo{App: app, Timer: "mon,10:00-12:00"})
 }
 
+func (s *YamlSuite) TestSnapYamlAppTimerOptions(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   daemon: oneshot
+   timer:
+     schedule: mon,10:00-12:00
+     randomized-delay: 5m
+     persistent: true
+ bar:
+   daemon: oneshot
+   timer:
+     on-boot: 15m
+     on-unit-active: 15m
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	foo := info.Apps["foo"]
+	c.Check(foo.Timer, DeepEquals, &snap.TimerInfo{
+		App:             foo,
+		Timer:           "mon,10:00-12:00",
+		RandomizedDelay: timeout.Timeout(5 * time.Minute),
+		Persistent:      true,
+	})
+	bar := info.Apps["bar"]
+	c.Check(bar.Timer, DeepEquals, &snap.TimerInfo{
+		App:          bar,
+		OnBoot:       timeout.Timeout(15 * time.Minute),
+		OnUnitActive: timeout.Timeout(15 * time.Minute),
+	})
+}
+
+func (s *YamlSuite) TestSnapYamlAppHealth(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   daemon: simple
+   health:
+     http: http://localhost:8080/healthz
+     interval: 30s
+     timeout: 5s
+     threshold: 3
+ bar:
+   daemon: simple
+   health:
+     command: bin/check
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	foo := info.Apps["foo"]
+	c.Check(foo.Health, DeepEquals, &snap.HealthProbeInfo{
+		App:       foo,
+		HTTP:      "http://localhost:8080/healthz",
+		Interval:  timeout.Timeout(30 * time.Second),
+		Timeout:   timeout.Timeout(5 * time.Second),
+		Threshold: 3,
+	})
+	bar := info.Apps["bar"]
+	c.Check(bar.Health, DeepEquals, &snap.HealthProbeInfo{App: bar, Command: "bin/check"})
+}
+
+func (s *YamlSuite) TestSnapYamlAppResources(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   daemon: simple
+   resources:
+     memory: 256MB
+     cpu: 2x50%
+     threads: 64
+ bar:
+   daemon: simple
+   resources:
+     cpu: 25%
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	foo := info.Apps["foo"]
+	c.Check(foo.Resources, DeepEquals, &snap.ResourceHintsInfo{
+		App:           foo,
+		Memory:        quantity.Size(256 * 1000 * 1000),
+		CPUCount:      2,
+		CPUPercentage: 50,
+		Threads:       64,
+	})
+	bar := info.Apps["bar"]
+	c.Check(bar.Resources, DeepEquals, &snap.ResourceHintsInfo{App: bar, CPUPercentage: 25})
+}
+
+func (s *YamlSuite) TestSnapYamlAppResourcesInvalid(c *C) {
+	for _, tc := range []struct {
+		resources string
+		err       string
+	}{
+		{"memory: 256", `invalid resources on app "foo": invalid memory: cannot parse "256": need a number with a unit as input`},
+		{"memory: 1QB", `invalid resources on app "foo": invalid memory: cannot parse "1QB": try 'kB' or 'MB'`},
+		{"cpu: 50", `invalid resources on app "foo": cannot parse cpu "50": expected a percentage like "90%" or "2x50%"`},
+		{"cpu: 2x", `invalid resources on app "foo": cannot parse cpu "2x": expected a percentage like "90%" or "2x50%"`},
+		{"cpu: 0%", `invalid resources on app "foo": cpu percentage cannot be zero`},
+	} {
+		y := fmt.Sprintf(`name: wat
+version: 42
+apps:
+ foo:
+   daemon: simple
+   resources:
+     %s
+`, tc.resources)
+		_, err := snap.InfoFromSnapYaml([]byte(y))
+		c.Check(err, ErrorMatches, tc.err, Commentf(tc.resources))
+	}
+}
+
+func (s *YamlSuite) TestSnapYamlAppAfterPlugs(c *C) {
+	y := []byte(`name: wat
+version: 42
+plugs:
+ db:
+  interface: content
+apps:
+ foo:
+   daemon: simple
+   after-plugs: [db]
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	c.Check(info.Apps["foo"].AfterPlugs, DeepEquals, []string{"db"})
+}
+
+func (s *YamlSuite) TestSnapYamlAppActivatesOnPath(c *C) {
+	y := []byte(`name: wat
+version: 42
+apps:
+ foo:
+   daemon: simple
+   activates-on-path:
+     - path: $SNAP_COMMON/inbox
+       trigger: directory-not-empty
+     - path: $SNAP_DATA/config
+`)
+	info, err := snap.InfoFromSnapYaml(y)
+	c.Assert(err, IsNil)
+	foo := info.Apps["foo"]
+	c.Check(foo.ActivatesOnPath, DeepEquals, []*snap.PathInfo{
+		{App: foo, Path: "$SNAP_COMMON/inbox", Trigger: "directory-not-empty"},
+		{App: foo, Path: "$SNAP_DATA/config", Trigger: "path-changed"},
+	})
+}
+
 func (s *YamlSuite) TestSnapYamlAppAutostart(c *C) {
 	yAutostart := []byte(`name: wat
 version: 42
@@ -290,6 +290,11 @@ apps:
    baz:
      command: bar-bin -x
      timer: 10:00-12:00,,mon,12:00~14:00
+   qux:
+     command: bar-bin -x
+     timer:
+       schedule: 10:00-12:00
+       persistent: true
 `))
 	c.Assert(err, IsNil)
 	info.Revision = snap.R(42)
@@ -299,6 +304,8 @@ apps:
 	c.Check(info.Apps["bar"].LauncherPostStopCommand(), Equals, "/usr/bin/snap run --command=post-stop foo.bar")
 	c.Check(info.Apps["foo"].LauncherCommand(), Equals, "/usr/bin/snap run foo")
 	c.Check(info.Apps["baz"].LauncherCommand(), Equals, `/usr/bin/snap run --timer="10:00-12:00,,mon,12:00~14:00" foo.baz`)
+	// catch-up runs happen outside of the schedule
+	c.Check(info.Apps["qux"].LauncherCommand(), Equals, "/usr/bin/snap run foo.qux")
 
 	// snap with instance key
 	info.InstanceKey = "instance"
@@ -22,6 +22,7 @@ package snap
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -671,6 +672,26 @@ func validateAppTimeouts(app *AppInfo) error {
 	return nil
 }
 
+func validateAppAfterPlugs(app *AppInfo) error {
+	if len(app.AfterPlugs) == 0 {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("must be a service to define after-plugs ordering")
+	}
+
+	for i, name := range app.AfterPlugs {
+		if _, ok := app.Plugs[name]; !ok {
+			return fmt.Errorf("after-plugs references plug %q which is not bound to the app", name)
+		}
+		if strutil.ListContains(app.AfterPlugs[:i], name) {
+			return fmt.Errorf("after-plugs references plug %q more than once", name)
+		}
+	}
+	return nil
+}
+
 func validateAppTimer(app *AppInfo) error {
 	if app.Timer == nil {
 		return nil
@@ -680,8 +701,129 @@ func validateAppTimer(app *AppInfo) error {
 		return errors.New("timer is only applicable to services")
 	}
 
-	if _, err := timeutil.ParseSchedule(app.Timer.Timer); err != nil {
-		return fmt.Errorf("timer has invalid format: %v", err)
+	timer := app.Timer
+	if timer.Timer != "" {
+		if _, err := timeutil.ParseSchedule(timer.Timer); err != nil {
+			return fmt.Errorf("timer has invalid format: %v", err)
+		}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value timeout.Timeout
+	}{
+		{"on-boot", timer.OnBoot},
+		{"on-unit-active", timer.OnUnitActive},
+		{"randomized-delay", timer.RandomizedDelay},
+	} {
+		if d.value < 0 {
+			return fmt.Errorf("timer %s cannot be negative", d.name)
+		}
+	}
+
+	switch {
+	case timer.Timer == "" && timer.OnBoot == 0 && timer.OnUnitActive == 0:
+		return errors.New("timer must have a schedule, on-boot or on-unit-active")
+	case timer.Timer == "" && timer.OnBoot == 0:
+		// the service is otherwise never activated for the timer to
+		// count from
+		return errors.New("timer on-unit-active requires a schedule or on-boot")
+	case timer.Persistent && timer.Timer == "":
+		return errors.New("timer persistent is only applicable with a schedule")
+	}
+
+	return nil
+}
+
+func validateAppHealth(app *AppInfo) error {
+	probe := app.Health
+	if probe == nil {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("health is only applicable to services")
+	}
+
+	kinds := 0
+	if probe.HTTP != "" {
+		kinds++
+		u, err := url.Parse(probe.HTTP)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("health http probe must be an http or https URL, not %q", probe.HTTP)
+		}
+		if !isLoopbackHost(u.Hostname()) {
+			return fmt.Errorf("health http probe must be of a loopback host, not %q", u.Hostname())
+		}
+	}
+	if probe.TCP != "" {
+		kinds++
+		host, port, err := net.SplitHostPort(probe.TCP)
+		if err != nil {
+			return fmt.Errorf("health tcp probe must be a host:port address: %v", err)
+		}
+		if !isLoopbackHost(host) {
+			return fmt.Errorf("health tcp probe must be of a loopback host, not %q", host)
+		}
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+			return fmt.Errorf("health tcp probe has invalid port %q", port)
+		}
+	}
+	if probe.Command != "" {
+		kinds++
+		if err := validateField("health command", probe.Command, appContentWhitelist); err != nil {
+			return err
+		}
+	}
+	if kinds != 1 {
+		return errors.New("health must declare exactly one of http, tcp or command probes")
+	}
+
+	if probe.Interval < 0 {
+		return errors.New("health interval cannot be negative")
+	}
+	if probe.Timeout < 0 {
+		return errors.New("health timeout cannot be negative")
+	}
+	if probe.Threshold < 0 {
+		return errors.New("health threshold cannot be negative")
+	}
+
+	return nil
+}
+
+// isLoopbackHost tells whether host is one of the loopback hosts health
+// probes are allowed to reach, snapd must not be used to probe other hosts
+// on behalf of snaps.
+func isLoopbackHost(host string) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func validateAppResources(app *AppInfo) error {
+	resources := app.Resources
+	if resources == nil {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("resources are only applicable to services")
+	}
+
+	if resources.Memory == 0 && resources.CPUPercentage == 0 && resources.Threads == 0 {
+		return errors.New("resources must have at least one of memory, cpu or threads")
+	}
+	if resources.CPUPercentage == 0 && resources.CPUCount != 0 {
+		return errors.New("resources cpu percentage must be set")
+	}
+	if resources.CPUPercentage > 100 {
+		return fmt.Errorf("resources cpu percentage must be between 1 and 100, not %d", resources.CPUPercentage)
+	}
+	if resources.Threads < 0 {
+		return errors.New("resources threads cannot be negative")
 	}
 
 	return nil
@@ -749,6 +891,72 @@ func validateAppActivatesOn(app *AppInfo) error {
 	return nil
 }
 
+// pathActivationContentWhitelist is the whitelist of legal chars in the
+// paths of activates-on-path; it notably excludes whitespace and "%", which
+// would be interpreted by systemd.
+var pathActivationContentWhitelist = regexp.MustCompile(`^[A-Za-z0-9/._+,@=$*?\[\]-]*$`)
+
+func validateAppActivatesOnPath(app *AppInfo) error {
+	if len(app.ActivatesOnPath) == 0 {
+		return nil
+	}
+
+	if !app.IsService() {
+		return errors.New("activates-on-path is only applicable to services")
+	}
+
+	var prefixes []string
+	switch app.DaemonScope {
+	case SystemDaemon:
+		prefixes = []string{"$SNAP_DATA", "$SNAP_COMMON", "$XDG_RUNTIME_DIR"}
+	case UserDaemon:
+		prefixes = []string{"$SNAP_USER_DATA", "$SNAP_USER_COMMON", "$XDG_RUNTIME_DIR"}
+	default:
+		return fmt.Errorf("cannot validate activates-on-path for daemon-scope %q", app.DaemonScope)
+	}
+
+	seen := make(map[PathInfo]bool, len(app.ActivatesOnPath))
+	for _, p := range app.ActivatesOnPath {
+		switch p.Trigger {
+		case "path-exists", "path-exists-glob", "path-changed", "path-modified", "directory-not-empty":
+			// valid
+		default:
+			return fmt.Errorf("invalid activates-on-path trigger %q for path %q", p.Trigger, p.Path)
+		}
+		if !pathActivationContentWhitelist.MatchString(p.Path) {
+			return fmt.Errorf("invalid activates-on-path path %q: contains invalid characters", p.Path)
+		}
+		if clean := filepath.Clean(p.Path); clean != p.Path {
+			return fmt.Errorf("invalid activates-on-path path %q: should be written as %q", p.Path, clean)
+		}
+		var rest string
+		hasPrefix := false
+		for _, prefix := range prefixes {
+			if p.Path == prefix || strings.HasPrefix(p.Path, prefix+"/") {
+				rest = p.Path[len(prefix):]
+				hasPrefix = true
+				break
+			}
+		}
+		if !hasPrefix {
+			return fmt.Errorf("invalid activates-on-path path %q: %s daemon paths must have a prefix of %s", p.Path, app.DaemonScope, strutil.Quoted(prefixes))
+		}
+		if strings.Contains(rest, "$") {
+			return fmt.Errorf("invalid activates-on-path path %q: variables are only supported as prefix", p.Path)
+		}
+		if strings.ContainsAny(rest, "*?[]") && p.Trigger != "path-exists-glob" {
+			return fmt.Errorf("invalid activates-on-path path %q: globs can only be used with the path-exists-glob trigger", p.Path)
+		}
+		key := PathInfo{Path: p.Path, Trigger: p.Trigger}
+		if seen[key] {
+			return fmt.Errorf("invalid activates-on-path path %q: duplicated for trigger %q", p.Path, p.Trigger)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
 // appContentWhitelist is the whitelist of legal chars in the "apps"
 // section of snap.yaml. Do not allow any of [',",`] here or snap-exec
 // will get confused. chainContentWhitelist is the same, but for the
@@ -827,6 +1035,10 @@ func ValidateApp(app *AppInfo) error {
 		return err
 	}
 
+	if err := validateAppActivatesOnPath(app); err != nil {
+		return err
+	}
+
 	if err := validateAppRestart(app); err != nil {
 		return err
 	}
@@ -836,11 +1048,22 @@ func ValidateApp(app *AppInfo) error {
 	if err := validateAppOrderNames(app, app.After); err != nil {
 		return err
 	}
+	if err := validateAppAfterPlugs(app); err != nil {
+		return err
+	}
 
 	if err := validateAppTimeouts(app); err != nil {
 		return err
 	}
 
+	if err := validateAppHealth(app); err != nil {
+		return err
+	}
+
+	if err := validateAppResources(app); err != nil {
+		return err
+	}
+
 	// validate stop-mode
 	if err := app.StopMode.Validate(); err != nil {
 		return err
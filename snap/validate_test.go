@@ -1544,6 +1544,90 @@ apps:
 	}
 }
 
+func (s *ValidateSuite) TestValidateAppAfterPlugs(c *C) {
+	meta := []byte(`
+name: foo
+version: 1.0
+plugs:
+ db:
+  interface: content
+ cache:
+  interface: network
+`)
+	tcs := []struct {
+		name string
+		desc string
+		err  string
+	}{{
+		name: "global plugs",
+		desc: `
+apps:
+ foo:
+   daemon: simple
+   after-plugs: [db, cache]
+`,
+	}, {
+		name: "app plug",
+		desc: `
+apps:
+ foo:
+   daemon: simple
+   plugs: [network-bind]
+   after-plugs: [network-bind]
+`,
+	}, {
+		name: "not a service",
+		desc: `
+apps:
+ foo:
+   after-plugs: [db]
+`,
+		err: `invalid definition of application "foo": must be a service to define after-plugs ordering`,
+	}, {
+		name: "missing plug",
+		desc: `
+apps:
+ foo:
+   daemon: simple
+   after-plugs: [queue]
+`,
+		err: `invalid definition of application "foo": after-plugs references plug "queue" which is not bound to the app`,
+	}, {
+		name: "plug of another app",
+		desc: `
+apps:
+ foo:
+   daemon: simple
+   after-plugs: [network-bind]
+ bar:
+   daemon: simple
+   plugs: [network-bind]
+`,
+		err: `invalid definition of application "foo": after-plugs references plug "network-bind" which is not bound to the app`,
+	}, {
+		name: "duplicated",
+		desc: `
+apps:
+ foo:
+   daemon: simple
+   after-plugs: [db, db]
+`,
+		err: `invalid definition of application "foo": after-plugs references plug "db" more than once`,
+	}}
+	for _, tc := range tcs {
+		c.Logf("trying %q", tc.name)
+		info, err := InfoFromSnapYaml(append(meta, tc.desc...))
+		c.Assert(err, IsNil)
+
+		err = Validate(info)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
 func (s *ValidateSuite) TestValidateAppWatchdogTimeout(c *C) {
 	s.testValidateAppTimeout(c, "watchdog")
 }
@@ -1648,6 +1732,78 @@ apps:
 		name: "invalid timer",
 		desc: badTimer,
 		err:  `timer has invalid format: cannot parse "mon2-wed3": invalid schedule fragment`,
+	}, {
+		name: "monotonic timer",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      on-boot: 15m
+      on-unit-active: 15m
+      randomized-delay: 1m
+`),
+	}, {
+		name: "persistent calendar timer",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      schedule: mon,10:00-12:00
+      persistent: true
+`),
+	}, {
+		name: "bad schedule in mapping",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      schedule: mon,10:00-12:00,mon2-wed3
+`),
+		err: `timer has invalid format: cannot parse "mon2-wed3": invalid schedule fragment`,
+	}, {
+		name: "no schedule",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      randomized-delay: 1m
+`),
+		err: `timer must have a schedule, on-boot or on-unit-active`,
+	}, {
+		name: "on-unit-active alone",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      on-unit-active: 15m
+`),
+		err: `timer on-unit-active requires a schedule or on-boot`,
+	}, {
+		name: "persistent without schedule",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      on-boot: 15m
+      persistent: true
+`),
+		err: `timer persistent is only applicable with a schedule`,
+	}, {
+		name: "negative duration",
+		desc: []byte(`
+apps:
+  foo:
+    daemon: oneshot
+    timer:
+      on-boot: -15m
+`),
+		err: `timer on-boot cannot be negative`,
 	}}
 	for _, tc := range tcs {
 		c.Logf("trying %q", tc.name)
@@ -1663,6 +1819,164 @@ apps:
 	}
 }
 
+func (s *YamlSuite) TestValidateAppHealth(c *C) {
+	meta := []byte(`
+name: foo
+version: 1.0
+`)
+	tcs := []struct {
+		name   string
+		health string
+		daemon string
+		err    string
+	}{
+		{name: "http", health: "http: http://localhost:8080/healthz"},
+		{name: "https", health: "http: https://127.0.0.1/ready"},
+		{name: "tcp", health: "tcp: localhost:5432"},
+		{name: "command", health: "command: bin/check --quick"},
+		{name: "all options", health: "tcp: localhost:5432\n      interval: 10s\n      timeout: 2s\n      threshold: 5"},
+		{name: "not a service", health: "tcp: localhost:5432", daemon: "-", err: "health is only applicable to services"},
+		{name: "none", health: "interval: 10s", err: "health must declare exactly one of http, tcp or command probes"},
+		{name: "two", health: "tcp: localhost:5432\n      command: bin/check", err: "health must declare exactly one of http, tcp or command probes"},
+		{name: "bad url", health: "http: ftp://localhost/", err: `health http probe must be an http or https URL, not "ftp://localhost/"`},
+		{name: "bad address", health: "tcp: localhost", err: "health tcp probe must be a host:port address: .*"},
+		{name: "bad port", health: "tcp: localhost:99999", err: `health tcp probe has invalid port "99999"`},
+		{name: "ipv6 loopback", health: "tcp: '[::1]:5432'"},
+		{name: "remote url", health: "http: http://example.com/healthz", err: `health http probe must be of a loopback host, not "example.com"`},
+		{name: "remote ip url", health: "http: http://10.0.0.1:8080/", err: `health http probe must be of a loopback host, not "10.0.0.1"`},
+		{name: "remote address", health: "tcp: 192.168.1.1:22", err: `health tcp probe must be of a loopback host, not "192.168.1.1"`},
+		{name: "any address", health: "tcp: 0.0.0.0:22", err: `health tcp probe must be of a loopback host, not "0.0.0.0"`},
+		{name: "bad command", health: "command: bin/check;reboot", err: `app description field 'health command' contains illegal "bin/check;reboot" \(legal: .*\)`},
+		{name: "negative threshold", health: "tcp: localhost:1\n      threshold: -1", err: "health threshold cannot be negative"},
+	}
+	for _, tc := range tcs {
+		c.Logf("trying %q", tc.name)
+		daemon := "\n    daemon: simple"
+		if tc.daemon == "-" {
+			daemon = ""
+		}
+		desc := fmt.Sprintf(`
+apps:
+  foo:%s
+    health:
+      %s
+`, daemon, tc.health)
+		info, err := InfoFromSnapYaml(append(meta, desc...))
+		c.Assert(err, IsNil)
+
+		err = Validate(info)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, `invalid definition of application "foo": `+tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *YamlSuite) TestValidateAppResources(c *C) {
+	meta := []byte(`
+name: foo
+version: 1.0
+`)
+	tcs := []struct {
+		name      string
+		resources string
+		daemon    string
+		err       string
+	}{
+		{name: "memory", resources: "memory: 256MB"},
+		{name: "cpu", resources: "cpu: 50%"},
+		{name: "cpu count", resources: "cpu: 2x50%"},
+		{name: "threads", resources: "threads: 64"},
+		{name: "all", resources: "memory: 1GB\n      cpu: 2x100%\n      threads: 128"},
+		{name: "not a service", resources: "memory: 256MB", daemon: "-", err: "resources are only applicable to services"},
+		{name: "none", resources: "{}", err: "resources must have at least one of memory, cpu or threads"},
+		{name: "cpu too high", resources: "cpu: 150%", err: "resources cpu percentage must be between 1 and 100, not 150"},
+		{name: "negative threads", resources: "threads: -1", err: "resources threads cannot be negative"},
+	}
+	for _, tc := range tcs {
+		c.Logf("trying %q", tc.name)
+		daemon := "\n    daemon: simple"
+		if tc.daemon == "-" {
+			daemon = ""
+		}
+		desc := fmt.Sprintf(`
+apps:
+  foo:%s
+    resources:
+      %s
+`, daemon, tc.resources)
+		info, err := InfoFromSnapYaml(append(meta, desc...))
+		c.Assert(err, IsNil)
+
+		err = Validate(info)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, `invalid definition of application "foo": `+tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
+func (s *YamlSuite) TestValidateAppActivatesOnPath(c *C) {
+	meta := []byte(`
+name: foo
+version: 1.0
+`)
+	tcs := []struct {
+		name   string
+		path   string
+		scope  string
+		daemon string
+		err    string
+	}{
+		{name: "common dir", path: "path: $SNAP_COMMON/inbox\n        trigger: directory-not-empty"},
+		{name: "data file", path: "path: $SNAP_DATA/config.yaml"},
+		{name: "whole dir", path: "path: $SNAP_COMMON\n        trigger: path-modified"},
+		{name: "glob", path: "path: $SNAP_COMMON/inbox/*.csv\n        trigger: path-exists-glob"},
+		{name: "runtime dir", path: "path: $XDG_RUNTIME_DIR/ready\n        trigger: path-exists"},
+		{name: "user dir", path: "path: $SNAP_USER_DATA/inbox", scope: "user"},
+		{name: "not a service", path: "path: $SNAP_COMMON/inbox", daemon: "-", err: "activates-on-path is only applicable to services"},
+		{name: "bad trigger", path: "path: $SNAP_COMMON/inbox\n        trigger: path-gone", err: `invalid activates-on-path trigger "path-gone" for path "\$SNAP_COMMON/inbox"`},
+		{name: "outside", path: "path: /etc/passwd", err: `invalid activates-on-path path "/etc/passwd": system daemon paths must have a prefix of "\$SNAP_DATA", "\$SNAP_COMMON", "\$XDG_RUNTIME_DIR"`},
+		{name: "snap dir", path: "path: $SNAP/bin", err: `invalid activates-on-path path "\$SNAP/bin": system daemon paths must have a prefix of .*`},
+		{name: "prefix lookalike", path: "path: $SNAP_COMMONX/inbox", err: `invalid activates-on-path path "\$SNAP_COMMONX/inbox": system daemon paths must have a prefix of .*`},
+		{name: "user paths for system", path: "path: $SNAP_USER_DATA/inbox", err: `invalid activates-on-path path "\$SNAP_USER_DATA/inbox": system daemon paths must have a prefix of .*`},
+		{name: "system paths for user", path: "path: $SNAP_COMMON/inbox", scope: "user", err: `invalid activates-on-path path "\$SNAP_COMMON/inbox": user daemon paths must have a prefix of "\$SNAP_USER_DATA", "\$SNAP_USER_COMMON", "\$XDG_RUNTIME_DIR"`},
+		{name: "unclean", path: "path: $SNAP_COMMON/../etc", err: `invalid activates-on-path path "\$SNAP_COMMON/../etc": should be written as "etc"`},
+		{name: "specifier", path: "path: $SNAP_COMMON/%h", err: `invalid activates-on-path path "\$SNAP_COMMON/%h": contains invalid characters`},
+		{name: "spaces", path: "path: $SNAP_COMMON/in box", err: `invalid activates-on-path path "\$SNAP_COMMON/in box": contains invalid characters`},
+		{name: "inner variable", path: "path: $SNAP_COMMON/$SNAP_DATA", err: `invalid activates-on-path path "\$SNAP_COMMON/\$SNAP_DATA": variables are only supported as prefix`},
+		{name: "glob without glob trigger", path: "path: $SNAP_COMMON/*.csv", err: `invalid activates-on-path path "\$SNAP_COMMON/\*.csv": globs can only be used with the path-exists-glob trigger`},
+		{name: "duplicated", path: "path: $SNAP_COMMON/inbox\n      - path: $SNAP_COMMON/inbox", err: `invalid activates-on-path path "\$SNAP_COMMON/inbox": duplicated for trigger "path-changed"`},
+	}
+	for _, tc := range tcs {
+		c.Logf("trying %q", tc.name)
+		daemon := "\n    daemon: simple"
+		if tc.daemon == "-" {
+			daemon = ""
+		}
+		if tc.scope != "" {
+			daemon += "\n    daemon-scope: " + tc.scope
+		}
+		desc := fmt.Sprintf(`
+apps:
+  foo:%s
+    activates-on-path:
+      - %s
+`, daemon, tc.path)
+		info, err := InfoFromSnapYaml(append(meta, desc...))
+		c.Assert(err, IsNil)
+
+		err = Validate(info)
+		if tc.err != "" {
+			c.Check(err, ErrorMatches, `invalid definition of application "foo": `+tc.err)
+		} else {
+			c.Check(err, IsNil)
+		}
+	}
+}
+
 func (s *ValidateSuite) TestValidateOsCannotHaveBase(c *C) {
 	info, err := InfoFromSnapYaml([]byte(`name: foo
 version: 1.0
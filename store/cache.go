@@ -20,28 +20,35 @@
 package store
 
 import (
+	"crypto"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/snapcore/snapd/logger"
 	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/strutil"
 )
 
 // overridden in the unit tests
-var osRemove = os.Remove
+var (
+	osRemove      = os.Remove
+	syscallStatfs = syscall.Statfs
+)
 
 // downloadCache is the interface that a store download cache must provide
 type downloadCache interface {
 	// Get retrieves the given cacheKey content and puts it into targetPath. Returns
 	// true if a cached file was moved to targetPath or if one was already there.
 	Get(cacheKey, targetPath string) bool
-	// Put adds a new file to the cache
-	Put(cacheKey, sourcePath string) error
+	// Put adds a new file to the cache, without evicting the pinned ones
+	Put(cacheKey, sourcePath string, pinned map[string]string) error
 	// Get full path of the file in cache
 	GetPath(cacheKey string) string
 }
@@ -55,7 +62,9 @@ func (cm *nullCache) Get(cacheKey, targetPath string) bool {
 func (cm *nullCache) GetPath(cacheKey string) string {
 	return ""
 }
-func (cm *nullCache) Put(cacheKey, sourcePath string) error { return nil }
+func (cm *nullCache) Put(cacheKey, sourcePath string, pinned map[string]string) error {
+	return nil
+}
 
 // changesByMtime sorts by the mtime of files
 type changesByMtime []os.FileInfo
@@ -68,6 +77,12 @@ func (s changesByMtime) Less(i, j int) bool { return s[i].ModTime().Before(s[j].
 type CacheManager struct {
 	cacheDir string
 	maxItems int
+
+	// maxSize is the maximum size of the items owned by the cache, or 0
+	maxSize int64
+	// minFreePercent is the share of the filesystem of the cache to keep
+	// free by evicting items, or 0
+	minFreePercent int
 }
 
 // NewCacheManager returns a new CacheManager with the given cacheDir
@@ -91,6 +106,35 @@ func NewCacheManager(cacheDir string, maxItems int) *CacheManager {
 	}
 }
 
+// SetLimits sets the maximum total size of the items owned by the cache and
+// the share of the filesystem to keep free, in percent. Items are evicted,
+// oldest first, until both hold. Zero values disable the limits.
+func (cm *CacheManager) SetLimits(maxSize int64, minFreePercent int) {
+	cm.maxSize = maxSize
+	cm.minFreePercent = minFreePercent
+}
+
+// ParseCacheLimits parses the limits of the download cache as set with the
+// store.cache.max-size option, a size such as "2GB", and the
+// store.cache.min-free option, a percentage such as "10%". Empty values
+// mean no limit.
+func ParseCacheLimits(maxSize, minFree string) (maxBytes int64, minFreePercent int, err error) {
+	if maxSize != "" {
+		maxBytes, err = strutil.ParseByteSize(maxSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot parse download cache maximum size: %v", err)
+		}
+	}
+	if minFree != "" {
+		percent, ok := strings.CutSuffix(minFree, "%")
+		minFreePercent, err = strconv.Atoi(percent)
+		if !ok || err != nil || minFreePercent < 0 || minFreePercent >= 100 {
+			return 0, 0, fmt.Errorf("download cache minimum free space %q must be a percentage between 0%% and 99%%", minFree)
+		}
+	}
+	return maxBytes, minFreePercent, nil
+}
+
 // GetPath returns the full path of the given content in the cache
 // or empty string
 func (cm *CacheManager) GetPath(cacheKey string) string {
@@ -114,8 +158,10 @@ func (cm *CacheManager) Get(cacheKey, targetPath string) bool {
 	return true
 }
 
-// Put adds a new file to the cache with the given cacheKey
-func (cm *CacheManager) Put(cacheKey, sourcePath string) error {
+// Put adds a new file to the cache with the given cacheKey. Items are
+// evicted to stay within the limits of the cache, except the pinned ones,
+// given with the reason why by cache key.
+func (cm *CacheManager) Put(cacheKey, sourcePath string, pinned map[string]string) error {
 	// always try to create the cache dir first or the following
 	// osutil.IsWritable will always fail if the dir is missing
 	_ = os.MkdirAll(cm.cacheDir, 0700)
@@ -140,7 +186,7 @@ func (cm *CacheManager) Put(cacheKey, sourcePath string) error {
 	if err != nil {
 		return err
 	}
-	return cm.cleanup()
+	return cm.cleanup(pinned)
 }
 
 // count returns the number of items in the cache
@@ -158,8 +204,25 @@ func (cm *CacheManager) path(cacheKey string) string {
 	return filepath.Join(cm.cacheDir, cacheKey)
 }
 
-// cleanup ensures that only maxItems are stored in the cache
-func (cm *CacheManager) cleanup() error {
+// lowOnSpace returns whether less than minFreePercent of the filesystem of
+// the cache is free.
+func (cm *CacheManager) lowOnSpace() bool {
+	if cm.minFreePercent <= 0 {
+		return false
+	}
+	var st syscall.Statfs_t
+	if err := syscallStatfs(cm.cacheDir, &st); err != nil {
+		logger.Noticef("cannot inspect free space of cache: %v", err)
+		return false
+	}
+	return st.Blocks > 0 && st.Bavail*100 < uint64(cm.minFreePercent)*st.Blocks
+}
+
+// cleanup ensures that only maxItems, of at most maxSize bytes overall, are
+// stored in the cache, and that enough of the filesystem is left free.
+// Files referenced elsewhere in the filesystem take no space of their own
+// and pinned files are never removed.
+func (cm *CacheManager) cleanup(pinned map[string]string) error {
 	entries, err := os.ReadDir(cm.cacheDir)
 	if err != nil {
 		return err
@@ -176,11 +239,12 @@ func (cm *CacheManager) cleanup() error {
 		fil = append(fil, fi)
 	}
 
-	if len(fil) <= cm.maxItems {
+	if len(fil) <= cm.maxItems && cm.maxSize == 0 && cm.minFreePercent == 0 {
 		return nil
 	}
 
 	numOwned := 0
+	var ownedSize int64
 	for _, fi := range fil {
 		n, err := hardLinkCount(fi)
 		if err != nil {
@@ -189,16 +253,26 @@ func (cm *CacheManager) cleanup() error {
 		// Only count the file if it is not referenced elsewhere in the filesystem
 		if n <= 1 {
 			numOwned++
+			ownedSize += fi.Size()
 		}
 	}
 
-	if numOwned <= cm.maxItems {
+	deleted := 0
+	mustEvict := func() bool {
+		if numOwned-deleted > cm.maxItems {
+			return true
+		}
+		if cm.maxSize > 0 && ownedSize > cm.maxSize {
+			return true
+		}
+		return cm.lowOnSpace()
+	}
+	if !mustEvict() {
 		return nil
 	}
 
 	var lastErr error
 	sort.Sort(changesByMtime(fil))
-	deleted := 0
 	for _, fi := range fil {
 		path := cm.path(fi.Name())
 		n, err := hardLinkCount(fi)
@@ -211,6 +285,10 @@ func (cm *CacheManager) cleanup() error {
 		if n > 1 {
 			continue
 		}
+		if reason := pinned[fi.Name()]; reason != "" {
+			logger.Debugf("Keeping pinned cache entry …%.5s: %s.", fi.Name(), reason)
+			continue
+		}
 		if err := osRemove(path); err != nil {
 			if !os.IsNotExist(err) {
 				logger.Noticef("cannot cleanup cache: %s", err)
@@ -219,13 +297,123 @@ func (cm *CacheManager) cleanup() error {
 			continue
 		}
 		deleted++
-		if numOwned-deleted <= cm.maxItems {
+		ownedSize -= fi.Size()
+		if !mustEvict() {
 			break
 		}
 	}
 	return lastErr
 }
 
+// CacheEntry describes an item of the download cache.
+type CacheEntry struct {
+	Digest  string    `json:"digest"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	// InUse is set if the item is also linked from elsewhere in the
+	// filesystem, e.g. as the blob of an installed revision, it then takes
+	// no space of its own.
+	InUse bool `json:"in-use,omitempty"`
+	// Pinned is the reason the item is never evicted, if any.
+	Pinned string `json:"pinned,omitempty"`
+}
+
+// Entries returns the items of the cache, the most recently used first,
+// marking the pinned ones with the reason why.
+func (cm *CacheManager) Entries(pinned map[string]string) ([]*CacheEntry, error) {
+	dirEntries, err := os.ReadDir(cm.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]*CacheEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		fi, err := dirEntry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				// evicted meanwhile
+				continue
+			}
+			return nil, err
+		}
+		n, _ := hardLinkCount(fi)
+		entries = append(entries, &CacheEntry{
+			Digest:  fi.Name(),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+			InUse:   n > 1,
+			Pinned:  pinned[fi.Name()],
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// Verify hashes the items of the cache and removes the ones which do not
+// match their sha3-384 digest, which it returns.
+func (cm *CacheManager) Verify() (corrupted []string, err error) {
+	entries, err := cm.Entries(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		digest, _, err := osutil.FileDigest(cm.path(entry.Digest), crypto.SHA3_384)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return corrupted, err
+		}
+		if fmt.Sprintf("%x", digest) == entry.Digest {
+			continue
+		}
+		logger.Noticef("Removing corrupted cache entry %s.", entry.Digest)
+		if err := osRemove(cm.path(entry.Digest)); err != nil && !os.IsNotExist(err) {
+			return corrupted, err
+		}
+		corrupted = append(corrupted, entry.Digest)
+	}
+	return corrupted, nil
+}
+
+// Purge removes the given items from the cache, or all the items which are
+// not pinned if none is given, and returns the removed ones.
+func (cm *CacheManager) Purge(digests []string, pinned map[string]string) (purged []string, err error) {
+	entries, err := cm.Entries(pinned)
+	if err != nil {
+		return nil, err
+	}
+	byDigest := make(map[string]*CacheEntry, len(entries))
+	for _, entry := range entries {
+		byDigest[entry.Digest] = entry
+	}
+	if len(digests) == 0 {
+		for _, entry := range entries {
+			if entry.Pinned == "" {
+				digests = append(digests, entry.Digest)
+			}
+		}
+	}
+	for _, digest := range digests {
+		entry := byDigest[digest]
+		if entry == nil {
+			return purged, fmt.Errorf("cannot purge %q: not in the download cache", digest)
+		}
+		if entry.Pinned != "" {
+			return purged, fmt.Errorf("cannot purge %q: pinned for %s", digest, entry.Pinned)
+		}
+		if err := osRemove(cm.path(digest)); err != nil && !os.IsNotExist(err) {
+			return purged, err
+		}
+		purged = append(purged, digest)
+	}
+	return purged, nil
+}
+
 // hardLinkCount returns the number of hardlinks for the given path
 func hardLinkCount(fi os.FileInfo) (uint64, error) {
 	if stat, ok := fi.Sys().(*syscall.Stat_t); ok && stat != nil {
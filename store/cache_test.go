@@ -24,8 +24,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"syscall"
 	"time"
 
+	"golang.org/x/crypto/sha3"
 	. "gopkg.in/check.v1"
 
 	"github.com/snapcore/snapd/osutil"
@@ -70,7 +72,7 @@ func (s *cacheSuite) TestPutMany(c *C) {
 
 	for i := 1; i < s.maxItems+10; i++ {
 		p := s.makeTestFileInDir(c, dataDir.Name(), fmt.Sprintf("f%d", i), fmt.Sprintf("%d", i))
-		err := s.cm.Put(fmt.Sprintf("cacheKey-%d", i), p)
+		err := s.cm.Put(fmt.Sprintf("cacheKey-%d", i), p, nil)
 		c.Check(err, IsNil)
 
 		// Remove the test file again, it is now only in the cache
@@ -103,7 +105,7 @@ func (s *cacheSuite) TestGetNotExistent(c *C) {
 func (s *cacheSuite) TestGet(c *C) {
 	canary := "some content"
 	p := s.makeTestFile(c, "foo", canary)
-	err := s.cm.Put("some-cache-key", p)
+	err := s.cm.Put("some-cache-key", p, nil)
 	c.Assert(err, IsNil)
 
 	targetPath := filepath.Join(s.tmp, "new-location")
@@ -120,7 +122,7 @@ func (s *cacheSuite) makeTestFiles(c *C, n int) (cacheKeys []string, testFiles [
 		p := s.makeTestFile(c, fmt.Sprintf("f%d", i), strconv.Itoa(i))
 		cacheKey := fmt.Sprintf("cacheKey-%d", i)
 		cacheKeys[i] = cacheKey
-		s.cm.Put(cacheKey, p)
+		s.cm.Put(cacheKey, p, nil)
 
 		// keep track of the test files
 		testFiles[i] = p
@@ -144,7 +146,7 @@ func (s *cacheSuite) TestCleanup(c *C) {
 		err := os.Remove(p)
 		c.Assert(err, IsNil)
 	}
-	s.cm.Cleanup()
+	s.cm.Cleanup(nil)
 
 	// the oldest files are removed from the cache
 	c.Check(osutil.FileExists(filepath.Join(s.cm.CacheDir(), cacheKeys[0])), Equals, false)
@@ -172,7 +174,7 @@ func (s *cacheSuite) TestCleanupContinuesOnError(c *C) {
 	defer restore()
 
 	// verify that cleanup returns the last error
-	err := s.cm.Cleanup()
+	err := s.cm.Cleanup(nil)
 	c.Check(err, ErrorMatches, "simulated error")
 
 	// and also verify that the cache still got cleaned up
@@ -221,9 +223,163 @@ func (s *cacheSuite) TestCacheHitOnErrExist(c *C) {
 	c.Assert(err, IsNil)
 
 	// put file in target path
-	c.Assert(s.cm.Put("foo", targetPath), IsNil)
+	c.Assert(s.cm.Put("foo", targetPath, nil), IsNil)
 
 	// cache tries to link to an occupied path
 	cacheHit := s.cm.Get("foo", targetPath)
 	c.Assert(cacheHit, Equals, true)
 }
+
+// makeOwnedTestFiles puts n files in the cache which are not referenced
+// elsewhere, the oldest first.
+func (s *cacheSuite) makeOwnedTestFiles(c *C, n int) []string {
+	cacheKeys, testFiles := s.makeTestFiles(c, n)
+	for _, p := range testFiles {
+		c.Assert(os.Remove(p), IsNil)
+	}
+	return cacheKeys
+}
+
+func (s *cacheSuite) cached(cacheKey string) bool {
+	return osutil.FileExists(filepath.Join(s.cm.CacheDir(), cacheKey))
+}
+
+func (s *cacheSuite) TestCleanupMaxSize(c *C) {
+	s.cm = store.NewCacheManager(c.MkDir(), 100)
+	// each file holds a single digit
+	s.cm.SetLimits(3, 0)
+	cacheKeys := s.makeOwnedTestFiles(c, 5)
+
+	c.Assert(s.cm.Cleanup(nil), IsNil)
+	c.Check(s.cm.Count(), Equals, 3)
+	c.Check(s.cached(cacheKeys[0]), Equals, false)
+	c.Check(s.cached(cacheKeys[1]), Equals, false)
+	c.Check(s.cached(cacheKeys[2]), Equals, true)
+	c.Check(s.cached(cacheKeys[4]), Equals, true)
+}
+
+func (s *cacheSuite) TestCleanupMaxSizeIgnoresLinkedFiles(c *C) {
+	s.cm = store.NewCacheManager(c.MkDir(), 100)
+	s.cm.SetLimits(1, 0)
+	// the files are still linked from elsewhere
+	s.makeTestFiles(c, 5)
+
+	c.Assert(s.cm.Cleanup(nil), IsNil)
+	c.Check(s.cm.Count(), Equals, 5)
+}
+
+func (s *cacheSuite) TestCleanupMinFreePercent(c *C) {
+	s.cm = store.NewCacheManager(c.MkDir(), 100)
+	s.cm.SetLimits(0, 70)
+	cacheKeys := s.makeOwnedTestFiles(c, 5)
+
+	// each cached file takes a tenth of the filesystem
+	restore := store.MockSyscallStatfs(func(path string, st *syscall.Statfs_t) error {
+		c.Check(path, Equals, s.cm.CacheDir())
+		st.Blocks = 100
+		st.Bavail = uint64(100 - 10*s.cm.Count())
+		return nil
+	})
+	defer restore()
+
+	c.Assert(s.cm.Cleanup(nil), IsNil)
+	c.Check(s.cm.Count(), Equals, 3)
+	c.Check(s.cached(cacheKeys[1]), Equals, false)
+	c.Check(s.cached(cacheKeys[2]), Equals, true)
+}
+
+func (s *cacheSuite) TestCleanupKeepsPinned(c *C) {
+	s.cm = store.NewCacheManager(c.MkDir(), 100)
+	s.cm.SetLimits(2, 0)
+	cacheKeys := s.makeOwnedTestFiles(c, 5)
+	pinned := map[string]string{cacheKeys[0]: "pending refresh of foo"}
+
+	c.Assert(s.cm.Cleanup(pinned), IsNil)
+	c.Check(s.cm.Count(), Equals, 2)
+	c.Check(s.cached(cacheKeys[0]), Equals, true)
+	c.Check(s.cached(cacheKeys[1]), Equals, false)
+	c.Check(s.cached(cacheKeys[3]), Equals, false)
+	c.Check(s.cached(cacheKeys[4]), Equals, true)
+}
+
+func (s *cacheSuite) TestEntries(c *C) {
+	entries, err := store.NewCacheManager(filepath.Join(s.tmp, "missing"), 5).Entries(nil)
+	c.Assert(err, IsNil)
+	c.Check(entries, HasLen, 0)
+
+	cacheKeys, _ := s.makeTestFiles(c, 2)
+	p := s.makeTestFile(c, "owned", "owned")
+	c.Assert(s.cm.Put("owned", p, nil), IsNil)
+	c.Assert(os.Remove(p), IsNil)
+	pinned := map[string]string{cacheKeys[1]: "pending refresh of foo"}
+
+	entries, err = s.cm.Entries(pinned)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	// the most recent first
+	c.Check(entries[0].Digest, Equals, "owned")
+	c.Check(entries[0].Size, Equals, int64(5))
+	c.Check(entries[0].InUse, Equals, false)
+	c.Check(entries[0].Pinned, Equals, "")
+	c.Check(entries[1].Digest, Equals, "cacheKey-1")
+	c.Check(entries[1].InUse, Equals, true)
+	c.Check(entries[1].Pinned, Equals, "pending refresh of foo")
+	c.Check(entries[2].Digest, Equals, "cacheKey-0")
+	c.Check(entries[2].InUse, Equals, true)
+	c.Check(entries[1].ModTime.After(entries[2].ModTime), Equals, true)
+}
+
+func (s *cacheSuite) TestVerify(c *C) {
+	good := []byte("good content")
+	goodDigest := fmt.Sprintf("%x", sha3.Sum384(good))
+	badDigest := fmt.Sprintf("%x", sha3.Sum384([]byte("other content")))
+	c.Assert(s.cm.Put(goodDigest, s.makeTestFile(c, "good", string(good)), nil), IsNil)
+	c.Assert(s.cm.Put(badDigest, s.makeTestFile(c, "bad", "corrupted"), nil), IsNil)
+
+	corrupted, err := s.cm.Verify()
+	c.Assert(err, IsNil)
+	c.Check(corrupted, DeepEquals, []string{badDigest})
+	c.Check(s.cached(goodDigest), Equals, true)
+	c.Check(s.cached(badDigest), Equals, false)
+}
+
+func (s *cacheSuite) TestPurge(c *C) {
+	cacheKeys := s.makeOwnedTestFiles(c, 4)
+	pinned := map[string]string{cacheKeys[3]: "pending refresh of foo"}
+
+	purged, err := s.cm.Purge([]string{cacheKeys[0]}, pinned)
+	c.Assert(err, IsNil)
+	c.Check(purged, DeepEquals, []string{cacheKeys[0]})
+	c.Check(s.cached(cacheKeys[0]), Equals, false)
+
+	_, err = s.cm.Purge([]string{cacheKeys[3]}, pinned)
+	c.Check(err, ErrorMatches, `cannot purge "cacheKey-3": pinned for pending refresh of foo`)
+	_, err = s.cm.Purge([]string{"../cacheKey-1"}, pinned)
+	c.Check(err, ErrorMatches, `cannot purge "../cacheKey-1": not in the download cache`)
+
+	// everything which is not pinned
+	purged, err = s.cm.Purge(nil, pinned)
+	c.Assert(err, IsNil)
+	c.Check(purged, DeepEquals, []string{cacheKeys[2], cacheKeys[1]})
+	c.Check(s.cm.Count(), Equals, 1)
+	c.Check(s.cached(cacheKeys[3]), Equals, true)
+}
+
+func (s *cacheSuite) TestParseCacheLimits(c *C) {
+	maxSize, minFree, err := store.ParseCacheLimits("", "")
+	c.Assert(err, IsNil)
+	c.Check(maxSize, Equals, int64(0))
+	c.Check(minFree, Equals, 0)
+
+	maxSize, minFree, err = store.ParseCacheLimits("2GB", "15%")
+	c.Assert(err, IsNil)
+	c.Check(maxSize, Equals, int64(2*1000*1000*1000))
+	c.Check(minFree, Equals, 15)
+
+	_, _, err = store.ParseCacheLimits("lots", "")
+	c.Check(err, ErrorMatches, `cannot parse download cache maximum size: cannot parse "lots": .*`)
+	for _, minFree := range []string{"15", "x%", "-1%", "100%"} {
+		_, _, err = store.ParseCacheLimits("", minFree)
+		c.Check(err, ErrorMatches, fmt.Sprintf(`download cache minimum free space %q must be a percentage between 0%% and 99%%`, minFree))
+	}
+}
@@ -0,0 +1,809 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package dirstore implements a store which serves snaps and assertions from
+// a local directory, as written by "snap download" or found in a seed.
+package dirstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/client"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/channel"
+	"github.com/snapcore/snapd/snap/naming"
+	"github.com/snapcore/snapd/snap/snapfile"
+	"github.com/snapcore/snapd/store"
+)
+
+var snapfileOpen = snapfile.Open
+
+// BackendPrefix prefixes the directory in the store.backend option.
+const BackendPrefix = "dir:"
+
+// ParseBackend returns the directory of a store.backend option of the form
+// "dir:<path>", or "" if the option is unset.
+func ParseBackend(backend string) (dir string, err error) {
+	if backend == "" {
+		return "", nil
+	}
+	dir, ok := strings.CutPrefix(backend, BackendPrefix)
+	if !ok {
+		return "", fmt.Errorf("store backend must be of the form %s<path>", BackendPrefix)
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("store backend directory %q must be absolute", dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// errNotSupported is returned for the store operations which have no
+// meaning without an online store.
+var errNotSupported = errors.New("not supported by a directory store")
+
+// Store serves snaps and their assertions from a directory. Snaps are found
+// through the snap-revision assertion matching their digest, snap files
+// without one are ignored. The directory is scanned again whenever it is
+// queried so that snaps added to it become available for refreshes.
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+	// files caches the snaps read from the directory by path, with
+	// their info if they are asserted
+	files map[string]*snapFile
+	// snaps holds the asserted snaps by snap-id
+	snaps map[string][]*snapFile
+	// assertions holds the latest revision of the assertions by the
+	// unique form of their reference
+	assertions map[string]asserts.Assertion
+}
+
+type snapFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	digest  string
+	info    *snap.Info
+}
+
+// New returns a store serving the snaps and assertions in dir.
+func New(dir string) *Store {
+	return &Store{
+		dir:   dir,
+		files: make(map[string]*snapFile),
+	}
+}
+
+// Dir returns the directory the store serves from.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// scan reads the assertions in the directory and indexes the snaps with a
+// matching snap-revision assertion. It must be called with mu held.
+func (s *Store) scan() error {
+	var snapPaths []string
+	assertions := make(map[string]asserts.Assertion)
+	err := filepath.Walk(s.dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".snap":
+			snapPaths = append(snapPaths, path)
+		case ".assert":
+			if err := readAssertions(path, assertions); err != nil {
+				logger.Noticef("cannot read assertions from %q: %v", path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot read store directory: %v", err)
+	}
+
+	snapRevisions := make(map[string]*asserts.SnapRevision)
+	declarations := make(map[string]*asserts.SnapDeclaration)
+	for _, a := range assertions {
+		switch a := a.(type) {
+		case *asserts.SnapRevision:
+			snapRevisions[a.SnapSHA3_384()] = a
+		case *asserts.SnapDeclaration:
+			declarations[a.SnapID()] = a
+		}
+	}
+
+	files := make(map[string]*snapFile, len(snapPaths))
+	snaps := make(map[string][]*snapFile)
+	for _, path := range snapPaths {
+		sf, err := s.readSnapFile(path)
+		if err != nil {
+			logger.Noticef("cannot read snap %q: %v", path, err)
+			continue
+		}
+		files[path] = sf
+		snapRev := snapRevisions[sf.digest]
+		if snapRev == nil {
+			logger.Debugf("ignoring snap %q without a snap-revision assertion", path)
+			continue
+		}
+		decl := declarations[snapRev.SnapID()]
+		if decl == nil {
+			logger.Debugf("ignoring snap %q without a snap-declaration assertion", path)
+			continue
+		}
+		if sf.info == nil || sf.info.SnapID != snapRev.SnapID() || sf.info.Revision.N != snapRev.SnapRevision() {
+			info, err := s.snapInfo(sf, snapRev, decl, assertions)
+			if err != nil {
+				logger.Noticef("cannot read snap %q: %v", path, err)
+				continue
+			}
+			sf.info = info
+		}
+		snaps[sf.info.SnapID] = append(snaps[sf.info.SnapID], sf)
+	}
+	for _, revs := range snaps {
+		// latest revision first
+		sort.Slice(revs, func(i, j int) bool {
+			return revs[j].info.Revision.N < revs[i].info.Revision.N
+		})
+	}
+
+	s.files = files
+	s.snaps = snaps
+	s.assertions = assertions
+	return nil
+}
+
+// readSnapFile returns the file at path, digesting and reading it again only
+// if it changed since the last scan.
+func (s *Store) readSnapFile(path string) (*snapFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if sf := s.files[path]; sf != nil && sf.size == fi.Size() && sf.modTime.Equal(fi.ModTime()) {
+		return sf, nil
+	}
+	digest, _, err := asserts.SnapFileSHA3_384(path)
+	if err != nil {
+		return nil, err
+	}
+	return &snapFile{
+		path:    path,
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+		digest:  digest,
+	}, nil
+}
+
+func (s *Store) snapInfo(sf *snapFile, snapRev *asserts.SnapRevision, decl *asserts.SnapDeclaration, assertions map[string]asserts.Assertion) (*snap.Info, error) {
+	container, err := snapfileOpen(sf.path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := snap.ReadInfoFromSnapFile(container, &snap.SideInfo{
+		RealName: decl.SnapName(),
+		SnapID:   decl.SnapID(),
+		Revision: snap.R(snapRev.SnapRevision()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	info.Publisher = snap.StoreAccount{ID: decl.PublisherID()}
+	accRef := &asserts.Ref{Type: asserts.AccountType, PrimaryKey: []string{decl.PublisherID()}}
+	if acc, ok := assertions[accRef.Unique()].(*asserts.Account); ok {
+		info.Publisher.Username = acc.Username()
+		info.Publisher.DisplayName = acc.DisplayName()
+		info.Publisher.Validation = acc.Validation()
+	}
+	info.DownloadInfo = snap.DownloadInfo{
+		Size:     sf.size,
+		Sha3_384: sf.digest,
+	}
+	return info, nil
+}
+
+func readAssertions(path string, assertions map[string]asserts.Assertion) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := asserts.NewDecoder(f)
+	for {
+		a, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		key := a.Ref().Unique()
+		if prev := assertions[key]; prev == nil || prev.Revision() < a.Revision() {
+			assertions[key] = a
+		}
+	}
+}
+
+// latest returns the most recent revision of the snap, or the given
+// revision if set, which the given epoch can read.
+func (s *Store) latest(snapID string, rev snap.Revision, epoch *snap.Epoch) *snapFile {
+	for _, sf := range s.snaps[snapID] {
+		if !rev.Unset() && sf.info.Revision != rev {
+			continue
+		}
+		if epoch != nil && !sf.info.Epoch.CanRead(*epoch) {
+			continue
+		}
+		return sf
+	}
+	return nil
+}
+
+func (s *Store) byName(name string) *snapFile {
+	for snapID, revs := range s.snaps {
+		if revs[0].info.SnapName() == name {
+			return s.latest(snapID, snap.Revision{}, nil)
+		}
+	}
+	return nil
+}
+
+// result returns a copy of the snap info as it is handed out by the store.
+func result(sf *snapFile, instanceName, channelName string) *snap.Info {
+	info := *sf.info
+	info.Channel = channelName
+	if instanceName != "" {
+		_, info.InstanceKey = snap.SplitInstanceName(instanceName)
+	}
+	return &info
+}
+
+// EnsureDeviceSession does nothing as a directory store has no sessions.
+func (s *Store) EnsureDeviceSession() error {
+	return nil
+}
+
+// SnapInfo returns the latest revision of the named snap.
+func (s *Store) SnapInfo(ctx context.Context, spec store.SnapSpec, user *auth.UserState) (*snap.Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	sf := s.byName(spec.Name)
+	if sf == nil {
+		return nil, store.ErrSnapNotFound
+	}
+	return result(sf, "", "stable"), nil
+}
+
+// SnapExists returns a reference to the named snap if the directory has a
+// revision of it. All revisions are considered to be in the stable channel.
+func (s *Store) SnapExists(ctx context.Context, spec store.SnapSpec, user *auth.UserState) (naming.SnapRef, *channel.Channel, error) {
+	info, err := s.SnapInfo(ctx, spec, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := channel.Channel{Name: "stable", Risk: "stable"}
+	return naming.NewSnapRef(info.SnapName(), info.SnapID), &ch, nil
+}
+
+// Find returns the latest revision of the snaps whose name, or title or
+// summary for non-prefix queries, contains the query.
+func (s *Store) Find(ctx context.Context, search *store.Search, user *auth.UserState) ([]*snap.Info, error) {
+	if search.Private {
+		return nil, store.ErrUnauthenticated
+	}
+	if search.Category != "" || search.CommonID != "" {
+		return nil, store.ErrBadQuery
+	}
+	query := strings.ToLower(strings.TrimSpace(search.Query))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	var found []*snap.Info
+	for snapID := range s.snaps {
+		sf := s.latest(snapID, snap.Revision{}, nil)
+		name := sf.info.SnapName()
+		var match bool
+		if search.Prefix {
+			match = strings.HasPrefix(name, query)
+		} else {
+			match = strings.Contains(name, query) ||
+				strings.Contains(strings.ToLower(sf.info.Title()), query) ||
+				strings.Contains(strings.ToLower(sf.info.Summary()), query)
+		}
+		if match {
+			found = append(found, result(sf, "", "stable"))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].SnapName() < found[j].SnapName()
+	})
+	return found, nil
+}
+
+// SnapAction resolves install, refresh and download actions to the snaps in
+// the directory, and the assertion query to its assertions. The channel of
+// the actions is not considered as the directory has no channels.
+func (s *Store) SnapAction(ctx context.Context, currentSnaps []*store.CurrentSnap, actions []*store.SnapAction, assertQuery store.AssertionQuery, user *auth.UserState, opts *store.RefreshOptions) ([]store.SnapActionResult, []store.AssertionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return nil, nil, err
+	}
+
+	var aresults []store.AssertionResult
+	if assertQuery != nil {
+		var err error
+		aresults, err = s.resolveAssertions(assertQuery)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	curSnaps := make(map[string]*store.CurrentSnap, len(currentSnaps))
+	for _, cur := range currentSnaps {
+		curSnaps[cur.InstanceName] = cur
+	}
+
+	var sars []store.SnapActionResult
+	refreshErrors := make(map[string]error)
+	installErrors := make(map[string]error)
+	downloadErrors := make(map[string]error)
+	for _, a := range actions {
+		switch a.Action {
+		case "refresh":
+			cur := curSnaps[a.InstanceName]
+			if cur == nil {
+				return nil, nil, fmt.Errorf("internal error: no current snap for refresh of %q", a.InstanceName)
+			}
+			epoch := cur.Epoch
+			sf := s.latest(a.SnapID, a.Revision, &epoch)
+			if sf == nil {
+				refreshErrors[a.InstanceName] = store.ErrNoUpdateAvailable
+				continue
+			}
+			if !a.ResourceInstall && (sf.info.Revision == cur.Revision || revisionIn(sf.info.Revision, cur.Block)) {
+				refreshErrors[a.InstanceName] = store.ErrNoUpdateAvailable
+				continue
+			}
+			sars = append(sars, store.SnapActionResult{Info: result(sf, a.InstanceName, a.Channel)})
+		case "install", "download":
+			snapName, _ := snap.SplitInstanceName(a.InstanceName)
+			var sf *snapFile
+			if a.SnapID != "" {
+				sf = s.latest(a.SnapID, a.Revision, nil)
+			} else if named := s.byName(snapName); named != nil {
+				sf = s.latest(named.info.SnapID, a.Revision, nil)
+			}
+			if sf == nil {
+				err := store.ErrSnapNotFound
+				if !a.Revision.Unset() {
+					err = &store.RevisionNotAvailableError{Action: a.Action, Channel: a.Channel}
+				}
+				if a.Action == "install" {
+					installErrors[a.InstanceName] = err
+				} else {
+					downloadErrors[a.InstanceName] = err
+				}
+				continue
+			}
+			sars = append(sars, store.SnapActionResult{Info: result(sf, a.InstanceName, a.Channel)})
+		default:
+			return nil, nil, fmt.Errorf("internal error: unsupported action %q", a.Action)
+		}
+	}
+
+	if len(refreshErrors)+len(installErrors)+len(downloadErrors) != 0 || len(sars) == 0 {
+		// normalize empty maps
+		if len(refreshErrors) == 0 {
+			refreshErrors = nil
+		}
+		if len(installErrors) == 0 {
+			installErrors = nil
+		}
+		if len(downloadErrors) == 0 {
+			downloadErrors = nil
+		}
+		return sars, aresults, &store.SnapActionError{
+			NoResults: len(sars) == 0 && len(aresults) == 0,
+			Refresh:   refreshErrors,
+			Install:   installErrors,
+			Download:  downloadErrors,
+		}
+	}
+	return sars, aresults, nil
+}
+
+func revisionIn(rev snap.Revision, revs []snap.Revision) bool {
+	for _, r := range revs {
+		if r == rev {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAssertions returns a result for each grouping of the query with
+// assertions newer than the ones queried. The stream URLs of the results
+// are references to the assertions, as understood by DownloadAssertions.
+func (s *Store) resolveAssertions(assertQuery store.AssertionQuery) ([]store.AssertionResult, error) {
+	toResolve, toResolveSeq, err := assertQuery.ToResolve()
+	if err != nil {
+		return nil, err
+	}
+
+	var aresults []store.AssertionResult
+	for grouping, atRevs := range toResolve {
+		var urls []string
+		for _, at := range atRevs {
+			a := s.assertions[at.Ref.Unique()]
+			if a == nil {
+				headers, _ := asserts.HeadersFromPrimaryKey(at.Type, at.PrimaryKey)
+				if err := assertQuery.AddError(&asserts.NotFoundError{Type: at.Type, Headers: headers}, &at.Ref); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if a.Revision() > at.Revision {
+				urls = append(urls, assertionURL(a.Ref()))
+			}
+		}
+		if len(urls) != 0 {
+			aresults = append(aresults, store.AssertionResult{Grouping: grouping, StreamURLs: urls})
+		}
+	}
+	for grouping, atSeqs := range toResolveSeq {
+		var urls []string
+		for _, atSeq := range atSeqs {
+			seq := atSeq.Sequence
+			if !atSeq.Pinned {
+				seq = 0
+			}
+			a := s.seqFormingAssertion(atSeq.Type, atSeq.SequenceKey, seq)
+			if a == nil {
+				if err := assertQuery.AddSequenceError(&asserts.NotFoundError{Type: atSeq.Type}, atSeq); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if a.(asserts.SequenceMember).Sequence() > atSeq.Sequence || a.Revision() > atSeq.Revision {
+				urls = append(urls, assertionURL(a.Ref()))
+			}
+		}
+		if len(urls) != 0 {
+			aresults = append(aresults, store.AssertionResult{Grouping: grouping, StreamURLs: urls})
+		}
+	}
+	return aresults, nil
+}
+
+// assertionURL returns a reference to the assertion in the form of the
+// path of the assertions endpoint of the store.
+func assertionURL(ref *asserts.Ref) string {
+	parts := make([]string, 0, 1+len(ref.PrimaryKey))
+	parts = append(parts, ref.Type.Name)
+	for _, k := range ref.PrimaryKey {
+		parts = append(parts, url.PathEscape(k))
+	}
+	return strings.Join(parts, "/")
+}
+
+func (s *Store) seqFormingAssertion(assertType *asserts.AssertionType, sequenceKey []string, sequence int) asserts.Assertion {
+	var found asserts.Assertion
+	for _, a := range s.assertions {
+		if a.Type() != assertType {
+			continue
+		}
+		primaryKey := a.Ref().PrimaryKey
+		if !equalKeys(primaryKey[:len(primaryKey)-1], sequenceKey) {
+			continue
+		}
+		seq := a.(asserts.SequenceMember).Sequence()
+		if sequence > 0 && seq != sequence {
+			continue
+		}
+		if found == nil || found.(asserts.SequenceMember).Sequence() < seq {
+			found = a
+		}
+	}
+	return found
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sections returns no sections as a directory store has none.
+func (s *Store) Sections(ctx context.Context, user *auth.UserState) ([]string, error) {
+	return nil, nil
+}
+
+// Categories returns no categories as a directory store has none.
+func (s *Store) Categories(ctx context.Context, user *auth.UserState) ([]store.CategoryDetails, error) {
+	return nil, nil
+}
+
+// WriteCatalogs writes the names of the snaps in the directory and adds
+// their apps as commands.
+func (s *Store) WriteCatalogs(ctx context.Context, names io.Writer, adder store.SnapAdder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return err
+	}
+	snapNames := make([]string, 0, len(s.snaps))
+	latest := make(map[string]*snap.Info, len(s.snaps))
+	for snapID := range s.snaps {
+		info := s.latest(snapID, snap.Revision{}, nil).info
+		snapNames = append(snapNames, info.SnapName())
+		latest[info.SnapName()] = info
+	}
+	sort.Strings(snapNames)
+	for _, name := range snapNames {
+		info := latest[name]
+		if _, err := fmt.Fprintln(names, name); err != nil {
+			return err
+		}
+		commands := make([]string, 0, len(info.Apps))
+		for _, app := range info.Apps {
+			if !app.IsService() {
+				commands = append(commands, app.Name)
+			}
+		}
+		sort.Strings(commands)
+		if err := adder.AddSnap(name, info.Version, info.Summary(), commands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileByDigest returns the snap file with the given digest. Downloads are
+// looked up by digest so that only the snaps in the directory are served.
+func (s *Store) fileByDigest(digest string) (*snapFile, error) {
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	for _, sf := range s.files {
+		if sf.info != nil && sf.digest == digest {
+			return sf, nil
+		}
+	}
+	return nil, store.ErrSnapNotFound
+}
+
+// Download copies the snap with the digest of the download info to
+// targetPath.
+func (s *Store) Download(ctx context.Context, name, targetPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *store.DownloadOptions) error {
+	s.mu.Lock()
+	sf, err := s.fileByDigest(downloadInfo.Sha3_384)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+	src, err := os.Open(sf.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	partialPath := targetPath + ".partial"
+	dst, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dst.Close()
+		os.Remove(partialPath)
+	}()
+
+	if pbar == nil {
+		pbar = progress.Null
+	}
+	pbar.Start(name, float64(sf.size))
+	defer pbar.Finished()
+	if _, err := io.Copy(io.MultiWriter(dst, pbar), &contextReader{ctx: ctx, r: src}); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, targetPath)
+}
+
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// DownloadStream returns a reader of the snap with the digest of the
+// download info, from the given offset.
+func (s *Store) DownloadStream(ctx context.Context, name string, downloadInfo *snap.DownloadInfo, resume int64, user *auth.UserState) (io.ReadCloser, int, error) {
+	s.mu.Lock()
+	sf, err := s.fileByDigest(downloadInfo.Sha3_384)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(sf.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	status := http.StatusOK
+	if resume > 0 {
+		if _, err := f.Seek(resume, io.SeekStart); err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		status = http.StatusPartialContent
+	}
+	return f, status, nil
+}
+
+// Assertion returns the assertion with the given primary key.
+func (s *Store) Assertion(assertType *asserts.AssertionType, primaryKey []string, user *auth.UserState) (asserts.Assertion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	ref := &asserts.Ref{Type: assertType, PrimaryKey: primaryKey}
+	if a := s.assertions[ref.Unique()]; a != nil {
+		return a, nil
+	}
+	headers, _ := asserts.HeadersFromPrimaryKey(assertType, primaryKey)
+	return nil, &asserts.NotFoundError{Type: assertType, Headers: headers}
+}
+
+// SeqFormingAssertion returns the latest revision of the given sequence of
+// a sequence-forming assertion, or of the latest sequence if sequence is
+// not positive.
+func (s *Store) SeqFormingAssertion(assertType *asserts.AssertionType, sequenceKey []string, sequence int, user *auth.UserState) (asserts.Assertion, error) {
+	if !assertType.SequenceForming() {
+		return nil, fmt.Errorf("internal error: requested non sequence-forming assertion type %q", assertType.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.scan(); err != nil {
+		return nil, err
+	}
+	if a := s.seqFormingAssertion(assertType, sequenceKey, sequence); a != nil {
+		return a, nil
+	}
+	headers := make(map[string]string, len(sequenceKey)+1)
+	for i, keyVal := range sequenceKey {
+		if i < len(assertType.PrimaryKey) {
+			headers[assertType.PrimaryKey[i]] = keyVal
+		}
+	}
+	if sequence > 0 {
+		headers[assertType.PrimaryKey[len(assertType.PrimaryKey)-1]] = fmt.Sprintf("%d", sequence)
+	}
+	return nil, &asserts.NotFoundError{Type: assertType, Headers: headers}
+}
+
+// DownloadAssertions adds the assertions referenced by the stream URLs
+// returned by SnapAction to the batch.
+func (s *Store) DownloadAssertions(streamURLs []string, b *asserts.Batch, user *auth.UserState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range streamURLs {
+		parts := strings.Split(u, "/")
+		assertType := asserts.Type(parts[0])
+		if assertType == nil {
+			return fmt.Errorf("invalid assertions stream URL: %q", u)
+		}
+		primaryKey := make([]string, 0, len(parts)-1)
+		for _, p := range parts[1:] {
+			k, err := url.PathUnescape(p)
+			if err != nil {
+				return fmt.Errorf("invalid assertions stream URL: %v", err)
+			}
+			primaryKey = append(primaryKey, k)
+		}
+		ref := &asserts.Ref{Type: assertType, PrimaryKey: primaryKey}
+		a := s.assertions[ref.Unique()]
+		if a == nil {
+			return &asserts.NotFoundError{Type: assertType}
+		}
+		if err := b.Add(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SuggestedCurrency returns no currency as nothing can be bought.
+func (s *Store) SuggestedCurrency() string {
+	return ""
+}
+
+func (s *Store) Buy(options *client.BuyOptions, user *auth.UserState) (*client.BuyResult, error) {
+	return nil, errNotSupported
+}
+
+func (s *Store) ReadyToBuy(*auth.UserState) error {
+	return errNotSupported
+}
+
+// ConnectivityCheck reports whether the directory is accessible.
+func (s *Store) ConnectivityCheck() (map[string]bool, error) {
+	return map[string]bool{
+		BackendPrefix + s.dir: osutil.IsDirectory(s.dir),
+	}, nil
+}
+
+func (s *Store) CreateCohorts(context.Context, []string) (map[string]string, error) {
+	return nil, errNotSupported
+}
+
+func (s *Store) LoginUser(username, password, otp string) (string, string, error) {
+	return "", "", errNotSupported
+}
+
+func (s *Store) UserInfo(email string) (*store.User, error) {
+	return nil, errNotSupported
+}
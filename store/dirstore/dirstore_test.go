@@ -0,0 +1,496 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dirstore_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/asserts"
+	"github.com/snapcore/snapd/asserts/assertstest"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/snapstate"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/snap/snapdir"
+	"github.com/snapcore/snapd/snap/snaptest"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/store/dirstore"
+	"github.com/snapcore/snapd/testutil"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var _ snapstate.StoreService = (*dirstore.Store)(nil)
+
+type dirstoreSuite struct {
+	testutil.BaseTest
+
+	dir          string
+	storeSigning *assertstest.StoreStack
+	dev1Acct     *asserts.Account
+
+	// snapDirs maps the snap files to directories with their content
+	snapDirs map[string]string
+}
+
+var _ = Suite(&dirstoreSuite{})
+
+func (s *dirstoreSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.storeSigning = assertstest.NewStoreStack("can0nical", nil)
+	s.dev1Acct = assertstest.NewAccount(s.storeSigning, "developer1", nil, "")
+
+	s.AddCleanup(snap.MockSanitizePlugsSlots(func(snapInfo *snap.Info) {}))
+	s.snapDirs = make(map[string]string)
+	s.AddCleanup(dirstore.MockSnapfileOpen(func(path string) (snap.Container, error) {
+		dir, ok := s.snapDirs[path]
+		if !ok {
+			return nil, fmt.Errorf("cannot open %q", path)
+		}
+		return snapdir.New(dir), nil
+	}))
+}
+
+// makeSnapFile writes a snap file with the given snap.yaml to path.
+func (s *dirstoreSuite) makeSnapFile(c *C, path, snapYaml string) {
+	dir := c.MkDir()
+	snaptest.PopulateDir(dir, [][]string{{"meta/snap.yaml", snapYaml}})
+	c.Assert(os.WriteFile(path, []byte("hsqs\n"+snapYaml), 0644), IsNil)
+	s.snapDirs[path] = dir
+}
+
+func (s *dirstoreSuite) writeAssertions(c *C, name string, as ...asserts.Assertion) {
+	var buf bytes.Buffer
+	enc := asserts.NewEncoder(&buf)
+	for _, a := range as {
+		c.Assert(enc.Encode(a), IsNil)
+	}
+	c.Assert(os.WriteFile(filepath.Join(s.dir, name), buf.Bytes(), 0644), IsNil)
+}
+
+// addSnap puts a revision of a snap and its assertions in the directory, as
+// "snap download" does.
+func (s *dirstoreSuite) addSnap(c *C, snapYaml string, rev int) string {
+	info, err := snap.InfoFromSnapYaml([]byte(snapYaml))
+	c.Assert(err, IsNil)
+	name := info.SnapName()
+	snapID := snaptest.AssertedSnapID(name)
+
+	snapPath := filepath.Join(s.dir, fmt.Sprintf("%s_%d.snap", name, rev))
+	s.makeSnapFile(c, snapPath, snapYaml)
+
+	digest, size, err := asserts.SnapFileSHA3_384(snapPath)
+	c.Assert(err, IsNil)
+	decl, err := s.storeSigning.Sign(asserts.SnapDeclarationType, map[string]interface{}{
+		"series":       "16",
+		"snap-id":      snapID,
+		"snap-name":    name,
+		"publisher-id": s.dev1Acct.AccountID(),
+		"timestamp":    time.Now().UTC().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	snapRev, err := s.storeSigning.Sign(asserts.SnapRevisionType, map[string]interface{}{
+		"snap-sha3-384": digest,
+		"snap-size":     fmt.Sprintf("%d", size),
+		"snap-id":       snapID,
+		"snap-revision": fmt.Sprintf("%d", rev),
+		"developer-id":  s.dev1Acct.AccountID(),
+		"timestamp":     time.Now().UTC().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	s.writeAssertions(c, fmt.Sprintf("%s_%d.assert", name, rev), s.dev1Acct, decl, snapRev)
+	return snapPath
+}
+
+func (s *dirstoreSuite) TestParseBackend(c *C) {
+	for _, t := range []struct {
+		backend, dir, err string
+	}{
+		{"", "", ""},
+		{"dir:/srv/snaps", "/srv/snaps", ""},
+		{"dir:/srv/snaps/", "/srv/snaps", ""},
+		{"dir:srv/snaps", "", `store backend directory "srv/snaps" must be absolute`},
+		{"/srv/snaps", "", `store backend must be of the form dir:<path>`},
+		{"http://example.com", "", `store backend must be of the form dir:<path>`},
+	} {
+		dir, err := dirstore.ParseBackend(t.backend)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err, Commentf(t.backend))
+			continue
+		}
+		c.Check(err, IsNil, Commentf(t.backend))
+		c.Check(dir, Equals, t.dir)
+	}
+}
+
+func (s *dirstoreSuite) TestSnapInfo(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1\nsummary: Foo things", 1)
+	s.addSnap(c, "name: foo\nversion: 2\nsummary: Foo things", 2)
+	sto := dirstore.New(s.dir)
+
+	info, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "foo"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(info.SnapName(), Equals, "foo")
+	c.Check(info.SnapID, Equals, snaptest.AssertedSnapID("foo"))
+	c.Check(info.Revision, Equals, snap.R(2))
+	c.Check(info.Version, Equals, "2")
+	c.Check(info.Summary(), Equals, "Foo things")
+	c.Check(info.Publisher.ID, Equals, s.dev1Acct.AccountID())
+	c.Check(info.Publisher.Username, Equals, "developer1")
+	c.Check(info.Sha3_384, Not(Equals), "")
+
+	_, err = sto.SnapInfo(context.Background(), store.SnapSpec{Name: "bar"}, nil)
+	c.Check(err, Equals, store.ErrSnapNotFound)
+}
+
+func (s *dirstoreSuite) TestUnassertedSnapsIgnored(c *C) {
+	s.makeSnapFile(c, filepath.Join(s.dir, "local_x1.snap"), "name: local\nversion: 1")
+	sto := dirstore.New(s.dir)
+
+	_, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "local"}, nil)
+	c.Check(err, Equals, store.ErrSnapNotFound)
+}
+
+func (s *dirstoreSuite) TestFind(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1\nsummary: Foo things", 1)
+	s.addSnap(c, "name: bar\nversion: 1\nsummary: Bar and foo", 3)
+	s.addSnap(c, "name: baz\nversion: 1\nsummary: Other", 5)
+	sto := dirstore.New(s.dir)
+
+	names := func(infos []*snap.Info) []string {
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.SnapName())
+		}
+		return names
+	}
+
+	found, err := sto.Find(context.Background(), &store.Search{Query: "foo"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(names(found), DeepEquals, []string{"bar", "foo"})
+
+	found, err = sto.Find(context.Background(), &store.Search{Query: "ba", Prefix: true}, nil)
+	c.Assert(err, IsNil)
+	c.Check(names(found), DeepEquals, []string{"bar", "baz"})
+
+	found, err = sto.Find(context.Background(), &store.Search{Query: "nothing"}, nil)
+	c.Assert(err, IsNil)
+	c.Check(found, HasLen, 0)
+
+	_, err = sto.Find(context.Background(), &store.Search{Query: "foo", Category: "featured"}, nil)
+	c.Check(err, Equals, store.ErrBadQuery)
+}
+
+func (s *dirstoreSuite) TestSnapActionInstall(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1", 1)
+	s.addSnap(c, "name: foo\nversion: 2", 2)
+	sto := dirstore.New(s.dir)
+
+	results, aresults, err := sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "install",
+		InstanceName: "foo_instance",
+		Channel:      "latest/stable",
+	}, {
+		Action:       "download",
+		InstanceName: "foo",
+		Revision:     snap.R(1),
+	}}, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(aresults, HasLen, 0)
+	c.Assert(results, HasLen, 2)
+	c.Check(results[0].InstanceName(), Equals, "foo_instance")
+	c.Check(results[0].Revision, Equals, snap.R(2))
+	c.Check(results[0].Channel, Equals, "latest/stable")
+	c.Check(results[1].InstanceName(), Equals, "foo")
+	c.Check(results[1].Revision, Equals, snap.R(1))
+}
+
+func (s *dirstoreSuite) TestSnapActionInstallErrors(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1", 1)
+	sto := dirstore.New(s.dir)
+
+	results, _, err := sto.SnapAction(context.Background(), nil, []*store.SnapAction{{
+		Action:       "install",
+		InstanceName: "bar",
+	}, {
+		Action:       "install",
+		InstanceName: "foo",
+		Revision:     snap.R(7),
+	}}, nil, nil, nil)
+	c.Check(results, HasLen, 0)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	saErr := err.(*store.SnapActionError)
+	c.Check(saErr.NoResults, Equals, true)
+	c.Check(saErr.Install["bar"], Equals, store.ErrSnapNotFound)
+	c.Check(saErr.Install["foo"], FitsTypeOf, &store.RevisionNotAvailableError{})
+}
+
+func (s *dirstoreSuite) TestSnapActionRefresh(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1", 1)
+	s.addSnap(c, "name: bar\nversion: 1", 3)
+	sto := dirstore.New(s.dir)
+
+	current := []*store.CurrentSnap{
+		{InstanceName: "foo", SnapID: snaptest.AssertedSnapID("foo"), Revision: snap.R(1)},
+		{InstanceName: "bar", SnapID: snaptest.AssertedSnapID("bar"), Revision: snap.R(2)},
+	}
+	actions := []*store.SnapAction{
+		{Action: "refresh", InstanceName: "foo", SnapID: snaptest.AssertedSnapID("foo")},
+		{Action: "refresh", InstanceName: "bar", SnapID: snaptest.AssertedSnapID("bar")},
+	}
+	results, _, err := sto.SnapAction(context.Background(), current, actions, nil, nil, nil)
+	c.Assert(results, HasLen, 1)
+	c.Check(results[0].InstanceName(), Equals, "bar")
+	c.Check(results[0].Revision, Equals, snap.R(3))
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	saErr := err.(*store.SnapActionError)
+	c.Check(saErr.NoResults, Equals, false)
+	c.Check(saErr.Refresh, DeepEquals, map[string]error{"foo": store.ErrNoUpdateAvailable})
+
+	// a new revision copied to the directory is picked up
+	s.addSnap(c, "name: foo\nversion: 2", 2)
+	results, _, err = sto.SnapAction(context.Background(), current, actions, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 2)
+	c.Check(results[0].Revision, Equals, snap.R(2))
+
+	// unless blocked
+	current[0].Block = []snap.Revision{snap.R(2)}
+	results, _, err = sto.SnapAction(context.Background(), current, actions, nil, nil, nil)
+	c.Assert(err, NotNil)
+	c.Check(results, HasLen, 1)
+}
+
+type fakeAssertionQuery struct {
+	toResolve    map[asserts.Grouping][]*asserts.AtRevision
+	toResolveSeq map[asserts.Grouping][]*asserts.AtSequence
+
+	errors []error
+}
+
+func (q *fakeAssertionQuery) ToResolve() (map[asserts.Grouping][]*asserts.AtRevision, map[asserts.Grouping][]*asserts.AtSequence, error) {
+	return q.toResolve, q.toResolveSeq, nil
+}
+
+func (q *fakeAssertionQuery) AddError(e error, ref *asserts.Ref) error {
+	q.errors = append(q.errors, e)
+	return nil
+}
+
+func (q *fakeAssertionQuery) AddSequenceError(e error, atSeq *asserts.AtSequence) error {
+	q.errors = append(q.errors, e)
+	return nil
+}
+
+func (q *fakeAssertionQuery) AddGroupingError(e error, grouping asserts.Grouping) error {
+	q.errors = append(q.errors, e)
+	return nil
+}
+
+func (s *dirstoreSuite) TestSnapActionAssertions(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1", 1)
+	vs, err := s.storeSigning.Sign(asserts.ValidationSetType, map[string]interface{}{
+		"type":         "validation-set",
+		"authority-id": "can0nical",
+		"series":       "16",
+		"account-id":   "can0nical",
+		"name":         "base-set",
+		"sequence":     "2",
+		"snaps": []interface{}{map[string]interface{}{
+			"name":     "foo",
+			"id":       snaptest.AssertedSnapID("foo"),
+			"presence": "required",
+		}},
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}, nil, "")
+	c.Assert(err, IsNil)
+	s.writeAssertions(c, "base-set.assert", vs)
+	sto := dirstore.New(s.dir)
+
+	declRef := asserts.Ref{Type: asserts.SnapDeclarationType, PrimaryKey: []string{"16", snaptest.AssertedSnapID("foo")}}
+	missingRef := asserts.Ref{Type: asserts.SnapDeclarationType, PrimaryKey: []string{"16", snaptest.AssertedSnapID("missing")}}
+	q := &fakeAssertionQuery{
+		toResolve: map[asserts.Grouping][]*asserts.AtRevision{
+			"g1": {
+				{Ref: declRef, Revision: asserts.RevisionNotKnown},
+				{Ref: missingRef, Revision: asserts.RevisionNotKnown},
+			},
+		},
+		toResolveSeq: map[asserts.Grouping][]*asserts.AtSequence{
+			"g2": {{
+				Type:        asserts.ValidationSetType,
+				SequenceKey: []string{"16", "can0nical", "base-set"},
+				Sequence:    1,
+				Revision:    0,
+			}},
+		},
+	}
+	_, aresults, err := sto.SnapAction(context.Background(), nil, nil, q, nil, nil)
+	c.Assert(err, FitsTypeOf, &store.SnapActionError{})
+	c.Check(err.(*store.SnapActionError).NoResults, Equals, false)
+	c.Assert(q.errors, HasLen, 1)
+	c.Check(q.errors[0], FitsTypeOf, &asserts.NotFoundError{})
+	c.Assert(aresults, HasLen, 2)
+	urls := make(map[asserts.Grouping][]string)
+	for _, ares := range aresults {
+		urls[ares.Grouping] = ares.StreamURLs
+	}
+	c.Check(urls, DeepEquals, map[asserts.Grouping][]string{
+		"g1": {"snap-declaration/16/" + snaptest.AssertedSnapID("foo")},
+		"g2": {"validation-set/16/can0nical/base-set/2"},
+	})
+
+	db, err := asserts.OpenDatabase(&asserts.DatabaseConfig{
+		Backstore: asserts.NewMemoryBackstore(),
+		Trusted:   s.storeSigning.Trusted,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(db.Add(s.storeSigning.StoreAccountKey("")), IsNil)
+	c.Assert(db.Add(s.dev1Acct), IsNil)
+	b := asserts.NewBatch(nil)
+	err = sto.DownloadAssertions(append(urls["g1"], urls["g2"]...), b, nil)
+	c.Assert(err, IsNil)
+	c.Assert(b.CommitTo(db, nil), IsNil)
+	_, err = db.Find(asserts.SnapDeclarationType, map[string]string{"series": "16", "snap-id": snaptest.AssertedSnapID("foo")})
+	c.Check(err, IsNil)
+}
+
+func (s *dirstoreSuite) TestAssertion(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1", 1)
+	sto := dirstore.New(s.dir)
+
+	a, err := sto.Assertion(asserts.SnapDeclarationType, []string{"16", snaptest.AssertedSnapID("foo")}, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.SnapDeclaration).SnapName(), Equals, "foo")
+
+	_, err = sto.Assertion(asserts.SnapDeclarationType, []string{"16", snaptest.AssertedSnapID("bar")}, nil)
+	c.Check(err, testutil.ErrorIs, &asserts.NotFoundError{})
+}
+
+func (s *dirstoreSuite) TestSeqFormingAssertion(c *C) {
+	for _, seq := range []string{"1", "3"} {
+		vs, err := s.storeSigning.Sign(asserts.ValidationSetType, map[string]interface{}{
+			"type":         "validation-set",
+			"authority-id": "can0nical",
+			"series":       "16",
+			"account-id":   "can0nical",
+			"name":         "base-set",
+			"sequence":     seq,
+			"snaps": []interface{}{map[string]interface{}{
+				"name": "foo",
+				"id":   snaptest.AssertedSnapID("foo"),
+			}},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		}, nil, "")
+		c.Assert(err, IsNil)
+		s.writeAssertions(c, "base-set-"+seq+".assert", vs)
+	}
+	sto := dirstore.New(s.dir)
+
+	seqKey := []string{"16", "can0nical", "base-set"}
+	a, err := sto.SeqFormingAssertion(asserts.ValidationSetType, seqKey, 0, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.ValidationSet).Sequence(), Equals, 3)
+
+	a, err = sto.SeqFormingAssertion(asserts.ValidationSetType, seqKey, 1, nil)
+	c.Assert(err, IsNil)
+	c.Check(a.(*asserts.ValidationSet).Sequence(), Equals, 1)
+
+	_, err = sto.SeqFormingAssertion(asserts.ValidationSetType, seqKey, 2, nil)
+	c.Check(err, testutil.ErrorIs, &asserts.NotFoundError{})
+}
+
+func (s *dirstoreSuite) TestDownload(c *C) {
+	snapPath := s.addSnap(c, "name: foo\nversion: 1", 1)
+	sto := dirstore.New(s.dir)
+
+	info, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "foo"}, nil)
+	c.Assert(err, IsNil)
+
+	target := filepath.Join(c.MkDir(), "downloads", "foo_1.snap")
+	err = sto.Download(context.Background(), "foo", target, &info.DownloadInfo, progress.Null, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(target, testutil.FileEquals, testutil.FileContentRef(snapPath))
+	c.Check(osutil.FileExists(target+".partial"), Equals, false)
+
+	// only the snaps of the directory are served
+	err = sto.Download(context.Background(), "foo", target, &snap.DownloadInfo{Sha3_384: "other"}, progress.Null, nil, nil)
+	c.Check(err, Equals, store.ErrSnapNotFound)
+}
+
+func (s *dirstoreSuite) TestDownloadStream(c *C) {
+	snapPath := s.addSnap(c, "name: foo\nversion: 1", 1)
+	sto := dirstore.New(s.dir)
+
+	info, err := sto.SnapInfo(context.Background(), store.SnapSpec{Name: "foo"}, nil)
+	c.Assert(err, IsNil)
+	content, err := os.ReadFile(snapPath)
+	c.Assert(err, IsNil)
+
+	r, status, err := sto.DownloadStream(context.Background(), "foo", &info.DownloadInfo, 10, nil)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	c.Check(status, Equals, http.StatusPartialContent)
+	data, err := io.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Check(data, DeepEquals, content[10:])
+}
+
+type testSnapAdder struct {
+	added []string
+}
+
+func (a *testSnapAdder) AddSnap(snapName, version, summary string, commands []string) error {
+	a.added = append(a.added, fmt.Sprintf("%s %s %v", snapName, version, commands))
+	return nil
+}
+
+func (s *dirstoreSuite) TestWriteCatalogs(c *C) {
+	s.addSnap(c, "name: foo\nversion: 1\napps:\n  foo:\n  svc:\n    daemon: simple\n  tool:", 1)
+	s.addSnap(c, "name: bar\nversion: 2", 2)
+	sto := dirstore.New(s.dir)
+
+	var names bytes.Buffer
+	adder := &testSnapAdder{}
+	c.Assert(sto.WriteCatalogs(context.Background(), &names, adder), IsNil)
+	c.Check(names.String(), Equals, "bar\nfoo\n")
+	c.Check(adder.added, DeepEquals, []string{"bar 2 []", "foo 1 [foo tool]"})
+}
+
+func (s *dirstoreSuite) TestConnectivityCheck(c *C) {
+	status, err := dirstore.New(s.dir).ConnectivityCheck()
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, map[string]bool{"dir:" + s.dir: true})
+
+	status, err = dirstore.New(filepath.Join(s.dir, "missing")).ConnectivityCheck()
+	c.Assert(err, IsNil)
+	c.Check(status, DeepEquals, map[string]bool{"dir:" + s.dir + "/missing": false})
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"os/exec"
+	"syscall"
 	"time"
 
 	"github.com/juju/ratelimit"
@@ -125,8 +126,8 @@ func (cm *CacheManager) CacheDir() string {
 	return cm.cacheDir
 }
 
-func (cm *CacheManager) Cleanup() error {
-	return cm.cleanup()
+func (cm *CacheManager) Cleanup(pinned map[string]string) error {
+	return cm.cleanup(pinned)
 }
 
 func (cm *CacheManager) Count() int {
@@ -262,3 +263,38 @@ type (
 )
 
 var ReportFetchAssertionsError = reportFetchAssertionsError
+
+func MockChunkedDownload(connections int, chunkSize, minSize int64) (restore func()) {
+	oldConnections := downloadConnections
+	oldChunkSize := downloadChunkSize
+	oldMinSize := chunkedDownloadMinSize
+	downloadConnections = connections
+	downloadChunkSize = chunkSize
+	chunkedDownloadMinSize = minSize
+	return func() {
+		downloadConnections = oldConnections
+		downloadChunkSize = oldChunkSize
+		chunkedDownloadMinSize = oldMinSize
+	}
+}
+
+func MockTimeNow(f func() time.Time) (restore func()) {
+	return testutil.Mock(&timeNow, f)
+}
+
+// RateLimitNow returns the rate enforced by the rate limiter of the
+// download options at this time, or 0 if unlimited.
+func RateLimitNow(opts *DownloadOptions) float64 {
+	if bucket := newRateLimiter(opts).currentBucket(); bucket != nil {
+		return bucket.Rate()
+	}
+	return 0
+}
+
+func MockSyscallStatfs(f func(string, *syscall.Statfs_t) error) (restore func()) {
+	return testutil.Mock(&syscallStatfs, f)
+}
+
+func MockMaxRecordedBodySize(size int) (restore func()) {
+	return testutil.Mock(&maxRecordedBodySize, size)
+}
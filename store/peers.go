@@ -0,0 +1,230 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+)
+
+// peerBlobsPath is the path under which peers serve the snaps of their
+// download cache, by sha3-384 digest.
+const peerBlobsPath = "/v1/snap-blobs/"
+
+var (
+	peerDialTimeout           = 5 * time.Second
+	peerResponseHeaderTimeout = 10 * time.Second
+)
+
+var validSha3_384 = regexp.MustCompile(`^[0-9a-f]{96}$`)
+
+// ParsePeers returns the addresses of a comma-separated list of host:port
+// peers, as in the store.peers option.
+func ParsePeers(peers string) ([]string, error) {
+	if peers == "" {
+		return nil, nil
+	}
+	addrs := strings.Split(peers, ",")
+	for i, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if host, port, err := net.SplitHostPort(addr); err != nil || host == "" || port == "" {
+			return nil, fmt.Errorf("store peer %q must be of the form host:port", addr)
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
+
+// PeerServer serves the snaps of the download cache to the other devices
+// of the network, which download from it before falling back to the store.
+//
+// Peers are not authenticated: anyone who can reach the listen address can
+// download any cached snap whose sha3-384 digest they know, including
+// private or paid ones. The server is therefore only started when
+// store.peer-listen is set, and that address should only be reachable from
+// trusted devices. Clients do not trust peers either, and check the size and
+// digest of what they download against the store's download info.
+type PeerServer struct {
+	cache    *CacheManager
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewPeerServer starts serving the snaps cached in cacheDir on the given
+// address.
+func NewPeerServer(addr, cacheDir string) (*PeerServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for peers: %v", err)
+	}
+	ps := &PeerServer{
+		// the server does not add to the cache, the count is irrelevant
+		cache:    NewCacheManager(cacheDir, 0),
+		listener: listener,
+	}
+	ps.server = &http.Server{
+		Handler:           ps,
+		ReadHeaderTimeout: peerResponseHeaderTimeout,
+	}
+	go func() {
+		if err := ps.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Noticef("cannot serve peers: %v", err)
+		}
+	}()
+	return ps, nil
+}
+
+// Addr returns the address the server listens on.
+func (ps *PeerServer) Addr() net.Addr {
+	return ps.listener.Addr()
+}
+
+// Stop stops serving peers.
+func (ps *PeerServer) Stop() error {
+	return ps.server.Close()
+}
+
+func (ps *PeerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	digest, ok := strings.CutPrefix(r.URL.Path, peerBlobsPath)
+	if !ok || !validSha3_384.MatchString(digest) {
+		http.NotFound(w, r)
+		return
+	}
+	path := ps.cache.GetPath(digest)
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+	logger.Debugf("Serving cached snap …%.5s to peer %s.", digest, r.RemoteAddr)
+	http.ServeFile(w, r, path)
+}
+
+// SetPeers sets the addresses, as host:port, of the peers to try
+// downloading snaps from before the store.
+func (s *Store) SetPeers(peers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.Peers = peers
+}
+
+func (s *Store) peers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg.Peers
+}
+
+var (
+	errPeerHashMismatch = errors.New("sha3-384 mismatch")
+	errPeerSizeMismatch = errors.New("size mismatch")
+)
+
+// downloadFromPeers tries to download the snap from the peers in turn and
+// returns whether one of them had it. The downloaded snap is only moved to
+// targetPath if it matches the digest of the download info.
+func (s *Store) downloadFromPeers(ctx context.Context, name, targetPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, dlOpts *DownloadOptions) bool {
+	peers := s.peers()
+	// without the size and digest the download cannot be checked
+	if len(peers) == 0 || downloadInfo.Size <= 0 || !validSha3_384.MatchString(downloadInfo.Sha3_384) {
+		return false
+	}
+	if pbar == nil {
+		pbar = progress.Null
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:           (&net.Dialer{Timeout: peerDialTimeout}).DialContext,
+			ResponseHeaderTimeout: peerResponseHeaderTimeout,
+		},
+	}
+	for _, peer := range peers {
+		err := downloadFromPeer(ctx, client, peer, name, targetPath, downloadInfo, pbar, dlOpts)
+		if err == nil {
+			logger.Debugf("Downloaded %q from peer %s.", name, peer)
+			return true
+		}
+		logger.Debugf("Cannot download %q from peer %s: %v", name, peer, err)
+	}
+	return false
+}
+
+func downloadFromPeer(ctx context.Context, client *http.Client, peer, name, targetPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, dlOpts *DownloadOptions) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://"+peer+peerBlobsPath+downloadInfo.Sha3_384, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength != downloadInfo.Size {
+		return fmt.Errorf("unexpected size %d", resp.ContentLength)
+	}
+
+	peerPath := targetPath + ".peer"
+	w, err := os.OpenFile(peerPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		w.Close()
+		os.Remove(peerPath)
+	}()
+
+	// read one byte more than expected to notice peers sending too much
+	body := newRateLimiter(dlOpts).Reader(io.LimitReader(resp.Body, downloadInfo.Size+1))
+	h := crypto.SHA3_384.New()
+	pbar.Start(name, float64(downloadInfo.Size))
+	n, err := io.Copy(io.MultiWriter(w, h, pbar), body)
+	pbar.Finished()
+	if err != nil {
+		return err
+	}
+	if n != downloadInfo.Size {
+		return errPeerSizeMismatch
+	}
+	if fmt.Sprintf("%x", h.Sum(nil)) != downloadInfo.Sha3_384 {
+		return errPeerHashMismatch
+	}
+	if err := w.Sync(); err != nil {
+		return err
+	}
+	return os.Rename(peerPath, targetPath)
+}
@@ -0,0 +1,218 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/dirs"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type peersSuite struct {
+	baseStoreSuite
+
+	cacheDir string
+	content  []byte
+	digest   string
+}
+
+var _ = Suite(&peersSuite{})
+
+func (s *peersSuite) SetUpTest(c *C) {
+	s.baseStoreSuite.SetUpTest(c)
+
+	s.cacheDir = c.MkDir()
+	s.content = []byte("snap blob content")
+	s.digest = fmt.Sprintf("%x", sha3.Sum384(s.content))
+}
+
+func (s *peersSuite) startPeer(c *C) *store.PeerServer {
+	ps, err := store.NewPeerServer("127.0.0.1:0", s.cacheDir)
+	c.Assert(err, IsNil)
+	s.AddCleanup(func() { ps.Stop() })
+	return ps
+}
+
+func (s *peersSuite) get(c *C, method, url string) (int, string) {
+	req, err := http.NewRequest(method, url, nil)
+	c.Assert(err, IsNil)
+	resp, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp.StatusCode, string(body)
+}
+
+func (s *peersSuite) TestPeerServer(c *C) {
+	c.Assert(os.WriteFile(filepath.Join(s.cacheDir, s.digest), s.content, 0644), IsNil)
+	ps := s.startPeer(c)
+	baseURL := "http://" + ps.Addr().String() + "/v1/snap-blobs/"
+
+	status, body := s.get(c, "GET", baseURL+s.digest)
+	c.Check(status, Equals, 200)
+	c.Check(body, Equals, string(s.content))
+
+	status, _ = s.get(c, "HEAD", baseURL+s.digest)
+	c.Check(status, Equals, 200)
+
+	// not in the cache
+	status, _ = s.get(c, "GET", baseURL+strings.Repeat("0", 96))
+	c.Check(status, Equals, 404)
+
+	// only digests are served
+	status, _ = s.get(c, "GET", baseURL+"../"+s.digest)
+	c.Check(status, Equals, 404)
+	status, _ = s.get(c, "GET", baseURL+"foo")
+	c.Check(status, Equals, 404)
+	status, _ = s.get(c, "GET", "http://"+ps.Addr().String()+"/"+s.digest)
+	c.Check(status, Equals, 404)
+
+	status, _ = s.get(c, "POST", baseURL+s.digest)
+	c.Check(status, Equals, 405)
+}
+
+func (s *peersSuite) TestPeerServerListenError(c *C) {
+	_, err := store.NewPeerServer("256.0.0.1:0", s.cacheDir)
+	c.Check(err, ErrorMatches, "cannot listen for peers: .*")
+}
+
+func (s *peersSuite) downloadInfo() *snap.DownloadInfo {
+	return &snap.DownloadInfo{
+		DownloadURL: "URL",
+		Size:        int64(len(s.content)),
+		Sha3_384:    s.digest,
+	}
+}
+
+func (s *peersSuite) TestDownloadFromPeer(c *C) {
+	c.Assert(os.WriteFile(filepath.Join(s.cacheDir, s.digest), s.content, 0644), IsNil)
+	ps := s.startPeer(c)
+
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, s *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		c.Errorf("unexpected download from the store")
+		return nil
+	})
+	defer restore()
+
+	sto := store.New(&store.Config{
+		CacheDownloads: 5,
+		Peers:          []string{"127.0.0.1:1", ps.Addr().String()},
+	}, nil)
+	path := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", path, s.downloadInfo(), nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(path, testutil.FileEquals, s.content)
+	c.Check(path+".peer", testutil.FileAbsent)
+
+	// and it is now cached locally, to be shared in turn
+	c.Check(filepath.Join(dirs.SnapDownloadCacheDir, s.digest), testutil.FileEquals, s.content)
+}
+
+func (s *peersSuite) TestDownloadFromPeerFallsBackOnHashMismatch(c *C) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Path, Equals, "/v1/snap-blobs/"+s.digest)
+		w.Write([]byte("snap blob CONTENT"))
+	}))
+	defer bad.Close()
+
+	storeDownloads := 0
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, st *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		storeDownloads++
+		c.Check(url, Equals, "URL")
+		w.Write(s.content)
+		return nil
+	})
+	defer restore()
+
+	sto := store.New(&store.Config{
+		Peers: []string{strings.TrimPrefix(bad.URL, "http://")},
+	}, nil)
+	path := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", path, s.downloadInfo(), nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownloads, Equals, 1)
+	c.Check(path, testutil.FileEquals, s.content)
+	c.Check(path+".peer", testutil.FileAbsent)
+}
+
+func (s *peersSuite) TestDownloadFromPeerFallsBackOnOversizedBody(c *C) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// flushing before the end sends the body without a content length
+		w.Write(s.content)
+		w.(http.Flusher).Flush()
+		w.Write([]byte("and more"))
+	}))
+	defer bad.Close()
+
+	storeDownloads := 0
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, st *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		storeDownloads++
+		w.Write(s.content)
+		return nil
+	})
+	defer restore()
+
+	sto := store.New(&store.Config{
+		Peers: []string{strings.TrimPrefix(bad.URL, "http://")},
+	}, nil)
+	path := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", path, s.downloadInfo(), nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownloads, Equals, 1)
+	c.Check(path, testutil.FileEquals, s.content)
+	c.Check(path+".peer", testutil.FileAbsent)
+	c.Check(s.logbuf.String(), testutil.Contains, "size mismatch")
+}
+
+func (s *peersSuite) TestDownloadFromPeerFallsBackWhenNotCached(c *C) {
+	ps := s.startPeer(c)
+
+	storeDownloads := 0
+	restore := store.MockDownload(func(ctx context.Context, name, sha3, url string, user *auth.UserState, st *store.Store, w io.ReadWriteSeeker, resume int64, pbar progress.Meter, dlOpts *store.DownloadOptions) error {
+		storeDownloads++
+		w.Write(s.content)
+		return nil
+	})
+	defer restore()
+
+	sto := store.New(&store.Config{}, nil)
+	sto.SetPeers([]string{ps.Addr().String()})
+	path := filepath.Join(c.MkDir(), "foo_1.snap")
+	err := sto.Download(s.ctx, "foo", path, s.downloadInfo(), nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(storeDownloads, Equals, 1)
+	c.Check(path, testutil.FileEquals, s.content)
+}
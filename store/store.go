@@ -111,6 +111,12 @@ type Config struct {
 
 	// CacheDownloads is the number of downloads that should be cached
 	CacheDownloads int
+	// CacheMaxSize is the maximum total size in bytes of the cached
+	// downloads, or 0 for no limit
+	CacheMaxSize int64
+	// CacheMinFreePercent is the share of the filesystem of the download
+	// cache which cached downloads are evicted to keep free, or 0
+	CacheMinFreePercent int
 
 	// Proxy returns the HTTP proxy to use when talking to the store
 	Proxy func(*http.Request) (*url.URL, error)
@@ -118,6 +124,10 @@ type Config struct {
 	// AssertionMaxFormats if set provides a way to override
 	// the assertion max formats sent to the store as supported.
 	AssertionMaxFormats map[string]int
+
+	// Peers are the addresses, as host:port, of the devices of the
+	// network to try downloading snaps from before the store
+	Peers []string
 }
 
 // setBaseURL updates the store API's base URL in the Config. Must not be used
@@ -475,7 +485,9 @@ func (s *Store) newHTTPClient(opts *httputil.ClientOptions) *http.Client {
 	opts.ExtraSSLCerts = &httputil.ExtraSSLCertsFromDir{
 		Dir: dirs.SnapdStoreSSLCertsDir,
 	}
-	return httputilNewHTTPClient(opts)
+	cli := httputilNewHTTPClient(opts)
+	cli.Transport = sessionTransport(cli.Transport)
+	return cli
 }
 
 func (s *Store) defaultSnapQuery() url.Values {
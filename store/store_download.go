@@ -71,6 +71,13 @@ func init() {
 			logger.Noticef("Cannot parse SNAPD_MIN_DOWNLOAD_SPEED as number")
 		}
 	}
+	if v := os.Getenv("SNAPD_DOWNLOAD_CONNECTIONS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			downloadConnections = n
+		} else {
+			logger.Noticef("Cannot parse SNAPD_DOWNLOAD_CONNECTIONS as number")
+		}
+	}
 	if v := os.Getenv("SNAPD_DOWNLOAD_MEAS_WINDOW"); v != "" {
 		if win, err := time.ParseDuration(v); err == nil {
 			downloadSpeedMeasureWindow = win
@@ -182,9 +189,21 @@ func (e HashError) Error() string {
 }
 
 type DownloadOptions struct {
-	RateLimit           int64
+	RateLimit int64
+	// RateSchedule overrides RateLimit during its windows.
+	RateSchedule        []RateWindow
 	Scheduled           bool
 	LeavePartialOnError bool
+	// CachePins are the cached downloads which must not be evicted to make
+	// room for this one, with the reason why, by sha3-384 digest.
+	CachePins map[string]string
+}
+
+func (opts *DownloadOptions) cachePins() map[string]string {
+	if opts == nil {
+		return nil
+	}
+	return opts.CachePins
 }
 
 // Download downloads the snap addressed by download info and returns its
@@ -208,6 +227,10 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 		return nil
 	}
 
+	if s.downloadFromPeers(ctx, name, targetPath, downloadInfo, pbar, dlOpts) {
+		return s.cacher.Put(downloadInfo.Sha3_384, targetPath, dlOpts.cachePins())
+	}
+
 	if s.useDeltas() {
 		logger.Debugf("Available deltas returned by store: %v", downloadInfo.Deltas)
 
@@ -222,6 +245,29 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 	}
 
 	partialPath := targetPath + ".partial"
+	if useChunkedDownload(downloadInfo, partialPath) {
+		err := s.downloadChunked(ctx, name, partialPath, downloadInfo, pbar, user, dlOpts)
+		if err == nil {
+			if err := os.Rename(partialPath, targetPath); err != nil {
+				return err
+			}
+			return s.cacher.Put(downloadInfo.Sha3_384, targetPath, dlOpts.cachePins())
+		}
+		if cancelled(ctx) {
+			// keep the completed chunks to resume from if asked to
+			if dlOpts == nil || !dlOpts.LeavePartialOnError {
+				os.Remove(partialPath)
+				os.Remove(chunkMapPath(partialPath))
+			}
+			return err
+		}
+		// fall back to a single stream from scratch
+		logger.Noticef("Cannot download %s in chunks: %v", name, err)
+		os.Remove(partialPath)
+	}
+	// the chunk map, if any, is stale for a single stream
+	os.Remove(chunkMapPath(partialPath))
+
 	w, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return err
@@ -298,7 +344,7 @@ func (s *Store) Download(ctx context.Context, name string, targetPath string, do
 		return err
 	}
 
-	return s.cacher.Put(downloadInfo.Sha3_384, targetPath)
+	return s.cacher.Put(downloadInfo.Sha3_384, targetPath, dlOpts.cachePins())
 }
 
 func downloadReqOpts(storeURL *url.URL, cdnHeader string, opts *DownloadOptions) *requestOptions {
@@ -429,6 +475,24 @@ func (w *TransferSpeedMonitoringWriter) Err() error {
 
 var ratelimitReader = ratelimit.Reader
 
+// newDownloadHTTPClient returns a client for downloading which does not
+// follow redirects with the authorization headers.
+func (s *Store) newDownloadHTTPClient(reqOptions *requestOptions) *http.Client {
+	cli := s.newHTTPClient(nil)
+	oldCheckRedirect := cli.CheckRedirect
+	if oldCheckRedirect == nil {
+		panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
+	}
+	cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		// remove user/device auth headers from being sent in "CDN" redirects
+		// see also: https://bugs.launchpad.net/snapd/+bug/2027993
+		// TODO: do we need to remove other identifying headers?
+		dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
+		return oldCheckRedirect(req, via)
+	}
+	return cli
+}
+
 var download = downloadImpl
 
 // download writes an http.Request showing a progress.Meter
@@ -478,18 +542,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
 		}
 		var resp *http.Response
-		cli := s.newHTTPClient(nil)
-		oldCheckRedirect := cli.CheckRedirect
-		if oldCheckRedirect == nil {
-			panic("internal error: the httputil.NewHTTPClient-produced http.Client must have CheckRedirect defined")
-		}
-		cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			// remove user/device auth headers from being sent in "CDN" redirects
-			// see also: https://bugs.launchpad.net/snapd/+bug/2027993
-			// TODO: do we need to remove other identifying headers?
-			dropAuthorization(req, &AuthorizeOptions{deviceAuth: true, apiLevel: reqOptions.APILevel})
-			return oldCheckRedirect(req, via)
-		}
+		cli := s.newDownloadHTTPClient(reqOptions)
 		resp, finalErr = s.doRequest(downloadCtx, cli, reqOptions, user)
 		if cancelled(downloadCtx) {
 			return fmt.Errorf("the download has been cancelled: %s", downloadCtx.Err())
@@ -536,12 +589,7 @@ func downloadImpl(ctx context.Context, name, sha3_384, downloadURL string, user
 		}
 		pbar.Start(name, dlSize)
 		mw := io.MultiWriter(w, h, pbar, tc)
-		var limiter io.Reader
-		limiter = resp.Body
-		if limit := dlOpts.RateLimit; limit > 0 {
-			bucket := ratelimit.NewBucketWithRate(float64(limit), 2*limit)
-			limiter = ratelimitReader(resp.Body, bucket)
-		}
+		limiter := newRateLimiter(dlOpts).Reader(resp.Body)
 
 		stopMonitorCh := tc.Monitor()
 		_, finalErr = io.Copy(mw, limiter)
@@ -763,8 +811,21 @@ func (s *Store) CacheDownloads() int {
 func (s *Store) SetCacheDownloads(fileCount int) {
 	s.cfg.CacheDownloads = fileCount
 	if fileCount > 0 {
-		s.cacher = NewCacheManager(dirs.SnapDownloadCacheDir, fileCount)
+		cm := NewCacheManager(dirs.SnapDownloadCacheDir, fileCount)
+		cm.SetLimits(s.cfg.CacheMaxSize, s.cfg.CacheMinFreePercent)
+		s.cacher = cm
 	} else {
 		s.cacher = &nullCache{}
 	}
 }
+
+// SetCacheLimits sets the maximum total size of the cached downloads and
+// the share of the filesystem of the cache to keep free, see
+// CacheManager.SetLimits.
+func (s *Store) SetCacheLimits(maxSize int64, minFreePercent int) {
+	s.cfg.CacheMaxSize = maxSize
+	s.cfg.CacheMinFreePercent = minFreePercent
+	if cm, ok := s.cacher.(*CacheManager); ok {
+		cm.SetLimits(maxSize, minFreePercent)
+	}
+}
@@ -0,0 +1,340 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/retry.v1"
+
+	"github.com/snapcore/snapd/httputil"
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/osutil"
+	"github.com/snapcore/snapd/overlord/auth"
+	"github.com/snapcore/snapd/progress"
+	"github.com/snapcore/snapd/snap"
+)
+
+var (
+	// downloadConnections is the number of parallel connections used to
+	// download large snaps.
+	downloadConnections = 4
+	// downloadChunkSize is the size of the ranges requested by each
+	// connection.
+	downloadChunkSize = int64(16 * 1024 * 1024)
+	// chunkedDownloadMinSize is the size from which snaps are downloaded
+	// in chunks.
+	chunkedDownloadMinSize = int64(64 * 1024 * 1024)
+)
+
+var errRangeNotSupported = errors.New("server does not support ranges")
+
+// chunkMap records which chunks of a download are complete, it is saved
+// next to the partial download so that it can be resumed after a restart.
+type chunkMap struct {
+	Sha3_384  string `json:"sha3-384"`
+	Size      int64  `json:"size"`
+	ChunkSize int64  `json:"chunk-size"`
+	Done      []bool `json:"done"`
+}
+
+func chunkMapPath(partialPath string) string {
+	return partialPath + ".chunks"
+}
+
+func newChunkMap(downloadInfo *snap.DownloadInfo) *chunkMap {
+	n := (downloadInfo.Size + downloadChunkSize - 1) / downloadChunkSize
+	return &chunkMap{
+		Sha3_384:  downloadInfo.Sha3_384,
+		Size:      downloadInfo.Size,
+		ChunkSize: downloadChunkSize,
+		Done:      make([]bool, n),
+	}
+}
+
+// loadChunkMap returns the saved chunk map of the download, or nil if there
+// is none or it is for another download.
+func loadChunkMap(path string, downloadInfo *snap.DownloadInfo) *chunkMap {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cm chunkMap
+	if err := json.Unmarshal(data, &cm); err != nil {
+		logger.Noticef("Cannot read chunk map %q: %v", path, err)
+		return nil
+	}
+	if cm.Sha3_384 != downloadInfo.Sha3_384 || cm.Size != downloadInfo.Size || cm.ChunkSize <= 0 ||
+		int64(len(cm.Done)) != (cm.Size+cm.ChunkSize-1)/cm.ChunkSize {
+		return nil
+	}
+	return &cm
+}
+
+func (cm *chunkMap) save(path string) error {
+	data, err := json.Marshal(cm)
+	if err != nil {
+		return err
+	}
+	return osutil.AtomicWriteFile(path, data, 0600, 0)
+}
+
+// chunk returns the first and last byte of the given chunk.
+func (cm *chunkMap) chunk(i int) (first, last int64) {
+	first = int64(i) * cm.ChunkSize
+	last = first + cm.ChunkSize - 1
+	if last >= cm.Size {
+		last = cm.Size - 1
+	}
+	return first, last
+}
+
+func (cm *chunkMap) doneSize() int64 {
+	var size int64
+	for i, done := range cm.Done {
+		if done {
+			first, last := cm.chunk(i)
+			size += last - first + 1
+		}
+	}
+	return size
+}
+
+// useChunkedDownload returns whether the snap should be downloaded in
+// chunks to partialPath. A partial download without chunk map is resumed as
+// a single stream instead.
+func useChunkedDownload(downloadInfo *snap.DownloadInfo, partialPath string) bool {
+	if downloadConnections < 2 || downloadInfo.Size < chunkedDownloadMinSize || downloadInfo.Sha3_384 == "" {
+		return false
+	}
+	if osutil.FileExists(partialPath) {
+		return loadChunkMap(chunkMapPath(partialPath), downloadInfo) != nil
+	}
+	return true
+}
+
+// offsetWriter writes sequentially from the given offset.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.w.WriteAt(p, ow.off)
+	ow.off += int64(n)
+	return n, err
+}
+
+// lockedWriter serializes the writes of the connections.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// downloadChunked downloads the snap to partialPath using parallel range
+// requests. The completed chunks are recorded as they are written so that
+// an interrupted download only fetches the missing ones.
+func (s *Store) downloadChunked(ctx context.Context, name, partialPath string, downloadInfo *snap.DownloadInfo, pbar progress.Meter, user *auth.UserState, dlOpts *DownloadOptions) error {
+	storeURL, err := url.Parse(downloadInfo.DownloadURL)
+	if err != nil {
+		return err
+	}
+	cdnHeader, err := s.cdnHeader()
+	if err != nil {
+		return err
+	}
+
+	mapPath := chunkMapPath(partialPath)
+	cm := loadChunkMap(mapPath, downloadInfo)
+	if cm == nil {
+		cm = newChunkMap(downloadInfo)
+	}
+	f, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(cm.Size); err != nil {
+		return err
+	}
+	if err := cm.save(mapPath); err != nil {
+		return err
+	}
+
+	var pending []int
+	for i, done := range cm.Done {
+		if !done {
+			pending = append(pending, i)
+		}
+	}
+	logger.Debugf("Downloading %q in %d chunks, %d of them left.", partialPath, len(cm.Done), len(pending))
+
+	tc, monitorCtx := NewTransferSpeedMonitoringWriterAndContext(ctx, downloadSpeedMeasureWindow, downloadSpeedMin)
+	downloadCtx, cancel := context.WithCancel(monitorCtx)
+	defer cancel()
+
+	if pbar == nil {
+		pbar = progress.Null
+	}
+	pbar.Start(name, float64(cm.Size))
+	pbar.Set(float64(cm.doneSize()))
+	meter := &lockedWriter{w: io.MultiWriter(pbar, tc)}
+	limiter := newRateLimiter(dlOpts)
+
+	var mu sync.Mutex
+	var firstErr error
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	for n := 0; n < downloadConnections && n < len(pending); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chunks {
+				first, last := cm.chunk(i)
+				err := s.downloadChunk(downloadCtx, storeURL, cdnHeader, user, f, first, last, meter, limiter, dlOpts)
+				mu.Lock()
+				if err == nil {
+					cm.Done[i] = true
+					err = cm.save(mapPath)
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	stopMonitorCh := tc.Monitor()
+	startTime := time.Now()
+feed:
+	for _, i := range pending {
+		select {
+		case chunks <- i:
+		case <-downloadCtx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+	close(stopMonitorCh)
+	pbar.Finished()
+
+	if err := tc.Err(); err != nil {
+		return err
+	}
+	if cancelled(monitorCtx) {
+		return fmt.Errorf("the download has been cancelled: %s", monitorCtx.Err())
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	logger.Debugf("Chunked download succeeded in %.03fs.", time.Since(startTime).Seconds())
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	os.Remove(mapPath)
+	digest, _, err := osutil.FileDigest(partialPath, crypto.SHA3_384)
+	if err != nil {
+		return err
+	}
+	if actualSha3 := fmt.Sprintf("%x", digest); actualSha3 != downloadInfo.Sha3_384 {
+		return HashError{name, actualSha3, downloadInfo.Sha3_384}
+	}
+	return nil
+}
+
+// downloadChunk downloads the bytes from first to last, both included, to
+// the same offset of w, retrying from where it stopped on errors.
+func (s *Store) downloadChunk(ctx context.Context, storeURL *url.URL, cdnHeader string, user *auth.UserState, w io.WriterAt, first, last int64, meter io.Writer, limiter *rateLimiter, dlOpts *DownloadOptions) error {
+	offset := first
+	var finalErr error
+	startTime := time.Now()
+	for attempt := retry.Start(downloadRetryStrategy, nil); attempt.Next(); {
+		reqOptions := downloadReqOpts(storeURL, cdnHeader, dlOpts)
+		reqOptions.ExtraHeaders["Range"] = fmt.Sprintf("bytes=%d-%d", offset, last)
+
+		httputil.MaybeLogRetryAttempt(reqOptions.URL.String(), attempt, startTime)
+
+		cli := s.newDownloadHTTPClient(reqOptions)
+		resp, err := s.doRequest(ctx, cli, reqOptions, user)
+		if cancelled(ctx) {
+			return ctx.Err()
+		}
+		if err != nil {
+			finalErr = err
+			if httputil.ShouldRetryAttempt(attempt, err) {
+				continue
+			}
+			break
+		}
+		if httputil.ShouldRetryHttpResponse(attempt, resp) {
+			resp.Body.Close()
+			continue
+		}
+		switch resp.StatusCode {
+		case 206: // Partial Content
+		case 200:
+			resp.Body.Close()
+			return errRangeNotSupported
+		default:
+			resp.Body.Close()
+			return &DownloadError{Code: resp.StatusCode, URL: resp.Request.URL}
+		}
+
+		ow := &offsetWriter{w: w, off: offset}
+		body := io.LimitReader(limiter.Reader(resp.Body), last-offset+1)
+		_, err = io.Copy(io.MultiWriter(ow, meter), body)
+		resp.Body.Close()
+		offset = ow.off
+		if cancelled(ctx) {
+			return ctx.Err()
+		}
+		if err == nil && offset <= last {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			finalErr = err
+			if httputil.ShouldRetryAttempt(attempt, err) {
+				continue
+			}
+			break
+		}
+		return nil
+	}
+	return finalErr
+}
@@ -0,0 +1,229 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	. "gopkg.in/check.v1"
+	"gopkg.in/retry.v1"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type chunkedDownloadSuite struct {
+	testutil.BaseTest
+
+	content []byte
+	info    *snap.DownloadInfo
+	path    string
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+var _ = Suite(&chunkedDownloadSuite{})
+
+func (s *chunkedDownloadSuite) SetUpTest(c *C) {
+	s.BaseTest.SetUpTest(c)
+
+	store.MockDownloadRetryStrategy(&s.BaseTest, retry.LimitCount(5, retry.Exponential{
+		Initial: time.Millisecond,
+		Factor:  2.5,
+	}))
+	s.AddCleanup(store.MockChunkedDownload(3, 4, 10))
+
+	s.content = []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	s.info = &snap.DownloadInfo{
+		Size:     int64(len(s.content)),
+		Sha3_384: fmt.Sprintf("%x", sha3.Sum384(s.content)),
+	}
+	s.path = filepath.Join(c.MkDir(), "foo_1.snap")
+	s.ranges = nil
+}
+
+// serve serves the content and records the requested ranges.
+func (s *chunkedDownloadSuite) serve(c *C, ranges bool) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+		if !ranges {
+			w.Write(s.content)
+			return
+		}
+		http.ServeContent(w, r, "foo_1.snap", time.Time{}, bytes.NewReader(s.content))
+	}))
+	s.AddCleanup(server.Close)
+	s.info.DownloadURL = server.URL
+}
+
+func (s *chunkedDownloadSuite) requestedRanges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ranges := append([]string(nil), s.ranges...)
+	sort.Strings(ranges)
+	return ranges
+}
+
+func (s *chunkedDownloadSuite) TestChunkedDownload(c *C) {
+	s.serve(c, true)
+
+	sto := store.New(&store.Config{}, nil)
+	err := sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.path+".partial", testutil.FileAbsent)
+	c.Check(s.path+".partial.chunks", testutil.FileAbsent)
+	c.Check(s.requestedRanges(), DeepEquals, []string{
+		"bytes=0-3", "bytes=12-15", "bytes=16-19", "bytes=20-23",
+		"bytes=24-27", "bytes=28-31", "bytes=32-35", "bytes=4-7", "bytes=8-11",
+	})
+}
+
+func (s *chunkedDownloadSuite) TestChunkedDownloadResumes(c *C) {
+	s.serve(c, true)
+
+	// the first three chunks and the last one were downloaded before a
+	// restart
+	partial := make([]byte, len(s.content))
+	copy(partial, s.content[:12])
+	copy(partial[32:], s.content[32:])
+	c.Assert(os.WriteFile(s.path+".partial", partial, 0600), IsNil)
+	data, err := json.Marshal(map[string]interface{}{
+		"sha3-384":   s.info.Sha3_384,
+		"size":       s.info.Size,
+		"chunk-size": 4,
+		"done":       []bool{true, true, true, false, false, false, false, false, true},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(s.path+".partial.chunks", data, 0600), IsNil)
+
+	sto := store.New(&store.Config{}, nil)
+	err = sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.path+".partial.chunks", testutil.FileAbsent)
+	c.Check(s.requestedRanges(), DeepEquals, []string{
+		"bytes=12-15", "bytes=16-19", "bytes=20-23", "bytes=24-27", "bytes=28-31",
+	})
+}
+
+func (s *chunkedDownloadSuite) TestChunkedDownloadIgnoresOtherChunkMap(c *C) {
+	s.serve(c, true)
+
+	c.Assert(os.WriteFile(s.path+".partial", make([]byte, len(s.content)), 0600), IsNil)
+	data, err := json.Marshal(map[string]interface{}{
+		"sha3-384":   "other",
+		"size":       s.info.Size,
+		"chunk-size": 4,
+		"done":       []bool{true, true, true, true, true, true, true, true, true},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(os.WriteFile(s.path+".partial.chunks", data, 0600), IsNil)
+
+	sto := store.New(&store.Config{}, nil)
+	err = sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.path+".partial.chunks", testutil.FileAbsent)
+	// the partial download of another revision is resumed as a single
+	// stream, which starts over as it is complete but has the wrong hash
+	c.Check(s.requestedRanges(), DeepEquals, []string{""})
+}
+
+func (s *chunkedDownloadSuite) TestPartialDownloadWithoutChunkMapResumesSingleStream(c *C) {
+	s.serve(c, true)
+
+	c.Assert(os.WriteFile(s.path+".partial", s.content[:20], 0600), IsNil)
+
+	sto := store.New(&store.Config{}, nil)
+	err := sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.requestedRanges(), DeepEquals, []string{"bytes=20-"})
+}
+
+func (s *chunkedDownloadSuite) TestChunkedDownloadFallsBackWithoutRanges(c *C) {
+	s.serve(c, false)
+
+	sto := store.New(&store.Config{}, nil)
+	err := sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.path+".partial", testutil.FileAbsent)
+	c.Check(s.path+".partial.chunks", testutil.FileAbsent)
+	// the last request is the single stream one
+	ranges := s.requestedRanges()
+	c.Check(ranges[0], Equals, "")
+}
+
+func (s *chunkedDownloadSuite) TestSmallSnapsAreNotChunked(c *C) {
+	s.serve(c, true)
+	s.AddCleanup(store.MockChunkedDownload(3, 4, 100))
+
+	sto := store.New(&store.Config{}, nil)
+	err := sto.Download(context.Background(), "foo", s.path, s.info, nil, nil, nil)
+	c.Assert(err, IsNil)
+	c.Check(s.path, testutil.FileEquals, s.content)
+	c.Check(s.requestedRanges(), DeepEquals, []string{""})
+}
+
+func (s *chunkedDownloadSuite) TestChunkedDownloadCancelledKeepsChunkMap(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=0-3" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		http.ServeContent(w, r, "foo_1.snap", time.Time{}, bytes.NewReader(s.content))
+	}))
+	defer server.Close()
+	s.info.DownloadURL = server.URL
+	s.AddCleanup(store.MockChunkedDownload(2, 4, 10))
+
+	sto := store.New(&store.Config{}, nil)
+	err := sto.Download(ctx, "foo", s.path, s.info, nil, nil, &store.DownloadOptions{LeavePartialOnError: true})
+	c.Assert(err, ErrorMatches, "the download has been cancelled: context canceled")
+	c.Check(s.path, testutil.FileAbsent)
+	c.Check(s.path+".partial", testutil.FilePresent)
+	data, err := os.ReadFile(s.path + ".partial.chunks")
+	c.Assert(err, IsNil)
+	var chunks struct {
+		Done []bool `json:"done"`
+	}
+	c.Assert(json.Unmarshal(data, &chunks), IsNil)
+	c.Check(chunks.Done, HasLen, 9)
+	c.Check(chunks.Done[1:], DeepEquals, make([]bool, 8))
+}
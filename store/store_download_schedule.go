@@ -0,0 +1,178 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+
+	"github.com/snapcore/snapd/strutil"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+var timeNow = time.Now
+
+// RateWindow is a daily window of time during which downloads are limited
+// to RateLimit bytes per second, or not limited if RateLimit is 0.
+type RateWindow struct {
+	Start     timeutil.Clock
+	End       timeutil.Clock
+	RateLimit int64
+}
+
+func clockMinutes(c timeutil.Clock) int {
+	return c.Hour*60 + c.Minute
+}
+
+// Includes returns whether the given time is within the window. Windows
+// ending before they start span midnight.
+func (w RateWindow) Includes(t time.Time) bool {
+	now := t.Hour()*60 + t.Minute()
+	start, end := clockMinutes(w.Start), clockMinutes(w.End)
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+func (w RateWindow) String() string {
+	if w.RateLimit == 0 {
+		return fmt.Sprintf("%s-%s=unlimited", w.Start, w.End)
+	}
+	return fmt.Sprintf("%s-%s=%dB", w.Start, w.End, w.RateLimit)
+}
+
+// ParseRateSchedule parses a comma-separated list of daily windows with
+// their rate limit, as in "9:00-17:00=125kB,22:00-6:00=unlimited".
+func ParseRateSchedule(schedule string) ([]RateWindow, error) {
+	var windows []RateWindow
+	for _, spec := range strutil.CommaSeparatedList(schedule) {
+		span, rate, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("cannot parse rate window %q: missing rate", spec)
+		}
+		start, end, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("cannot parse rate window %q: not a valid interval", spec)
+		}
+		var w RateWindow
+		var err error
+		if w.Start, err = timeutil.ParseClock(start); err != nil {
+			return nil, fmt.Errorf("cannot parse rate window %q: %v", spec, err)
+		}
+		if w.End, err = timeutil.ParseClock(end); err != nil {
+			return nil, fmt.Errorf("cannot parse rate window %q: %v", spec, err)
+		}
+		if w.Start == w.End {
+			return nil, fmt.Errorf("cannot parse rate window %q: empty interval", spec)
+		}
+		if rate != "unlimited" {
+			if w.RateLimit, err = strutil.ParseByteSize(rate); err != nil {
+				return nil, fmt.Errorf("cannot parse rate window %q: %v", spec, err)
+			}
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// rateLimit returns the rate limit of the downloads at the given time, the
+// first matching window of the schedule wins over the default rate limit.
+func (opts *DownloadOptions) rateLimit(t time.Time) int64 {
+	if opts == nil {
+		return 0
+	}
+	for _, w := range opts.RateSchedule {
+		if w.Includes(t) {
+			return w.RateLimit
+		}
+	}
+	return opts.RateLimit
+}
+
+// rateLimiter limits the combined rate of the readers it wraps, following
+// the schedule of the download options. It is shared by the connections of
+// a chunked download.
+type rateLimiter struct {
+	opts *DownloadOptions
+
+	mu     sync.Mutex
+	rate   int64
+	bucket *ratelimit.Bucket
+}
+
+func newRateLimiter(opts *DownloadOptions) *rateLimiter {
+	rl := &rateLimiter{opts: opts}
+	rl.currentBucket()
+	return rl
+}
+
+// currentBucket returns the bucket for the rate limit in effect now, or nil
+// if there is no limit.
+func (rl *rateLimiter) currentBucket() *ratelimit.Bucket {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rate := rl.opts.rateLimit(timeNow())
+	if rate != rl.rate || (rate > 0 && rl.bucket == nil) {
+		rl.rate = rate
+		rl.bucket = nil
+		if rate > 0 {
+			rl.bucket = ratelimit.NewBucketWithRate(float64(rate), 2*rate)
+		}
+	}
+	return rl.bucket
+}
+
+// Reader returns a reader limited by the rate limiter.
+func (rl *rateLimiter) Reader(r io.Reader) io.Reader {
+	if rl.opts == nil || len(rl.opts.RateSchedule) == 0 {
+		// the rate never changes
+		if bucket := rl.currentBucket(); bucket != nil {
+			return ratelimitReader(r, bucket)
+		}
+		return r
+	}
+	return &scheduledReader{r: r, limiter: rl}
+}
+
+type scheduledReader struct {
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func (sr *scheduledReader) Read(p []byte) (int, error) {
+	bucket := sr.limiter.currentBucket()
+	if bucket == nil {
+		return sr.r.Read(p)
+	}
+	// read no more than the bucket holds so that a change of window is
+	// noticed soon enough
+	if capacity := bucket.Capacity(); int64(len(p)) > capacity {
+		p = p[:capacity]
+	}
+	n, err := sr.r.Read(p)
+	bucket.Wait(int64(n))
+	return n, err
+}
@@ -0,0 +1,121 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/timeutil"
+)
+
+type rateScheduleSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&rateScheduleSuite{})
+
+func (s *rateScheduleSuite) TestParseRateSchedule(c *C) {
+	windows, err := store.ParseRateSchedule("9:00-17:00=125kB, 22:00-6:00=unlimited")
+	c.Assert(err, IsNil)
+	c.Check(windows, DeepEquals, []store.RateWindow{{
+		Start:     timeutil.Clock{Hour: 9},
+		End:       timeutil.Clock{Hour: 17},
+		RateLimit: 125000,
+	}, {
+		Start: timeutil.Clock{Hour: 22},
+		End:   timeutil.Clock{Hour: 6},
+	}})
+	c.Check(windows[0].String(), Equals, "09:00-17:00=125000B")
+	c.Check(windows[1].String(), Equals, "22:00-06:00=unlimited")
+
+	windows, err = store.ParseRateSchedule("")
+	c.Assert(err, IsNil)
+	c.Check(windows, HasLen, 0)
+}
+
+func (s *rateScheduleSuite) TestParseRateScheduleErrors(c *C) {
+	for _, tc := range []struct {
+		schedule string
+		err      string
+	}{
+		{"9:00-17:00", `cannot parse rate window "9:00-17:00": missing rate`},
+		{"9:00=1MB", `cannot parse rate window "9:00=1MB": not a valid interval`},
+		{"9:00-25:00=1MB", `cannot parse rate window "9:00-25:00=1MB": cannot parse "25:00"`},
+		{"x-17:00=1MB", `cannot parse rate window "x-17:00=1MB": cannot parse "x"`},
+		{"9:00-9:00=1MB", `cannot parse rate window "9:00-9:00=1MB": empty interval`},
+		{"9:00-17:00=fast", `cannot parse rate window "9:00-17:00=fast": cannot parse "fast": .*`},
+		{"9:00-17:00=-1MB", `cannot parse rate window "9:00-17:00=-1MB": cannot parse "-1MB": size cannot be negative`},
+	} {
+		_, err := store.ParseRateSchedule(tc.schedule)
+		c.Check(err, ErrorMatches, tc.err, Commentf(tc.schedule))
+	}
+}
+
+func (s *rateScheduleSuite) TestRateWindowIncludes(c *C) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 1, hour, minute, 0, 0, time.Local)
+	}
+	day := store.RateWindow{Start: timeutil.Clock{Hour: 9}, End: timeutil.Clock{Hour: 17, Minute: 30}}
+	c.Check(day.Includes(at(8, 59)), Equals, false)
+	c.Check(day.Includes(at(9, 0)), Equals, true)
+	c.Check(day.Includes(at(17, 29)), Equals, true)
+	c.Check(day.Includes(at(17, 30)), Equals, false)
+
+	night := store.RateWindow{Start: timeutil.Clock{Hour: 22}, End: timeutil.Clock{Hour: 6}}
+	c.Check(night.Includes(at(21, 59)), Equals, false)
+	c.Check(night.Includes(at(22, 0)), Equals, true)
+	c.Check(night.Includes(at(0, 0)), Equals, true)
+	c.Check(night.Includes(at(5, 59)), Equals, true)
+	c.Check(night.Includes(at(6, 0)), Equals, false)
+
+	untilMidnight := store.RateWindow{Start: timeutil.Clock{Hour: 20}, End: timeutil.Clock{Hour: 24}}
+	c.Check(untilMidnight.Includes(at(23, 59)), Equals, true)
+	c.Check(untilMidnight.Includes(at(0, 0)), Equals, false)
+}
+
+func (s *rateScheduleSuite) TestRateLimitFollowsSchedule(c *C) {
+	windows, err := store.ParseRateSchedule("9:00-17:00=1MB,22:00-6:00=unlimited")
+	c.Assert(err, IsNil)
+	opts := &store.DownloadOptions{RateLimit: 2000, RateSchedule: windows}
+
+	for _, tc := range []struct {
+		hour int
+		rate float64
+	}{
+		{10, 1000 * 1000},
+		{23, 0},
+		{3, 0},
+		// outside of the windows the default rate limit applies
+		{7, 2000},
+		{18, 2000},
+	} {
+		now := time.Date(2024, 3, 1, tc.hour, 0, 0, 0, time.Local)
+		restore := store.MockTimeNow(func() time.Time { return now })
+		c.Check(store.RateLimitNow(opts), Equals, tc.rate, Commentf("%d:00", tc.hour))
+		restore()
+	}
+
+	c.Check(store.RateLimitNow(nil), Equals, float64(0))
+	c.Check(store.RateLimitNow(&store.DownloadOptions{RateLimit: 10}), Equals, float64(10))
+}
@@ -0,0 +1,393 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/snapcore/snapd/logger"
+)
+
+// A store session can be recorded by pointing SNAPD_STORE_RECORD at a
+// file, and replayed without reaching the network by pointing
+// SNAPD_STORE_REPLAY at such a file. Recordings are sanitised of
+// authorization headers, macaroons and credentials so that they can be
+// attached to bug reports.
+const (
+	recordEnvKey = "SNAPD_STORE_RECORD"
+	replayEnvKey = "SNAPD_STORE_REPLAY"
+)
+
+// maxRecordedBodySize is the size after which bodies are truncated in
+// recordings, mainly to avoid recording whole snap downloads.
+var maxRecordedBodySize = 1 << 20
+
+// RecordedExchange is a store request and its response as captured by
+// a Recorder.
+type RecordedExchange struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"request-header,omitempty"`
+	RequestBody    []byte      `json:"request-body,omitempty"`
+	Status         int         `json:"status,omitempty"`
+	ResponseHeader http.Header `json:"response-header,omitempty"`
+	ResponseBody   []byte      `json:"response-body,omitempty"`
+	// BodyTruncated is set when only the beginning of the response
+	// body was recorded.
+	BodyTruncated bool `json:"body-truncated,omitempty"`
+	// Error is set when the request failed without a response.
+	Error string `json:"error,omitempty"`
+}
+
+func (e *RecordedExchange) key() string {
+	return exchangeKey(e.Method, e.URL)
+}
+
+// exchangeKey identifies requests by method, path and query, ignoring
+// the host so that a session can be replayed by a server at a
+// different address.
+func exchangeKey(method, rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest := rawURL[i+3:]
+		if j := strings.IndexByte(rest, '/'); j >= 0 {
+			rawURL = rest[j:]
+		} else {
+			rawURL = "/"
+		}
+	}
+	return method + " " + rawURL
+}
+
+const redacted = "<redacted>"
+
+var sensitiveJSONField = regexp.MustCompile(`("(?:[A-Za-z_-]*macaroon[A-Za-z_-]*|password|otp)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+func sanitiseHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	h = h.Clone()
+	for name := range h {
+		if strings.Contains(name, "Authorization") || name == "Cookie" || name == "Set-Cookie" {
+			h[name] = []string{redacted}
+		}
+	}
+	return h
+}
+
+func sanitiseBody(body []byte) []byte {
+	return sensitiveJSONField.ReplaceAll(body, []byte(`${1}"`+redacted+`"`))
+}
+
+// Recorder captures store requests and responses as JSON lines.
+type Recorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecorder returns a Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+func (r *Recorder) record(e *RecordedExchange) {
+	e.RequestHeader = sanitiseHeader(e.RequestHeader)
+	e.RequestBody = sanitiseBody(e.RequestBody)
+	e.ResponseHeader = sanitiseHeader(e.ResponseHeader)
+	e.ResponseBody = sanitiseBody(e.ResponseBody)
+	line, err := json.Marshal(e)
+	if err != nil {
+		logger.Noticef("cannot record store exchange: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		logger.Noticef("cannot record store exchange: %v", err)
+	}
+}
+
+// Transport returns a http.RoundTripper recording the exchanges done
+// through tr.
+func (r *Recorder) Transport(tr http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{rec: r, tr: tr}
+}
+
+type recordingTransport struct {
+	rec *Recorder
+	tr  http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := &RecordedExchange{
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: req.Header,
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		// request bodies are small, read them whole
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		e.RequestBody = body
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	rsp, err := t.tr.RoundTrip(req)
+	if err != nil {
+		e.Error = err.Error()
+		t.rec.record(e)
+		return nil, err
+	}
+	e.Status = rsp.StatusCode
+	e.ResponseHeader = rsp.Header
+	rsp.Body = &recordingBody{ReadCloser: rsp.Body, rec: t.rec, e: e}
+	return rsp, nil
+}
+
+// recordingBody records the exchange once the response body has been
+// consumed or closed, keeping downloads streamed.
+type recordingBody struct {
+	io.ReadCloser
+	rec  *Recorder
+	e    *RecordedExchange
+	buf  bytes.Buffer
+	done bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if room := maxRecordedBodySize - b.buf.Len(); room >= n {
+			b.buf.Write(p[:n])
+		} else {
+			if room > 0 {
+				b.buf.Write(p[:room])
+			}
+			b.e.BodyTruncated = true
+		}
+	}
+	if err == io.EOF {
+		b.finish(false)
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish(true)
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish(early bool) {
+	if b.done {
+		return
+	}
+	b.done = true
+	if early {
+		// the reader gave up before the end of the body
+		b.e.BodyTruncated = true
+	}
+	b.e.ResponseBody = b.buf.Bytes()
+	b.rec.record(b.e)
+}
+
+// Replayer serves back the exchanges of a recorded store session.
+// Requests are matched on method, path and query; repeated requests get
+// the recorded responses in order, the last one being served again
+// once they are exhausted.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]*RecordedExchange
+	served    map[string]int
+}
+
+// NewReplayer returns a Replayer for the session recorded in r.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	rp := &Replayer{
+		exchanges: make(map[string][]*RecordedExchange),
+		served:    make(map[string]int),
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 4*maxRecordedBodySize+64*1024)
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e RecordedExchange
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("cannot read recorded store session: line %d: %v", n, err)
+		}
+		k := e.key()
+		rp.exchanges[k] = append(rp.exchanges[k], &e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read recorded store session: %v", err)
+	}
+	return rp, nil
+}
+
+func (rp *Replayer) next(method, rawURL string) (*RecordedExchange, error) {
+	k := exchangeKey(method, rawURL)
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	candidates := rp.exchanges[k]
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no recorded store response for %s", k)
+	}
+	i := rp.served[k]
+	if i >= len(candidates) {
+		i = len(candidates) - 1
+	}
+	rp.served[k] = i + 1
+	return candidates[i], nil
+}
+
+// RoundTrip is from the http.RoundTripper interface.
+func (rp *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	e, err := rp.next(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	if e.Error != "" {
+		return nil, fmt.Errorf("%s (replayed)", e.Error)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.ResponseHeader.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.ResponseBody)),
+		ContentLength: int64(len(e.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// ServeHTTP is from the http.Handler interface, it allows to use a
+// Replayer as a fake store server.
+func (rp *Replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e, err := rp.next(req.Method, req.URL.RequestURI())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if e.Error != "" {
+		http.Error(w, e.Error, http.StatusBadGateway)
+		return
+	}
+	for name, values := range e.ResponseHeader {
+		// the body might have been truncated
+		if name == "Content-Length" {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.WriteHeader(e.Status)
+	w.Write(e.ResponseBody)
+}
+
+var (
+	sessionMu        sync.Mutex
+	sessionRecorders = make(map[string]*Recorder)
+	sessionReplayers = make(map[string]*Replayer)
+)
+
+func sessionRecorder(path string) (*Recorder, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if rec := sessionRecorders[path]; rec != nil {
+		return rec, nil
+	}
+	// recordings are sanitised but still describe the device
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	rec := NewRecorder(f)
+	sessionRecorders[path] = rec
+	return rec, nil
+}
+
+func sessionReplayer(path string) (*Replayer, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	if rp := sessionReplayers[path]; rp != nil {
+		return rp, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rp, err := NewReplayer(f)
+	if err != nil {
+		return nil, err
+	}
+	sessionReplayers[path] = rp
+	return rp, nil
+}
+
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// sessionTransport wraps tr to record the store session or replaces it
+// to replay a recorded one, as requested by the environment.
+func sessionTransport(tr http.RoundTripper) http.RoundTripper {
+	if path := os.Getenv(replayEnvKey); path != "" {
+		rp, err := sessionReplayer(path)
+		if err != nil {
+			// never fall back to the network when replaying
+			return failingTransport{err: fmt.Errorf("cannot replay store session: %v", err)}
+		}
+		return rp
+	}
+	if path := os.Getenv(recordEnvKey); path != "" {
+		rec, err := sessionRecorder(path)
+		if err != nil {
+			logger.Noticef("cannot record store session: %v", err)
+			return tr
+		}
+		return rec.Transport(tr)
+	}
+	return tr
+}
@@ -0,0 +1,278 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package store_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/store"
+	"github.com/snapcore/snapd/testutil"
+)
+
+type recordSuite struct {
+	baseStoreSuite
+}
+
+var _ = Suite(&recordSuite{})
+
+func readRecording(c *C, data []byte) []*store.RecordedExchange {
+	var exchanges []*store.RecordedExchange
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var e store.RecordedExchange
+		c.Assert(json.Unmarshal(line, &e), IsNil)
+		exchanges = append(exchanges, &e)
+	}
+	return exchanges
+}
+
+func (s *recordSuite) TestRecorderSanitises(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		c.Assert(err, IsNil)
+		// the server gets the real thing
+		c.Check(string(body), Equals, `{"discharge_macaroon": "secret", "email": "me@example.com", "password":"hunter2"}`)
+		c.Check(r.Header.Get("Authorization"), Equals, `Macaroon root="secret"`)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"macaroon":"also \"secret\"","footprint":"kept"}`)
+	}))
+	defer mockServer.Close()
+
+	var buf bytes.Buffer
+	rec := store.NewRecorder(&buf)
+	cli := &http.Client{Transport: rec.Transport(http.DefaultTransport)}
+
+	req, err := http.NewRequest("POST", mockServer.URL+"/api/v2/tokens/refresh?x=1", strings.NewReader(`{"discharge_macaroon": "secret", "email": "me@example.com", "password":"hunter2"}`))
+	c.Assert(err, IsNil)
+	req.Header.Set("Authorization", `Macaroon root="secret"`)
+	req.Header.Set("Snap-Device-Authorization", `Macaroon root="secret"`)
+	req.Header.Set("Snap-Device-Series", "16")
+	rsp, err := cli.Do(req)
+	c.Assert(err, IsNil)
+	body, err := io.ReadAll(rsp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(rsp.Body.Close(), IsNil)
+	// the client gets the real thing
+	c.Check(string(body), Equals, `{"macaroon":"also \"secret\"","footprint":"kept"}`)
+
+	c.Check(buf.String(), Not(testutil.Contains), "secret")
+	c.Check(buf.String(), Not(testutil.Contains), "hunter2")
+
+	exchanges := readRecording(c, buf.Bytes())
+	c.Assert(exchanges, HasLen, 1)
+	e := exchanges[0]
+	c.Check(e.Method, Equals, "POST")
+	c.Check(e.URL, Equals, mockServer.URL+"/api/v2/tokens/refresh?x=1")
+	c.Check(e.RequestHeader.Get("Authorization"), Equals, "<redacted>")
+	c.Check(e.RequestHeader.Get("Snap-Device-Authorization"), Equals, "<redacted>")
+	c.Check(e.RequestHeader.Get("Snap-Device-Series"), Equals, "16")
+	c.Check(string(e.RequestBody), Equals, `{"discharge_macaroon": "<redacted>", "email": "me@example.com", "password":"<redacted>"}`)
+	c.Check(e.Status, Equals, 200)
+	c.Check(e.ResponseHeader.Get("Content-Type"), Equals, "application/json")
+	c.Check(string(e.ResponseBody), Equals, `{"macaroon":"<redacted>","footprint":"kept"}`)
+	c.Check(e.BodyTruncated, Equals, false)
+}
+
+func (s *recordSuite) TestRecorderTruncatesBodies(c *C) {
+	restore := store.MockMaxRecordedBodySize(4)
+	defer restore()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "0123456789")
+	}))
+	defer mockServer.Close()
+
+	var buf bytes.Buffer
+	cli := &http.Client{Transport: store.NewRecorder(&buf).Transport(http.DefaultTransport)}
+
+	// fully read
+	rsp, err := cli.Get(mockServer.URL + "/download")
+	c.Assert(err, IsNil)
+	body, err := io.ReadAll(rsp.Body)
+	c.Assert(err, IsNil)
+	c.Check(string(body), Equals, "0123456789")
+	rsp.Body.Close()
+
+	// closed early
+	rsp, err = cli.Get(mockServer.URL + "/download")
+	c.Assert(err, IsNil)
+	rsp.Body.Close()
+
+	exchanges := readRecording(c, buf.Bytes())
+	c.Assert(exchanges, HasLen, 2)
+	c.Check(string(exchanges[0].ResponseBody), Equals, "0123")
+	c.Check(exchanges[0].BodyTruncated, Equals, true)
+	c.Check(exchanges[1].BodyTruncated, Equals, true)
+}
+
+func (s *recordSuite) TestRecorderRecordsErrors(c *C) {
+	var buf bytes.Buffer
+	cli := &http.Client{Transport: store.NewRecorder(&buf).Transport(http.DefaultTransport)}
+	_, err := cli.Get("http://127.0.0.1:0/nowhere")
+	c.Assert(err, NotNil)
+
+	exchanges := readRecording(c, buf.Bytes())
+	c.Assert(exchanges, HasLen, 1)
+	c.Check(exchanges[0].Error, Not(Equals), "")
+	c.Check(exchanges[0].Status, Equals, 0)
+}
+
+const recordedSession = `{"method":"GET","url":"https://api.snapcraft.io/v2/snaps/info/foo?fields=a","status":200,"response-header":{"Content-Type":["application/json"]},"response-body":"Zmlyc3Q="}
+{"method":"GET","url":"https://api.snapcraft.io/v2/snaps/info/foo?fields=a","status":404,"response-body":"c2Vjb25k"}
+
+{"method":"POST","url":"https://api.snapcraft.io/v2/snaps/refresh","error":"connection reset"}
+`
+
+func (s *recordSuite) TestReplayerRoundTrip(c *C) {
+	rp, err := store.NewReplayer(strings.NewReader(recordedSession))
+	c.Assert(err, IsNil)
+	cli := &http.Client{Transport: rp}
+
+	get := func(u string) (int, string, string) {
+		rsp, err := cli.Get(u)
+		c.Assert(err, IsNil)
+		defer rsp.Body.Close()
+		body, err := io.ReadAll(rsp.Body)
+		c.Assert(err, IsNil)
+		return rsp.StatusCode, rsp.Header.Get("Content-Type"), string(body)
+	}
+
+	// the host does not matter
+	status, ct, body := get("http://localhost:1234/v2/snaps/info/foo?fields=a")
+	c.Check(status, Equals, 200)
+	c.Check(ct, Equals, "application/json")
+	c.Check(body, Equals, "first")
+	status, _, body = get("https://api.snapcraft.io/v2/snaps/info/foo?fields=a")
+	c.Check(status, Equals, 404)
+	c.Check(body, Equals, "second")
+	// the last response is served again
+	status, _, body = get("https://api.snapcraft.io/v2/snaps/info/foo?fields=a")
+	c.Check(status, Equals, 404)
+	c.Check(body, Equals, "second")
+
+	_, err = cli.Get("https://api.snapcraft.io/v2/snaps/info/foo?fields=b")
+	c.Check(err, ErrorMatches, `.*no recorded store response for GET /v2/snaps/info/foo\?fields=b`)
+
+	_, err = cli.Post("https://api.snapcraft.io/v2/snaps/refresh", "application/json", strings.NewReader("{}"))
+	c.Check(err, ErrorMatches, `.*connection reset \(replayed\)`)
+}
+
+func (s *recordSuite) TestReplayerServeHTTP(c *C) {
+	rp, err := store.NewReplayer(strings.NewReader(recordedSession))
+	c.Assert(err, IsNil)
+	server := httptest.NewServer(rp)
+	defer server.Close()
+
+	rsp, err := http.Get(server.URL + "/v2/snaps/info/foo?fields=a")
+	c.Assert(err, IsNil)
+	body, err := io.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	c.Assert(err, IsNil)
+	c.Check(rsp.StatusCode, Equals, 200)
+	c.Check(string(body), Equals, "first")
+
+	rsp, err = http.Get(server.URL + "/v2/snaps/info/bar")
+	c.Assert(err, IsNil)
+	rsp.Body.Close()
+	c.Check(rsp.StatusCode, Equals, 404)
+
+	rsp, err = http.Post(server.URL+"/v2/snaps/refresh", "application/json", nil)
+	c.Assert(err, IsNil)
+	rsp.Body.Close()
+	c.Check(rsp.StatusCode, Equals, 502)
+}
+
+func (s *recordSuite) TestNewReplayerBadRecording(c *C) {
+	_, err := store.NewReplayer(strings.NewReader("{\"method\":\"GET\"}\nnot json\n"))
+	c.Check(err, ErrorMatches, `cannot read recorded store session: line 2: .*`)
+}
+
+func (s *recordSuite) TestRecordAndReplayStoreSession(c *C) {
+	n := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(c, r, "GET", sectionsPath)
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.WriteHeader(200)
+		io.WriteString(w, MockSectionsJSON)
+		n++
+	}))
+	serverURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: serverURL,
+	}
+	dauthCtx := &testDauthContext{c: c, device: s.device}
+
+	recording := filepath.Join(c.MkDir(), "session.json")
+	os.Setenv("SNAPD_STORE_RECORD", recording)
+	defer os.Unsetenv("SNAPD_STORE_RECORD")
+
+	sto := store.New(&cfg, dauthCtx)
+	sections, err := sto.Sections(s.ctx, s.user)
+	c.Assert(err, IsNil)
+	c.Check(sections, DeepEquals, []string{"featured", "database"})
+	c.Check(n, Equals, 1)
+	mockServer.Close()
+
+	data, err := os.ReadFile(recording)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Not(testutil.Contains), s.user.StoreMacaroon)
+	c.Check(string(data), Not(testutil.Contains), s.device.SessionMacaroon)
+	st, err := os.Stat(recording)
+	c.Assert(err, IsNil)
+	c.Check(st.Mode().Perm(), Equals, os.FileMode(0600))
+
+	// now replay it, the store server is gone
+	os.Unsetenv("SNAPD_STORE_RECORD")
+	os.Setenv("SNAPD_STORE_REPLAY", recording)
+	defer os.Unsetenv("SNAPD_STORE_REPLAY")
+
+	sto = store.New(&cfg, dauthCtx)
+	sections, err = sto.Sections(s.ctx, s.user)
+	c.Assert(err, IsNil)
+	c.Check(sections, DeepEquals, []string{"featured", "database"})
+	c.Check(n, Equals, 1)
+}
+
+func (s *recordSuite) TestReplayMissingRecordingDoesNotUseNetwork(c *C) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Fatalf("unexpected request")
+	}))
+	defer mockServer.Close()
+	serverURL, _ := url.Parse(mockServer.URL)
+	cfg := store.Config{
+		StoreBaseURL: serverURL,
+	}
+
+	os.Setenv("SNAPD_STORE_REPLAY", filepath.Join(c.MkDir(), "missing.json"))
+	defer os.Unsetenv("SNAPD_STORE_REPLAY")
+
+	sto := store.New(&cfg, &testDauthContext{c: c, device: s.device})
+	_, err := sto.Sections(s.ctx, s.user)
+	c.Check(err, ErrorMatches, `.*cannot replay store session: open .*/missing.json: no such file or directory`)
+}
@@ -104,6 +104,10 @@ func (s *emulation) InactiveEnterTimestamp(unit string) (time.Time, error) {
 	return time.Time{}, &notImplementedError{"InactiveEnterTimestamp"}
 }
 
+func (s *emulation) RestartStatus(units []string) ([]*ServiceRestartStatus, error) {
+	return nil, &notImplementedError{"RestartStatus"}
+}
+
 func (s *emulation) CurrentMemoryUsage(unit string) (quantity.Size, error) {
 	return 0, &notImplementedError{"CurrentMemoryUsage"}
 }
@@ -120,7 +124,7 @@ func (s *emulation) IsActive(service string) (bool, error) {
 	return false, &notImplementedError{"IsActive"}
 }
 
-func (s *emulation) LogReader(services []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+func (s *emulation) LogReader(services []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error) {
 	return nil, fmt.Errorf("LogReader")
 }
 
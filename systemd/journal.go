@@ -21,10 +21,16 @@ package systemd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log/syslog"
 	"net"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var journalStdoutPath = "/run/systemd/journal/stdout"
@@ -70,3 +76,115 @@ func NewJournalStreamFile(identifier string, priority syslog.Priority, levelPref
 
 	return conn.File()
 }
+
+// LogQuery restricts which journal entries are returned by LogReader, on top
+// of the selection by unit and number of lines.
+type LogQuery struct {
+	// Since and Until, when set, limit the entries to the ones logged at
+	// or after Since and at or before Until respectively.
+	Since time.Time
+	Until time.Time
+	// Priority is a syslog priority, either by name or number, or a
+	// range of priorities in the form FROM..TO.
+	Priority string
+	// Grep is a regular expression the message must match.
+	Grep string
+	// Boot selects the entries of a specific boot, either by offset
+	// (0 is the current boot, -1 the previous one, and so on) or by
+	// boot ID.
+	Boot string
+	// AfterCursor resumes reading after the entry with the given cursor.
+	AfterCursor string
+}
+
+var logPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+func validateLogPriority(prio string) error {
+	if n, err := strconv.Atoi(prio); err == nil {
+		if n < 0 || n >= len(logPriorities) {
+			return fmt.Errorf("invalid log priority %q", prio)
+		}
+		return nil
+	}
+	for _, name := range logPriorities {
+		if prio == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid log priority %q", prio)
+}
+
+var bootIDRegexp = regexp.MustCompile(`^(-?[0-9]+|[0-9a-f]{32})$`)
+
+// Validate checks that the query can be passed on to journalctl.
+func (q *LogQuery) Validate() error {
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return errors.New("log query end time is before its start time")
+	}
+	if q.Priority != "" {
+		from, to, isRange := strings.Cut(q.Priority, "..")
+		if err := validateLogPriority(from); err != nil {
+			return err
+		}
+		if isRange {
+			if err := validateLogPriority(to); err != nil {
+				return err
+			}
+		}
+	}
+	if q.Boot != "" && !bootIDRegexp.MatchString(q.Boot) {
+		return fmt.Errorf("invalid boot %q: expected an offset or a boot ID", q.Boot)
+	}
+	if strings.ContainsAny(q.AfterCursor, "\n\x00") {
+		return fmt.Errorf("invalid log cursor %q", q.AfterCursor)
+	}
+	return nil
+}
+
+func journalTime(t time.Time) string {
+	// journalctl accepts seconds since the epoch prefixed by "@", which
+	// avoids any ambiguity about timezones
+	return fmt.Sprintf("@%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
+
+// args returns the journalctl options implementing the query. Values are
+// always attached to their option so that they cannot be mistaken for
+// options themselves.
+func (q *LogQuery) args() []string {
+	if q == nil {
+		return nil
+	}
+	var args []string
+	if !q.Since.IsZero() {
+		args = append(args, "--since="+journalTime(q.Since))
+	}
+	if !q.Until.IsZero() {
+		args = append(args, "--until="+journalTime(q.Until))
+	}
+	if q.Priority != "" {
+		args = append(args, "--priority="+q.Priority)
+	}
+	if q.Grep != "" {
+		args = append(args, "--grep="+q.Grep)
+	}
+	if q.Boot != "" {
+		args = append(args, "--boot="+q.Boot)
+	}
+	if q.AfterCursor != "" {
+		args = append(args, "--after-cursor="+q.AfterCursor)
+	}
+	return args
+}
+
+// AuditLogReader returns a reader for the JSON log of the kernel and audit
+// messages, which is where AppArmor and seccomp report denials. The last n
+// messages are returned first, then new messages as they arrive if follow is
+// set.
+func AuditLogReader(n int, follow bool) (io.ReadCloser, error) {
+	args := []string{"-o", "json", "--no-pager", "-n", strconv.Itoa(n)}
+	if follow {
+		args = append(args, "-f")
+	}
+	args = append(args, "_TRANSPORT=kernel", "+", "_TRANSPORT=audit")
+	return osutilStreamCommand("journalctl", args...)
+}
@@ -20,6 +20,7 @@
 package systemd_test
 
 import (
+	"io"
 	"log/syslog"
 	"net"
 	"path"
@@ -87,3 +88,20 @@ func (j *journalTestSuite) TestStreamFileHeader(c *C) {
 
 	<-doneCh
 }
+
+func (j *journalTestSuite) TestAuditLogReader(c *C) {
+	var args []string
+	restore := MockOsutilStreamCommand(func(name string, myargs ...string) (io.ReadCloser, error) {
+		c.Check(name, Equals, "journalctl")
+		args = myargs
+		return nil, nil
+	})
+	defer restore()
+
+	_, err := AuditLogReader(0, true)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "0", "-f", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit"})
+	_, err = AuditLogReader(10, false)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "_TRANSPORT=kernel", "+", "_TRANSPORT=audit"})
+}
@@ -34,9 +34,12 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"text/template"
 	"time"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/snapcore/snapd/dirs"
 	"github.com/snapcore/snapd/gadget/quantity"
 	"github.com/snapcore/snapd/logger"
@@ -276,11 +279,12 @@ func EnsureAtLeast(requiredVersion int) error {
 var osutilStreamCommand = osutil.StreamCommand
 
 // jctl calls journalctl to get the JSON logs of the given services.
-var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+var jctl = func(svcs []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error) {
+	queryArgs := query.args()
 	// args will need two entries per service, plus a fixed number (give or take
-	// one) for the initial options.
-	args := make([]string, 0, 2*len(svcs)+7)        // We have at most 7 extra arguments
-	args = append(args, "-o", "json", "--no-pager") //   3...
+	// one) for the initial options, plus the ones for the query.
+	args := make([]string, 0, 2*len(svcs)+7+len(queryArgs)) // We have at most 7 extra arguments
+	args = append(args, "-o", "json", "--no-pager")         //   3...
 	if n < 0 {
 		args = append(args, "--no-tail") // < 2
 	} else {
@@ -292,6 +296,7 @@ var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, e
 	if namespaces {
 		args = append(args, "--namespace=*") // ... + 1 == 7
 	}
+	args = append(args, queryArgs...)
 
 	for i := range svcs {
 		args = append(args, "-u", svcs[i]) // this is why 2×
@@ -300,7 +305,7 @@ var jctl = func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, e
 	return osutilStreamCommand("journalctl", args...)
 }
 
-func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error)) func() {
+func MockJournalctl(f func(svcs []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error)) func() {
 	oldJctl := jctl
 	jctl = f
 	return func() {
@@ -411,6 +416,10 @@ type Systemd interface {
 	// unit's transition to inactive.
 	// TODO: incorporate this result into Status instead?
 	InactiveEnterTimestamp(unit string) (time.Time, error)
+	// RestartStatus returns the restart count and the last exit of the
+	// main process of the given service units, in the same order as unit
+	// names passed in argument.
+	RestartStatus(units []string) ([]*ServiceRestartStatus, error)
 	// IsEnabled checks whether the given service is enabled.
 	IsEnabled(service string) (bool, error)
 	// IsActive checks whether the given service is Active
@@ -420,7 +429,8 @@ type Systemd interface {
 	// as it grows.
 	// If namespaces is set to true, the log reader will include journal namespace
 	// logs, and is required to get logs for services which are in journal namespaces.
-	LogReader(services []string, n int, follow, namespaces bool) (io.ReadCloser, error)
+	// If query is not nil, only the log entries matching it are returned.
+	LogReader(services []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error)
 	// EnsureMountUnitFile adds/enables/starts a mount unit.
 	EnsureMountUnitFile(description, what, where, fstype string, flags EnsureMountUnitFlags) (string, error)
 	// EnsureMountUnitFileWithOptions adds/enables/starts a mount unit with options.
@@ -503,6 +513,9 @@ const (
 	// the default target for systemd timer units that we generate
 	TimersTarget = "timers.target"
 
+	// the default target for systemd path units that we generate
+	PathsTarget = "paths.target"
+
 	// the target for systemd user session units that we generate
 	UserServicesTarget = "default.target"
 )
@@ -682,8 +695,8 @@ func (s *systemd) StartNoBlock(serviceNames []string) error {
 	return err
 }
 
-func (*systemd) LogReader(serviceNames []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
-	return jctl(serviceNames, n, follow, namespaces)
+func (*systemd) LogReader(serviceNames []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error) {
+	return jctl(serviceNames, n, follow, namespaces, query)
 }
 
 var statusregex = regexp.MustCompile(`(?m)^(?:(.+?)=(.*)|(.*))?$`)
@@ -716,6 +729,7 @@ var unitProperties = map[string][]string{
 	".timer":  baseProperties,
 	".socket": baseProperties,
 	".target": baseProperties,
+	".path":   baseProperties,
 	// in service units, Type is the daemon type
 	".service": extendedProperties,
 	// in mount units, Type is the fs type
@@ -950,6 +964,127 @@ func (s *systemd) InactiveEnterTimestamp(unit string) (time.Time, error) {
 	return inactiveEnterTime, nil
 }
 
+// ServiceRestartStatus describes the restarts and the last exit of the main
+// process of a service unit, as tracked by systemd since the unit was last
+// started explicitly.
+type ServiceRestartStatus struct {
+	// Name is the name of the unit.
+	Name string
+	// Restarts is the number of automatic restarts of the unit.
+	Restarts int
+	// ExitCode is the exit status of the main process, if it exited.
+	ExitCode int
+	// ExitSignal is the name of the signal that terminated the main
+	// process, if it was killed.
+	ExitSignal string
+	// ExitTime is the time the main process last exited, or the zero time
+	// if it has not.
+	ExitTime time.Time
+	// Result is the result of the last run of the unit, e.g. "success",
+	// "exit-code", "signal", "core-dump" or "start-limit-hit".
+	Result string
+}
+
+// values of ExecMainCode, see waitid(2)
+const (
+	cldExited = 1
+	cldKilled = 2
+	cldDumped = 3
+)
+
+// Failed returns whether the main process of the unit last exited in a way
+// that systemd considers a failure.
+func (st *ServiceRestartStatus) Failed() bool {
+	switch {
+	case st.Result != "" && st.Result != "success":
+		return true
+	case st.ExitCode != 0:
+		return true
+	}
+	switch st.ExitSignal {
+	case "", "SIGHUP", "SIGINT", "SIGTERM", "SIGPIPE":
+		// these are clean signals for systemd
+		return false
+	}
+	return true
+}
+
+var restartStatusProperties = []string{"Id", "NRestarts", "ExecMainCode", "ExecMainStatus", "ExecMainExitTimestamp", "Result"}
+
+func (s *systemd) RestartStatus(units []string) ([]*ServiceRestartStatus, error) {
+	if len(units) == 0 {
+		return nil, nil
+	}
+	cmd := append([]string{"show", "--property=" + strings.Join(restartStatusProperties, ",")}, units...)
+	out, err := s.systemctl(cmd...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the properties of each unit are separated by an empty line
+	blocks := strings.Split(strings.TrimSpace(string(out)), "\n\n")
+	if len(blocks) != len(units) {
+		return nil, fmt.Errorf("cannot get restart status: expected %d results, got %d", len(units), len(blocks))
+	}
+	sts := make([]*ServiceRestartStatus, len(units))
+	for i, block := range blocks {
+		st, err := parseRestartStatus(block)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get restart status of %q: %v", units[i], err)
+		}
+		if st.Name != units[i] {
+			return nil, fmt.Errorf("cannot get restart status: queried status of %q but got status of %q", units[i], st.Name)
+		}
+		sts[i] = st
+	}
+	return sts, nil
+}
+
+func parseRestartStatus(block string) (*ServiceRestartStatus, error) {
+	st := &ServiceRestartStatus{}
+	var code, status int
+	for _, line := range strings.Split(block, "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("bad line %q in ‘systemctl show’ output", line)
+		}
+		k, v := kv[0], strings.TrimSpace(kv[1])
+		var err error
+		switch k {
+		case "Id":
+			st.Name = v
+		case "NRestarts":
+			// NRestarts is not available with systemd < 235
+			if v != "" && v != "[not set]" {
+				st.Restarts, err = strconv.Atoi(v)
+			}
+		case "ExecMainCode":
+			code, err = strconv.Atoi(v)
+		case "ExecMainStatus":
+			status, err = strconv.Atoi(v)
+		case "ExecMainExitTimestamp":
+			if v != "" {
+				st.ExitTime, err = time.Parse("Mon 2006-01-02 15:04:05 MST", v)
+			}
+		case "Result":
+			st.Result = v
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %q", k, v)
+		}
+	}
+	switch code {
+	case cldExited:
+		st.ExitCode = status
+	case cldKilled, cldDumped:
+		st.ExitSignal = unix.SignalName(syscall.Signal(status))
+		if st.ExitSignal == "" {
+			st.ExitSignal = strconv.Itoa(status)
+		}
+	}
+	return st, nil
+}
+
 func (s *systemd) Status(unitNames []string) ([]*UnitStatus, error) {
 	if s.mode == GlobalUserMode {
 		return s.getGlobalUserStatus(unitNames...)
@@ -962,7 +1097,7 @@ func (s *systemd) Status(unitNames []string) ([]*UnitStatus, error) {
 	for _, name := range unitNames {
 		// Group units with the same query string together to
 		// optimize the number of 'systemctl' invocations.
-		if strings.HasSuffix(name, ".timer") || strings.HasSuffix(name, ".socket") || strings.HasSuffix(name, ".target") {
+		if strings.HasSuffix(name, ".timer") || strings.HasSuffix(name, ".socket") || strings.HasSuffix(name, ".target") || strings.HasSuffix(name, ".path") {
 			// Units using the baseProperties query
 			limitedUnits = append(limitedUnits, name)
 		} else {
@@ -1364,6 +1499,46 @@ func (l Log) PID() string {
 	return "-"
 }
 
+// Unit is the name of the systemd unit which logged the message, or "" if
+// there is none.
+func (l Log) Unit() string {
+	unit, err := l.parseLogRawMessageString("_SYSTEMD_UNIT", func([]string) (string, error) {
+		return "", fmt.Errorf("multiple units not supported")
+	})
+	if err != nil {
+		return ""
+	}
+	return unit
+}
+
+// Priority is the syslog priority of the message, from 0 (emerg) to 7
+// (debug), or -1 if it is missing or invalid.
+func (l Log) Priority() int {
+	prio, err := l.parseLogRawMessageString("PRIORITY", func([]string) (string, error) {
+		return "", fmt.Errorf("multiple priorities not supported")
+	})
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.Atoi(prio)
+	if err != nil || n < 0 || n > 7 {
+		return -1
+	}
+	return n
+}
+
+// Cursor is the journal cursor of the entry, which can be used to resume
+// reading the journal after it, or "" if there is none.
+func (l Log) Cursor() string {
+	cursor, err := l.parseLogRawMessageString("__CURSOR", func([]string) (string, error) {
+		return "", fmt.Errorf("multiple cursors not supported")
+	})
+	if err != nil {
+		return ""
+	}
+	return cursor
+}
+
 type UnitLifetime int
 
 const (
@@ -164,7 +164,7 @@ func (s *SystemdTestSuite) myRun(args ...string) (out []byte, delay time.Duratio
 	return out, delayReq, err
 }
 
-func (s *SystemdTestSuite) myJctl(svcs []string, n int, follow, namespaces bool) (io.ReadCloser, error) {
+func (s *SystemdTestSuite) myJctl(svcs []string, n int, follow, namespaces bool, query *LogQuery) (io.ReadCloser, error) {
 	var err error
 	var out []byte
 
@@ -938,7 +938,7 @@ func (s *SystemdTestSuite) TestKill(c *C) {
 func (s *SystemdTestSuite) TestLogErrJctl(c *C) {
 	s.jerrs = []error{errors.New("mock journalctl error")}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false, nil)
 	c.Check(err, NotNil)
 	c.Check(reader, IsNil)
 	c.Check(s.jns, DeepEquals, []string{"24"})
@@ -954,7 +954,7 @@ func (s *SystemdTestSuite) TestLogs(c *C) {
 `
 	s.jouts = [][]byte{[]byte(expected)}
 
-	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false)
+	reader, err := New(SystemMode, s.rep).LogReader([]string{"foo"}, 24, false, false, nil)
 	c.Check(err, IsNil)
 	logs, err := io.ReadAll(reader)
 	c.Assert(err, IsNil)
@@ -1128,6 +1128,24 @@ func (s *SystemdTestSuite) TestLogPID(c *C) {
 	}.PID(), Equals, "42")
 }
 
+func (s *SystemdTestSuite) TestLogUnit(c *C) {
+	c.Check(Log{}.Unit(), Equals, "")
+	c.Check(Log{"_SYSTEMD_UNIT": mustJSONMarshal("snap.foo.bar.service")}.Unit(), Equals, "snap.foo.bar.service")
+	c.Check(Log{"_SYSTEMD_UNIT": mustJSONMarshal([]string{"a", "b"})}.Unit(), Equals, "")
+}
+
+func (s *SystemdTestSuite) TestLogPriority(c *C) {
+	c.Check(Log{}.Priority(), Equals, -1)
+	c.Check(Log{"PRIORITY": mustJSONMarshal("3")}.Priority(), Equals, 3)
+	c.Check(Log{"PRIORITY": mustJSONMarshal("9")}.Priority(), Equals, -1)
+	c.Check(Log{"PRIORITY": mustJSONMarshal("high")}.Priority(), Equals, -1)
+}
+
+func (s *SystemdTestSuite) TestLogCursor(c *C) {
+	c.Check(Log{}.Cursor(), Equals, "")
+	c.Check(Log{"__CURSOR": mustJSONMarshal("s=abc;i=1")}.Cursor(), Equals, "s=abc;i=1")
+}
+
 func (s *SystemdTestSuite) TestTime(c *C) {
 	t, err := Log{}.Time()
 	c.Check(t.IsZero(), Equals, true)
@@ -1679,18 +1697,66 @@ func (s *SystemdTestSuite) TestJctl(c *C) {
 		return nil, nil
 	})
 
-	_, err = Jctl([]string{"foo", "bar"}, 10, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, 10, false, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "10", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar", "baz"}, 99, true, false)
+	_, err = Jctl([]string{"foo", "bar", "baz"}, 99, true, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "-n", "99", "-f", "-u", "foo", "-u", "bar", "-u", "baz"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, false)
+	_, err = Jctl([]string{"foo", "bar"}, -1, false, false, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "-u", "foo", "-u", "bar"})
-	_, err = Jctl([]string{"foo", "bar"}, -1, false, true)
+	_, err = Jctl([]string{"foo", "bar"}, -1, false, true, nil)
 	c.Assert(err, IsNil)
 	c.Check(args, DeepEquals, []string{"-o", "json", "--no-pager", "--no-tail", "--namespace=*", "-u", "foo", "-u", "bar"})
+
+	query := &LogQuery{
+		Since:       time.Unix(1700000000, 123456000),
+		Until:       time.Unix(1700003600, 0),
+		Priority:    "err..warning",
+		Grep:        "-f oops",
+		Boot:        "-1",
+		AfterCursor: "s=abc;i=1",
+	}
+	_, err = Jctl([]string{"foo"}, -1, true, false, query)
+	c.Assert(err, IsNil)
+	c.Check(args, DeepEquals, []string{
+		"-o", "json", "--no-pager", "--no-tail", "-f",
+		"--since=@1700000000.123456", "--until=@1700003600.000000",
+		"--priority=err..warning", "--grep=-f oops", "--boot=-1", "--after-cursor=s=abc;i=1",
+		"-u", "foo",
+	})
+}
+
+func (s *SystemdTestSuite) TestLogQueryValidate(c *C) {
+	now := time.Now()
+	for _, q := range []LogQuery{
+		{},
+		{Since: now, Until: now.Add(time.Hour)},
+		{Priority: "err"},
+		{Priority: "3"},
+		{Priority: "emerg..notice"},
+		{Boot: "0"},
+		{Boot: "-2"},
+		{Boot: "0123456789abcdef0123456789abcdef"},
+		{AfterCursor: "s=abc;i=1"},
+	} {
+		c.Check(q.Validate(), IsNil, Commentf("%+v", q))
+	}
+
+	for _, t := range []struct {
+		q   LogQuery
+		err string
+	}{
+		{LogQuery{Since: now, Until: now.Add(-time.Hour)}, "log query end time is before its start time"},
+		{LogQuery{Priority: "8"}, `invalid log priority "8"`},
+		{LogQuery{Priority: "loud"}, `invalid log priority "loud"`},
+		{LogQuery{Priority: "err..loud"}, `invalid log priority "loud"`},
+		{LogQuery{Boot: "last"}, `invalid boot "last": expected an offset or a boot ID`},
+		{LogQuery{AfterCursor: "a\nb"}, `invalid log cursor "a\\nb"`},
+	} {
+		c.Check(t.q.Validate(), ErrorMatches, t.err, Commentf("%+v", t.q))
+	}
 }
 
 func (s *SystemdTestSuite) TestIsActiveUnderRoot(c *C) {
@@ -2571,6 +2637,90 @@ func (s *SystemdTestSuite) TestInactiveEnterTimestampMalformedMore(c *C) {
 	c.Check(stamp.IsZero(), Equals, true)
 }
 
+func (s *SystemdTestSuite) TestRestartStatus(c *C) {
+	s.outs = [][]byte{
+		[]byte(`Id=foo.service
+NRestarts=3
+ExecMainCode=1
+ExecMainStatus=2
+ExecMainExitTimestamp=Fri 2021-04-16 15:32:21 UTC
+Result=exit-code
+
+Id=bar.service
+NRestarts=0
+ExecMainCode=2
+ExecMainStatus=15
+ExecMainExitTimestamp=Fri 2021-04-16 15:30:00 UTC
+Result=success
+
+Id=baz.service
+NRestarts=7
+ExecMainCode=3
+ExecMainStatus=11
+ExecMainExitTimestamp=Fri 2021-04-16 15:32:21 UTC
+Result=start-limit-hit
+
+Id=new.service
+NRestarts=0
+ExecMainCode=0
+ExecMainStatus=0
+ExecMainExitTimestamp=
+Result=success
+`),
+	}
+
+	sts, err := New(SystemMode, s.rep).RestartStatus([]string{"foo.service", "bar.service", "baz.service", "new.service"})
+	c.Assert(err, IsNil)
+	c.Check(s.argses, DeepEquals, [][]string{
+		{"show", "--property=Id,NRestarts,ExecMainCode,ExecMainStatus,ExecMainExitTimestamp,Result", "foo.service", "bar.service", "baz.service", "new.service"},
+	})
+	c.Check(sts, DeepEquals, []*ServiceRestartStatus{{
+		Name:     "foo.service",
+		Restarts: 3,
+		ExitCode: 2,
+		ExitTime: time.Date(2021, time.April, 16, 15, 32, 21, 0, time.UTC),
+		Result:   "exit-code",
+	}, {
+		Name:       "bar.service",
+		ExitSignal: "SIGTERM",
+		ExitTime:   time.Date(2021, time.April, 16, 15, 30, 0, 0, time.UTC),
+		Result:     "success",
+	}, {
+		Name:       "baz.service",
+		Restarts:   7,
+		ExitSignal: "SIGSEGV",
+		ExitTime:   time.Date(2021, time.April, 16, 15, 32, 21, 0, time.UTC),
+		Result:     "start-limit-hit",
+	}, {
+		Name:   "new.service",
+		Result: "success",
+	}})
+	c.Check(sts[0].Failed(), Equals, true)
+	c.Check(sts[1].Failed(), Equals, false)
+	c.Check(sts[2].Failed(), Equals, true)
+	c.Check(sts[3].Failed(), Equals, false)
+}
+
+func (s *SystemdTestSuite) TestRestartStatusErrors(c *C) {
+	s.outs = [][]byte{
+		[]byte("Id=foo.service\nNRestarts=1\n"),
+		[]byte("Id=bar.service\nNRestarts=1\n"),
+		[]byte("Id=foo.service\nNRestarts=many\n"),
+		[]byte("mocked failure"),
+	}
+	s.errors = []error{nil, nil, nil, fmt.Errorf("mocked failure")}
+	sysd := New(SystemMode, s.rep)
+
+	_, err := sysd.RestartStatus([]string{"foo.service", "bar.service"})
+	c.Check(err, ErrorMatches, `cannot get restart status: expected 2 results, got 1`)
+	_, err = sysd.RestartStatus([]string{"foo.service"})
+	c.Check(err, ErrorMatches, `cannot get restart status: queried status of "foo.service" but got status of "bar.service"`)
+	_, err = sysd.RestartStatus([]string{"foo.service"})
+	c.Check(err, ErrorMatches, `cannot get restart status of "foo.service": invalid value of NRestarts: "many"`)
+	_, err = sysd.RestartStatus([]string{"foo.service"})
+	c.Check(err, ErrorMatches, `mocked failure`)
+}
+
 func (s *SystemdTestSuite) TestSystemdRunError(c *C) {
 	sr := testutil.MockCommand(c, "systemd-run", `echo "fail"; exit 11`)
 	defer sr.Restore()
@@ -0,0 +1,74 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/snapcore/snapd/store"
+)
+
+type cmdReplay struct {
+	Addr    string `long:"addr" default:"localhost:11028" description:"Store address"`
+	Session string `long:"session" required:"yes" description:"Store session recorded with SNAPD_STORE_RECORD"`
+}
+
+var shortReplayHelp = "Serve a recorded store session"
+
+// waitForStop returns when the command is asked to stop.
+var waitForStop = func() {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+}
+
+func (x *cmdReplay) Execute(args []string) error {
+	f, err := os.Open(x.Session)
+	if err != nil {
+		return err
+	}
+	rp, err := store.NewReplayer(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	l, err := net.Listen("tcp", x.Addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %v", x.Addr, err)
+	}
+	srv := &http.Server{Handler: rp}
+	go srv.Serve(l)
+
+	waitForStop()
+
+	return srv.Close()
+}
+
+func init() {
+	if _, err := parser.AddCommand("replay", shortReplayHelp, "", &cmdReplay{}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,85 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type replaySuite struct{}
+
+var _ = Suite(&replaySuite{})
+
+const recordedSession = `{"method":"GET","url":"https://api.snapcraft.io/v2/snaps/info/foo","status":200,"response-header":{"Content-Type":["application/json"]},"response-body":"e30="}
+`
+
+func freeAddr(c *C) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func (s *replaySuite) TestReplay(c *C) {
+	session := filepath.Join(c.MkDir(), "session.json")
+	c.Assert(os.WriteFile(session, []byte(recordedSession), 0644), IsNil)
+	addr := freeAddr(c)
+
+	var status int
+	var contentType, body string
+	old := waitForStop
+	defer func() { waitForStop = old }()
+	waitForStop = func() {
+		rsp, err := http.Get("http://" + addr + "/v2/snaps/info/foo")
+		c.Assert(err, IsNil)
+		defer rsp.Body.Close()
+		data, err := io.ReadAll(rsp.Body)
+		c.Assert(err, IsNil)
+		status, contentType, body = rsp.StatusCode, rsp.Header.Get("Content-Type"), string(data)
+	}
+
+	_, err := parser.ParseArgs([]string{"replay", "--addr", addr, "--session", session})
+	c.Assert(err, IsNil)
+	c.Check(status, Equals, 200)
+	c.Check(contentType, Equals, "application/json")
+	c.Check(body, Equals, "{}")
+}
+
+func (s *replaySuite) TestReplayMissingSession(c *C) {
+	_, err := parser.ParseArgs([]string{"replay", "--session", filepath.Join(c.MkDir(), "missing.json")})
+	c.Check(err, ErrorMatches, "open .*/missing.json: no such file or directory")
+}
+
+func (s *replaySuite) TestReplayBadSession(c *C) {
+	session := filepath.Join(c.MkDir(), "session.json")
+	c.Assert(os.WriteFile(session, []byte("not json\n"), 0644), IsNil)
+
+	_, err := parser.ParseArgs([]string{"replay", "--session", session})
+	c.Check(err, ErrorMatches, "cannot read recorded store session: line 1: .*")
+}
@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// environmentValueEscaper escapes the characters with a special meaning in a
+// double quoted systemd Environment= assignment.
+var environmentValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`)
+
+// GenerateSnapServiceEnvironmentDropIn generates the systemd drop-in setting
+// the given environment overrides for a snap service.
+func GenerateSnapServiceEnvironmentDropIn(env map[string]string) []byte {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("[Service]\n# Auto-generated, DO NOT EDIT\n")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "Environment=\"%s=%s\"\n", k, environmentValueEscaper.Replace(env[k]))
+	}
+	return buf.Bytes()
+}
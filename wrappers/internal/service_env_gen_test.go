@@ -0,0 +1,44 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal_test
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/wrappers/internal"
+)
+
+type serviceEnvGenSuite struct{}
+
+var _ = Suite(&serviceEnvGenSuite{})
+
+func (s *serviceEnvGenSuite) TestGenerateSnapServiceEnvironmentDropIn(c *C) {
+	content := internal.GenerateSnapServiceEnvironmentDropIn(map[string]string{
+		"LOG_LEVEL": "debug",
+		"GREETING":  `say "hi" \o/ 100%`,
+		"EMPTY":     "",
+	})
+	c.Check(string(content), Equals, `[Service]
+# Auto-generated, DO NOT EDIT
+Environment="EMPTY="
+Environment="GREETING=say \"hi\" \\o/ 100%%"
+Environment="LOG_LEVEL=debug"
+`)
+}
@@ -0,0 +1,102 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/snapcore/snapd/logger"
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/systemd"
+)
+
+// pathTriggerDirectives maps the activates-on-path triggers to the
+// corresponding systemd.path(5) directives.
+var pathTriggerDirectives = map[string]string{
+	"path-exists":         "PathExists",
+	"path-exists-glob":    "PathExistsGlob",
+	"path-changed":        "PathChanged",
+	"path-modified":       "PathModified",
+	"directory-not-empty": "DirectoryNotEmpty",
+}
+
+// GenerateSnapServicePathUnitFile generates the systemd .path unit which
+// activates the service when one of its activates-on-path conditions is
+// met.
+func GenerateSnapServicePathUnitFile(app *snap.AppInfo) ([]byte, error) {
+	pathTemplate := `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path activation for snap application {{.App.Snap.InstanceName}}.{{.App.Name}}
+{{- if .MountUnit}}
+Requires={{.MountUnit}}
+After={{.MountUnit}}
+{{- end}}
+X-Snappy=yes
+
+[Path]
+Unit={{.ServiceFileName}}
+{{ range .Watches }}{{ . }}
+{{ end }}
+[Install]
+WantedBy={{.PathsTarget}}
+`
+	var templateOut bytes.Buffer
+	t := template.Must(template.New("path-wrapper").Parse(pathTemplate))
+
+	watches := make([]string, 0, len(app.ActivatesOnPath))
+	for _, p := range app.ActivatesOnPath {
+		directive, ok := pathTriggerDirectives[p.Trigger]
+		if !ok {
+			return nil, fmt.Errorf("internal error: unknown activates-on-path trigger %q", p.Trigger)
+		}
+		watches = append(watches, directive+"="+renderDaemonPath(app, p.Path))
+	}
+
+	wrapperData := struct {
+		App             *snap.AppInfo
+		ServiceFileName string
+		PathsTarget     string
+		MountUnit       string
+		Watches         []string
+	}{
+		App:             app,
+		ServiceFileName: filepath.Base(app.ServiceFile()),
+		PathsTarget:     systemd.PathsTarget,
+		Watches:         watches,
+	}
+	switch app.DaemonScope {
+	case snap.SystemDaemon:
+		wrapperData.MountUnit = filepath.Base(systemd.MountUnitPath(app.Snap.MountDir()))
+	case snap.UserDaemon:
+		// nothing
+	default:
+		panic("unknown snap.DaemonScope")
+	}
+
+	if err := t.Execute(&templateOut, wrapperData); err != nil {
+		// this can never happen, except we forget a variable
+		logger.Panicf("Unable to execute template: %v", err)
+	}
+
+	return templateOut.Bytes(), nil
+}
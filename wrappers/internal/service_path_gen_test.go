@@ -0,0 +1,127 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+
+/*
+ * Copyright (C) 2026 Canonical Ltd
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License version 3 as
+ * published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/snapcore/snapd/snap"
+	"github.com/snapcore/snapd/testutil"
+	"github.com/snapcore/snapd/wrappers/internal"
+)
+
+type servicePathUnitGenSuite struct {
+	testutil.BaseTest
+}
+
+var _ = Suite(&servicePathUnitGenSuite{})
+
+func (s *servicePathUnitGenSuite) TestGenerateSnapServicePathUnitFile(c *C) {
+	const expectedFmt = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path activation for snap application some-snap.app
+Requires=%s-some\x2dsnap-44.mount
+After=%s-some\x2dsnap-44.mount
+X-Snappy=yes
+
+[Path]
+Unit=snap.some-snap.app.service
+DirectoryNotEmpty=%s/inbox
+PathExistsGlob=%s/ready/*.flag
+PathChanged=%s/config
+
+[Install]
+WantedBy=paths.target
+`
+
+	si := &snap.Info{
+		SuggestedName: "some-snap",
+		Version:       "1.0",
+		SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+	}
+	service := &snap.AppInfo{
+		Snap:        si,
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.SystemDaemon,
+	}
+	service.ActivatesOnPath = []*snap.PathInfo{
+		{App: service, Path: "$SNAP_COMMON/inbox", Trigger: "directory-not-empty"},
+		{App: service, Path: "$SNAP_COMMON/ready/*.flag", Trigger: "path-exists-glob"},
+		{App: service, Path: "$SNAP_DATA/config", Trigger: "path-changed"},
+	}
+
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, nil)
+	c.Assert(err, IsNil)
+	c.Check(strings.Contains(string(generatedWrapper), "[Install]"), Equals, false)
+
+	generated, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Check(string(generated), Equals, fmt.Sprintf(expectedFmt, mountUnitPrefix, mountUnitPrefix,
+		si.CommonDataDir(), si.CommonDataDir(), si.DataDir()))
+}
+
+func (s *servicePathUnitGenSuite) TestGenerateSnapServicePathUnitFileUserDaemon(c *C) {
+	const expected = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Path activation for snap application some-snap.app
+X-Snappy=yes
+
+[Path]
+Unit=snap.some-snap.app.service
+PathModified=%h/snap/some-snap/common/inbox
+
+[Install]
+WantedBy=paths.target
+`
+
+	si := &snap.Info{
+		SuggestedName: "some-snap",
+		Version:       "1.0",
+		SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+	}
+	service := &snap.AppInfo{
+		Snap:        si,
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.UserDaemon,
+	}
+	service.ActivatesOnPath = []*snap.PathInfo{
+		{App: service, Path: "$SNAP_USER_COMMON/inbox", Trigger: "path-modified"},
+	}
+
+	generated, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Check(string(generated), Equals, expected)
+}
+
+func (s *servicePathUnitGenSuite) TestGenerateSnapServicePathUnitFileBadTrigger(c *C) {
+	si := &snap.Info{SuggestedName: "some-snap", SideInfo: snap.SideInfo{Revision: snap.R(44)}}
+	service := &snap.AppInfo{Snap: si, Name: "app", Daemon: "simple", DaemonScope: snap.SystemDaemon}
+	service.ActivatesOnPath = []*snap.PathInfo{{App: service, Path: "$SNAP_COMMON/inbox", Trigger: "path-gone"}}
+
+	_, err := internal.GenerateSnapServicePathUnitFile(service)
+	c.Check(err, ErrorMatches, `internal error: unknown activates-on-path trigger "path-gone"`)
+}
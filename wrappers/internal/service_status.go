@@ -255,5 +255,10 @@ func SnapServiceUnits(app *snap.AppInfo) (service string, activators []string) {
 	if app.Timer != nil {
 		activators = append(activators, filepath.Base(app.Timer.File()))
 	}
+
+	// Add application path activation
+	if len(app.ActivatesOnPath) > 0 {
+		activators = append(activators, filepath.Base(app.PathFile()))
+	}
 	return app.ServiceName(), activators
 }
@@ -32,6 +32,7 @@ import (
 	"github.com/snapcore/snapd/randutil"
 	"github.com/snapcore/snapd/snap"
 	"github.com/snapcore/snapd/systemd"
+	"github.com/snapcore/snapd/timeout"
 	"github.com/snapcore/snapd/timeutil"
 )
 
@@ -251,6 +252,12 @@ func generateOnCalendarSchedules(schedule []*timeutil.Schedule) []string {
 	return calendarEvents
 }
 
+// timerSeconds returns the duration in whole seconds, rounded up, as systemd
+// does not accept the exponent notation of large floating point numbers.
+func timerSeconds(t timeout.Timeout) int64 {
+	return int64((time.Duration(t) + time.Second - 1) / time.Second)
+}
+
 func GenerateSnapServiceTimerUnitFile(app *snap.AppInfo) ([]byte, error) {
 	timerTemplate := `[Unit]
 # Auto-generated, DO NOT EDIT
@@ -264,22 +271,37 @@ X-Snappy=yes
 [Timer]
 Unit={{.ServiceFileName}}
 {{ range .Schedules }}OnCalendar={{ . }}
+{{ end -}}
+{{- if .Timer.OnBoot }}OnBootSec={{ timerSeconds .Timer.OnBoot }}
+{{ end -}}
+{{- if .Timer.OnUnitActive }}OnUnitActiveSec={{ timerSeconds .Timer.OnUnitActive }}
+{{ end -}}
+{{- if .Timer.RandomizedDelay }}RandomizedDelaySec={{ timerSeconds .Timer.RandomizedDelay }}
+{{ end -}}
+{{- if .Timer.Persistent }}Persistent=true
 {{ end }}
 [Install]
 WantedBy={{.TimersTarget}}
 `
 	var templateOut bytes.Buffer
-	t := template.Must(template.New("timer-wrapper").Parse(timerTemplate))
+	tmpl := template.New("timer-wrapper")
+	tmpl.Funcs(template.FuncMap{
+		"timerSeconds": timerSeconds,
+	})
+	t := template.Must(tmpl.Parse(timerTemplate))
 
-	timerSchedule, err := timeutil.ParseSchedule(app.Timer.Timer)
-	if err != nil {
-		return nil, err
+	var schedules []string
+	if app.Timer.Timer != "" {
+		timerSchedule, err := timeutil.ParseSchedule(app.Timer.Timer)
+		if err != nil {
+			return nil, err
+		}
+		schedules = generateOnCalendarSchedules(timerSchedule)
 	}
 
-	schedules := generateOnCalendarSchedules(timerSchedule)
-
 	wrapperData := struct {
 		App             *snap.AppInfo
+		Timer           *snap.TimerInfo
 		ServiceFileName string
 		TimersTarget    string
 		TimerName       string
@@ -287,6 +309,7 @@ WantedBy={{.TimersTarget}}
 		Schedules       []string
 	}{
 		App:             app,
+		Timer:           app.Timer,
 		ServiceFileName: filepath.Base(app.ServiceFile()),
 		TimersTarget:    systemd.TimersTarget,
 		TimerName:       app.Name,
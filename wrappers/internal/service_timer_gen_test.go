@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
 	. "gopkg.in/check.v1"
 
@@ -82,6 +83,136 @@ WantedBy=timers.target
 	c.Assert(string(generatedWrapper), Equals, expectedService)
 }
 
+func (s *serviceTimerUnitGenSuite) TestServiceTimerUnitOptions(c *C) {
+	const expectedServiceFmt = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Timer app for snap application snap.app
+Requires=%s-snap-44.mount
+After=%s-snap-44.mount
+X-Snappy=yes
+
+[Timer]
+Unit=snap.snap.app.service
+OnCalendar=*-*-* 10:00
+OnCalendar=*-*-* 11:00
+OnBootSec=900
+RandomizedDelaySec=300
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`
+
+	expectedService := fmt.Sprintf(expectedServiceFmt, mountUnitPrefix, mountUnitPrefix)
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.SystemDaemon,
+		StopTimeout: timeout.DefaultTimeout,
+		Timer: &snap.TimerInfo{
+			Timer:           "10:00-12:00/2",
+			OnBoot:          timeout.Timeout(15 * time.Minute),
+			RandomizedDelay: timeout.Timeout(5 * time.Minute),
+			Persistent:      true,
+		},
+	}
+	service.Timer.App = service
+
+	generatedWrapper, err := internal.GenerateSnapServiceTimerUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Assert(string(generatedWrapper), Equals, expectedService)
+}
+
+func (s *serviceTimerUnitGenSuite) TestServiceTimerUnitMonotonic(c *C) {
+	const expectedServiceFmt = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Timer app for snap application snap.app
+Requires=%s-snap-44.mount
+After=%s-snap-44.mount
+X-Snappy=yes
+
+[Timer]
+Unit=snap.snap.app.service
+OnBootSec=60
+OnUnitActiveSec=3600
+
+[Install]
+WantedBy=timers.target
+`
+
+	expectedService := fmt.Sprintf(expectedServiceFmt, mountUnitPrefix, mountUnitPrefix)
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "oneshot",
+		DaemonScope: snap.SystemDaemon,
+		StopTimeout: timeout.DefaultTimeout,
+		Timer: &snap.TimerInfo{
+			OnBoot:       timeout.Timeout(time.Minute),
+			OnUnitActive: timeout.Timeout(time.Hour),
+		},
+	}
+	service.Timer.App = service
+
+	generatedWrapper, err := internal.GenerateSnapServiceTimerUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Assert(string(generatedWrapper), Equals, expectedService)
+}
+
+func (s *serviceTimerUnitGenSuite) TestServiceTimerUnitLongDurations(c *C) {
+	const expectedServiceFmt = `[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Timer app for snap application snap.app
+Requires=%s-snap-44.mount
+After=%s-snap-44.mount
+X-Snappy=yes
+
+[Timer]
+Unit=snap.snap.app.service
+OnBootSec=1209600
+OnUnitActiveSec=3628800
+RandomizedDelaySec=2
+
+[Install]
+WantedBy=timers.target
+`
+
+	expectedService := fmt.Sprintf(expectedServiceFmt, mountUnitPrefix, mountUnitPrefix)
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "oneshot",
+		DaemonScope: snap.SystemDaemon,
+		StopTimeout: timeout.DefaultTimeout,
+		Timer: &snap.TimerInfo{
+			OnBoot:          timeout.Timeout(14 * 24 * time.Hour),
+			OnUnitActive:    timeout.Timeout(6 * 7 * 24 * time.Hour),
+			RandomizedDelay: timeout.Timeout(1500 * time.Millisecond),
+		},
+	}
+	service.Timer.App = service
+
+	generatedWrapper, err := internal.GenerateSnapServiceTimerUnitFile(service)
+	c.Assert(err, IsNil)
+	c.Assert(string(generatedWrapper), Equals, expectedService)
+}
+
 func (s *serviceTimerUnitGenSuite) TestServiceTimerUnitBadTimer(c *C) {
 	service := &snap.AppInfo{
 		Snap: &snap.Info{
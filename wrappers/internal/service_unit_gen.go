@@ -49,6 +49,14 @@ type SnapServicesUnitOptions struct {
 	// CoreMountedSnapdSnapDep is whether the generated unit should depend on
 	// the provided snapd snapd being mounted
 	CoreMountedSnapdSnapDep string
+
+	// ExternalDependencies maps app names to the service units of other
+	// snaps that the app wants to be ordered after.
+	ExternalDependencies map[string][]string
+
+	// EnvironmentOverrides maps app names to environment variables set
+	// for the service in a drop-in of its unit.
+	EnvironmentOverrides map[string]map[string]string
 }
 
 func serviceStopTimeout(app *snap.AppInfo) time.Duration {
@@ -120,6 +128,9 @@ Requires={{.MountUnit}}
 {{- if .PrerequisiteTarget}}
 Wants={{.PrerequisiteTarget}}
 {{- end}}
+{{- if .ExternalDependencies}}
+Wants={{ stringsJoin .ExternalDependencies " " }}
+{{- end}}
 {{- if .After}}
 After={{ stringsJoin .After " " }}
 {{- end}}
@@ -184,7 +195,7 @@ Slice={{.SliceUnit}}
 {{- if .LogNamespace}}
 LogNamespace={{.LogNamespace}}
 {{- end}}
-{{- if not (or .App.Sockets .App.Timer .App.ActivatesOn) }}
+{{- if not (or .App.Sockets .App.Timer .App.ActivatesOn .App.ActivatesOnPath) }}
 
 [Install]
 WantedBy={{.ServicesTarget}}
@@ -254,6 +265,7 @@ WantedBy={{.ServicesTarget}}
 		BusName                  string
 		Before                   []string
 		After                    []string
+		ExternalDependencies     []string
 		InterfaceServiceSnippets string
 		SliceUnit                string
 		LogNamespace             string
@@ -313,6 +325,10 @@ WantedBy={{.ServicesTarget}}
 	if wrapperData.MountUnit != "" {
 		wrapperData.After = append([]string{wrapperData.MountUnit}, wrapperData.After...)
 	}
+	if deps := opts.ExternalDependencies[appInfo.Name]; len(deps) != 0 {
+		wrapperData.ExternalDependencies = deps
+		wrapperData.After = append(wrapperData.After, deps...)
+	}
 	if opts.CoreMountedSnapdSnapDep != "" {
 		wrapperData.CoreMountedSnapdSnapDep = []string{opts.CoreMountedSnapdSnapDep}
 	}
@@ -573,6 +573,51 @@ WantedBy=multi-user.target
 	}
 }
 
+func (s *serviceUnitGenSuite) TestServiceExternalDependencies(c *C) {
+	service := &snap.AppInfo{
+		Snap: &snap.Info{
+			SuggestedName: "snap",
+			Version:       "0.3.4",
+			SideInfo:      snap.SideInfo{Revision: snap.R(44)},
+		},
+		Name:        "app",
+		Command:     "bin/foo start",
+		Daemon:      "simple",
+		DaemonScope: snap.SystemDaemon,
+	}
+
+	opts := &internal.SnapServicesUnitOptions{
+		ExternalDependencies: map[string][]string{
+			"app":   {"snap.db.server.service", "snap.db.worker.service"},
+			"other": {"snap.other.svc.service"},
+		},
+	}
+	generatedWrapper, err := internal.GenerateSnapServiceUnitFile(service, opts)
+	c.Assert(err, IsNil)
+
+	c.Check(string(generatedWrapper), Equals, fmt.Sprintf(`[Unit]
+# Auto-generated, DO NOT EDIT
+Description=Service for snap application snap.app
+Requires=%s-snap-44.mount
+Wants=network.target
+Wants=snap.db.server.service snap.db.worker.service
+After=%s-snap-44.mount network.target snapd.apparmor.service snap.db.server.service snap.db.worker.service
+X-Snappy=yes
+
+[Service]
+EnvironmentFile=-/etc/environment
+ExecStart=/usr/bin/snap run snap.app
+SyslogIdentifier=snap.app
+Restart=on-failure
+WorkingDirectory=/var/snap/snap/44
+TimeoutStopSec=30
+Type=simple
+
+[Install]
+WantedBy=multi-user.target
+`, mountUnitPrefix, mountUnitPrefix))
+}
+
 func (s *serviceUnitGenSuite) TestKillModeSig(c *C) {
 	for _, rm := range []string{"sigterm", "sighup", "sigusr1", "sigusr2", "sigint"} {
 		service := &snap.AppInfo{
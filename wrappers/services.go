@@ -198,7 +198,7 @@ func reloadOrRestartServices(sysd systemd.Systemd, cli *userServiceClient, reloa
 }
 
 func serviceIsActivated(app *snap.AppInfo) bool {
-	return len(app.Sockets) > 0 || app.Timer != nil || len(app.ActivatesOn) > 0
+	return len(app.Sockets) > 0 || app.Timer != nil || len(app.ActivatesOn) > 0 || len(app.ActivatesOnPath) > 0
 }
 
 func serviceIsSlotActivated(app *snap.AppInfo) bool {
@@ -420,6 +420,12 @@ func tryFileUpdate(path string, desiredContent []byte) (old *osutil.MemoryFileSt
 	}
 }
 
+// serviceEnvironmentDropInFile returns the path of the drop-in carrying the
+// environment overrides of the service.
+func serviceEnvironmentDropInFile(app *snap.AppInfo) string {
+	return filepath.Join(app.ServiceFile()+".d", "snap-environment.conf")
+}
+
 type SnapServiceOptions struct {
 	// VitalityRank is the rank of all services in the specified snap used by
 	// the OOM killer when OOM conditions are reached.
@@ -427,11 +433,21 @@ type SnapServiceOptions struct {
 
 	// QuotaGroup is the quota group for the specified snap.
 	QuotaGroup *quota.Group
+
+	// ExternalDependencies maps app names of the snap to the service units
+	// of other snaps, connected through an interface, that the app should
+	// be ordered after.
+	ExternalDependencies map[string][]string
+
+	// EnvironmentOverrides maps app names of the snap to environment
+	// variables set for the service in a drop-in of its unit.
+	EnvironmentOverrides map[string]map[string]string
 }
 
 // ObserveChangeCallback can be invoked by EnsureSnapServices to observe
 // the previous content of a unit and the new on a change.
-// unitType can be "service", "socket", "timer". name is empty for a timer.
+// unitType can be "service", "socket", "timer", "path". name is empty for a
+// timer and a path.
 type ObserveChangeCallback func(app *snap.AppInfo, grp *quota.Group, unitType string, name, old, new string)
 
 // EnsureSnapServicesOptions is the set of options applying to the
@@ -568,6 +584,35 @@ func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *sna
 		return nil
 	}
 
+	handleFileRemoval := func(app *snap.AppInfo, unitType string, name, path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		st, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		if es.observeChange != nil {
+			es.observeChange(app, nil, unitType, name, string(content), "")
+		}
+		es.modifiedUnits[path] = &osutil.MemoryFileState{Content: content, Mode: st.Mode()}
+		switch app.DaemonScope {
+		case snap.SystemDaemon:
+			es.systemDaemonReloadNeeded = true
+		case snap.UserDaemon:
+			es.userDaemonReloadNeeded = true
+		}
+		return nil
+	}
+
 	// lets sort the service list before generating them for
 	// consistency when testing
 	services := snapInfo.Services()
@@ -610,6 +655,8 @@ func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *sna
 			QuotaGroup:              quotaGrp,
 			VitalityRank:            opts.VitalityRank,
 			CoreMountedSnapdSnapDep: opts.CoreMountedSnapdSnapDep,
+			ExternalDependencies:    opts.ExternalDependencies,
+			EnvironmentOverrides:    opts.EnvironmentOverrides,
 		})
 		if err != nil {
 			return err
@@ -620,6 +667,18 @@ func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *sna
 			return err
 		}
 
+		// the environment overrides go in a drop-in of the service unit,
+		// which is removed once there are no more overrides
+		envPath := serviceEnvironmentDropInFile(svc)
+		if env := opts.EnvironmentOverrides[svc.Name]; len(env) != 0 {
+			content := internal.GenerateSnapServiceEnvironmentDropIn(env)
+			if err := handleFileModification(svc, "service", svc.Name, envPath, content); err != nil {
+				return err
+			}
+		} else if err := handleFileRemoval(svc, "service", svc.Name, envPath); err != nil {
+			return err
+		}
+
 		// Generate systemd .socket files if needed
 		socketFiles, err := internal.GenerateSnapSocketUnitFiles(svc)
 		if err != nil {
@@ -642,6 +701,17 @@ func (es *ensureSnapServicesContext) ensureSnapServiceSystemdUnits(snapInfo *sna
 				return err
 			}
 		}
+
+		if len(svc.ActivatesOnPath) > 0 {
+			content, err := internal.GenerateSnapServicePathUnitFile(svc)
+			if err != nil {
+				return err
+			}
+			path := svc.PathFile()
+			if err := handleFileModification(svc, "path", "", path, content); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -662,8 +732,10 @@ func (es *ensureSnapServicesContext) ensureSnapsSystemdServices() (*quota.QuotaG
 
 		// always use RequireMountedSnapdSnap options from the global options
 		genServiceOpts := &internal.SnapServicesUnitOptions{
-			VitalityRank: snapSvcOpts.VitalityRank,
-			QuotaGroup:   snapSvcOpts.QuotaGroup,
+			VitalityRank:         snapSvcOpts.VitalityRank,
+			QuotaGroup:           snapSvcOpts.QuotaGroup,
+			ExternalDependencies: snapSvcOpts.ExternalDependencies,
+			EnvironmentOverrides: snapSvcOpts.EnvironmentOverrides,
 		}
 		if es.opts.RequireMountedSnapdSnap {
 			// on core 18+ systems, the snapd tooling is exported
@@ -1124,6 +1196,20 @@ func RemoveSnapServices(s *snap.Info, inter Interacter) error {
 			systemUnitFiles = append(systemUnitFiles, path)
 		}
 
+		if len(app.ActivatesOnPath) > 0 {
+			path := app.PathFile()
+
+			pathName := filepath.Base(path)
+			logger.Noticef("RemoveSnapServices - path %s", pathName)
+			switch app.DaemonScope {
+			case snap.SystemDaemon:
+				systemUnits = append(systemUnits, pathName)
+			case snap.UserDaemon:
+				userUnits = append(userUnits, pathName)
+			}
+			systemUnitFiles = append(systemUnitFiles, path)
+		}
+
 		logger.Noticef("RemoveSnapServices - disabling %s", serviceName)
 		switch app.DaemonScope {
 		case snap.SystemDaemon:
@@ -1131,7 +1217,8 @@ func RemoveSnapServices(s *snap.Info, inter Interacter) error {
 		case snap.UserDaemon:
 			userUnits = append(userUnits, serviceName)
 		}
-		systemUnitFiles = append(systemUnitFiles, app.ServiceFile())
+		envDropIn := serviceEnvironmentDropInFile(app)
+		systemUnitFiles = append(systemUnitFiles, app.ServiceFile(), envDropIn, filepath.Dir(envDropIn))
 	}
 
 	// disable all collected systemd units
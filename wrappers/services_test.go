@@ -229,6 +229,69 @@ WantedBy=multi-user.target
 	))
 }
 
+func (s *servicesTestSuite) TestEnsureSnapServicesEnvironmentOverrides(c *C) {
+	seen := make(map[string]string)
+	cb := func(app *snap.AppInfo, grp *quota.Group, unitType, name string, old, new string) {
+		seen[fmt.Sprintf("%s:%s:%s:%s", app.Snap.InstanceName(), app.Name, unitType, name)] = new
+	}
+
+	info := snaptest.MockSnap(c, packageHello, &snap.SideInfo{Revision: snap.R(12)})
+	dropIn := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap.hello-snap.svc1.service.d/snap-environment.conf")
+
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {EnvironmentOverrides: map[string]map[string]string{
+			"svc1": {"LOG_LEVEL": "debug"},
+		}},
+	}
+	err := wrappers.EnsureSnapServices(m, nil, cb, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"daemon-reload"},
+	})
+	c.Check(dropIn, testutil.FileEquals, `[Service]
+# Auto-generated, DO NOT EDIT
+Environment="LOG_LEVEL=debug"
+`)
+
+	// unchanged overrides do not cause a reload
+	s.sysdLog = nil
+	err = wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, HasLen, 0)
+
+	// the drop-in is removed with the last override
+	seen = make(map[string]string)
+	m[info] = nil
+	err = wrappers.EnsureSnapServices(m, nil, cb, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"daemon-reload"},
+	})
+	c.Check(seen, DeepEquals, map[string]string{
+		"hello-snap:svc1:service:svc1": "",
+	})
+	c.Check(osutil.FileExists(dropIn), Equals, false)
+}
+
+func (s *servicesTestSuite) TestRemoveSnapServicesRemovesEnvironmentOverrides(c *C) {
+	info := snaptest.MockSnap(c, packageHello, &snap.SideInfo{Revision: snap.R(12)})
+	dropIn := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap.hello-snap.svc1.service.d/snap-environment.conf")
+
+	m := map[*snap.Info]*wrappers.SnapServiceOptions{
+		info: {EnvironmentOverrides: map[string]map[string]string{
+			"svc1": {"LOG_LEVEL": "debug"},
+		}},
+	}
+	err := wrappers.EnsureSnapServices(m, nil, nil, progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(dropIn), Equals, true)
+
+	err = wrappers.RemoveSnapServices(info, &progress.Null)
+	c.Assert(err, IsNil)
+	c.Check(osutil.FileExists(dropIn), Equals, false)
+	c.Check(osutil.FileExists(filepath.Dir(dropIn)), Equals, false)
+}
+
 func (s *servicesTestSuite) TestEnsureSnapServicesWithQuotas(c *C) {
 	info := snaptest.MockSnap(c, packageHello, &snap.SideInfo{Revision: snap.R(12)})
 	svcFile := filepath.Join(dirs.GlobalRootDir, "/etc/systemd/system/snap.hello-snap.svc1.service")
@@ -4710,6 +4773,59 @@ func (s *servicesTestSuite) TestAddRemoveSnapWithTimersAddsRemovesTimerFiles(c *
 	c.Check(osutil.FileExists(app.ServiceFile()), Equals, false)
 }
 
+func (s *servicesTestSuite) TestStartSnapPathEnableStart(c *C) {
+	svc1Name := "snap.hello-snap.svc1.service"
+	svc2Path := "snap.hello-snap.svc2.path"
+
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  activates-on-path:
+   - path: $SNAP_COMMON/inbox
+     trigger: directory-not-empty
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	// fix the apps order to make the test stable
+	apps := []*snap.AppInfo{info.Apps["svc1"], info.Apps["svc2"]}
+	opts := &wrappers.StartServicesOptions{Enable: true}
+	err := wrappers.StartServices(apps, nil, opts, &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+	c.Check(s.sysdLog, DeepEquals, [][]string{
+		{"--no-reload", "enable", svc2Path, svc1Name},
+		{"daemon-reload"},
+		{"start", svc2Path},
+		{"start", svc1Name},
+	}, Commentf("calls: %v", s.sysdLog))
+}
+
+func (s *servicesTestSuite) TestAddRemoveSnapWithPathsAddsRemovesPathFiles(c *C) {
+	info := snaptest.MockSnap(c, packageHello+`
+ svc2:
+  command: bin/hello
+  daemon: simple
+  activates-on-path:
+   - path: $SNAP_COMMON/inbox
+     trigger: directory-not-empty
+`, &snap.SideInfo{Revision: snap.R(12)})
+
+	err := s.addSnapServices(info, false)
+	c.Assert(err, IsNil)
+
+	app := info.Apps["svc2"]
+	c.Check(app.PathFile(), testutil.FileContains, "DirectoryNotEmpty="+info.CommonDataDir()+"/inbox\n")
+	c.Check(app.ServiceFile(), Not(testutil.FileContains), "[Install]")
+
+	err = wrappers.StopServices(info.Services(), nil, "", &progress.Null, s.perfTimings)
+	c.Assert(err, IsNil)
+
+	err = wrappers.RemoveSnapServices(info, &progress.Null)
+	c.Assert(err, IsNil)
+
+	c.Check(osutil.FileExists(app.PathFile()), Equals, false)
+	c.Check(osutil.FileExists(app.ServiceFile()), Equals, false)
+}
+
 func (s *servicesTestSuite) TestFailedAddSnapCleansUp(c *C) {
 	info := snaptest.MockSnap(c, packageHello+`
  svc2: